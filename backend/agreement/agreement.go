@@ -0,0 +1,273 @@
+// Package agreement mengukur kesepakatan antara skor AI dan skor guru: quadratic weighted
+// kappa, galat absolut rata-rata, korelasi Pearson/Spearman, dan bias sistematis.
+package agreement
+
+import (
+	"errors"
+	"math"
+	"sistem-skripsi/backend/models"
+	"sort"
+	"strconv"
+)
+
+// Dimensi pengelompokan laporan
+const (
+	ByQuestion      = "question"
+	ByLevel         = "level"
+	ByAspect        = "aspect"
+	ByPromptVersion = "prompt_version"
+)
+
+// Skor 0–100 dibagi menjadi 11 kategori (0, 10, ..., 100) untuk menghitung kappa
+const kappaCategories = 11
+
+var ErrUnknownDimension = errors.New("dimensi pengelompokan tidak dikenal")
+
+// Pair adalah satu pasangan skor AI dan skor guru pada skala 0–100
+type Pair struct {
+	AI   float64
+	Guru float64
+}
+
+// Metrics berisi ukuran kesepakatan. Ukuran yang tidak terdefinisi (mis. korelasi dengan
+// kurang dari dua pasangan atau tanpa variasi skor) bernilai nil.
+type Metrics struct {
+	Jumlah   int      `json:"jumlah"`
+	QWK      *float64 `json:"qwk"`
+	MAE      float64  `json:"mae"`
+	Pearson  *float64 `json:"pearson"`
+	Spearman *float64 `json:"spearman"`
+	Bias     float64  `json:"bias"` // Rata-rata (AI − guru); positif berarti AI lebih murah hati
+}
+
+// Group adalah ukuran kesepakatan untuk satu nilai dimensi
+type Group struct {
+	Kunci      string `json:"kunci"`
+	Label      string `json:"label"`
+	Keterangan string `json:"keterangan,omitempty"`
+	Metrics
+}
+
+// Report berisi ukuran keseluruhan dan per kelompok, diurutkan dari MAE terbesar
+type Report struct {
+	Dimensi     string   `json:"dimensi"`
+	Keseluruhan Metrics  `json:"keseluruhan"`
+	Kelompok    []*Group `json:"kelompok"`
+}
+
+// Source menyediakan pasangan skor; dipenuhi oleh store.Store
+type Source interface {
+	GetAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error)
+	GetAspectAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error)
+}
+
+// Load mengambil pasangan skor yang sesuai dimensi dari src lalu menyusun laporannya
+func Load(src Source, filter models.AgreementFilter, by string) (*Report, error) {
+	if !IsDimension(by) {
+		return nil, ErrUnknownDimension
+	}
+	var pairs []*models.AgreementPair
+	var err error
+	if by == ByAspect {
+		pairs, err = src.GetAspectAgreementPairs(filter)
+	} else {
+		pairs, err = src.GetAgreementPairs(filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Build(pairs, by)
+}
+
+// IsDimension melaporkan apakah by adalah dimensi pengelompokan yang didukung
+func IsDimension(by string) bool {
+	switch by {
+	case ByQuestion, ByLevel, ByAspect, ByPromptVersion:
+		return true
+	}
+	return false
+}
+
+// Build mengelompokkan pasangan skor menurut dimensi by. Untuk ByAspect, pairs harus berupa
+// pasangan skor per aspek rubrik.
+func Build(pairs []*models.AgreementPair, by string) (*Report, error) {
+	if !IsDimension(by) {
+		return nil, ErrUnknownDimension
+	}
+	report := &Report{Dimensi: by, Kelompok: []*Group{}}
+	all := make([]Pair, len(pairs))
+	groups := map[string]*Group{}
+	grouped := map[string][]Pair{}
+	for i, p := range pairs {
+		all[i] = Pair{AI: p.SkorAI, Guru: p.SkorGuru}
+		key, label, note := groupKey(p, by)
+		if _, ok := groups[key]; !ok {
+			g := &Group{Kunci: key, Label: label, Keterangan: note}
+			groups[key] = g
+			report.Kelompok = append(report.Kelompok, g)
+		}
+		grouped[key] = append(grouped[key], all[i])
+	}
+
+	report.Keseluruhan = Compute(all)
+	for _, g := range report.Kelompok {
+		g.Metrics = Compute(grouped[g.Kunci])
+	}
+	sort.SliceStable(report.Kelompok, func(i, j int) bool {
+		if report.Kelompok[i].MAE != report.Kelompok[j].MAE {
+			return report.Kelompok[i].MAE > report.Kelompok[j].MAE
+		}
+		return report.Kelompok[i].Kunci < report.Kelompok[j].Kunci
+	})
+	return report, nil
+}
+
+func groupKey(p *models.AgreementPair, by string) (key, label, note string) {
+	switch by {
+	case ByQuestion:
+		return p.SoalID, p.TeksSoal, p.LevelKognitif
+	case ByLevel:
+		if p.LevelKognitif == "" {
+			return "-", "Tanpa level", ""
+		}
+		return p.LevelKognitif, p.LevelKognitif, ""
+	case ByAspect:
+		return p.RubricID, p.NamaAspek, p.TeksSoal
+	default:
+		if p.PromptVersi == 0 {
+			return p.LevelKognitif + "/-", p.LevelKognitif + " tanpa versi", ""
+		}
+		versi := strconv.Itoa(p.PromptVersi)
+		return p.LevelKognitif + "/v" + versi, p.LevelKognitif + " versi " + versi, ""
+	}
+}
+
+// Compute menghitung semua ukuran kesepakatan untuk sekumpulan pasangan skor
+func Compute(pairs []Pair) Metrics {
+	m := Metrics{Jumlah: len(pairs)}
+	if len(pairs) == 0 {
+		return m
+	}
+	ai := make([]float64, len(pairs))
+	guru := make([]float64, len(pairs))
+	var absSum, diffSum float64
+	for i, p := range pairs {
+		ai[i], guru[i] = p.AI, p.Guru
+		absSum += math.Abs(p.AI - p.Guru)
+		diffSum += p.AI - p.Guru
+	}
+	m.MAE = round(absSum / float64(len(pairs)))
+	m.Bias = round(diffSum / float64(len(pairs)))
+	m.QWK = optional(QuadraticWeightedKappa(pairs))
+	m.Pearson = optional(Pearson(ai, guru))
+	m.Spearman = optional(Pearson(ranks(ai), ranks(guru)))
+	return m
+}
+
+// QuadraticWeightedKappa menghitung kappa berbobot kuadratik atas kategori skor (skor/10
+// dibulatkan). ok bernilai false jika kesepakatan acak yang diharapkan tidak memiliki variasi.
+func QuadraticWeightedKappa(pairs []Pair) (kappa float64, ok bool) {
+	if len(pairs) == 0 {
+		return 0, false
+	}
+	var observed [kappaCategories][kappaCategories]float64
+	var histAI, histGuru [kappaCategories]float64
+	for _, p := range pairs {
+		a, g := category(p.AI), category(p.Guru)
+		observed[a][g]++
+		histAI[a]++
+		histGuru[g]++
+	}
+
+	n := float64(len(pairs))
+	var numerator, denominator float64
+	for i := 0; i < kappaCategories; i++ {
+		for j := 0; j < kappaCategories; j++ {
+			weight := float64((i-j)*(i-j)) / float64((kappaCategories-1)*(kappaCategories-1))
+			numerator += weight * observed[i][j]
+			denominator += weight * histAI[i] * histGuru[j] / n
+		}
+	}
+	if denominator == 0 {
+		return 0, false
+	}
+	return 1 - numerator/denominator, true
+}
+
+// Pearson menghitung koefisien korelasi Pearson. ok bernilai false jika kurang dari dua
+// pasangan atau salah satu deret tidak bervariasi.
+func Pearson(x, y []float64) (r float64, ok bool) {
+	if len(x) < 2 || len(x) != len(y) {
+		return 0, false
+	}
+	var meanX, meanY float64
+	for i := range x {
+		meanX += x[i]
+		meanY += y[i]
+	}
+	meanX /= float64(len(x))
+	meanY /= float64(len(y))
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
+
+// ranks mengembalikan peringkat (mulai 1) dengan rata-rata peringkat untuk nilai kembar,
+// sehingga korelasi Spearman = Pearson atas peringkat
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] < values[order[j]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && values[order[end]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end+1) / 2 // Rata-rata peringkat start+1 .. end
+		for k := start; k < end; k++ {
+			result[order[k]] = rank
+		}
+		start = end
+	}
+	return result
+}
+
+func category(score float64) int {
+	c := int(math.Round(score / 10))
+	if c < 0 {
+		return 0
+	}
+	if c >= kappaCategories {
+		return kappaCategories - 1
+	}
+	return c
+}
+
+func optional(v float64, ok bool) *float64 {
+	if !ok {
+		return nil
+	}
+	v = round4(v)
+	return &v
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,144 @@
+package agreement
+
+import (
+	"errors"
+	"math"
+	"sistem-skripsi/backend/models"
+	"testing"
+)
+
+func TestComputePerfectAgreement(t *testing.T) {
+	m := Compute([]Pair{{0, 0}, {50, 50}, {100, 100}})
+	if m.Jumlah != 3 || m.MAE != 0 || m.Bias != 0 {
+		t.Fatalf("Compute = %+v, ingin jumlah 3 tanpa galat", m)
+	}
+	for name, v := range map[string]*float64{"QWK": m.QWK, "Pearson": m.Pearson, "Spearman": m.Spearman} {
+		if v == nil || *v != 1 {
+			t.Errorf("%s = %v, ingin 1", name, v)
+		}
+	}
+}
+
+func TestComputeSystematicBias(t *testing.T) {
+	m := Compute([]Pair{{70, 60}, {80, 70}, {90, 80}, {60, 50}})
+	if m.MAE != 10 {
+		t.Errorf("MAE = %v, ingin 10", m.MAE)
+	}
+	if m.Bias != 10 {
+		t.Errorf("Bias = %v, ingin 10 (AI lebih murah hati)", m.Bias)
+	}
+	if m.Pearson == nil || *m.Pearson != 1 {
+		t.Errorf("Pearson = %v, ingin 1", m.Pearson)
+	}
+
+	m = Compute([]Pair{{40, 60}, {50, 55}})
+	if m.Bias != -12.5 || m.MAE != 12.5 {
+		t.Errorf("Compute = %+v, ingin MAE 12.5 dan bias -12.5", m)
+	}
+}
+
+func TestComputeUndefinedMetrics(t *testing.T) {
+	if m := Compute(nil); m.Jumlah != 0 || m.QWK != nil || m.Pearson != nil || m.Spearman != nil {
+		t.Errorf("Compute tanpa pasangan = %+v, ingin semua ukuran nil", m)
+	}
+	// Satu pasangan: korelasi tidak terdefinisi
+	if m := Compute([]Pair{{80, 70}}); m.Pearson != nil || m.Spearman != nil {
+		t.Errorf("korelasi satu pasangan = %v/%v, ingin nil", m.Pearson, m.Spearman)
+	}
+	// Skor guru tidak bervariasi
+	if m := Compute([]Pair{{60, 80}, {90, 80}}); m.Pearson != nil {
+		t.Errorf("Pearson tanpa variasi = %v, ingin nil", *m.Pearson)
+	}
+}
+
+func TestQuadraticWeightedKappa(t *testing.T) {
+	// Dua kategori bertetangga yang tertukar: kesepakatan lebih buruk daripada acak
+	k, ok := QuadraticWeightedKappa([]Pair{{0, 10}, {10, 0}})
+	if !ok || math.Abs(k-(-1)) > 1e-9 {
+		t.Errorf("QWK = %v (%v), ingin -1", k, ok)
+	}
+	// Semua skor di satu kategori: kesepakatan acak tidak bervariasi
+	if _, ok := QuadraticWeightedKappa([]Pair{{50, 52}, {48, 50}}); ok {
+		t.Error("QWK tanpa variasi kategori seharusnya tidak terdefinisi")
+	}
+	// Skor di luar 0–100 dimasukkan ke kategori ujung
+	if k, ok := QuadraticWeightedKappa([]Pair{{-5, 0}, {120, 100}}); !ok || k != 1 {
+		t.Errorf("QWK skor di luar rentang = %v (%v), ingin 1", k, ok)
+	}
+}
+
+func TestSpearmanUsesRanks(t *testing.T) {
+	m := Compute([]Pair{{10, 1}, {20, 2}, {30, 3}, {100, 4}})
+	if m.Spearman == nil || *m.Spearman != 1 {
+		t.Errorf("Spearman hubungan monoton = %v, ingin 1", m.Spearman)
+	}
+	if m.Pearson == nil || *m.Pearson >= 1 {
+		t.Errorf("Pearson hubungan tidak linear = %v, ingin < 1", m.Pearson)
+	}
+}
+
+func TestRanksAveragesTies(t *testing.T) {
+	got := ranks([]float64{30, 10, 30, 20})
+	want := []float64{3.5, 1, 3.5, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ranks = %v, ingin %v", got, want)
+		}
+	}
+}
+
+func TestBuildGroupsByLevel(t *testing.T) {
+	pairs := []*models.AgreementPair{
+		{SoalID: "s1", LevelKognitif: "C1", SkorAI: 80, SkorGuru: 80},
+		{SoalID: "s2", LevelKognitif: "C4", SkorAI: 90, SkorGuru: 60},
+		{SoalID: "s3", LevelKognitif: "", SkorAI: 70, SkorGuru: 60},
+	}
+	report, err := Build(pairs, ByLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Keseluruhan.Jumlah != 3 {
+		t.Errorf("jumlah keseluruhan = %d, ingin 3", report.Keseluruhan.Jumlah)
+	}
+	var keys []string
+	for _, g := range report.Kelompok {
+		keys = append(keys, g.Kunci)
+	}
+	// Diurutkan dari MAE terbesar
+	want := []string{"C4", "-", "C1"}
+	if len(keys) != len(want) {
+		t.Fatalf("kelompok = %v, ingin %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("kelompok = %v, ingin %v", keys, want)
+		}
+	}
+	if report.Kelompok[1].Label != "Tanpa level" {
+		t.Errorf("label level kosong = %q, ingin %q", report.Kelompok[1].Label, "Tanpa level")
+	}
+}
+
+func TestBuildGroupsByPromptVersion(t *testing.T) {
+	pairs := []*models.AgreementPair{
+		{LevelKognitif: "C2", PromptVersi: 3, SkorAI: 70, SkorGuru: 70},
+		{LevelKognitif: "C2", SkorAI: 70, SkorGuru: 70},
+	}
+	report, err := Build(pairs, ByPromptVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, g := range report.Kelompok {
+		got[g.Kunci] = g.Label
+	}
+	if got["C2/v3"] != "C2 versi 3" || got["C2/-"] != "C2 tanpa versi" {
+		t.Errorf("kelompok versi prompt = %v", got)
+	}
+}
+
+func TestBuildUnknownDimension(t *testing.T) {
+	if _, err := Build(nil, "kelas"); !errors.Is(err, ErrUnknownDimension) {
+		t.Errorf("Build dimensi tidak dikenal: err = %v, ingin ErrUnknownDimension", err)
+	}
+}
@@ -0,0 +1,176 @@
+// Package audit menyusun entri jejak audit: selisih keadaan sebelum dan sesudah aksi, serta
+// rantai hash yang membuat perubahan atau penghapusan entri lama dapat dideteksi. Setiap entri
+// menyimpan hash entri sebelumnya, dan hash-nya sendiri dihitung dari hash tersebut beserta
+// seluruh isi entri, sehingga mengubah satu entri memutus rantai semua entri sesudahnya.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sistem-skripsi/backend/models"
+	"strings"
+	"time"
+)
+
+// GenesisHash adalah hash "sebelumnya" untuk entri pertama
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Field yang tidak pernah disimpan di jejak audit; updated_at selalu berubah dan hanya
+// menambah derau
+var omittedFields = map[string]bool{
+	"password":      true,
+	"password_hash": true,
+	"secret":        true,
+	"private_key":   true,
+	"token":         true,
+	"updated_at":    true,
+}
+
+// Diff membandingkan representasi JSON before dan after lalu mengembalikan field yang berubah.
+// Salah satunya boleh nil, mis. saat data dibuat atau dihapus.
+func Diff(before any, after any) (map[string]models.AuditChange, error) {
+	b, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.AuditChange{}
+	for key, old := range b {
+		if value, ok := a[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = models.AuditChange{Sebelum: old, Sesudah: value}
+		}
+	}
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = models.AuditChange{Sesudah: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+func fields(v any) (map[string]any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for key := range m {
+		if omittedFields[strings.ToLower(key)] {
+			delete(m, key)
+		}
+	}
+	return m, nil
+}
+
+// hashInput menetapkan urutan field yang di-hash. Map dikodekan dengan kunci terurut oleh
+// encoding/json sehingga hasilnya stabil setelah disimpan dan dibaca ulang dari JSONB.
+type hashInput struct {
+	HashSebelumnya string                        `json:"hash_sebelumnya"`
+	AktorID        string                        `json:"aktor_id"`
+	AktorPeran     string                        `json:"aktor_peran"`
+	Aksi           string                        `json:"aksi"`
+	TargetJenis    string                        `json:"target_jenis"`
+	TargetID       string                        `json:"target_id"`
+	KelasID        string                        `json:"kelas_id"`
+	Perubahan      map[string]models.AuditChange `json:"perubahan"`
+	Metadata       map[string]any                `json:"metadata"`
+	IP             string                        `json:"ip"`
+	RequestID      string                        `json:"request_id"`
+	CreatedAt      string                        `json:"created_at"`
+}
+
+// Hash menghitung hash entri dari isi entri dan e.HashSebelumnya. CreatedAt dibulatkan ke
+// mikrodetik karena itulah presisi timestamp Postgres.
+func Hash(e *models.AuditEntry) (string, error) {
+	input := hashInput{
+		HashSebelumnya: e.HashSebelumnya,
+		AktorID:        e.AktorID,
+		AktorPeran:     e.AktorPeran,
+		Aksi:           e.Aksi,
+		TargetJenis:    e.TargetJenis,
+		TargetID:       e.TargetID,
+		KelasID:        e.KelasID,
+		IP:             e.IP,
+		RequestID:      e.RequestID,
+		CreatedAt:      Timestamp(e.CreatedAt).Format(time.RFC3339Nano),
+	}
+	// Map kosong dan nil disimpan sama-sama sebagai NULL. Isi map dinormalkan lewat JSON agar
+	// sama persis dengan nilai yang dibaca ulang dari database.
+	if len(e.Perubahan) > 0 {
+		if err := normalize(e.Perubahan, &input.Perubahan); err != nil {
+			return "", err
+		}
+	}
+	if len(e.Metadata) > 0 {
+		if err := normalize(e.Metadata, &input.Metadata); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func normalize(v any, target any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// Timestamp menormalkan waktu entri ke UTC dengan presisi mikrodetik
+func Timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Verifier memeriksa entri satu per satu sesuai urutan rantai
+type Verifier struct {
+	prev    string
+	checked int
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{prev: GenesisHash}
+}
+
+// Check mengembalikan alasan jika entri tidak menyambung ke entri sebelumnya atau isinya
+// tidak cocok dengan hash yang tersimpan
+func (v *Verifier) Check(e *models.AuditEntry) (string, error) {
+	if e.HashSebelumnya != v.prev {
+		return "hash sebelumnya tidak cocok; ada entri yang dihapus atau disisipkan", nil
+	}
+	hash, err := Hash(e)
+	if err != nil {
+		return "", err
+	}
+	if hash != e.Hash {
+		return "isi entri tidak cocok dengan hash-nya; entri telah diubah", nil
+	}
+	v.prev = e.Hash
+	v.checked++
+	return "", nil
+}
+
+// Checked mengembalikan jumlah entri yang lolos pemeriksaan
+func (v *Verifier) Checked() int {
+	return v.checked
+}
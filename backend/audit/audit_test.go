@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"encoding/json"
+	"sistem-skripsi/backend/models"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Nama      string    `json:"nama"`
+	Peran     string    `json:"peran"`
+	Password  string    `json:"password"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	before := &user{Nama: "Budi", Peran: "siswa", Password: "lama", UpdatedAt: time.Unix(1, 0)}
+	after := &user{Nama: "Budi", Peran: "guru", Password: "baru", UpdatedAt: time.Unix(2, 0)}
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Diff = %v, ingin hanya perubahan peran", changes)
+	}
+	if c := changes["peran"]; c.Sebelum != "siswa" || c.Sesudah != "guru" {
+		t.Errorf("perubahan peran = %+v", c)
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	var none *user
+	created, err := Diff(none, &user{Nama: "Ani", Peran: "siswa"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := created["nama"]; !ok || c.Sebelum != nil || c.Sesudah != "Ani" {
+		t.Errorf("perubahan saat dibuat = %v", created)
+	}
+	if _, ok := created["password"]; ok {
+		t.Error("password tidak boleh masuk jejak audit")
+	}
+
+	deleted, err := Diff(&user{Nama: "Ani"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := deleted["nama"]; c.Sebelum != "Ani" || c.Sesudah != nil {
+		t.Errorf("perubahan saat dihapus = %v", deleted)
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	changes, err := Diff(&user{Nama: "Ani", UpdatedAt: time.Unix(1, 0)}, &user{Nama: "Ani", UpdatedAt: time.Unix(2, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes != nil {
+		t.Errorf("Diff tanpa perubahan = %v, ingin nil", changes)
+	}
+}
+
+func entry(prev string, createdAt time.Time) *models.AuditEntry {
+	e := &models.AuditEntry{
+		AktorID:        "u1",
+		AktorPeran:     "guru",
+		Aksi:           "submission.override",
+		TargetJenis:    "submission",
+		TargetID:       "sub-1",
+		KelasID:        "k1",
+		Perubahan:      map[string]models.AuditChange{"skor_guru": {Sebelum: nil, Sesudah: 85}},
+		Metadata:       map[string]any{"alasan": "rubrik"},
+		CreatedAt:      createdAt,
+		HashSebelumnya: prev,
+	}
+	return e
+}
+
+func chain(t *testing.T, n int) []*models.AuditEntry {
+	t.Helper()
+	prev := GenesisHash
+	entries := make([]*models.AuditEntry, n)
+	for i := range entries {
+		e := entry(prev, time.Date(2026, 3, 10, 8, i, 0, 123456789, time.UTC))
+		hash, err := Hash(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Hash = hash
+		entries[i] = e
+		prev = hash
+	}
+	return entries
+}
+
+func TestHashStableAfterDatabaseRoundTrip(t *testing.T) {
+	e := entry(GenesisHash, time.Date(2026, 3, 10, 15, 0, 0, 123456789, time.FixedZone("WIB", 7*60*60)))
+	want, err := Hash(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nilai yang dibaca ulang dari database: waktu UTC presisi mikrodetik dan JSONB yang
+	// mengembalikan angka sebagai float64
+	var perubahan map[string]models.AuditChange
+	data, _ := json.Marshal(e.Perubahan)
+	if err := json.Unmarshal(data, &perubahan); err != nil {
+		t.Fatal(err)
+	}
+	stored := *e
+	stored.CreatedAt = Timestamp(e.CreatedAt)
+	stored.Perubahan = perubahan
+	if got, err := Hash(&stored); err != nil || got != want {
+		t.Errorf("hash setelah disimpan = %s (%v), ingin %s", got, err, want)
+	}
+
+	// Map kosong dan nil sama-sama disimpan sebagai NULL
+	empty := *e
+	empty.Metadata = map[string]any{}
+	none := *e
+	none.Metadata = nil
+	h1, _ := Hash(&empty)
+	h2, _ := Hash(&none)
+	if h1 != h2 {
+		t.Error("hash metadata kosong dan nil seharusnya sama")
+	}
+}
+
+func TestHashDependsOnContentAndPrevious(t *testing.T) {
+	base := entry(GenesisHash, time.Unix(1700000000, 0))
+	h, _ := Hash(base)
+
+	changed := *base
+	changed.TargetID = "sub-2"
+	if got, _ := Hash(&changed); got == h {
+		t.Error("mengubah target seharusnya mengubah hash")
+	}
+	relinked := *base
+	relinked.HashSebelumnya = h
+	if got, _ := Hash(&relinked); got == h {
+		t.Error("mengubah hash sebelumnya seharusnya mengubah hash")
+	}
+}
+
+func TestVerifierAcceptsIntactChain(t *testing.T) {
+	v := NewVerifier()
+	for _, e := range chain(t, 3) {
+		reason, err := v.Check(e)
+		if err != nil || reason != "" {
+			t.Fatalf("Check = %q (%v), ingin lolos", reason, err)
+		}
+	}
+	if v.Checked() != 3 {
+		t.Errorf("Checked = %d, ingin 3", v.Checked())
+	}
+}
+
+func TestVerifierDetectsTampering(t *testing.T) {
+	entries := chain(t, 3)
+	entries[1].Metadata = map[string]any{"alasan": "diubah"}
+	v := NewVerifier()
+	if reason, _ := v.Check(entries[0]); reason != "" {
+		t.Fatalf("entri pertama = %q, ingin lolos", reason)
+	}
+	if reason, _ := v.Check(entries[1]); reason == "" {
+		t.Error("entri yang diubah seharusnya ditolak")
+	}
+	if v.Checked() != 1 {
+		t.Errorf("Checked = %d, ingin 1", v.Checked())
+	}
+}
+
+func TestVerifierDetectsDeletion(t *testing.T) {
+	entries := chain(t, 3)
+	v := NewVerifier()
+	v.Check(entries[0])
+	if reason, _ := v.Check(entries[2]); reason == "" {
+		t.Error("rantai dengan entri yang dihapus seharusnya ditolak")
+	}
+}
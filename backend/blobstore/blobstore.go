@@ -0,0 +1,35 @@
+// Package blobstore menyediakan abstraksi penyimpanan file (materi, lampiran) yang dapat
+// memakai disk lokal maupun layanan kompatibel S3 seperti MinIO.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob tidak ditemukan")
+	ErrInvalidKey = errors.New("kunci blob tidak valid")
+)
+
+// Store adalah kontrak penyimpanan objek berbasis kunci (mis. "materials/<kelas>/<file>.pdf")
+type Store interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Kunci hanya boleh berupa path relatif tanpa segmen ".." agar tidak keluar dari root
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Command agreement mencetak laporan kesepakatan skor AI dengan skor guru.
+//
+//	go run ./cmd/agreement -by prompt_version -kelas <id> -format csv > kesepakatan.csv
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sistem-skripsi/backend/agreement"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+	"strconv"
+	"text/tabwriter"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	by := flag.String("by", agreement.ByLevel, "pengelompokan: question, level, aspect, atau prompt_version")
+	kelas := flag.String("kelas", "", "ID kelas (kosong: semua kelas)")
+	assignment := flag.String("assignment", "", "ID tugas (kosong: semua tugas)")
+	format := flag.String("format", "text", "format keluaran: text, csv, atau json")
+	flag.Parse()
+
+	if !agreement.IsDimension(*by) {
+		log.Fatalf("Dimensi %q tidak dikenal", *by)
+	}
+	pgStore, err := store.NewPostgresStore()
+	if err != nil {
+		log.Fatal("Gagal terhubung ke database:", err)
+	}
+
+	report, err := agreement.Load(pgStore, models.AgreementFilter{KelasID: *kelas, AssignmentID: *assignment}, *by)
+	if err != nil {
+		log.Fatal("Gagal menghitung kesepakatan skor:", err)
+	}
+
+	switch *format {
+	case "text":
+		err = writeText(os.Stdout, report)
+	case "csv":
+		err = writeCSV(os.Stdout, report)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	default:
+		log.Fatalf("Format %q tidak dikenal", *format)
+	}
+	if err != nil {
+		log.Fatal("Gagal menulis laporan:", err)
+	}
+}
+
+var header = []string{"kunci", "label", "jumlah", "qwk", "mae", "pearson", "spearman", "bias"}
+
+func row(key, label string, m agreement.Metrics) []string {
+	return []string{
+		key, label, strconv.Itoa(m.Jumlah), optional(m.QWK), formatFloat(m.MAE),
+		optional(m.Pearson), optional(m.Spearman), formatFloat(m.Bias),
+	}
+}
+
+func writeText(w io.Writer, report *agreement.Report) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Kesepakatan skor AI dan guru per %s\n\n", report.Dimensi)
+	writeTabRow(tw, header)
+	writeTabRow(tw, row("*", "Keseluruhan", report.Keseluruhan))
+	for _, g := range report.Kelompok {
+		label := g.Label
+		if len([]rune(label)) > 60 {
+			label = string([]rune(label)[:57]) + "..."
+		}
+		writeTabRow(tw, row(g.Kunci, label, g.Metrics))
+	}
+	return tw.Flush()
+}
+
+func writeTabRow(w io.Writer, cells []string) {
+	for i, c := range cells {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, c)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeCSV(w io.Writer, report *agreement.Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.Write(row("*", "Keseluruhan", report.Keseluruhan))
+	for _, g := range report.Kelompok {
+		cw.Write(row(g.Kunci, g.Label, g.Metrics))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func optional(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return formatFloat(*v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,350 @@
+// Command ltimock menjalankan platform LTI 1.3 tiruan untuk menguji integrasi LTI secara lokal
+// tanpa Moodle atau Canvas: login OIDC, peluncuran resource link dan deep linking, roster NRPS,
+// serta kolom nilai AGS yang mencatat setiap nilai yang diterima.
+//
+//	go run ./cmd/ltimock -addr :9100 -tool http://localhost:8080
+//
+// Daftarkan platform yang dicetak saat start di /api/admin/lti/platforms, lalu buka
+// http://localhost:9100/ untuk memilih pengguna dan jenis peluncuran.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"sistem-skripsi/backend/lti"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	clientID     = "sage-tool"
+	deploymentID = "mock-deployment"
+	contextID    = "mock-course-101"
+)
+
+type mockUser struct {
+	ID     string
+	Name   string
+	Email  string
+	Roles  []string
+	Status string
+}
+
+var users = []mockUser{
+	{ID: "guru-1", Name: "Guru Tiruan", Email: "guru.tiruan@example.com", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"}},
+	{ID: "siswa-1", Name: "Siswa Satu", Email: "siswa.satu@example.com", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"}},
+	{ID: "siswa-2", Name: "Siswa Dua", Email: "siswa.dua@example.com", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"}},
+	{ID: "siswa-3", Name: "Siswa Tiga", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"}, Status: "Inactive"},
+}
+
+type platform struct {
+	issuer   string
+	toolURL  string
+	key      *lti.SigningKey
+	toolKeys *lti.JWKSCache
+
+	mu        sync.Mutex
+	lineItems []*lti.LineItemResource
+}
+
+func main() {
+	addr := flag.String("addr", ":9100", "alamat server platform tiruan")
+	issuer := flag.String("issuer", "http://localhost:9100", "URL publik platform tiruan (issuer)")
+	toolURL := flag.String("tool", "http://localhost:8080", "URL publik backend (LTI_TOOL_URL)")
+	flag.Parse()
+
+	kid, privatePEM, err := lti.GenerateKey()
+	if err != nil {
+		log.Fatal("Gagal membuat kunci platform:", err)
+	}
+	private, err := lti.ParsePrivateKey(privatePEM)
+	if err != nil {
+		log.Fatal("Gagal membaca kunci platform:", err)
+	}
+	p := &platform{
+		issuer:   strings.TrimRight(*issuer, "/"),
+		toolURL:  strings.TrimRight(*toolURL, "/"),
+		key:      &lti.SigningKey{Kid: kid, Key: private},
+		toolKeys: lti.NewJWKSCache(nil),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", p.handleIndex)
+	mux.HandleFunc("GET /start", p.handleStart)
+	mux.HandleFunc("GET /jwks", p.handleJWKS)
+	mux.HandleFunc("GET /auth", p.handleAuth)
+	mux.HandleFunc("POST /auth", p.handleAuth)
+	mux.HandleFunc("POST /token", p.handleToken)
+	mux.HandleFunc("GET /nrps", p.handleMembers)
+	mux.HandleFunc("GET /lineitems", p.handleGetLineItems)
+	mux.HandleFunc("POST /lineitems", p.handleCreateLineItem)
+	mux.HandleFunc("POST /lineitems/{id}/scores", p.handleScore)
+	mux.HandleFunc("POST /deep-link-return", p.handleDeepLinkReturn)
+
+	registration, _ := json.MarshalIndent(map[string]any{
+		"nama":           "Platform Tiruan",
+		"issuer":         p.issuer,
+		"client_id":      clientID,
+		"auth_login_url": p.issuer + "/auth",
+		"auth_token_url": p.issuer + "/token",
+		"jwks_url":       p.issuer + "/jwks",
+		"deployment_ids": []string{deploymentID},
+	}, "", "  ")
+	log.Printf("Daftarkan platform ini di POST /api/admin/lti/platforms:\n%s", registration)
+	log.Printf("Platform LTI tiruan berjalan di %s", *addr)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatal(err)
+	}
+}
+
+var indexPage = template.Must(template.New("index").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Platform LTI Tiruan</title></head>
+<body>
+<h1>Platform LTI Tiruan</h1>
+<form action="/start" method="get">
+<p><label>Pengguna <select name="user">{{range .}}<option value="{{.ID}}">{{.Name}} ({{.ID}})</option>{{end}}</select></label></p>
+<p><label>Jenis <select name="type"><option value="resource">Resource link</option><option value="deeplink">Deep linking</option></select></label></p>
+<p><label>ID resource link <input name="resource_link" value="tautan-1"></label></p>
+<p><label>ID tugas (custom assignment_id, opsional) <input name="assignment_id"></label></p>
+<p><button type="submit">Luncurkan</button></p>
+</form>
+</body></html>`))
+
+var autoPost = template.Must(template.New("post").Parse(`<!doctype html>
+<html><body onload="document.forms[0].submit()">
+<form action="{{.Action}}" method="post">{{range $k, $v := .Fields}}<input type="hidden" name="{{$k}}" value="{{$v}}">{{end}}
+<noscript><button type="submit">Lanjutkan</button></noscript></form>
+</body></html>`))
+
+func (p *platform) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	indexPage.Execute(w, users)
+}
+
+// Memulai login OIDC pihak ketiga ke tool; pilihan peluncuran dibawa lewat lti_message_hint
+func (p *platform) handleStart(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	hint, _ := json.Marshal(map[string]string{
+		"type":          query.Get("type"),
+		"resource_link": query.Get("resource_link"),
+		"assignment_id": query.Get("assignment_id"),
+	})
+	target := url.Values{
+		"iss":              {p.issuer},
+		"client_id":        {clientID},
+		"login_hint":       {query.Get("user")},
+		"lti_message_hint": {string(hint)},
+		"target_link_uri":  {p.toolURL + "/api/lti/launch"},
+	}
+	http.Redirect(w, r, p.toolURL+"/api/lti/login?"+target.Encode(), http.StatusFound)
+}
+
+func (p *platform) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, "application/json", lti.JWKSet{Keys: []lti.JWK{lti.PublicJWK(p.key.Kid, &p.key.Key.PublicKey)}})
+}
+
+// Endpoint otorisasi: menerbitkan id_token bertanda tangan dan mengirimkannya ke redirect_uri tool
+func (p *platform) handleAuth(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("client_id") != clientID || r.Form.Get("response_type") != "id_token" || r.Form.Get("nonce") == "" {
+		http.Error(w, "permintaan otorisasi tidak valid", http.StatusBadRequest)
+		return
+	}
+	user, ok := findUser(r.Form.Get("login_hint"))
+	if !ok {
+		http.Error(w, "login_hint tidak dikenal", http.StatusBadRequest)
+		return
+	}
+	var hint map[string]string
+	json.Unmarshal([]byte(r.Form.Get("lti_message_hint")), &hint)
+
+	now := time.Now()
+	claims := lti.LaunchClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce:         r.Form.Get("nonce"),
+		Name:          user.Name,
+		Email:         user.Email,
+		Version:       lti.Version,
+		DeploymentID:  deploymentID,
+		TargetLinkURI: p.toolURL + "/api/lti/launch",
+		Roles:         user.Roles,
+		Context:       &lti.Context{ID: contextID, Label: "MK101", Title: "Kursus Tiruan 101"},
+		NRPS:          &lti.NamesRoleService{ContextMembershipsURL: p.issuer + "/nrps", ServiceVersions: []string{"2.0"}},
+		AGS: &lti.GradeService{
+			Scope:     []string{lti.ScopeLineItem, lti.ScopeLineItemReadonly, lti.ScopeScore},
+			LineItems: p.issuer + "/lineitems",
+		},
+	}
+	if hint["type"] == "deeplink" {
+		claims.MessageType = lti.MessageDeepLinking
+		claims.DeepLinking = &lti.DeepLinkingSettings{
+			ReturnURL:      p.issuer + "/deep-link-return",
+			AcceptTypes:    []string{"ltiResourceLink"},
+			AcceptMultiple: true,
+			Data:           "mock-data",
+		}
+	} else {
+		claims.MessageType = lti.MessageResourceLink
+		linkID := hint["resource_link"]
+		if linkID == "" {
+			linkID = "tautan-1"
+		}
+		claims.ResourceLink = &lti.ResourceLink{ID: linkID, Title: "Tugas " + linkID}
+		if id := hint["assignment_id"]; id != "" {
+			claims.Custom = map[string]string{"assignment_id": id}
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.key.Kid
+	idToken, err := token.SignedString(p.key.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	autoPost.Execute(w, map[string]any{
+		"Action": r.Form.Get("redirect_uri"),
+		"Fields": map[string]string{"id_token": idToken, "state": r.Form.Get("state")},
+	})
+}
+
+// Endpoint token OAuth2: memverifikasi client_assertion terhadap JWKS tool
+func (p *platform) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+	if err := p.verifyToolJWT(r.Context(), r.PostForm.Get("client_assertion"), p.issuer+"/token"); err != nil {
+		log.Printf("client_assertion ditolak: %v", err)
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	token, _ := lti.RandomToken()
+	writeJSON(w, "application/json", map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"scope":        r.PostForm.Get("scope"),
+	})
+}
+
+func (p *platform) handleMembers(w http.ResponseWriter, r *http.Request) {
+	members := []lti.Member{}
+	for _, u := range users {
+		status := u.Status
+		if status == "" {
+			status = "Active"
+		}
+		members = append(members, lti.Member{UserID: u.ID, Status: status, Name: u.Name, Email: u.Email, Roles: u.Roles})
+	}
+	writeJSON(w, "application/vnd.ims.lti-nrps.v2.membershipcontainer+json", map[string]any{
+		"id":      p.issuer + "/nrps",
+		"context": lti.Context{ID: contextID, Label: "MK101", Title: "Kursus Tiruan 101"},
+		"members": members,
+	})
+}
+
+func (p *platform) handleGetLineItems(w http.ResponseWriter, r *http.Request) {
+	linkID := r.URL.Query().Get("resource_link_id")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	items := []*lti.LineItemResource{}
+	for _, li := range p.lineItems {
+		if linkID == "" || li.ResourceLinkID == linkID {
+			items = append(items, li)
+		}
+	}
+	writeJSON(w, "application/vnd.ims.lis.v2.lineitemcontainer+json", items)
+}
+
+func (p *platform) handleCreateLineItem(w http.ResponseWriter, r *http.Request) {
+	var item lti.LineItemResource
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	item.ID = fmt.Sprintf("%s/lineitems/%d", p.issuer, len(p.lineItems)+1)
+	p.lineItems = append(p.lineItems, &item)
+	p.mu.Unlock()
+	log.Printf("Kolom nilai dibuat: %s (%s, resource link %s)", item.ID, item.Label, item.ResourceLinkID)
+	w.Header().Set("Content-Type", "application/vnd.ims.lis.v2.lineitem+json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+func (p *platform) handleScore(w http.ResponseWriter, r *http.Request) {
+	var score lti.Score
+	if err := json.NewDecoder(r.Body).Decode(&score); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Nilai diterima untuk kolom %s: pengguna %s = %.2f/%.0f (%s, %s)",
+		r.PathValue("id"), score.UserID, score.ScoreGiven, score.ScoreMaximum, score.ActivityProgress, score.GradingProgress)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Menerima respons deep linking dari tool dan menampilkan konten yang dipilih
+func (p *platform) handleDeepLinkReturn(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var response struct {
+		jwt.RegisteredClaims
+		ContentItems []lti.ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+	}
+	_, err := jwt.ParseWithClaims(r.PostForm.Get("JWT"), &response, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.toolKeys.Key(r.Context(), p.toolURL+"/api/lti/jwks", kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(clientID), jwt.WithAudience(p.issuer))
+	if err != nil {
+		http.Error(w, "respons deep linking tidak valid: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, item := range response.ContentItems {
+		log.Printf("Konten deep linking diterima: %s %q custom=%v", item.Type, item.Title, item.Custom)
+	}
+	writeJSON(w, "application/json", response.ContentItems)
+}
+
+func (p *platform) verifyToolJWT(ctx context.Context, raw string, audience string) error {
+	_, err := jwt.ParseWithClaims(raw, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.toolKeys.Key(ctx, p.toolURL+"/api/lti/jwks", kid)
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(clientID), jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	return err
+}
+
+func findUser(id string) (mockUser, bool) {
+	for _, u := range users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return mockUser{}, false
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_nama_lengkap;
+DROP INDEX IF EXISTS idx_users_peran;
+
+ALTER TABLE users DROP COLUMN updated_at;
+ALTER TABLE users DROP COLUMN token_version;
+ALTER TABLE users DROP COLUMN must_change_password;
+ALTER TABLE users DROP COLUMN is_active;
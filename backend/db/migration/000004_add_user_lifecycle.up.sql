@@ -0,0 +1,10 @@
+-- Kolom untuk siklus hidup akun (nonaktifkan, reset password, pencabutan token)
+ALTER TABLE users ADD COLUMN is_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+-- Dinaikkan setiap kali peran, status, atau password berubah agar token lama tidak berlaku
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+-- Index untuk pencarian dan filter daftar pengguna
+CREATE INDEX idx_users_peran ON users(peran);
+CREATE INDEX idx_users_nama_lengkap ON users(LOWER(nama_lengkap));
@@ -0,0 +1,19 @@
+-- Kelas tanpa pemilik tidak dapat dikembalikan ke skema lama tanpa menghapus materi dan
+-- jawaban siswanya; pemilik baru harus ditetapkan terlebih dahulu.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM classes WHERE guru_id IS NULL) THEN
+        RAISE EXCEPTION 'Masih ada kelas tanpa guru; tetapkan pemiliknya sebelum rollback';
+    END IF;
+END $$;
+
+ALTER TABLE classes DROP CONSTRAINT classes_guru_id_fkey;
+ALTER TABLE classes ALTER COLUMN guru_id SET NOT NULL;
+ALTER TABLE classes ADD CONSTRAINT classes_guru_id_fkey
+    FOREIGN KEY (guru_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE classes DROP COLUMN archived_at;
+
+ALTER TABLE users DROP COLUMN purged_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
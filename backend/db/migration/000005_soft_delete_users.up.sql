@@ -0,0 +1,17 @@
+-- Soft delete pengguna dengan masa retensi sebelum dianonimkan
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Pengguna yang masa retensinya lewat dianonimkan alih-alih dihapus, agar jawaban, hasil AI
+-- dan nilai yang terhubung lewat FK ON DELETE CASCADE tetap utuh di buku nilai dan analitik.
+ALTER TABLE users ADD COLUMN purged_at TIMESTAMP WITH TIME ZONE;
+
+-- Kelas dapat diarsipkan (hanya-baca) alih-alih ikut terhapus
+ALTER TABLE classes ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+-- Menghapus guru tidak lagi menghapus kelas beserta seluruh materi dan jawaban siswa.
+-- Kelas arsip yang pemiliknya dihapus permanen tetap tersimpan tanpa pemilik.
+ALTER TABLE classes DROP CONSTRAINT classes_guru_id_fkey;
+ALTER TABLE classes ALTER COLUMN guru_id DROP NOT NULL;
+ALTER TABLE classes ADD CONSTRAINT classes_guru_id_fkey
+    FOREIGN KEY (guru_id) REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS class_staff;
+
+DROP TYPE IF EXISTS class_staff_status;
+DROP TYPE IF EXISTS class_staff_role;
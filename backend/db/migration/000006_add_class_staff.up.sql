@@ -0,0 +1,18 @@
+-- Staf pengajar tambahan pada kelas. Pemilik kelas tetap ditentukan oleh classes.guru_id,
+-- sehingga tabel ini hanya menyimpan guru pendamping dan asisten.
+CREATE TYPE class_staff_role AS ENUM ('co_teacher', 'assistant');
+CREATE TYPE class_staff_status AS ENUM ('pending', 'accepted');
+
+CREATE TABLE class_staff (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kelas_id UUID NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    peran class_staff_role NOT NULL,
+    status class_staff_status NOT NULL DEFAULT 'pending',
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    invited_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(kelas_id, user_id)
+);
+
+CREATE INDEX idx_class_staff_user_id ON class_staff(user_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_classes_guru_id;
+
+ALTER TABLE classes DROP COLUMN cloned_from;
+ALTER TABLE classes DROP COLUMN updated_at;
+ALTER TABLE classes DROP COLUMN semester;
+ALTER TABLE classes DROP COLUMN tahun_ajaran;
+ALTER TABLE classes DROP COLUMN mata_pelajaran;
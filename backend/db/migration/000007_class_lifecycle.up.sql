@@ -0,0 +1,10 @@
+-- Informasi periode dan mata pelajaran kelas
+ALTER TABLE classes ADD COLUMN mata_pelajaran VARCHAR(255);
+ALTER TABLE classes ADD COLUMN tahun_ajaran VARCHAR(20); -- contoh: 2025/2026
+ALTER TABLE classes ADD COLUMN semester VARCHAR(10);     -- ganjil atau genap
+ALTER TABLE classes ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+-- Kelas asal ketika kelas dibuat dengan fitur clone
+ALTER TABLE classes ADD COLUMN cloned_from UUID REFERENCES classes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_classes_guru_id ON classes(guru_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_materials_kelas_id;
+
+ALTER TABLE materials DROP COLUMN updated_at;
+ALTER TABLE materials DROP COLUMN ukuran_file;
+ALTER TABLE materials DROP COLUMN tipe_file;
+ALTER TABLE materials DROP COLUMN nama_file;
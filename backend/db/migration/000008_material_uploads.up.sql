@@ -0,0 +1,7 @@
+-- Metadata file materi yang diunggah. file_url berisi kunci objek pada blobstore.
+ALTER TABLE materials ADD COLUMN nama_file VARCHAR(255);
+ALTER TABLE materials ADD COLUMN tipe_file VARCHAR(150);
+ALTER TABLE materials ADD COLUMN ukuran_file BIGINT;
+ALTER TABLE materials ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+CREATE INDEX idx_materials_kelas_id ON materials(kelas_id);
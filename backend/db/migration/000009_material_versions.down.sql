@@ -0,0 +1,9 @@
+ALTER TABLE ai_results DROP COLUMN chunk_ids;
+ALTER TABLE ai_results DROP COLUMN material_version_id;
+
+ALTER TABLE materials DROP COLUMN versi_terkini;
+
+DROP TABLE IF EXISTS material_chunks;
+DROP TABLE IF EXISTS material_versions;
+
+DROP FUNCTION IF EXISTS reject_immutable_update();
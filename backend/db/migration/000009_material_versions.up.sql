@@ -0,0 +1,47 @@
+-- Versi materi yang tidak dapat diubah. Setiap perubahan konten membuat versi baru
+-- sehingga konteks yang dilihat AI saat menilai selalu dapat ditelusuri.
+CREATE TABLE material_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    materi_id UUID NOT NULL REFERENCES materials(id) ON DELETE CASCADE,
+    nomor_versi INTEGER NOT NULL,
+    judul VARCHAR(255) NOT NULL,
+    isi_materi TEXT,
+    file_url TEXT,
+    content_hash CHAR(64) NOT NULL, -- sha256 hex dari judul + isi_materi
+    editor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(materi_id, nomor_versi)
+);
+
+-- Potongan (chunk) konteks RAG milik satu versi materi
+CREATE TABLE material_chunks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    versi_id UUID NOT NULL REFERENCES material_versions(id) ON DELETE CASCADE,
+    urutan INTEGER NOT NULL,
+    isi TEXT NOT NULL,
+    UNIQUE(versi_id, urutan)
+);
+
+CREATE FUNCTION reject_immutable_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'Baris pada tabel % tidak dapat diubah', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER material_versions_immutable BEFORE UPDATE ON material_versions
+    FOR EACH ROW EXECUTE FUNCTION reject_immutable_update();
+CREATE TRIGGER material_chunks_immutable BEFORE UPDATE ON material_chunks
+    FOR EACH ROW EXECUTE FUNCTION reject_immutable_update();
+
+ALTER TABLE materials ADD COLUMN versi_terkini INTEGER NOT NULL DEFAULT 1;
+
+-- Versi awal untuk materi yang sudah ada. Chunk-nya dibuat aplikasi saat pertama kali dibutuhkan.
+INSERT INTO material_versions (materi_id, nomor_versi, judul, isi_materi, file_url, content_hash, editor_id, created_at)
+SELECT id, 1, judul, isi_materi, file_url,
+       encode(sha256(convert_to(judul || E'\n\n' || COALESCE(isi_materi, ''), 'UTF8')), 'hex'),
+       pengunggah_id, created_at
+FROM materials;
+
+-- Jejak konteks yang dipakai saat AI menilai
+ALTER TABLE ai_results ADD COLUMN material_version_id UUID REFERENCES material_versions(id) ON DELETE SET NULL;
+ALTER TABLE ai_results ADD COLUMN chunk_ids UUID[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_class_members_siswa_id;
+DROP INDEX IF EXISTS idx_essay_submissions_assignment;
+
+ALTER TABLE essay_submissions DROP COLUMN penalti_persen;
+ALTER TABLE essay_submissions DROP COLUMN hari_terlambat;
+ALTER TABLE essay_submissions DROP COLUMN assignment_id;
+
+DROP TABLE IF EXISTS assignment_extensions;
+DROP TABLE IF EXISTS assignment_questions;
+DROP TABLE IF EXISTS assignments;
@@ -0,0 +1,51 @@
+-- Tugas mengelompokkan soal esai sebuah kelas dengan jadwal pengerjaan.
+-- Semua waktu disimpan dalam UTC; zona_waktu (nama IANA) dipakai untuk menafsirkan
+-- input tanpa offset dan untuk menampilkan jadwal kepada pengguna.
+CREATE TABLE assignments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kelas_id UUID NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    judul VARCHAR(255) NOT NULL,
+    deskripsi TEXT,
+    dibuka_pada TIMESTAMP WITH TIME ZONE NOT NULL,
+    ditutup_pada TIMESTAMP WITH TIME ZONE NOT NULL,
+    batas_terlambat TIMESTAMP WITH TIME ZONE, -- NULL: pengumpulan terlambat tidak diterima
+    penalti_per_hari FLOAT NOT NULL DEFAULT 0, -- persen nilai per hari keterlambatan
+    zona_waktu VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    dibuat_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ditutup_pada > dibuka_pada),
+    CHECK (batas_terlambat IS NULL OR batas_terlambat >= ditutup_pada),
+    CHECK (penalti_per_hari >= 0 AND penalti_per_hari <= 100)
+);
+
+CREATE INDEX idx_assignments_kelas_id ON assignments(kelas_id);
+
+CREATE TABLE assignment_questions (
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    soal_id UUID NOT NULL REFERENCES essay_questions(id) ON DELETE CASCADE,
+    urutan INTEGER NOT NULL,
+    PRIMARY KEY (assignment_id, soal_id)
+);
+
+-- Perpanjangan tenggat untuk siswa tertentu; menggantikan ditutup_pada dan batas_terlambat tugas
+CREATE TABLE assignment_extensions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    siswa_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ditutup_pada TIMESTAMP WITH TIME ZONE NOT NULL,
+    batas_terlambat TIMESTAMP WITH TIME ZONE,
+    alasan TEXT,
+    diberikan_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(assignment_id, siswa_id),
+    CHECK (batas_terlambat IS NULL OR batas_terlambat >= ditutup_pada)
+);
+
+-- Jawaban yang dikumpulkan melalui tugas beserta keterlambatannya
+ALTER TABLE essay_submissions ADD COLUMN assignment_id UUID REFERENCES assignments(id) ON DELETE SET NULL;
+ALTER TABLE essay_submissions ADD COLUMN hari_terlambat INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE essay_submissions ADD COLUMN penalti_persen FLOAT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_essay_submissions_assignment ON essay_submissions(assignment_id, siswa_id);
+CREATE INDEX idx_class_members_siswa_id ON class_members(siswa_id);
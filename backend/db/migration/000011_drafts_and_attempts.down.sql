@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_essay_submissions_attempt;
+ALTER TABLE essay_submissions DROP COLUMN percobaan_ke;
+
+DROP TABLE IF EXISTS essay_drafts;
+
+ALTER TABLE assignments DROP COLUMN kebijakan_nilai;
+ALTER TABLE assignments DROP COLUMN maks_percobaan;
+
+DROP TYPE IF EXISTS score_policy;
@@ -0,0 +1,25 @@
+-- Cara menentukan nilai yang dihitung jika siswa mengumpulkan lebih dari sekali
+CREATE TYPE score_policy AS ENUM ('best', 'latest', 'average');
+
+ALTER TABLE assignments ADD COLUMN maks_percobaan INTEGER NOT NULL DEFAULT 1 CHECK (maks_percobaan >= 1);
+ALTER TABLE assignments ADD COLUMN kebijakan_nilai score_policy NOT NULL DEFAULT 'latest';
+
+-- Draf jawaban yang disimpan otomatis. versi naik setiap kali disimpan dan dipakai untuk
+-- mendeteksi penyimpanan dari tab/perangkat lain (optimistic concurrency).
+CREATE TABLE essay_drafts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    soal_id UUID NOT NULL REFERENCES essay_questions(id) ON DELETE CASCADE,
+    siswa_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    teks_jawaban TEXT NOT NULL DEFAULT '',
+    versi INTEGER NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(assignment_id, soal_id, siswa_id)
+);
+
+-- Setiap pengumpulan adalah satu percobaan dengan hasil AI-nya sendiri
+ALTER TABLE essay_submissions ADD COLUMN percobaan_ke INTEGER NOT NULL DEFAULT 1;
+
+CREATE UNIQUE INDEX idx_essay_submissions_attempt
+    ON essay_submissions(assignment_id, soal_id, siswa_id, percobaan_ke)
+    WHERE assignment_id IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS exam_integrity_events;
+DROP TABLE IF EXISTS exam_sessions;
+
+ALTER TABLE assignments DROP CONSTRAINT IF EXISTS assignments_durasi_ujian;
+ALTER TABLE assignments DROP COLUMN durasi_menit;
+ALTER TABLE assignments DROP COLUMN mode_ujian;
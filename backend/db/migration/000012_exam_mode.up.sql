@@ -0,0 +1,35 @@
+-- Mode ujian: soal dikerjakan dalam batas waktu yang dihitung server sejak siswa memulai
+ALTER TABLE assignments ADD COLUMN mode_ujian BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE assignments ADD COLUMN durasi_menit INTEGER;
+ALTER TABLE assignments ADD CONSTRAINT assignments_durasi_ujian
+    CHECK (NOT mode_ujian OR (durasi_menit IS NOT NULL AND durasi_menit > 0));
+
+-- Sesi ujian per siswa. Token hanya disimpan dalam bentuk hash sha256.
+CREATE TABLE exam_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    siswa_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL,
+    dimulai_pada TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    berakhir_pada TIMESTAMP WITH TIME ZONE NOT NULL,
+    selesai_pada TIMESTAMP WITH TIME ZONE,
+    alasan_selesai VARCHAR(20), -- 'dikumpulkan' atau 'waktu_habis'
+    ip_awal VARCHAR(64),
+    ip_terakhir VARCHAR(64),
+    UNIQUE(assignment_id, siswa_id)
+);
+
+CREATE INDEX idx_exam_sessions_berjalan ON exam_sessions(berakhir_pada) WHERE selesai_pada IS NULL;
+
+-- Catatan integritas selama ujian: dilaporkan klien (tab_blur, paste, ...) atau dideteksi server (ip_change)
+CREATE TABLE exam_integrity_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES exam_sessions(id) ON DELETE CASCADE,
+    jenis VARCHAR(30) NOT NULL,
+    detail TEXT,
+    ip VARCHAR(64),
+    terjadi_pada TIMESTAMP WITH TIME ZONE, -- Waktu menurut klien, jika dilaporkan
+    dicatat_pada TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_exam_integrity_events_session ON exam_integrity_events(session_id, dicatat_pada);
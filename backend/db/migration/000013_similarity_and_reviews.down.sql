@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_essay_submissions_soal_id;
+
+ALTER TABLE teacher_reviews DROP COLUMN reviewer_id;
+
+DROP TABLE IF EXISTS similarity_flags;
+DROP TYPE IF EXISTS similarity_flag_status;
+DROP TYPE IF EXISTS similarity_flag_type;
+DROP TABLE IF EXISTS submission_fingerprints;
@@ -0,0 +1,35 @@
+-- Sidik jari teks jawaban untuk deteksi kemiripan. minhash disimpan sebagai BIGINT[]
+-- (uint64 yang dibaca sebagai bilangan bertanda).
+CREATE TABLE submission_fingerprints (
+    submission_id UUID PRIMARY KEY REFERENCES essay_submissions(id) ON DELETE CASCADE,
+    minhash BIGINT[] NOT NULL,
+    simhash BIGINT NOT NULL,
+    jumlah_shingle INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TYPE similarity_flag_type AS ENUM ('antar_jawaban', 'materi');
+CREATE TYPE similarity_flag_status AS ENUM ('baru', 'dikonfirmasi', 'diabaikan');
+
+-- Tanda kemiripan. Untuk antar_jawaban, submission_id adalah jawaban yang lebih baru dan
+-- pembanding_id jawaban lama yang mirip; untuk materi, pembanding_id kosong.
+CREATE TABLE similarity_flags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    submission_id UUID NOT NULL REFERENCES essay_submissions(id) ON DELETE CASCADE,
+    pembanding_id UUID REFERENCES essay_submissions(id) ON DELETE CASCADE,
+    jenis similarity_flag_type NOT NULL,
+    metode VARCHAR(20) NOT NULL, -- minhash, simhash, atau containment
+    skor FLOAT NOT NULL,
+    status similarity_flag_status NOT NULL DEFAULT 'baru',
+    ditinjau_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_similarity_flags_unique
+    ON similarity_flags(submission_id, COALESCE(pembanding_id, '00000000-0000-0000-0000-000000000000'::uuid), jenis);
+CREATE INDEX idx_similarity_flags_pembanding ON similarity_flags(pembanding_id);
+
+-- Penilai pada tinjauan guru
+ALTER TABLE teacher_reviews ADD COLUMN reviewer_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_essay_submissions_soal_id ON essay_submissions(soal_id);
@@ -0,0 +1,4 @@
+ALTER TABLE ai_results DROP COLUMN prompt_template_id;
+
+DROP TABLE IF EXISTS prompt_template_aktif;
+DROP TABLE IF EXISTS prompt_templates;
@@ -0,0 +1,124 @@
+-- Template prompt penilaian (Go text/template) per level kognitif. Setiap perubahan membuat
+-- versi baru; versi lama tidak dapat diubah agar hasil penilaian dapat ditelusuri.
+CREATE TABLE prompt_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    level_kognitif cognitive_level NOT NULL,
+    versi INTEGER NOT NULL,
+    isi TEXT NOT NULL,
+    catatan TEXT,
+    dibuat_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(level_kognitif, versi)
+);
+
+CREATE TRIGGER prompt_templates_immutable BEFORE UPDATE ON prompt_templates
+    FOR EACH ROW EXECUTE FUNCTION reject_immutable_update();
+
+-- Versi yang dipakai saat menilai untuk setiap level
+CREATE TABLE prompt_template_aktif (
+    level_kognitif cognitive_level PRIMARY KEY,
+    template_id UUID NOT NULL REFERENCES prompt_templates(id),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO prompt_templates (level_kognitif, versi, isi, catatan) VALUES ('C1', 1, $tpl$Anda adalah guru yang menilai jawaban esai siswa pada level kognitif Mengingat (C1).
+Nilai apakah siswa dapat menyebutkan kembali fakta, istilah, dan definisi dengan tepat. Utamakan ketepatan dan kelengkapan informasi dibanding gaya bahasa.
+
+Soal:
+{{.Soal}}
+{{if .KunciJawaban}}
+Kunci jawaban:
+{{.KunciJawaban}}
+{{end}}
+Aspek penilaian:
+{{range .Rubrik}}- {{.NamaAspek}} (bobot {{.Bobot}}){{if .Deskripsi}}: {{.Deskripsi}}{{end}}
+{{else}}- Ketepatan dan kelengkapan jawaban (bobot 1)
+{{end}}
+Kutipan materi:
+{{range .Konteks}}[{{.ID}}]
+{{.Isi}}
+
+{{else}}(tidak ada kutipan materi)
+{{end}}
+Jawaban siswa:
+{{.Jawaban}}
+
+Beri skor 0-100 untuk setiap aspek dan gunakan hanya informasi dari soal, kunci jawaban, dan kutipan materi di atas.$tpl$, 'Template awal');
+
+INSERT INTO prompt_templates (level_kognitif, versi, isi, catatan) VALUES ('C2', 1, $tpl$Anda adalah guru yang menilai jawaban esai siswa pada level kognitif Memahami (C2).
+Nilai apakah siswa dapat menjelaskan konsep dengan kata-katanya sendiri, memberi contoh, atau merangkum. Jawaban yang hanya menyalin teks materi tanpa penjelasan tidak mendapat skor penuh.
+
+Soal:
+{{.Soal}}
+{{if .KunciJawaban}}
+Kunci jawaban:
+{{.KunciJawaban}}
+{{end}}
+Aspek penilaian:
+{{range .Rubrik}}- {{.NamaAspek}} (bobot {{.Bobot}}){{if .Deskripsi}}: {{.Deskripsi}}{{end}}
+{{else}}- Ketepatan dan kelengkapan jawaban (bobot 1)
+{{end}}
+Kutipan materi:
+{{range .Konteks}}[{{.ID}}]
+{{.Isi}}
+
+{{else}}(tidak ada kutipan materi)
+{{end}}
+Jawaban siswa:
+{{.Jawaban}}
+
+Beri skor 0-100 untuk setiap aspek dan gunakan hanya informasi dari soal, kunci jawaban, dan kutipan materi di atas.$tpl$, 'Template awal');
+
+INSERT INTO prompt_templates (level_kognitif, versi, isi, catatan) VALUES ('C3', 1, $tpl$Anda adalah guru yang menilai jawaban esai siswa pada level kognitif Menerapkan (C3).
+Nilai apakah siswa dapat menerapkan konsep atau prosedur pada situasi yang diberikan. Perhatikan ketepatan langkah dan hasil, bukan sekadar penyebutan konsep.
+
+Soal:
+{{.Soal}}
+{{if .KunciJawaban}}
+Kunci jawaban:
+{{.KunciJawaban}}
+{{end}}
+Aspek penilaian:
+{{range .Rubrik}}- {{.NamaAspek}} (bobot {{.Bobot}}){{if .Deskripsi}}: {{.Deskripsi}}{{end}}
+{{else}}- Ketepatan dan kelengkapan jawaban (bobot 1)
+{{end}}
+Kutipan materi:
+{{range .Konteks}}[{{.ID}}]
+{{.Isi}}
+
+{{else}}(tidak ada kutipan materi)
+{{end}}
+Jawaban siswa:
+{{.Jawaban}}
+
+Beri skor 0-100 untuk setiap aspek dan gunakan hanya informasi dari soal, kunci jawaban, dan kutipan materi di atas.$tpl$, 'Template awal');
+
+INSERT INTO prompt_templates (level_kognitif, versi, isi, catatan) VALUES ('C4', 1, $tpl$Anda adalah guru yang menilai jawaban esai siswa pada level kognitif Menganalisis (C4).
+Nilai apakah siswa dapat menguraikan masalah menjadi bagian-bagian, membandingkan, dan menjelaskan hubungan sebab-akibat dengan argumen yang didukung materi.
+
+Soal:
+{{.Soal}}
+{{if .KunciJawaban}}
+Kunci jawaban:
+{{.KunciJawaban}}
+{{end}}
+Aspek penilaian:
+{{range .Rubrik}}- {{.NamaAspek}} (bobot {{.Bobot}}){{if .Deskripsi}}: {{.Deskripsi}}{{end}}
+{{else}}- Ketepatan dan kelengkapan jawaban (bobot 1)
+{{end}}
+Kutipan materi:
+{{range .Konteks}}[{{.ID}}]
+{{.Isi}}
+
+{{else}}(tidak ada kutipan materi)
+{{end}}
+Jawaban siswa:
+{{.Jawaban}}
+
+Beri skor 0-100 untuk setiap aspek dan gunakan hanya informasi dari soal, kunci jawaban, dan kutipan materi di atas.$tpl$, 'Template awal');
+
+INSERT INTO prompt_template_aktif (level_kognitif, template_id)
+SELECT level_kognitif, id FROM prompt_templates WHERE versi = 1;
+
+-- Versi template yang dipakai untuk menghasilkan hasil AI
+ALTER TABLE ai_results ADD COLUMN prompt_template_id UUID REFERENCES prompt_templates(id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS grading_attempts;
+DROP TABLE IF EXISTS ai_aspect_scores;
+
+ALTER TABLE rubrics DROP CONSTRAINT IF EXISTS rubrics_skor_range_check;
+ALTER TABLE rubrics DROP COLUMN IF EXISTS skor_maks;
+ALTER TABLE rubrics DROP COLUMN IF EXISTS skor_min;
+
+ALTER TABLE ai_results DROP COLUMN status;
+DROP TYPE IF EXISTS ai_result_status;
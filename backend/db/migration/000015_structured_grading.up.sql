@@ -0,0 +1,37 @@
+-- Status hasil penilaian AI. Hasil gagal tidak memiliki skor; keluaran mentah model
+-- disimpan di logs_rag dan jawaban menunggu penilaian guru.
+CREATE TYPE ai_result_status AS ENUM ('berhasil', 'gagal');
+ALTER TABLE ai_results ADD COLUMN status ai_result_status NOT NULL DEFAULT 'berhasil';
+
+-- Rentang skor per aspek rubrik (mis. 1-4). Kosong berarti aspek dinilai 0-100.
+ALTER TABLE rubrics ADD COLUMN skor_min FLOAT;
+ALTER TABLE rubrics ADD COLUMN skor_maks FLOAT;
+ALTER TABLE rubrics ADD CONSTRAINT rubrics_skor_range_check
+    CHECK ((skor_min IS NULL AND skor_maks IS NULL) OR (skor_min IS NOT NULL AND skor_maks > skor_min));
+
+-- Skor per aspek rubrik beserta alasan dan chunk materi yang dikutip model
+CREATE TABLE ai_aspect_scores (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ai_result_id UUID NOT NULL REFERENCES ai_results(id) ON DELETE CASCADE,
+    rubric_id UUID REFERENCES rubrics(id) ON DELETE SET NULL,
+    nama_aspek VARCHAR(255) NOT NULL,
+    skor FLOAT NOT NULL,
+    alasan TEXT,
+    chunk_ids UUID[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_ai_aspect_scores_result ON ai_aspect_scores(ai_result_id);
+CREATE INDEX idx_ai_aspect_scores_rubric ON ai_aspect_scores(rubric_id);
+
+-- Percobaan penilaian otomatis yang gagal (mis. layanan model tidak dapat dihubungi).
+-- Jawaban dilewati antrean sampai next_attempt_at agar satu jawaban yang selalu gagal atau
+-- gangguan penyedia tidak menahan seluruh antrean.
+CREATE TABLE grading_attempts (
+    submission_id UUID PRIMARY KEY REFERENCES essay_submissions(id) ON DELETE CASCADE,
+    percobaan INT NOT NULL DEFAULT 0,
+    galat_terakhir TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_grading_attempts_next ON grading_attempts(next_attempt_at);
@@ -0,0 +1,12 @@
+DELETE FROM ai_results WHERE status = 'ditunda';
+ALTER TYPE ai_result_status RENAME TO ai_result_status_old;
+CREATE TYPE ai_result_status AS ENUM ('berhasil', 'gagal');
+ALTER TABLE ai_results
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN status TYPE ai_result_status USING status::text::ai_result_status,
+    ALTER COLUMN status SET DEFAULT 'berhasil';
+DROP TYPE ai_result_status_old;
+
+DROP TABLE IF EXISTS llm_budgets;
+DROP TABLE IF EXISTS llm_usage;
+DROP TABLE IF EXISTS llm_prices;
@@ -0,0 +1,48 @@
+-- Harga model per satu juta token (USD). Model dicocokkan sebagai awalan nama model pada
+-- respons, mis. harga "gemini-1.5-flash" berlaku untuk "gemini-1.5-flash-002".
+CREATE TABLE llm_prices (
+    provider VARCHAR(50) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    harga_prompt_per_juta NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    harga_completion_per_juta NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (provider, model)
+);
+
+INSERT INTO llm_prices (provider, model, harga_prompt_per_juta, harga_completion_per_juta) VALUES
+    ('gemini', 'gemini-1.5-flash', 0.075, 0.30),
+    ('gemini', 'gemini-1.5-pro', 1.25, 5.00);
+
+-- Satu baris per panggilan model. Kelas dan guru (pemilik kelas) disalin saat pencatatan
+-- agar laporan tetap benar walaupun kelas berpindah pemilik.
+CREATE TABLE llm_usage (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    submission_id UUID REFERENCES essay_submissions(id) ON DELETE SET NULL,
+    kelas_id UUID REFERENCES classes(id) ON DELETE SET NULL,
+    guru_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    provider VARCHAR(50) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    biaya_usd NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    keluaran_valid BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_llm_usage_created_at ON llm_usage(created_at);
+CREATE INDEX idx_llm_usage_guru ON llm_usage(guru_id, created_at);
+CREATE INDEX idx_llm_usage_kelas ON llm_usage(kelas_id, created_at);
+
+-- Anggaran bulanan (USD). guru_id kosong berarti anggaran seluruh sekolah.
+CREATE TABLE llm_budgets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    guru_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    batas_usd NUMERIC(12, 4) NOT NULL CHECK (batas_usd >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_llm_budgets_sekolah ON llm_budgets((guru_id IS NULL)) WHERE guru_id IS NULL;
+
+-- Jawaban yang tidak dinilai otomatis karena anggaran habis; menunggu penilaian guru
+ALTER TYPE ai_result_status ADD VALUE 'ditunda';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS analytics_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS mastery_rollup;
+DROP VIEW IF EXISTS counted_submission_scores;
+DROP VIEW IF EXISTS submission_final_scores;
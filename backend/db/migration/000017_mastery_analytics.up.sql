@@ -0,0 +1,85 @@
+-- Nilai akhir setiap jawaban: nilai guru jika sudah ditinjau, selain itu skor AI yang berhasil.
+-- Nilai belum dikurangi penalti keterlambatan karena dipakai untuk mengukur penguasaan.
+CREATE VIEW submission_final_scores AS
+SELECT es.id AS submission_id,
+       es.siswa_id,
+       es.soal_id,
+       es.assignment_id,
+       m.kelas_id,
+       q.level_kognitif,
+       COALESCE(tr.skor_final, ar.skor_ai) AS skor,
+       CASE WHEN tr.id IS NOT NULL THEN 'guru' ELSE 'ai' END AS sumber,
+       es.submitted_at
+FROM essay_submissions es
+JOIN essay_questions q ON q.id = es.soal_id
+JOIN materials m ON m.id = q.materi_id
+LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+LEFT JOIN ai_results ar ON ar.submission_id = es.id AND ar.status = 'berhasil'
+WHERE tr.id IS NOT NULL OR ar.skor_ai IS NOT NULL;
+
+-- Nilai yang dihitung per siswa, soal dan tugas mengikuti kebijakan nilai tugas (best, latest,
+-- average) seperti scoring.Counted dan buku nilai. Jawaban di luar tugas memakai latest.
+-- Untuk latest, percobaan terakhir yang belum dinilai berarti belum ada nilai yang dihitung.
+CREATE VIEW counted_submission_scores AS
+SELECT *
+FROM (
+    SELECT kelas_id,
+           siswa_id,
+           soal_id,
+           assignment_id,
+           level_kognitif,
+           CASE kebijakan
+               WHEN 'best' THEN MAX(skor)
+               WHEN 'average' THEN AVG(skor)
+               ELSE MAX(skor) FILTER (WHERE urutan_terbalik = 1)
+           END AS skor,
+           MAX(submitted_at) FILTER (WHERE skor IS NOT NULL) AS submitted_at
+    FROM (
+        SELECT es.siswa_id,
+               es.soal_id,
+               es.assignment_id,
+               m.kelas_id,
+               q.level_kognitif,
+               COALESCE(tr.skor_final, ar.skor_ai) AS skor,
+               es.submitted_at,
+               COALESCE(a.kebijakan_nilai::text, 'latest') AS kebijakan,
+               ROW_NUMBER() OVER (
+                   PARTITION BY es.siswa_id, es.soal_id, es.assignment_id
+                   ORDER BY es.percobaan_ke DESC, es.submitted_at DESC
+               ) AS urutan_terbalik
+        FROM essay_submissions es
+        JOIN essay_questions q ON q.id = es.soal_id
+        JOIN materials m ON m.id = q.materi_id
+        LEFT JOIN assignments a ON a.id = es.assignment_id
+        LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+        LEFT JOIN ai_results ar ON ar.submission_id = es.id AND ar.status = 'berhasil'
+    ) attempts
+    GROUP BY kelas_id, siswa_id, soal_id, assignment_id, level_kognitif, kebijakan
+) counted
+WHERE skor IS NOT NULL;
+
+-- Rekap penguasaan: satu baris per nilai yang dihitung beserta rentang nilainya (10 rentang
+-- selebar 10). Pengelompokan per minggu atau bulan dilakukan saat query dengan zona waktu
+-- yang dikonfigurasi. Diperbarui berkala dengan REFRESH MATERIALIZED VIEW CONCURRENTLY.
+CREATE MATERIALIZED VIEW mastery_rollup AS
+SELECT kelas_id,
+       siswa_id,
+       soal_id,
+       COALESCE(assignment_id::text, '') AS tugas_kunci,
+       level_kognitif,
+       skor,
+       GREATEST(1, LEAST(width_bucket(skor, 0, 100, 10), 10)) AS rentang,
+       submitted_at
+FROM counted_submission_scores
+WHERE level_kognitif IS NOT NULL;
+
+CREATE UNIQUE INDEX idx_mastery_rollup_key ON mastery_rollup(siswa_id, soal_id, tugas_kunci);
+CREATE INDEX idx_mastery_rollup_kelas ON mastery_rollup(kelas_id, level_kognitif);
+
+-- Waktu terakhir materialized view diperbarui
+CREATE TABLE analytics_refreshes (
+    nama VARCHAR(100) PRIMARY KEY,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO analytics_refreshes (nama, refreshed_at) VALUES ('mastery_rollup', NOW());
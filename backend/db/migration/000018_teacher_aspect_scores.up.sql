@@ -0,0 +1,7 @@
+-- Skor guru per aspek rubrik (opsional) untuk membandingkan penilaian AI per aspek
+CREATE TABLE teacher_aspect_scores (
+    review_id UUID NOT NULL REFERENCES teacher_reviews(id) ON DELETE CASCADE,
+    rubric_id UUID NOT NULL REFERENCES rubrics(id) ON DELETE CASCADE,
+    skor FLOAT NOT NULL CHECK (skor >= 0 AND skor <= 100),
+    PRIMARY KEY (review_id, rubric_id)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS lti_deep_link_sessions;
+DROP TABLE IF EXISTS lti_resource_links;
+DROP TABLE IF EXISTS lti_contexts;
+DROP TABLE IF EXISTS lti_users;
+DROP TABLE IF EXISTS lti_login_states;
+DROP TABLE IF EXISTS lti_keys;
+DROP TABLE IF EXISTS lti_platforms;
@@ -0,0 +1,80 @@
+-- Platform LMS (Moodle, Canvas, dsb.) yang memakai aplikasi ini sebagai tool LTI 1.3
+CREATE TABLE lti_platforms (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    nama VARCHAR(255) NOT NULL,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    auth_login_url TEXT NOT NULL,
+    auth_token_url TEXT NOT NULL,
+    jwks_url TEXT NOT NULL,
+    deployment_ids TEXT[] NOT NULL DEFAULT '{}', -- Kosong: semua deployment diterima
+    -- Email dari klaim peluncuran hanya dipakai untuk menautkan akun yang sudah ada jika
+    -- superadmin menyatakan platform tersebut memverifikasi email penggunanya
+    trust_email BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (issuer, client_id)
+);
+
+-- Kunci RSA tool untuk menandatangani respons deep linking dan permintaan token layanan.
+-- Kunci yang dipensiunkan tetap dipublikasikan di JWKS sampai dihapus.
+CREATE TABLE lti_keys (
+    kid VARCHAR(64) PRIMARY KEY,
+    private_key TEXT NOT NULL,
+    aktif BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- State dan nonce inisiasi login OIDC; dipakai sekali saat peluncuran
+CREATE TABLE lti_login_states (
+    state VARCHAR(64) PRIMARY KEY,
+    nonce VARCHAR(64) NOT NULL,
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Pengguna platform (sub) yang dipetakan ke akun aplikasi
+CREATE TABLE lti_users (
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    sub TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (platform_id, sub)
+);
+
+CREATE INDEX idx_lti_users_user ON lti_users(user_id);
+
+-- Kursus platform yang ditautkan ke kelas
+CREATE TABLE lti_contexts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    context_id TEXT NOT NULL,
+    kelas_id UUID NOT NULL REFERENCES classes(id) ON DELETE CASCADE,
+    judul TEXT,
+    memberships_url TEXT, -- Layanan NRPS
+    lineitems_url TEXT,   -- Layanan AGS
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (platform_id, context_id)
+);
+
+-- Tautan aktivitas platform yang mengarah ke tugas
+CREATE TABLE lti_resource_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    context_id UUID NOT NULL REFERENCES lti_contexts(id) ON DELETE CASCADE,
+    resource_link_id TEXT NOT NULL,
+    assignment_id UUID REFERENCES assignments(id) ON DELETE SET NULL,
+    lineitem_url TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (context_id, resource_link_id)
+);
+
+-- Sesi deep linking antara peluncuran guru dan pemilihan tugas
+CREATE TABLE lti_deep_link_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    platform_id UUID NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    deployment_id TEXT NOT NULL,
+    return_url TEXT NOT NULL,
+    data TEXT,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kelas_id UUID REFERENCES classes(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,38 @@
+-- Kotak masuk notifikasi per pengguna. kunci mencegah notifikasi ganda untuk kejadian yang
+-- sama (mis. "tenggat_dekat:<assignment_id>") saat penjadwal berjalan berulang kali.
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    jenis VARCHAR(40) NOT NULL,
+    judul VARCHAR(255) NOT NULL,
+    isi TEXT NOT NULL,
+    tautan TEXT,
+    kelas_id UUID REFERENCES classes(id) ON DELETE CASCADE,
+    kunci VARCHAR(255),
+    dibaca_pada TIMESTAMP WITH TIME ZONE,
+    email_dikirim_pada TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, kunci)
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE dibaca_pada IS NULL;
+
+-- Preferensi per jenis notifikasi; tanpa baris berarti notifikasi dan email aktif
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    jenis VARCHAR(40) NOT NULL,
+    aktif BOOLEAN NOT NULL DEFAULT TRUE,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, jenis)
+);
+
+-- Satu ringkasan email per pengguna per hari (tanggal lokal)
+CREATE TABLE notification_digests (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tanggal DATE NOT NULL,
+    jumlah INT NOT NULL DEFAULT 0,
+    dikirim_pada TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (user_id, tanggal)
+);
@@ -0,0 +1,25 @@
+-- Slot jadwal terakhir yang sudah dijalankan per job; mencegah slot yang sama dijalankan ulang
+-- oleh instance lain
+CREATE TABLE scheduler_jobs (
+    nama VARCHAR(64) PRIMARY KEY,
+    jadwal_terakhir TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Riwayat eksekusi job terjadwal maupun manual
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job VARCHAR(64) NOT NULL,
+    pemicu VARCHAR(20) NOT NULL CHECK (pemicu IN ('jadwal', 'manual')),
+    dipicu_oleh UUID REFERENCES users(id) ON DELETE SET NULL,
+    jadwal TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(20) NOT NULL DEFAULT 'berjalan' CHECK (status IN ('berjalan', 'berhasil', 'gagal')),
+    jumlah_diproses INT NOT NULL DEFAULT 0,
+    galat TEXT,
+    instance VARCHAR(255) NOT NULL,
+    mulai_pada TIMESTAMP WITH TIME ZONE NOT NULL,
+    selesai_pada TIMESTAMP WITH TIME ZONE,
+    durasi_ms INT
+);
+
+CREATE INDEX idx_job_runs_job ON job_runs(job, mulai_pada DESC);
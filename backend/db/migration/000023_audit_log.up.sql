@@ -0,0 +1,40 @@
+-- Jejak audit aksi yang menyangkut keamanan dan nilai. Aktor dan target disimpan tanpa foreign
+-- key agar entri tetap utuh setelah pengguna atau kelas dihapus permanen.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    aktor_id UUID,
+    aktor_peran VARCHAR(20),
+    aksi VARCHAR(64) NOT NULL,
+    target_jenis VARCHAR(40),
+    target_id VARCHAR(64),
+    kelas_id UUID,
+    perubahan JSONB,
+    metadata JSONB,
+    ip VARCHAR(64),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- hash = SHA-256 atas hash_sebelumnya dan isi entri; lihat package audit
+    hash_sebelumnya CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_audit_log_created ON audit_log(created_at DESC);
+CREATE INDEX idx_audit_log_aktor ON audit_log(aktor_id, created_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_jenis, target_id);
+CREATE INDEX idx_audit_log_kelas ON audit_log(kelas_id) WHERE kelas_id IS NOT NULL;
+CREATE INDEX idx_audit_log_request ON audit_log(request_id) WHERE request_id IS NOT NULL;
+
+-- Jejak audit hanya boleh ditambah
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log hanya dapat ditambah, tidak dapat diubah atau dihapus';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,94 @@
+// Package deadline menghitung status jadwal pengerjaan tugas dan penalti keterlambatan.
+package deadline
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Zona waktu default untuk tugas yang tidak menyebutkan zona waktunya
+const DefaultZone = "Asia/Jakarta"
+
+// Status pengerjaan tugas pada suatu waktu
+const (
+	StatusBelumDibuka = "belum_dibuka"
+	StatusDibuka      = "dibuka"
+	StatusTerlambat   = "terlambat" // Lewat tenggat, masih diterima dengan penalti
+	StatusDitutup     = "ditutup"
+)
+
+var ErrInvalidTime = errors.New("deadline: format waktu tidak valid")
+
+// Window adalah jadwal efektif seorang siswa, sudah memperhitungkan perpanjangan
+type Window struct {
+	DibukaPada     time.Time
+	DitutupPada    time.Time
+	BatasTerlambat *time.Time // nil: pengumpulan terlambat tidak diterima
+	PenaltiPerHari float64    // persen per hari keterlambatan
+}
+
+type Result struct {
+	Status        string  `json:"status"`
+	HariTerlambat int     `json:"hari_terlambat"`
+	PenaltiPersen float64 `json:"penalti_persen"`
+}
+
+// Evaluate menentukan status pada waktu now. Keterlambatan dihitung per 24 jam yang
+// dimulai sejak tenggat, sehingga terlambat satu menit sudah dihitung satu hari.
+func (w Window) Evaluate(now time.Time) Result {
+	switch {
+	case now.Before(w.DibukaPada):
+		return Result{Status: StatusBelumDibuka}
+	case !now.After(w.DitutupPada):
+		return Result{Status: StatusDibuka}
+	case w.BatasTerlambat == nil || now.After(*w.BatasTerlambat):
+		return Result{Status: StatusDitutup}
+	}
+
+	days := int(math.Ceil(now.Sub(w.DitutupPada).Hours() / 24))
+	return Result{
+		Status:        StatusTerlambat,
+		HariTerlambat: days,
+		PenaltiPersen: math.Min(100, float64(days)*w.PenaltiPerHari),
+	}
+}
+
+// Accepting melaporkan apakah jawaban masih dapat dikumpulkan
+func (r Result) Accepting() bool {
+	return r.Status == StatusDibuka || r.Status == StatusTerlambat
+}
+
+// ApplyPenalty mengurangi skor sesuai persentase penalti
+func ApplyPenalty(score float64, penaltiPersen float64) float64 {
+	return score * (1 - penaltiPersen/100)
+}
+
+// Format waktu lokal yang diterima selain RFC 3339
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseTime membaca waktu RFC 3339. Waktu tanpa offset ditafsirkan pada zona loc.
+func ParseTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidTime
+}
+
+// LoadZone memuat zona waktu IANA; nama kosong berarti DefaultZone
+func LoadZone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultZone
+	}
+	return time.LoadLocation(name)
+}
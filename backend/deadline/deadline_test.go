@@ -0,0 +1,112 @@
+package deadline
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWindowEvaluate(t *testing.T) {
+	dibuka := time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+	ditutup := time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC)
+	batas := ditutup.Add(72 * time.Hour)
+	w := Window{DibukaPada: dibuka, DitutupPada: ditutup, BatasTerlambat: &batas, PenaltiPerHari: 10}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want Result
+	}{
+		{"sebelum dibuka", dibuka.Add(-time.Second), Result{Status: StatusBelumDibuka}},
+		{"tepat dibuka", dibuka, Result{Status: StatusDibuka}},
+		{"tepat tenggat", ditutup, Result{Status: StatusDibuka}},
+		{"terlambat satu menit", ditutup.Add(time.Minute), Result{Status: StatusTerlambat, HariTerlambat: 1, PenaltiPersen: 10}},
+		{"terlambat tepat 24 jam", ditutup.Add(24 * time.Hour), Result{Status: StatusTerlambat, HariTerlambat: 1, PenaltiPersen: 10}},
+		{"terlambat lebih dari 24 jam", ditutup.Add(25 * time.Hour), Result{Status: StatusTerlambat, HariTerlambat: 2, PenaltiPersen: 20}},
+		{"tepat batas terlambat", batas, Result{Status: StatusTerlambat, HariTerlambat: 3, PenaltiPersen: 30}},
+		{"lewat batas terlambat", batas.Add(time.Second), Result{Status: StatusDitutup}},
+	}
+	for _, tt := range tests {
+		if got := w.Evaluate(tt.now); got != tt.want {
+			t.Errorf("%s: Evaluate = %+v, ingin %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// Tanpa batas terlambat, tugas langsung ditutup setelah tenggat
+	w.BatasTerlambat = nil
+	if got := w.Evaluate(ditutup.Add(time.Minute)); got.Status != StatusDitutup || got.Accepting() {
+		t.Errorf("tanpa batas terlambat: %+v, ingin ditutup", got)
+	}
+}
+
+func TestWindowEvaluatePenaltyCapped(t *testing.T) {
+	ditutup := time.Date(2026, 3, 9, 7, 0, 0, 0, time.UTC)
+	batas := ditutup.AddDate(0, 0, 30)
+	w := Window{DitutupPada: ditutup, BatasTerlambat: &batas, PenaltiPerHari: 15}
+	got := w.Evaluate(ditutup.AddDate(0, 0, 10))
+	if got.PenaltiPersen != 100 || got.HariTerlambat != 10 {
+		t.Errorf("Evaluate = %+v, ingin penalti maksimal 100%% setelah 10 hari", got)
+	}
+}
+
+func TestResultAccepting(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusBelumDibuka: false,
+		StatusDibuka:      true,
+		StatusTerlambat:   true,
+		StatusDitutup:     false,
+	} {
+		if got := (Result{Status: status}).Accepting(); got != want {
+			t.Errorf("Accepting(%s) = %v, ingin %v", status, got, want)
+		}
+	}
+}
+
+func TestApplyPenalty(t *testing.T) {
+	if got := ApplyPenalty(80, 25); got != 60 {
+		t.Errorf("ApplyPenalty(80, 25) = %v, ingin 60", got)
+	}
+	if got := ApplyPenalty(80, 0); got != 80 {
+		t.Errorf("ApplyPenalty(80, 0) = %v, ingin 80", got)
+	}
+	if got := ApplyPenalty(80, 100); got != 0 {
+		t.Errorf("ApplyPenalty(80, 100) = %v, ingin 0", got)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"2026-03-09T00:00:00Z",
+		"2026-03-09T07:00:00+07:00",
+		"2026-03-09T07:00:00",
+		"2026-03-09T07:00",
+		"2026-03-09 07:00:00",
+		"2026-03-09 07:00",
+	} {
+		got, err := ParseTime(value, wib)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTime(%q) = %s, ingin %s", value, got, want)
+		}
+	}
+	for _, value := range []string{"", "09/03/2026", "2026-03-09", "besok"} {
+		if _, err := ParseTime(value, wib); !errors.Is(err, ErrInvalidTime) {
+			t.Errorf("ParseTime(%q): err = %v, ingin ErrInvalidTime", value, err)
+		}
+	}
+}
+
+func TestLoadZone(t *testing.T) {
+	loc, err := LoadZone("UTC")
+	if err != nil || loc != time.UTC {
+		t.Errorf("LoadZone(UTC) = %v (%v)", loc, err)
+	}
+	if _, err := LoadZone("Bumi/Tengah"); err == nil {
+		t.Error("LoadZone zona tidak dikenal seharusnya gagal")
+	}
+}
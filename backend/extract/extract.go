@@ -0,0 +1,215 @@
+// Package extract mengambil teks polos dari dokumen materi (PDF, DOCX, PPTX, TXT)
+// agar isinya dapat disimpan di isi_materi dan dipakai pipeline RAG.
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Tipe MIME yang didukung
+const (
+	MIMEPDF  = "application/pdf"
+	MIMEDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMEPPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MIMEText = "text/plain"
+)
+
+var (
+	ErrUnsupportedType = errors.New("tipe file tidak didukung")
+	ErrNoText          = errors.New("dokumen tidak mengandung teks yang dapat diekstrak")
+)
+
+// Supported melaporkan apakah tipe MIME dapat diekstrak
+func Supported(mimeType string) bool {
+	switch mimeType {
+	case MIMEPDF, MIMEDOCX, MIMEPPTX, MIMEText:
+		return true
+	}
+	return false
+}
+
+// Text mengekstrak teks polos dari dokumen dengan tipe MIME yang sudah dideteksi.
+// Spasi berlebih dirapikan dan baris kosong beruntun diringkas.
+func Text(r io.ReaderAt, size int64, mimeType string) (string, error) {
+	var text string
+	var err error
+	switch mimeType {
+	case MIMEText:
+		text, err = plainText(r, size)
+	case MIMEDOCX:
+		text, err = docxText(r, size)
+	case MIMEPPTX:
+		text, err = pptxText(r, size)
+	case MIMEPDF:
+		text, err = pdfText(r, size)
+	default:
+		return "", ErrUnsupportedType
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = normalize(text)
+	if text == "" {
+		return "", ErrNoText
+	}
+	return text, nil
+}
+
+func plainText(r io.ReaderAt, size int64) (string, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("file teks harus berenkode UTF-8")
+	}
+	return string(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), nil
+}
+
+func docxText(r io.ReaderAt, size int64) (string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			return zipEntryText(f)
+		}
+	}
+	return "", fmt.Errorf("word/document.xml tidak ditemukan")
+}
+
+var slideName = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+
+// Slide diurutkan berdasarkan nomornya, bukan urutan di dalam arsip zip
+func pptxText(r io.ReaderAt, size int64) (string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	type slide struct {
+		number int
+		file   *zip.File
+	}
+	var slides []slide
+	for _, f := range archive.File {
+		if m := slideName.FindStringSubmatch(f.Name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			slides = append(slides, slide{number: n, file: f})
+		}
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].number < slides[j].number })
+
+	var parts []string
+	for _, s := range slides {
+		text, err := zipEntryText(s.file)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+func zipEntryText(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	return officeXMLText(rc)
+}
+
+// officeXMLText membaca teks dari XML WordprocessingML maupun DrawingML. Keduanya memakai
+// elemen t (teks), p (paragraf), br, dan tab sehingga cukup dicocokkan berdasarkan nama lokal.
+func officeXMLText(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+	var b strings.Builder
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteByte('\t')
+			case "br", "cr":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// Library PDF dapat panic pada file rusak; ubah menjadi error biasa
+func pdfText(r io.ReaderAt, size int64) (text string, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("gagal membaca PDF: %v", rec)
+		}
+	}()
+
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(plain)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+var (
+	horizontalSpace = regexp.MustCompile(`[ \t\f\v\x{00a0}]+`)
+	manyNewlines    = regexp.MustCompile(`\n{3,}`)
+)
+
+func normalize(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\x00", "")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(horizontalSpace.ReplaceAllString(line, " "))
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(manyNewlines.ReplaceAllString(text, "\n\n"))
+}
@@ -0,0 +1,174 @@
+// Package gradebook menyusun buku nilai kelas (siswa × tugas) dan rapor per siswa dari
+// percobaan jawaban, mengikuti kebijakan nilai dan penalti keterlambatan setiap tugas.
+package gradebook
+
+import (
+	"fmt"
+	"math"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/scoring"
+)
+
+// Book berisi tugas kelas dan soal-soalnya; dipakai untuk membuat header dan baris ekspor
+type Book struct {
+	Assignments []*models.Assignment
+	Questions   map[string]*models.Question // Berdasarkan ID soal, termasuk rubriknya
+	WithAspects bool
+}
+
+// Nilai satu soal pada sebuah tugas
+type QuestionGrade struct {
+	Nomor        int
+	Soal         *models.Question
+	Skor         *float64 // Nilai yang dihitung menurut kebijakan tugas
+	Dijawab      bool
+	Aspek        map[string]float64 // rubric_id -> skor; skor guru menggantikan skor AI
+	UmpanBalikAI string
+	CatatanGuru  string
+}
+
+// Nilai satu tugas. Nilai adalah rata-rata semua soal dengan soal tak dijawab bernilai 0;
+// nil jika belum ada jawaban atau masih ada jawaban yang belum dinilai.
+type AssignmentGrade struct {
+	Assignment *models.Assignment
+	Nilai      *float64
+	Soal       []*QuestionGrade
+}
+
+// Grades menghitung nilai setiap tugas untuk seorang siswa
+func (b *Book) Grades(student *models.GradebookStudent) []*AssignmentGrade {
+	attempts := map[[2]string][]*models.GradebookAttempt{}
+	for _, a := range student.Percobaan {
+		key := [2]string{a.AssignmentID, a.SoalID}
+		attempts[key] = append(attempts[key], a)
+	}
+
+	grades := make([]*AssignmentGrade, len(b.Assignments))
+	for i, assignment := range b.Assignments {
+		grade := &AssignmentGrade{Assignment: assignment}
+		var sum float64
+		answered, pending := 0, false
+		for n, soalID := range assignment.SoalIDs {
+			q := questionGrade(assignment.KebijakanNilai, attempts[[2]string{assignment.ID, soalID}])
+			q.Nomor, q.Soal = n+1, b.Questions[soalID]
+			grade.Soal = append(grade.Soal, q)
+			if q.Dijawab {
+				answered++
+				if q.Skor == nil {
+					pending = true
+				} else {
+					sum += *q.Skor
+				}
+			}
+		}
+		if answered > 0 && !pending && len(assignment.SoalIDs) > 0 {
+			nilai := round(sum / float64(len(assignment.SoalIDs)))
+			grade.Nilai = &nilai
+		}
+		grades[i] = grade
+	}
+	return grades
+}
+
+func questionGrade(policy string, attempts []*models.GradebookAttempt) *QuestionGrade {
+	q := &QuestionGrade{Dijawab: len(attempts) > 0}
+	scores := make([]*float64, len(attempts))
+	for i, a := range attempts {
+		scores[i] = scoring.Attempt(a.SkorAI, a.SkorGuru, a.PenaltiPersen)
+	}
+	if counted := scoring.Counted(policy, scores); counted != nil {
+		v := round(*counted)
+		q.Skor = &v
+	}
+	// Rincian aspek dan umpan balik diambil dari percobaan terakhir yang sudah dinilai
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if scores[i] == nil {
+			continue
+		}
+		a := attempts[i]
+		q.Aspek = map[string]float64{}
+		for id, skor := range a.AspekAI {
+			q.Aspek[id] = skor
+		}
+		for id, skor := range a.AspekGuru {
+			q.Aspek[id] = skor
+		}
+		q.UmpanBalikAI, q.CatatanGuru = a.UmpanBalikAI, a.CatatanGuru
+		break
+	}
+	return q
+}
+
+// Average menghitung rata-rata nilai tugas yang sudah ada; nil jika belum ada
+func Average(grades []*AssignmentGrade) *float64 {
+	var sum float64
+	n := 0
+	for _, g := range grades {
+		if g.Nilai != nil {
+			sum += *g.Nilai
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	avg := round(sum / float64(n))
+	return &avg
+}
+
+// Header mengembalikan judul kolom ekspor
+func (b *Book) Header() []any {
+	header := []any{"Nomor Identitas", "Nama Siswa"}
+	for _, a := range b.Assignments {
+		header = append(header, a.Judul)
+		if !b.WithAspects {
+			continue
+		}
+		for n, soalID := range a.SoalIDs {
+			header = append(header, fmt.Sprintf("%s / Soal %d", a.Judul, n+1))
+			if q := b.Questions[soalID]; q != nil {
+				for _, r := range q.Rubrik {
+					header = append(header, fmt.Sprintf("%s / Soal %d / %s", a.Judul, n+1, r.NamaAspek))
+				}
+			}
+		}
+	}
+	return append(header, "Rata-rata")
+}
+
+// Row mengembalikan baris ekspor seorang siswa; sel tanpa nilai bernilai nil
+func (b *Book) Row(student *models.GradebookStudent) []any {
+	grades := b.Grades(student)
+	row := []any{student.NomorIdentitas, student.NamaSiswa}
+	for _, g := range grades {
+		row = append(row, number(g.Nilai))
+		if !b.WithAspects {
+			continue
+		}
+		for _, q := range g.Soal {
+			row = append(row, number(q.Skor))
+			if q.Soal == nil {
+				continue
+			}
+			for _, r := range q.Soal.Rubrik {
+				if skor, ok := q.Aspek[r.ID]; ok {
+					row = append(row, round(skor))
+				} else {
+					row = append(row, nil)
+				}
+			}
+		}
+	}
+	return append(row, number(Average(grades)))
+}
+
+func number(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,136 @@
+// Package gradecache menyimpan hasil penilaian AI agar jawaban yang sama (setelah dinormalkan)
+// untuk soal, rubrik, versi materi, dan versi prompt yang sama tidak dinilai ulang oleh model.
+// Semua masukan tersebut menjadi bagian dari kunci, sehingga perubahan salah satunya otomatis
+// membuat entri lama tidak terpakai lagi sampai kedaluwarsa.
+package gradecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sistem-skripsi/backend/grading"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/similarity"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultTTL = 7 * 24 * time.Hour
+
+// Entry adalah hasil penilaian yang dapat dipakai ulang
+type Entry struct {
+	Result       grading.Result `json:"result"`
+	ChunkIDs     []string       `json:"chunk_ids"`
+	SubmissionID string         `json:"submission_id"` // Jawaban yang pertama kali dinilai model
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// Cache adalah kontrak penyimpanan entri. Get mengembalikan nil tanpa error jika kunci tidak ada.
+type Cache interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+	// Flush membuang semua entri, mis. setelah penyedia atau model diganti
+	Flush(ctx context.Context) error
+	Name() string
+}
+
+// KeyInput adalah semua masukan yang menentukan hasil penilaian
+type KeyInput struct {
+	Question          *models.Question
+	Jawaban           string
+	MaterialVersionID string
+	PromptTemplateID  string
+}
+
+// Key menghitung kunci cache. Jawaban dinormalkan (huruf kecil, tanpa tanda baca dan spasi
+// berlebih); soal, kunci jawaban, level, dan isi rubrik di-hash sebagai versi rubrik.
+func Key(in KeyInput) string {
+	q := in.Question
+	rubric := sha256.New()
+	for _, r := range q.Rubrik {
+		fmt.Fprintf(rubric, "%s\x00%s\x00%s\x00%g\x00", r.ID, r.NamaAspek, r.Deskripsi, r.Bobot)
+		if r.SkorMin != nil && r.SkorMaks != nil {
+			fmt.Fprintf(rubric, "%g-%g\x00", *r.SkorMin, *r.SkorMaks)
+		}
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		q.ID,
+		q.TeksSoal,
+		q.KunciJawaban,
+		q.LevelKognitif,
+		hex.EncodeToString(rubric.Sum(nil)),
+		in.MaterialVersionID,
+		in.PromptTemplateID,
+		strings.Join(similarity.Words(in.Jawaban), " "),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Stats adalah ringkasan pemakaian cache sejak server berjalan
+type Stats struct {
+	Backend string  `json:"backend"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Stores  int64   `json:"stores"`
+	Errors  int64   `json:"errors"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// Metered membungkus Cache dan menghitung hit, miss, penyimpanan, dan error
+type Metered struct {
+	cache                          Cache
+	hits, misses, stores, failures atomic.Int64
+}
+
+func NewMetered(cache Cache) *Metered {
+	return &Metered{cache: cache}
+}
+
+func (m *Metered) Name() string { return m.cache.Name() }
+
+func (m *Metered) Get(ctx context.Context, key string) (*Entry, error) {
+	entry, err := m.cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		m.failures.Add(1)
+	case entry == nil:
+		m.misses.Add(1)
+	default:
+		m.hits.Add(1)
+	}
+	return entry, err
+}
+
+func (m *Metered) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	err := m.cache.Set(ctx, key, entry, ttl)
+	if err != nil {
+		m.failures.Add(1)
+	} else {
+		m.stores.Add(1)
+	}
+	return err
+}
+
+func (m *Metered) Flush(ctx context.Context) error {
+	return m.cache.Flush(ctx)
+}
+
+func (m *Metered) Stats() Stats {
+	stats := Stats{
+		Backend: m.cache.Name(),
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+		Stores:  m.stores.Load(),
+		Errors:  m.failures.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
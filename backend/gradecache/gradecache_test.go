@@ -0,0 +1,112 @@
+package gradecache
+
+import (
+	"context"
+	"sistem-skripsi/backend/models"
+	"testing"
+	"time"
+)
+
+func question() *models.Question {
+	return &models.Question{
+		ID:            "q1",
+		TeksSoal:      "Jelaskan proses fotosintesis.",
+		KunciJawaban:  "Tumbuhan mengubah cahaya menjadi energi kimia.",
+		LevelKognitif: "C2",
+		Rubrik: []*models.Rubric{
+			{ID: "r1", NamaAspek: "Konsep", Deskripsi: "Menjelaskan konsep", Bobot: 60},
+			{ID: "r2", NamaAspek: "Istilah", Bobot: 40},
+		},
+	}
+}
+
+func key(mutate func(*KeyInput)) string {
+	in := KeyInput{
+		Question:          question(),
+		Jawaban:           "Tumbuhan mengubah cahaya matahari menjadi glukosa.",
+		MaterialVersionID: "mv1",
+		PromptTemplateID:  "pt1",
+	}
+	if mutate != nil {
+		mutate(&in)
+	}
+	return Key(in)
+}
+
+func TestKeyNormalizesAnswer(t *testing.T) {
+	base := key(nil)
+	same := key(func(in *KeyInput) { in.Jawaban = "  tumbuhan MENGUBAH cahaya   matahari, menjadi glukosa!" })
+	if same != base {
+		t.Error("jawaban yang hanya berbeda huruf besar, spasi, dan tanda baca seharusnya berkunci sama")
+	}
+	if key(nil) != base {
+		t.Error("kunci tidak stabil untuk masukan yang sama")
+	}
+}
+
+func TestKeyChangesWithInputs(t *testing.T) {
+	base := key(nil)
+	mutations := map[string]func(*KeyInput){
+		"jawaban":         func(in *KeyInput) { in.Jawaban = "Tumbuhan menghasilkan oksigen." },
+		"teks soal":       func(in *KeyInput) { in.Question.TeksSoal = "Apa itu fotosintesis?" },
+		"kunci jawaban":   func(in *KeyInput) { in.Question.KunciJawaban = "" },
+		"level":           func(in *KeyInput) { in.Question.LevelKognitif = "C3" },
+		"bobot rubrik":    func(in *KeyInput) { in.Question.Rubrik[0].Bobot = 50 },
+		"aspek rubrik":    func(in *KeyInput) { in.Question.Rubrik = in.Question.Rubrik[:1] },
+		"versi materi":    func(in *KeyInput) { in.MaterialVersionID = "mv2" },
+		"template":        func(in *KeyInput) { in.PromptTemplateID = "pt2" },
+		"batas kolom":     func(in *KeyInput) { in.MaterialVersionID, in.PromptTemplateID = "mv1pt1", "" },
+		"deskripsi aspek": func(in *KeyInput) { in.Question.Rubrik[1].Deskripsi = "Memakai istilah" },
+		"rentang skor": func(in *KeyInput) {
+			lo, hi := 1.0, 4.0
+			in.Question.Rubrik[0].SkorMin, in.Question.Rubrik[0].SkorMaks = &lo, &hi
+		},
+	}
+	for name, mutate := range mutations {
+		if key(mutate) == base {
+			t.Errorf("mengubah %s seharusnya mengubah kunci", name)
+		}
+	}
+}
+
+func TestMeteredMemory(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMetered(NewMemory(2))
+	if entry, err := cache.Get(ctx, "a"); entry != nil || err != nil {
+		t.Fatalf("Get kunci kosong = %v (%v)", entry, err)
+	}
+	cache.Set(ctx, "a", &Entry{SubmissionID: "s1"}, time.Hour)
+	if entry, _ := cache.Get(ctx, "a"); entry == nil || entry.SubmissionID != "s1" {
+		t.Fatalf("Get setelah Set = %v", entry)
+	}
+	cache.Set(ctx, "kedaluwarsa", &Entry{}, -time.Second)
+	if entry, _ := cache.Get(ctx, "kedaluwarsa"); entry != nil {
+		t.Error("entri kedaluwarsa seharusnya tidak dikembalikan")
+	}
+
+	stats := cache.Stats()
+	if stats.Backend != "memory" || stats.Hits != 1 || stats.Misses != 2 || stats.Stores != 2 || stats.HitRate != 1.0/3 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	cache.Flush(ctx)
+	if entry, _ := cache.Get(ctx, "a"); entry != nil {
+		t.Error("entri seharusnya hilang setelah Flush")
+	}
+}
+
+func TestMemoryEvictsSoonestExpiring(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	m.Set(ctx, "pendek", &Entry{}, time.Minute)
+	m.Set(ctx, "panjang", &Entry{}, time.Hour)
+	m.Set(ctx, "baru", &Entry{}, time.Hour)
+	if entry, _ := m.Get(ctx, "pendek"); entry != nil {
+		t.Error("entri yang paling cepat kedaluwarsa seharusnya dibuang")
+	}
+	for _, key := range []string{"panjang", "baru"} {
+		if entry, _ := m.Get(ctx, key); entry == nil {
+			t.Errorf("entri %q seharusnya masih ada", key)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package gradecache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory menyimpan entri di memori proses dengan batas jumlah entri. Jika penuh, entri
+// yang paling cepat kedaluwarsa dibuang lebih dulu.
+type Memory struct {
+	mu         sync.Mutex
+	entries    map[string]memoryItem
+	maxEntries int
+}
+
+type memoryItem struct {
+	entry     *Entry
+	expiresAt time.Time
+}
+
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &Memory{entries: map[string]memoryItem{}, maxEntries: maxEntries}
+}
+
+func (m *Memory) Name() string { return "memory" }
+
+func (m *Memory) Get(ctx context.Context, key string) (*Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(item.expiresAt) {
+		delete(m.entries, key)
+		return nil, nil
+	}
+	return item.entry, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+	m.entries[key] = memoryItem{entry: entry, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[string]memoryItem{}
+	return nil
+}
+
+// evict membuang entri kedaluwarsa; jika tidak ada, entri yang paling cepat kedaluwarsa
+func (m *Memory) evict() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for key, item := range m.entries {
+		if now.After(item.expiresAt) {
+			delete(m.entries, key)
+			continue
+		}
+		if oldestKey == "" || item.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, item.expiresAt
+		}
+	}
+	if len(m.entries) >= m.maxEntries {
+		delete(m.entries, oldestKey)
+	}
+}
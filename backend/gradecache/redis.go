@@ -0,0 +1,85 @@
+package gradecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sistem-skripsi/backend/redis"
+	"strconv"
+	"time"
+)
+
+// Redis menyimpan entri sebagai JSON dengan TTL bawaan Redis. Flush menaikkan nomor
+// generasi yang menjadi bagian dari setiap kunci, sehingga tidak perlu memindai kunci lama;
+// entri generasi lama hilang sendiri saat kedaluwarsa.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	if prefix == "" {
+		prefix = "grading-cache"
+	}
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (r *Redis) Name() string { return "redis" }
+
+func (r *Redis) generation(ctx context.Context) (string, error) {
+	reply, err := r.client.Do(ctx, "GET", r.prefix+":gen")
+	if errors.Is(err, redis.ErrNil) {
+		return "0", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	gen, _ := reply.(string)
+	return gen, nil
+}
+
+func (r *Redis) key(ctx context.Context, key string) (string, error) {
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.prefix + ":" + gen + ":" + key, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) (*Entry, error) {
+	fullKey, err := r.key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := r.client.Do(ctx, "GET", fullKey)
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, _ := reply.(string)
+	var entry Entry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error {
+	fullKey, err := r.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Do(ctx, "SET", fullKey, string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+func (r *Redis) Flush(ctx context.Context) error {
+	_, err := r.client.Do(ctx, "INCR", r.prefix+":gen")
+	return err
+}
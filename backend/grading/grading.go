@@ -0,0 +1,273 @@
+// Package grading meminta keluaran JSON terstruktur dari model bahasa, memvalidasinya terhadap
+// rubrik soal, dan memperbaiki keluaran yang rusak dengan jumlah percobaan terbatas.
+package grading
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sistem-skripsi/backend/llm"
+	"strings"
+)
+
+const (
+	MinScore = 0
+	MaxScore = 100
+	// Jumlah permintaan perbaikan setelah keluaran pertama tidak valid
+	DefaultMaxRepairs = 2
+	// Aspek yang dinilai jika soal tidak memiliki rubrik, sama dengan template prompt bawaan
+	DefaultAspect = "Ketepatan dan kelengkapan jawaban"
+)
+
+var ErrInvalidOutput = errors.New("keluaran model tidak sesuai skema")
+
+// Aspect adalah satu aspek rubrik yang harus dinilai model. Min dan Max adalah rentang skor
+// aspek; jika Max tidak lebih besar dari Min, aspek dinilai MinScore sampai MaxScore.
+type Aspect struct {
+	RubricID string
+	Nama     string
+	Bobot    float64
+	Min      float64
+	Max      float64
+}
+
+// Range mengembalikan rentang skor yang berlaku untuk aspek
+func (a Aspect) Range() (float64, float64) {
+	if a.Max > a.Min {
+		return a.Min, a.Max
+	}
+	return MinScore, MaxScore
+}
+
+// Spec menentukan keluaran yang dianggap valid untuk satu jawaban
+type Spec struct {
+	Aspek    []Aspect
+	ChunkIDs []string // Chunk yang boleh dikutip model
+}
+
+// Skema keluaran yang diminta dari model
+type output struct {
+	Aspek      []aspectOutput `json:"aspek"`
+	UmpanBalik *string        `json:"umpan_balik"`
+}
+
+type aspectOutput struct {
+	NamaAspek *string  `json:"nama_aspek"`
+	Skor      *float64 `json:"skor"`
+	Alasan    *string  `json:"alasan"`
+	ChunkIDs  []string `json:"chunk_ids"`
+}
+
+// AspectScore adalah skor satu aspek yang sudah divalidasi
+type AspectScore struct {
+	RubricID  string   `json:"rubric_id,omitempty"`
+	NamaAspek string   `json:"nama_aspek"`
+	Skor      float64  `json:"skor"`
+	Alasan    string   `json:"alasan"`
+	ChunkIDs  []string `json:"chunk_ids"`
+}
+
+// Result adalah hasil penilaian yang sudah divalidasi. Koreksi mencatat perubahan yang
+// dilakukan terhadap keluaran model, mis. skor yang dipotong ke rentang rubrik.
+type Result struct {
+	Skor       float64       `json:"skor"`
+	UmpanBalik string        `json:"umpan_balik"`
+	Aspek      []AspectScore `json:"aspek"`
+	Koreksi    []string      `json:"koreksi,omitempty"`
+}
+
+// Attempt mencatat satu panggilan model: keluaran mentah, alasan penolakannya (jika ada),
+// dan data pemakaiannya
+type Attempt struct {
+	Output           string `json:"output"`
+	Galat            string `json:"galat,omitempty"`
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	LatencyMS        int64  `json:"latency_ms"`
+}
+
+// Aspects mengembalikan aspek spec, atau aspek bawaan jika soal tidak memiliki rubrik
+func (s Spec) Aspects() []Aspect {
+	if len(s.Aspek) == 0 {
+		return []Aspect{{Nama: DefaultAspect, Bobot: 1}}
+	}
+	return s.Aspek
+}
+
+// Instructions adalah petunjuk format keluaran yang ditambahkan setelah prompt template
+func Instructions(spec Spec) string {
+	// Rentang disebut per aspek hanya jika ada rubrik yang tidak memakai rentang bawaan
+	custom := false
+	for _, a := range spec.Aspects() {
+		if lo, hi := a.Range(); lo != MinScore || hi != MaxScore {
+			custom = true
+		}
+	}
+	var names []string
+	for _, a := range spec.Aspects() {
+		if custom {
+			lo, hi := a.Range()
+			names = append(names, fmt.Sprintf("%q (skor %g sampai %g)", a.Nama, lo, hi))
+		} else {
+			names = append(names, fmt.Sprintf("%q", a.Nama))
+		}
+	}
+	scoreRule := fmt.Sprintf("angka %d sampai %d", MinScore, MaxScore)
+	if custom {
+		scoreRule = "angka dalam rentang skor aspek tersebut"
+	}
+	return fmt.Sprintf(`Balas HANYA dengan satu objek JSON tanpa teks lain, dengan format:
+{"aspek": [{"nama_aspek": string, "skor": number, "alasan": string, "chunk_ids": [string]}], "umpan_balik": string}
+- "aspek" berisi tepat satu entri untuk setiap aspek berikut: %s
+- "skor" adalah %s
+- "alasan" menjelaskan skor aspek tersebut
+- "chunk_ids" berisi ID kutipan materi (di dalam tanda [ ]) yang mendukung alasan, boleh kosong
+- "umpan_balik" berisi umpan balik keseluruhan untuk siswa`, strings.Join(names, ", "), scoreRule)
+}
+
+// Parse memvalidasi keluaran model terhadap spec. Skor di luar rentang aspek dipotong dan ID chunk
+// yang tidak dikenal dibuang; kesalahan lain menghasilkan error yang membungkus ErrInvalidOutput.
+func Parse(raw string, spec Spec) (*Result, error) {
+	body := extractJSON(raw)
+	if body == "" {
+		return nil, fmt.Errorf("%w: tidak ada objek JSON", ErrInvalidOutput)
+	}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.DisallowUnknownFields()
+	var out output
+	if err := decoder.Decode(&out); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+	if out.UmpanBalik == nil || strings.TrimSpace(*out.UmpanBalik) == "" {
+		return nil, fmt.Errorf("%w: umpan_balik wajib diisi", ErrInvalidOutput)
+	}
+
+	aspects := spec.Aspects()
+	byName := map[string]int{}
+	for i, a := range aspects {
+		byName[normalizeName(a.Nama)] = i
+	}
+	allowedChunks := map[string]bool{}
+	for _, id := range spec.ChunkIDs {
+		allowedChunks[id] = true
+	}
+
+	result := &Result{UmpanBalik: strings.TrimSpace(*out.UmpanBalik), Aspek: make([]AspectScore, len(aspects))}
+	seen := make([]bool, len(aspects))
+	for _, a := range out.Aspek {
+		if a.NamaAspek == nil || a.Skor == nil || a.Alasan == nil {
+			return nil, fmt.Errorf("%w: setiap aspek wajib memiliki nama_aspek, skor, dan alasan", ErrInvalidOutput)
+		}
+		i, ok := byName[normalizeName(*a.NamaAspek)]
+		if !ok {
+			return nil, fmt.Errorf("%w: aspek %q tidak ada di rubrik", ErrInvalidOutput, *a.NamaAspek)
+		}
+		if seen[i] {
+			return nil, fmt.Errorf("%w: aspek %q dinilai lebih dari sekali", ErrInvalidOutput, *a.NamaAspek)
+		}
+		seen[i] = true
+
+		score := *a.Skor
+		lo, hi := aspects[i].Range()
+		if clamped := math.Min(math.Max(score, lo), hi); clamped != score {
+			result.Koreksi = append(result.Koreksi, fmt.Sprintf("skor aspek %q %g dipotong menjadi %g", aspects[i].Nama, score, clamped))
+			score = clamped
+		}
+		chunkIDs := []string{}
+		for _, id := range a.ChunkIDs {
+			if allowedChunks[id] {
+				chunkIDs = append(chunkIDs, id)
+			} else {
+				result.Koreksi = append(result.Koreksi, fmt.Sprintf("chunk %q pada aspek %q tidak dikenal dan diabaikan", id, aspects[i].Nama))
+			}
+		}
+		result.Aspek[i] = AspectScore{
+			RubricID:  aspects[i].RubricID,
+			NamaAspek: aspects[i].Nama,
+			Skor:      score,
+			Alasan:    strings.TrimSpace(*a.Alasan),
+			ChunkIDs:  chunkIDs,
+		}
+	}
+	for i, ok := range seen {
+		if !ok {
+			return nil, fmt.Errorf("%w: aspek %q belum dinilai", ErrInvalidOutput, aspects[i].Nama)
+		}
+	}
+
+	result.Skor = weightedScore(aspects, result.Aspek)
+	return result, nil
+}
+
+// Grade meminta penilaian dari model lalu, jika keluarannya tidak valid, meminta perbaikan
+// paling banyak maxRepairs kali. Semua keluaran mentah dikembalikan untuk dicatat. Error
+// yang membungkus ErrInvalidOutput berarti model tidak pernah menghasilkan keluaran valid;
+// error lain berasal dari klien model.
+func Grade(ctx context.Context, client llm.Client, prompt string, spec Spec, maxRepairs int) (*Result, []Attempt, error) {
+	var attempts []Attempt
+	request := prompt
+	for i := 0; i <= maxRepairs; i++ {
+		resp, err := client.Generate(ctx, llm.Request{Prompt: request})
+		if err != nil {
+			return nil, attempts, err
+		}
+		attempt := Attempt{
+			Output:           resp.Text,
+			Provider:         resp.Provider,
+			Model:            resp.Model,
+			PromptTokens:     resp.PromptTokens,
+			CompletionTokens: resp.CompletionTokens,
+			LatencyMS:        resp.Latency.Milliseconds(),
+		}
+		result, err := Parse(resp.Text, spec)
+		if err == nil {
+			attempts = append(attempts, attempt)
+			return result, attempts, nil
+		}
+		attempt.Galat = err.Error()
+		attempts = append(attempts, attempt)
+		request = repairPrompt(prompt, resp.Text, err)
+	}
+	return nil, attempts, fmt.Errorf("%w setelah %d percobaan", ErrInvalidOutput, len(attempts))
+}
+
+func repairPrompt(prompt string, raw string, err error) string {
+	return prompt + "\n\nKeluaran Anda sebelumnya tidak dapat diproses (" + err.Error() + "):\n" + raw +
+		"\n\nKirim ulang penilaian HANYA sebagai objek JSON yang valid sesuai format di atas."
+}
+
+// extractJSON mengambil objek JSON dari keluaran, termasuk yang dibungkus blok kode markdown
+// atau didahului kalimat pengantar
+func extractJSON(raw string) string {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return ""
+	}
+	return raw[start : end+1]
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// weightedScore menghitung skor akhir (0-100) sebagai rata-rata berbobot skor aspek yang
+// sudah dinormalisasi ke rentang masing-masing. Jika semua bobot nol, setiap aspek berbobot sama.
+func weightedScore(aspects []Aspect, scores []AspectScore) float64 {
+	var total, weights, plain float64
+	for i, a := range aspects {
+		lo, hi := a.Range()
+		percent := MinScore + (scores[i].Skor-lo)/(hi-lo)*(MaxScore-MinScore)
+		total += a.Bobot * percent
+		weights += a.Bobot
+		plain += percent
+	}
+	if weights <= 0 {
+		total, weights = plain, float64(len(aspects))
+	}
+	return math.Round(total/weights*100) / 100
+}
@@ -0,0 +1,216 @@
+package grading
+
+import (
+	"context"
+	"errors"
+	"sistem-skripsi/backend/llm"
+	"strings"
+	"testing"
+)
+
+// Respons model direkam di testdata/llm; nama file adalah sha256 dari prompt
+const fixtureDir = "../testdata/llm"
+
+var testSpec = Spec{
+	Aspek: []Aspect{
+		{RubricID: "r1", Nama: "Konsep", Bobot: 60},
+		{RubricID: "r2", Nama: "Istilah", Bobot: 40},
+	},
+	ChunkIDs: []string{"c1", "c2"},
+}
+
+func gradingPrompt(jawaban string) string {
+	return "Nilai jawaban siswa untuk soal \"Jelaskan proses fotosintesis.\"\nJawaban: " + jawaban + "\n\n" + Instructions(testSpec)
+}
+
+func TestParse(t *testing.T) {
+	raw := "Berikut penilaiannya:\n```json\n" + `{
+  "aspek": [
+    {"nama_aspek": "  istilah ", "skor": 120, "alasan": " Istilah tepat. ", "chunk_ids": ["c2", "c9"]},
+    {"nama_aspek": "Konsep", "skor": 70, "alasan": "Konsep benar.", "chunk_ids": []}
+  ],
+  "umpan_balik": " Pertahankan. "
+}` + "\n```"
+	result, err := Parse(raw, testSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// (70×60 + 100×40) / 100
+	if result.Skor != 82 {
+		t.Errorf("Skor = %v, ingin 82", result.Skor)
+	}
+	if result.UmpanBalik != "Pertahankan." {
+		t.Errorf("UmpanBalik = %q", result.UmpanBalik)
+	}
+	// Aspek diurutkan sesuai rubrik dan memakai nama dari rubrik
+	if a := result.Aspek[0]; a.RubricID != "r1" || a.NamaAspek != "Konsep" || a.Skor != 70 {
+		t.Errorf("aspek pertama = %+v", a)
+	}
+	istilah := result.Aspek[1]
+	if istilah.NamaAspek != "Istilah" || istilah.Skor != 100 || istilah.Alasan != "Istilah tepat." {
+		t.Errorf("aspek kedua = %+v", istilah)
+	}
+	if len(istilah.ChunkIDs) != 1 || istilah.ChunkIDs[0] != "c2" {
+		t.Errorf("chunk aspek kedua = %v, ingin [c2]", istilah.ChunkIDs)
+	}
+	if len(result.Koreksi) != 2 {
+		t.Errorf("Koreksi = %q, ingin pemotongan skor dan chunk tidak dikenal", result.Koreksi)
+	}
+}
+
+func TestParseDefaultAspect(t *testing.T) {
+	raw := `{"aspek": [{"nama_aspek": "Ketepatan dan kelengkapan jawaban", "skor": 65.555, "alasan": "Cukup."}], "umpan_balik": "Cukup baik."}`
+	result, err := Parse(raw, Spec{})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if result.Skor != 65.56 || result.Aspek[0].NamaAspek != DefaultAspect {
+		t.Errorf("Parse = %+v", result)
+	}
+}
+
+func TestParseZeroWeights(t *testing.T) {
+	spec := Spec{Aspek: []Aspect{{Nama: "A"}, {Nama: "B"}}}
+	raw := `{"aspek": [{"nama_aspek": "A", "skor": 90, "alasan": "-"}, {"nama_aspek": "B", "skor": 60, "alasan": "-"}], "umpan_balik": "-"}`
+	result, err := Parse(raw, spec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if result.Skor != 75 {
+		t.Errorf("Skor tanpa bobot = %v, ingin rata-rata 75", result.Skor)
+	}
+}
+
+func TestParseRubricRange(t *testing.T) {
+	spec := Spec{Aspek: []Aspect{
+		{Nama: "Konsep", Bobot: 50, Min: 1, Max: 4},
+		{Nama: "Istilah", Bobot: 50},
+	}}
+	raw := `{"aspek": [{"nama_aspek": "Konsep", "skor": 3, "alasan": "-"}, {"nama_aspek": "Istilah", "skor": 80, "alasan": "-"}], "umpan_balik": "-"}`
+	result, err := Parse(raw, spec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Skor aspek disimpan pada rentang rubrik; skor akhir memakai persentase: (66,67×50 + 80×50) / 100
+	if result.Aspek[0].Skor != 3 || result.Skor != 73.33 {
+		t.Errorf("Parse = %+v, ingin skor aspek 3 dan skor akhir 73,33", result)
+	}
+
+	raw = `{"aspek": [{"nama_aspek": "Konsep", "skor": 7, "alasan": "-"}, {"nama_aspek": "Istilah", "skor": 0.5, "alasan": "-"}], "umpan_balik": "-"}`
+	result, err = Parse(raw, spec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if result.Aspek[0].Skor != 4 || result.Aspek[1].Skor != 0.5 || len(result.Koreksi) != 1 {
+		t.Errorf("Parse = %+v, ingin skor Konsep dipotong ke 4 saja", result)
+	}
+	if result.Skor != 50.25 {
+		t.Errorf("Skor = %v, ingin 50,25", result.Skor)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	konsep := `{"nama_aspek": "Konsep", "skor": 70, "alasan": "-"}`
+	istilah := `{"nama_aspek": "Istilah", "skor": 70, "alasan": "-"}`
+	tests := map[string]string{
+		"bukan JSON":           "Maaf, saya tidak dapat menilai jawaban ini.",
+		"JSON rusak":           `{"aspek": [` + konsep + `, "umpan_balik": "-"}`,
+		"field tidak dikenal":  `{"aspek": [` + konsep + `,` + istilah + `], "umpan_balik": "-", "skor_total": 70}`,
+		"tanpa umpan balik":    `{"aspek": [` + konsep + `,` + istilah + `]}`,
+		"umpan balik kosong":   `{"aspek": [` + konsep + `,` + istilah + `], "umpan_balik": "  "}`,
+		"aspek tidak dikenal":  `{"aspek": [` + konsep + `,` + istilah + `, {"nama_aspek": "Kerapian", "skor": 70, "alasan": "-"}], "umpan_balik": "-"}`,
+		"aspek ganda":          `{"aspek": [` + konsep + `,` + konsep + `], "umpan_balik": "-"}`,
+		"aspek belum dinilai":  `{"aspek": [` + konsep + `], "umpan_balik": "-"}`,
+		"skor tidak ada":       `{"aspek": [` + konsep + `, {"nama_aspek": "Istilah", "alasan": "-"}], "umpan_balik": "-"}`,
+		"skor bukan angka":     `{"aspek": [` + konsep + `, {"nama_aspek": "Istilah", "skor": "70", "alasan": "-"}], "umpan_balik": "-"}`,
+		"alasan tidak ada":     `{"aspek": [` + konsep + `, {"nama_aspek": "Istilah", "skor": 70}], "umpan_balik": "-"}`,
+		"kurung kurawal salah": "} {",
+	}
+	for name, raw := range tests {
+		if _, err := Parse(raw, testSpec); !errors.Is(err, ErrInvalidOutput) {
+			t.Errorf("%s: err = %v, ingin ErrInvalidOutput", name, err)
+		}
+	}
+}
+
+func TestInstructionsListsAspects(t *testing.T) {
+	got := Instructions(testSpec)
+	if !strings.Contains(got, `"Konsep", "Istilah"`) {
+		t.Errorf("Instructions tidak menyebut semua aspek rubrik:\n%s", got)
+	}
+	if !strings.Contains(Instructions(Spec{}), `"`+DefaultAspect+`"`) {
+		t.Error("Instructions tanpa rubrik seharusnya menyebut aspek bawaan")
+	}
+	if !strings.Contains(got, "angka 0 sampai 100") {
+		t.Errorf("Instructions seharusnya menyebut rentang bawaan:\n%s", got)
+	}
+
+	spec := Spec{Aspek: []Aspect{{Nama: "Konsep", Min: 1, Max: 4}, {Nama: "Istilah"}}}
+	got = Instructions(spec)
+	if !strings.Contains(got, `"Konsep" (skor 1 sampai 4), "Istilah" (skor 0 sampai 100)`) {
+		t.Errorf("Instructions tidak menyebut rentang skor per aspek:\n%s", got)
+	}
+}
+
+func TestGradeValidOutput(t *testing.T) {
+	result, attempts, err := Grade(context.Background(), llm.NewReplay(fixtureDir), gradingPrompt("Tumbuhan mengubah cahaya matahari menjadi glukosa di kloroplas."), testSpec, DefaultMaxRepairs)
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if result.Skor != 76 {
+		t.Errorf("Skor = %v, ingin 76", result.Skor)
+	}
+	if len(result.Aspek[0].ChunkIDs) != 1 || result.Aspek[0].ChunkIDs[0] != "c1" {
+		t.Errorf("chunk aspek Konsep = %v, ingin [c1]", result.Aspek[0].ChunkIDs)
+	}
+	if len(attempts) != 1 {
+		t.Fatalf("jumlah percobaan = %d, ingin 1", len(attempts))
+	}
+	if a := attempts[0]; a.Galat != "" || a.Provider != "openai" || a.PromptTokens == 0 || a.Output == "" {
+		t.Errorf("percobaan = %+v", a)
+	}
+}
+
+func TestGradeRepairsInvalidOutput(t *testing.T) {
+	result, attempts, err := Grade(context.Background(), llm.NewReplay(fixtureDir), gradingPrompt("Fotosintesis terjadi di daun."), testSpec, DefaultMaxRepairs)
+	if err != nil {
+		t.Fatalf("Grade: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("jumlah percobaan = %d, ingin 2", len(attempts))
+	}
+	if !strings.Contains(attempts[0].Galat, "umpan_balik wajib diisi") || attempts[1].Galat != "" {
+		t.Errorf("galat percobaan = %q, %q", attempts[0].Galat, attempts[1].Galat)
+	}
+	if result.Skor != 46 || result.UmpanBalik == "" {
+		t.Errorf("hasil perbaikan = %+v, ingin skor 46 dengan umpan balik", result)
+	}
+}
+
+func TestGradeGivesUpAfterMaxRepairs(t *testing.T) {
+	result, attempts, err := Grade(context.Background(), llm.NewReplay(fixtureDir), gradingPrompt("Saya tidak tahu."), testSpec, DefaultMaxRepairs)
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("err = %v, ingin ErrInvalidOutput", err)
+	}
+	if result != nil {
+		t.Errorf("hasil = %+v, ingin nil", result)
+	}
+	if len(attempts) != DefaultMaxRepairs+1 {
+		t.Fatalf("jumlah percobaan = %d, ingin %d", len(attempts), DefaultMaxRepairs+1)
+	}
+	for i, a := range attempts {
+		if a.Galat == "" || a.Output == "" {
+			t.Errorf("percobaan %d = %+v, ingin keluaran mentah dan galat tercatat", i+1, a)
+		}
+	}
+}
+
+func TestGradeClientError(t *testing.T) {
+	_, attempts, err := Grade(context.Background(), llm.NewReplay(fixtureDir), gradingPrompt("Jawaban tanpa rekaman."), testSpec, DefaultMaxRepairs)
+	if !errors.Is(err, llm.ErrFixtureNotFound) || errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("err = %v, ingin ErrFixtureNotFound", err)
+	}
+	if len(attempts) != 0 {
+		t.Errorf("jumlah percobaan = %d, ingin 0", len(attempts))
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"sistem-skripsi/backend/agreement"
+	"sistem-skripsi/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Kesepakatan Skor AI dan Guru ---
+
+// Kesepakatan skor AI dengan skor guru di satu kelas. ?by=question|level|aspect|prompt_version
+// (default level) menentukan pengelompokan; ?assignment_id= membatasi ke satu tugas.
+func (s *Server) handleGetClassAgreement(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capReview) {
+		return
+	}
+	filter := models.AgreementFilter{KelasID: classID, AssignmentID: r.URL.Query().Get("assignment_id")}
+	s.writeAgreementReport(w, r, filter)
+}
+
+// Kesepakatan skor AI dengan skor guru di seluruh sekolah; ?kelas_id= membatasi ke satu kelas
+func (s *Server) handleGetAgreement(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AgreementFilter{KelasID: query.Get("kelas_id"), AssignmentID: query.Get("assignment_id")}
+	s.writeAgreementReport(w, r, filter)
+}
+
+func (s *Server) writeAgreementReport(w http.ResponseWriter, r *http.Request, filter models.AgreementFilter) {
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = agreement.ByLevel
+	}
+	if !agreement.IsDimension(by) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "by harus question, level, aspect, atau prompt_version"})
+		return
+	}
+
+	report, err := agreement.Load(s.store, filter, by)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghitung kesepakatan skor"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
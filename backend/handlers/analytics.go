@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Kriteria ketuntasan minimal (KKM) default untuk menghitung siswa tuntas per level
+const defaultBatasTuntas = 75
+
+// --- Handlers Analitik Penguasaan Level Kognitif ---
+
+// Penguasaan C1–C4 untuk kelas dan setiap siswa. Data berasal dari rekap yang diperbarui
+// berkala; waktu pembaruan terakhir ada di diperbarui_pada.
+func (s *Server) handleGetClassMastery(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+	batasTuntas, ok := batasTuntasParam(w, r)
+	if !ok {
+		return
+	}
+
+	mastery, err := s.store.GetClassMastery(classID, "", batasTuntas)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil analitik penguasaan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, mastery)
+}
+
+// Tren rata-rata per level setiap minggu (default) atau bulan, untuk kelas atau satu siswa (?siswa_id=)
+func (s *Server) handleGetMasteryTrend(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+	interval, ok := trendIntervalParam(w, r)
+	if !ok {
+		return
+	}
+
+	trend, err := s.store.GetMasteryTrend(classID, r.URL.Query().Get("siswa_id"), interval, usageZone().String())
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tren penguasaan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, trend)
+}
+
+// Sebaran nilai per level; ?level= membatasi ke satu level
+func (s *Server) handleGetMasteryHistogram(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+	level := r.URL.Query().Get("level")
+	if level != "" && !isCognitiveLevel(level) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Level harus C1, C2, C3, atau C4"})
+		return
+	}
+
+	histogram, err := s.store.GetMasteryHistogram(classID, level)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sebaran nilai"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, histogram)
+}
+
+// Penguasaan dan tren satu siswa, untuk guru
+func (s *Server) handleGetStudentMastery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !s.authorizeClass(w, r, vars["id"], capViewClass) {
+		return
+	}
+	s.writeStudentMastery(w, r, vars["id"], vars["siswaId"])
+}
+
+// Penguasaan dan tren siswa yang sedang login pada salah satu kelasnya
+func (s *Server) handleGetMyMastery(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	member, err := s.store.IsClassMember(classID, claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa anggota kelas"})
+		return
+	}
+	if !member {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Kelas tidak ditemukan"})
+		return
+	}
+	s.writeStudentMastery(w, r, classID, claims.UserID)
+}
+
+func (s *Server) writeStudentMastery(w http.ResponseWriter, r *http.Request, classID string, siswaID string) {
+	batasTuntas, ok := batasTuntasParam(w, r)
+	if !ok {
+		return
+	}
+	interval, ok := trendIntervalParam(w, r)
+	if !ok {
+		return
+	}
+
+	mastery, err := s.store.GetClassMastery(classID, siswaID, batasTuntas)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil analitik penguasaan"})
+		return
+	}
+	trend, err := s.store.GetMasteryTrend(classID, siswaID, interval, usageZone().String())
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tren penguasaan"})
+		return
+	}
+
+	level := []*models.MasteryLevel{}
+	if len(mastery.Siswa) > 0 {
+		level = mastery.Siswa[0].Level
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"siswa_id":        siswaID,
+		"batas_tuntas":    batasTuntas,
+		"diperbarui_pada": mastery.DiperbaruiPada,
+		"level":           level,
+		"tren":            trend,
+	})
+}
+
+// Memperbarui rekap analitik sekarang tanpa menunggu jadwal berkala
+func (s *Server) handleRefreshAnalytics(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.RefreshMasteryRollup(); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui rekap analitik"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Rekap analitik berhasil diperbarui"})
+}
+
+// RefreshAnalytics memperbarui rekap penguasaan. Dipanggil berkala dari main.
+func (s *Server) RefreshAnalytics(now time.Time) (int, error) {
+	return 0, s.store.RefreshMasteryRollup()
+}
+
+func batasTuntasParam(w http.ResponseWriter, r *http.Request) (float64, bool) {
+	value := r.URL.Query().Get("batas_tuntas")
+	if value == "" {
+		return defaultBatasTuntas, true
+	}
+	batas, err := strconv.ParseFloat(value, 64)
+	if err != nil || batas < 0 || batas > 100 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "batas_tuntas harus angka 0 sampai 100"})
+		return 0, false
+	}
+	return batas, true
+}
+
+func trendIntervalParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	switch interval := r.URL.Query().Get("interval"); interval {
+	case "", "week":
+		return "week", true
+	case "month":
+		return interval, true
+	}
+	WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "interval harus 'week' atau 'month'"})
+	return "", false
+}
+
+func isCognitiveLevel(level string) bool {
+	switch level {
+	case "C1", "C2", "C3", "C4":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/deadline"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/scoring"
+	"sistem-skripsi/backend/store"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Tugas (guru) ---
+
+func (s *Server) handleGetAssignments(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+
+	assignments, err := s.store.GetAssignmentsByClassID(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar tugas"})
+		return
+	}
+	for _, a := range assignments {
+		localizeAssignment(a)
+	}
+	WriteJSON(w, http.StatusOK, assignments)
+}
+
+func (s *Server) handleCreateAssignment(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageContent) {
+		return
+	}
+
+	assignment, ok := decodeAssignmentRequest(w, r)
+	if !ok {
+		return
+	}
+	assignment.KelasID = classID
+	assignment.DibuatOleh = claims.UserID
+
+	if err := s.store.CreateAssignment(assignment); err != nil {
+		writeAssignmentSaveError(w, err)
+		return
+	}
+	localizeAssignment(assignment)
+	WriteJSON(w, http.StatusCreated, assignment)
+}
+
+func (s *Server) handleGetAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+
+	questions, err := s.store.GetAssignmentQuestions(assignment.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil soal tugas"})
+		return
+	}
+	assignment.Soal = questions
+	localizeAssignment(assignment)
+	WriteJSON(w, http.StatusOK, assignment)
+}
+
+// Mengganti jadwal, kebijakan keterlambatan, dan daftar soal tugas
+func (s *Server) handleUpdateAssignment(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.assignmentForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	assignment, ok := decodeAssignmentRequest(w, r)
+	if !ok {
+		return
+	}
+	assignment.ID = existing.ID
+	assignment.KelasID = existing.KelasID
+
+	if err := s.store.UpdateAssignment(assignment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas tidak ditemukan"})
+			return
+		}
+		writeAssignmentSaveError(w, err)
+		return
+	}
+	localizeAssignment(assignment)
+	WriteJSON(w, http.StatusOK, assignment)
+}
+
+func (s *Server) handleDeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	if _, err := s.store.DeleteAssignment(assignment.ID); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus tugas"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Tugas berhasil dihapus"})
+}
+
+func (s *Server) handleGetAssignmentExtensions(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+
+	extensions, err := s.store.GetAssignmentExtensions(assignment.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar perpanjangan"})
+		return
+	}
+	loc := assignmentZone(assignment)
+	for _, e := range extensions {
+		localizeExtension(e, loc)
+	}
+	WriteJSON(w, http.StatusOK, extensions)
+}
+
+// Memberikan atau mengganti perpanjangan tenggat untuk seorang siswa anggota kelas
+func (s *Server) handleSetAssignmentExtension(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, ok := s.assignmentForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+	siswaID := mux.Vars(r)["siswaId"]
+
+	var req models.ExtensionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tenggat baru wajib diisi"})
+		return
+	}
+
+	loc := assignmentZone(assignment)
+	extension := models.AssignmentExtension{
+		AssignmentID:  assignment.ID,
+		SiswaID:       siswaID,
+		Alasan:        strings.TrimSpace(req.Alasan),
+		DiberikanOleh: claims.UserID,
+	}
+	var err error
+	if extension.DitutupPada, err = deadline.ParseTime(req.DitutupPada, loc); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format ditutup_pada tidak valid"})
+		return
+	}
+	if req.BatasTerlambat != "" {
+		batas, err := deadline.ParseTime(req.BatasTerlambat, loc)
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format batas_terlambat tidak valid"})
+			return
+		}
+		if batas.Before(extension.DitutupPada) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Batas terlambat tidak boleh sebelum tenggat"})
+			return
+		}
+		extension.BatasTerlambat = &batas
+	}
+
+	member, err := s.store.IsClassMember(assignment.KelasID, siswaID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa anggota kelas"})
+		return
+	}
+	if !member {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Siswa bukan anggota kelas ini"})
+		return
+	}
+
+	if err := s.store.SetAssignmentExtension(&extension); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan perpanjangan"})
+		return
+	}
+	localizeExtension(&extension, loc)
+	WriteJSON(w, http.StatusOK, extension)
+}
+
+func (s *Server) handleDeleteAssignmentExtension(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	rows, err := s.store.DeleteAssignmentExtension(assignment.ID, mux.Vars(r)["siswaId"])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus perpanjangan"})
+		return
+	}
+	if rows == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Perpanjangan tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Perpanjangan berhasil dihapus"})
+}
+
+// --- Handlers Tugas (siswa) ---
+
+// Tugas yang masih dapat dikerjakan siswa, dari tenggat terdekat
+func (s *Server) handleGetUpcomingAssignments(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	now := time.Now()
+	assignments, err := s.store.GetUpcomingAssignments(claims.UserID, now)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar tugas"})
+		return
+	}
+	for _, a := range assignments {
+		applyStudentStatus(a, a.Perpanjangan, now)
+		localizeAssignment(a)
+	}
+	WriteJSON(w, http.StatusOK, assignments)
+}
+
+func (s *Server) handleGetStudentAssignment(w http.ResponseWriter, r *http.Request) {
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	applyStudentStatus(assignment, extension, now)
+	showQuestions := assignment.Status != deadline.StatusBelumDibuka
+	if assignment.ModeUjian {
+		// Soal ujian baru ditampilkan setelah siswa memulai sesi
+		claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+		session, _, err := s.store.GetExamSession(assignment.ID, claims.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi ujian"})
+			return
+		}
+		if session != nil {
+			session.SisaDetik = remainingSeconds(session, now)
+			localizeExamSession(session, assignmentZone(assignment))
+		}
+		assignment.SesiUjian = session
+		showQuestions = showQuestions && session != nil
+	}
+	if showQuestions {
+		questions, err := s.store.GetAssignmentQuestions(assignment.ID)
+		if err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil soal tugas"})
+			return
+		}
+		for _, q := range questions {
+			q.KunciJawaban = ""
+		}
+		assignment.Soal = questions
+	}
+	localizeAssignment(assignment)
+	WriteJSON(w, http.StatusOK, assignment)
+}
+
+// --- Helper ---
+
+// assignmentForRequest memuat tugas dari path {id} lalu memeriksa hak akses pada kelasnya
+func (s *Server) assignmentForRequest(w http.ResponseWriter, r *http.Request, capability classCapability) (*models.Assignment, bool) {
+	assignment, err := s.store.GetAssignmentByID(mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas tidak ditemukan"})
+			return nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data tugas"})
+		return nil, false
+	}
+	if !s.authorizeClass(w, r, assignment.KelasID, capability) {
+		return nil, false
+	}
+	return assignment, true
+}
+
+// studentAssignmentForRequest memuat tugas dari path {id} untuk siswa anggota kelasnya,
+// beserta perpanjangan milik siswa tersebut jika ada. Tugas di kelas arsip tidak ditampilkan.
+func (s *Server) studentAssignmentForRequest(w http.ResponseWriter, r *http.Request) (*models.Assignment, *models.AssignmentExtension, bool) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	assignment, err := s.store.GetAssignmentByID(mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas tidak ditemukan"})
+			return nil, nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data tugas"})
+		return nil, nil, false
+	}
+
+	member, err := s.store.IsClassMember(assignment.KelasID, claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa anggota kelas"})
+		return nil, nil, false
+	}
+	access, err := s.store.GetClassAccess(assignment.KelasID, claims.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa anggota kelas"})
+		return nil, nil, false
+	}
+	if !member || access == nil || access.Archived {
+		// Tidak membedakan "tidak ada" dan "bukan anggota" agar id tugas tidak dapat ditebak
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas tidak ditemukan"})
+		return nil, nil, false
+	}
+
+	extension, err := s.store.GetAssignmentExtension(assignment.ID, claims.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data perpanjangan"})
+		return nil, nil, false
+	}
+	assignment.Perpanjangan = extension
+	return assignment, extension, true
+}
+
+// decodeAssignmentRequest membaca dan memvalidasi body tugas, termasuk urutan jadwalnya
+func decodeAssignmentRequest(w http.ResponseWriter, r *http.Request) (*models.Assignment, bool) {
+	var req models.AssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return nil, false
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Data tugas tidak lengkap atau tidak valid"})
+		return nil, false
+	}
+
+	loc, err := deadline.LoadZone(req.ZonaWaktu)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Zona waktu tidak dikenal"})
+		return nil, false
+	}
+	assignment := &models.Assignment{
+		Judul:          strings.TrimSpace(req.Judul),
+		Deskripsi:      req.Deskripsi,
+		PenaltiPerHari: req.PenaltiPerHari,
+		ZonaWaktu:      loc.String(),
+		MaksPercobaan:  req.MaksPercobaan,
+		KebijakanNilai: req.KebijakanNilai,
+		SoalIDs:        req.SoalIDs,
+	}
+	if assignment.MaksPercobaan == 0 {
+		assignment.MaksPercobaan = 1
+	}
+	if assignment.KebijakanNilai == "" {
+		assignment.KebijakanNilai = scoring.PolicyLatest
+	}
+	if req.ModeUjian {
+		assignment.ModeUjian = true
+		assignment.DurasiMenit = req.DurasiMenit
+	}
+	if assignment.DibukaPada, err = deadline.ParseTime(req.DibukaPada, loc); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format dibuka_pada tidak valid"})
+		return nil, false
+	}
+	if assignment.DitutupPada, err = deadline.ParseTime(req.DitutupPada, loc); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format ditutup_pada tidak valid"})
+		return nil, false
+	}
+	if !assignment.DitutupPada.After(assignment.DibukaPada) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Waktu tutup harus setelah waktu buka"})
+		return nil, false
+	}
+	if req.BatasTerlambat != "" {
+		batas, err := deadline.ParseTime(req.BatasTerlambat, loc)
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format batas_terlambat tidak valid"})
+			return nil, false
+		}
+		if batas.Before(assignment.DitutupPada) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Batas terlambat tidak boleh sebelum waktu tutup"})
+			return nil, false
+		}
+		assignment.BatasTerlambat = &batas
+	}
+	return assignment, true
+}
+
+func writeAssignmentSaveError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrQuestionNotInClass) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Semua soal harus berasal dari materi di kelas ini"})
+		return
+	}
+	WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan tugas"})
+}
+
+// assignmentWindow menghasilkan jadwal efektif siswa; perpanjangan menggantikan tenggat tugas
+func assignmentWindow(a *models.Assignment, extension *models.AssignmentExtension) deadline.Window {
+	window := deadline.Window{
+		DibukaPada:     a.DibukaPada,
+		DitutupPada:    a.DitutupPada,
+		BatasTerlambat: a.BatasTerlambat,
+		PenaltiPerHari: a.PenaltiPerHari,
+	}
+	if extension != nil {
+		window.DitutupPada = extension.DitutupPada
+		window.BatasTerlambat = extension.BatasTerlambat
+	}
+	return window
+}
+
+func applyStudentStatus(a *models.Assignment, extension *models.AssignmentExtension, now time.Time) {
+	result := assignmentWindow(a, extension).Evaluate(now)
+	a.Status = result.Status
+	a.HariTerlambat = result.HariTerlambat
+	a.PenaltiPersen = result.PenaltiPersen
+}
+
+func assignmentZone(a *models.Assignment) *time.Location {
+	loc, err := deadline.LoadZone(a.ZonaWaktu)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// localizeAssignment menampilkan semua waktu tugas pada zona waktu tugas
+func localizeAssignment(a *models.Assignment) {
+	loc := assignmentZone(a)
+	a.DibukaPada = a.DibukaPada.In(loc)
+	a.DitutupPada = a.DitutupPada.In(loc)
+	if a.BatasTerlambat != nil {
+		batas := a.BatasTerlambat.In(loc)
+		a.BatasTerlambat = &batas
+	}
+	if a.Perpanjangan != nil {
+		localizeExtension(a.Perpanjangan, loc)
+	}
+}
+
+func localizeExtension(e *models.AssignmentExtension, loc *time.Location) {
+	e.DitutupPada = e.DitutupPada.In(loc)
+	if e.BatasTerlambat != nil {
+		batas := e.BatasTerlambat.In(loc)
+		e.BatasTerlambat = &batas
+	}
+}
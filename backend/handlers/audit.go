@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/audit"
+	"sistem-skripsi/backend/models"
+	"time"
+)
+
+// recordAudit menambahkan entri jejak audit untuk aksi pada permintaan r. Aktor diambil dari
+// klaim token; r nil berarti aksi sistem (mis. job terjadwal). before dan after adalah keadaan
+// target sebelum dan sesudah aksi (boleh nil) dan hanya field yang berubah yang disimpan.
+// Kegagalan hanya dicatat di log karena aksinya sudah terjadi.
+func (s *Server) recordAudit(r *http.Request, entry models.AuditEntry, before any, after any) {
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		log.Printf("Gagal menyusun perubahan audit %s: %v", entry.Aksi, err)
+	}
+	entry.Perubahan = changes
+	if r != nil {
+		if claims, ok := r.Context().Value(userClaimsKey).(*models.Claims); ok && entry.AktorID == "" {
+			entry.AktorID = claims.UserID
+			entry.AktorPeran = claims.Peran
+		}
+		entry.IP = clientIP(r)
+		entry.RequestID, _ = r.Context().Value(requestIDKey).(string)
+	}
+	if err := s.store.AppendAuditEntry(&entry); err != nil {
+		log.Printf("Gagal mencatat audit %s pada %s %s: %v", entry.Aksi, entry.TargetJenis, entry.TargetID, err)
+	}
+}
+
+// --- Handlers Jejak Audit (Superadmin) ---
+
+// Daftar jejak audit terbaru lebih dulu. Filter: aktor_id, aksi (akhiri dengan titik untuk
+// awalan, mis. "user."), target_jenis, target_id, kelas_id, request_id, ip, serta from dan to
+// berformat YYYY-MM-DD (inklusif, zona waktu laporan) atau RFC 3339.
+func (s *Server) handleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, limit := parsePagination(r)
+	filter := models.AuditFilter{
+		AktorID:     q.Get("aktor_id"),
+		Aksi:        q.Get("aksi"),
+		TargetJenis: q.Get("target_jenis"),
+		TargetID:    q.Get("target_id"),
+		KelasID:     q.Get("kelas_id"),
+		RequestID:   q.Get("request_id"),
+		IP:          q.Get("ip"),
+		Page:        page,
+		Limit:       limit,
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.Dari, "to": &filter.Sampai} {
+		value := q.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseAuditTime(value, param == "to")
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter '" + param + "' harus berformat YYYY-MM-DD atau RFC 3339"})
+			return
+		}
+		*target = &t
+	}
+
+	entries, total, err := s.store.GetAuditLog(filter)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil jejak audit"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, models.PaginatedResponse{Data: entries, Page: page, Limit: limit, Total: total})
+}
+
+// Tanggal tanpa jam untuk batas akhir mencakup seluruh hari tersebut
+func parseAuditTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, usageZone())
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+var errAuditChainBroken = errors.New("rantai audit terputus")
+
+// Memeriksa seluruh rantai hash dari entri pertama. Entri pertama yang tidak cocok menandai
+// titik manipulasi; entri sesudahnya tidak diperiksa lagi.
+func (s *Server) handleVerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	verifier := audit.NewVerifier()
+	result := models.AuditVerification{Valid: true}
+	err := s.store.StreamAuditLog(func(e *models.AuditEntry) error {
+		reason, err := verifier.Check(e)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			id := e.ID
+			result.Valid = false
+			result.RusakPadaID = &id
+			result.Alasan = reason
+			return errAuditChainBroken
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa jejak audit"})
+		return
+	}
+	result.JumlahDiperiksa = verifier.Checked()
+	WriteJSON(w, http.StatusOK, result)
+}
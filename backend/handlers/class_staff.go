@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Staf Kelas ---
+
+func (s *Server) handleGetClassStaff(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+
+	staff, err := s.store.GetClassStaff(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar staf kelas"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, staff)
+}
+
+func (s *Server) handleInviteClassStaff(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageClass) {
+		return
+	}
+
+	var req models.InviteStaffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Identifier wajib diisi dan peran harus co_teacher atau assistant"})
+		return
+	}
+
+	invitation, err := s.store.InviteClassStaff(classID, req.Identifier, req.Peran, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInviteeNotTeacher):
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Pengguna tidak ditemukan atau bukan guru aktif"})
+		case errors.Is(err, store.ErrAlreadyClassStaff):
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Pengguna sudah menjadi staf atau sudah diundang ke kelas ini"})
+		default:
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengundang staf"})
+		}
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditStaffInvited, TargetJenis: "user", TargetID: invitation.UserID, KelasID: classID}, nil, invitation)
+	WriteJSON(w, http.StatusCreated, invitation)
+}
+
+func (s *Server) handleUpdateClassStaff(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if !s.authorizeClass(w, r, vars["id"], capManageClass) {
+		return
+	}
+
+	var req models.UpdateStaffRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Peran harus co_teacher atau assistant"})
+		return
+	}
+
+	rowsAffected, err := s.store.UpdateClassStaffRole(vars["id"], vars["userId"], req.Peran)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengubah peran staf"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Staf tidak ditemukan"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditStaffRoleChanged, TargetJenis: "user", TargetID: vars["userId"], KelasID: vars["id"]},
+		nil, map[string]string{"peran": req.Peran})
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Peran staf berhasil diubah"})
+}
+
+// Pemilik dapat mengeluarkan staf, dan staf dapat keluar dari kelas atas kemauan sendiri
+func (s *Server) handleRemoveClassStaff(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	vars := mux.Vars(r)
+	if vars["userId"] != claims.UserID && !s.authorizeClass(w, r, vars["id"], capManageClass) {
+		return
+	}
+
+	rowsAffected, err := s.store.RemoveClassStaff(vars["id"], vars["userId"])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus staf"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Staf tidak ditemukan"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditStaffRemoved, TargetJenis: "user", TargetID: vars["userId"], KelasID: vars["id"]}, nil, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Staf berhasil dihapus dari kelas"})
+}
+
+// --- Handlers Undangan Staf ---
+
+func (s *Server) handleGetStaffInvitations(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	invitations, err := s.store.GetPendingStaffInvitations(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar undangan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, invitations)
+}
+
+func (s *Server) handleAcceptStaffInvitation(w http.ResponseWriter, r *http.Request) {
+	s.respondStaffInvitation(w, r, true, "Undangan diterima")
+}
+
+func (s *Server) handleDeclineStaffInvitation(w http.ResponseWriter, r *http.Request) {
+	s.respondStaffInvitation(w, r, false, "Undangan ditolak")
+}
+
+func (s *Server) respondStaffInvitation(w http.ResponseWriter, r *http.Request, accept bool, successMessage string) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	rowsAffected, err := s.store.RespondStaffInvitation(mux.Vars(r)["id"], claims.UserID, accept)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memproses undangan"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Undangan tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": successMessage})
+}
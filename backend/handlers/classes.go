@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sistem-skripsi/backend/models"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Siklus Hidup Kelas ---
+
+func (s *Server) handleGetClass(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+
+	class, err := s.store.GetClassByID(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data kelas"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, class)
+}
+
+func (s *Server) handleUpdateClass(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageClass) {
+		return
+	}
+
+	var req models.UpdateClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Data tidak lengkap atau tidak valid"})
+		return
+	}
+
+	before, _ := s.store.GetClassByID(classID)
+	class, err := s.store.UpdateClass(classID, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Kelas telah diarsipkan dan hanya dapat dibaca"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui kelas"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditClassUpdated, TargetJenis: "class", TargetID: classID, KelasID: classID}, before, class)
+	WriteJSON(w, http.StatusOK, class)
+}
+
+func (s *Server) handleArchiveClass(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageClass) {
+		return
+	}
+	s.setClassArchived(w, r, classID, true, "Kelas berhasil diarsipkan")
+}
+
+func (s *Server) handleUnarchiveClass(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.checkClassAccess(w, r, classID, capManageClass, true) {
+		return
+	}
+	s.setClassArchived(w, r, classID, false, "Kelas berhasil diaktifkan kembali")
+}
+
+func (s *Server) setClassArchived(w http.ResponseWriter, r *http.Request, classID string, archived bool, successMessage string) {
+	rowsAffected, err := s.store.SetClassArchived(classID, archived)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengubah status arsip kelas"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusConflict, map[string]string{"message": "Status arsip kelas tidak berubah"})
+		return
+	}
+	action := models.AuditClassUnarchived
+	if archived {
+		action = models.AuditClassArchived
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: action, TargetJenis: "class", TargetID: classID, KelasID: classID},
+		map[string]bool{"diarsipkan": !archived}, map[string]bool{"diarsipkan": archived})
+	WriteJSON(w, http.StatusOK, map[string]string{"message": successMessage})
+}
+
+// Menyalin materi, soal, dan rubrik ke kelas baru milik pengguna untuk periode berikutnya.
+// Kelas arsip tetap boleh disalin karena hanya dibaca.
+func (s *Server) handleCloneClass(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	if !s.checkClassAccess(w, r, classID, capManageContent, true) {
+		return
+	}
+
+	var req models.CloneClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Semester harus ganjil atau genap"})
+		return
+	}
+
+	clone := models.Class{
+		GuruID:      claims.UserID,
+		NamaKelas:   req.NamaKelas,
+		TahunAjaran: req.TahunAjaran,
+		Semester:    req.Semester,
+		PeranSaya:   models.ClassRoleOwner,
+	}
+	if err := s.store.CloneClass(classID, &clone); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyalin kelas"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{
+		Aksi:        models.AuditClassCloned,
+		TargetJenis: "class",
+		TargetID:    clone.ID,
+		KelasID:     clone.ID,
+		Metadata:    map[string]any{"kelas_asal": classID},
+	}, nil, clone)
+	WriteJSON(w, http.StatusCreated, clone)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/realtime"
+	"sistem-skripsi/backend/webhook"
+	"time"
+)
+
+const (
+	eventHeartbeat    = 25 * time.Second
+	eventWriteTimeout = 10 * time.Second
+	eventReconnectMS  = 3000
+)
+
+// --- Peristiwa ---
+
+// publishSubmissionEvent menerbitkan peristiwa jawaban ke langganan webhook (kelas dan global),
+// ke staf kelas yang sedang terhubung, dan ke siswa pemilik jawaban. ID peristiwa sama di semua
+// saluran. Kegagalan hanya dicatat agar tidak membatalkan aksi yang memicu peristiwa.
+func (s *Server) publishSubmissionEvent(classID string, event string, data models.SubmissionEvent) {
+	now := time.Now()
+	payload, err := webhook.NewPayload(event, classID, data, now)
+	if err != nil {
+		log.Printf("Gagal membuat peristiwa %s: %v", event, err)
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Gagal membuat peristiwa %s: %v", event, err)
+		return
+	}
+	if _, err := s.store.EnqueueWebhookEvent(classID, payload.ID, event, body); err != nil {
+		log.Printf("Gagal menjadwalkan webhook %s untuk kelas %s: %v", event, classID, err)
+	}
+
+	msg, err := realtime.NewMessage(payload.ID, event, data, now)
+	if err != nil {
+		log.Printf("Gagal membuat pesan realtime %s: %v", event, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, topic := range []string{realtime.ClassTopic(classID), realtime.UserTopic(data.SiswaID)} {
+		if err := s.events.Publish(ctx, topic, msg); err != nil {
+			log.Printf("Gagal menerbitkan %s ke %s: %v", event, topic, err)
+		}
+	}
+}
+
+func (s *Server) publishSubmissionCreated(classID string, sub *models.Submission) {
+	s.publishSubmissionEvent(classID, webhook.EventSubmissionCreated, models.SubmissionEvent{
+		SubmissionID:  sub.ID,
+		AssignmentID:  sub.AssignmentID,
+		SoalID:        sub.SoalID,
+		SiswaID:       sub.SiswaID,
+		PercobaanKe:   sub.PercobaanKe,
+		HariTerlambat: sub.HariTerlambat,
+	})
+	s.notifyReviewPending(classID, sub)
+}
+
+// publishGradingResult mengirim grading.completed atau grading.failed sesuai status hasil AI
+func (s *Server) publishGradingResult(sub *models.Submission, result *models.AIResult) {
+	event := webhook.EventGradingCompleted
+	if result.Status == models.AIResultFailed {
+		event = webhook.EventGradingFailed
+	} else if result.Status != models.AIResultSucceeded {
+		return
+	}
+	classID, err := s.store.GetSubmissionClassID(sub.ID)
+	if err != nil {
+		log.Printf("Gagal mengambil kelas jawaban %s untuk peristiwa %s: %v", sub.ID, event, err)
+		return
+	}
+	s.publishSubmissionEvent(classID, event, models.SubmissionEvent{
+		SubmissionID: sub.ID,
+		AssignmentID: sub.AssignmentID,
+		SoalID:       sub.SoalID,
+		SiswaID:      sub.SiswaID,
+		PercobaanKe:  sub.PercobaanKe,
+		StatusAI:     result.Status,
+		SkorAI:       result.SkorAI,
+	})
+}
+
+func (s *Server) publishReview(review *models.TeacherReview) {
+	sub, classID, err := s.store.GetSubmissionWithClass(review.SubmissionID)
+	if err != nil {
+		log.Printf("Gagal mengambil jawaban %s untuk peristiwa %s: %v", review.SubmissionID, webhook.EventReviewPublished, err)
+		return
+	}
+	s.publishSubmissionEvent(classID, webhook.EventReviewPublished, models.SubmissionEvent{
+		SubmissionID: sub.ID,
+		AssignmentID: sub.AssignmentID,
+		SoalID:       sub.SoalID,
+		SiswaID:      sub.SiswaID,
+		PercobaanKe:  sub.PercobaanKe,
+		SkorFinal:    &review.SkorFinal,
+		ReviewerID:   review.ReviewerID,
+	})
+	s.notifyGradePublished(classID, sub, review)
+}
+
+// --- Handlers Aliran Peristiwa ---
+
+// Aliran Server-Sent Events. Setiap pengguna menerima peristiwa jawabannya sendiri; staf
+// kelas juga menerima perubahan antrean tinjauan untuk setiap ?kelas_id= yang diminta.
+// Peristiwa yang terjadi saat klien terputus tidak diputar ulang, sehingga klien sebaiknya
+// memuat ulang data setiap kali tersambung.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	topics, ok := s.eventTopics(w, r)
+	if !ok {
+		return
+	}
+	rc := http.NewResponseController(w)
+	sub := s.events.Subscribe(topics...)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Nginx tidak menahan aliran
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n: tersambung\n\n", eventReconnectMS)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				// Antrean penuh; klien tersambung ulang otomatis
+				return
+			}
+			data, err := json.Marshal(msg)
+			if err != nil {
+				log.Printf("Gagal mengodekan pesan realtime %s: %v", msg.ID, err)
+				continue
+			}
+			rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event, data)
+		case <-heartbeat.C:
+			rc.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Alternatif WebSocket untuk jaringan yang memotong SSE. Pesan berbentuk JSON yang sama dengan
+// data SSE; pesan dari klien diabaikan.
+func (s *Server) handleEventSocket(w http.ResponseWriter, r *http.Request) {
+	topics, ok := s.eventTopics(w, r)
+	if !ok {
+		return
+	}
+	conn, err := realtime.Upgrade(w, r)
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Permintaan upgrade WebSocket tidak valid"})
+		return
+	}
+	sub := s.events.Subscribe(topics...)
+	defer sub.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		conn.ReadLoop(2 * eventHeartbeat)
+		close(closed)
+	}()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				conn.Close(realtime.CloseTryLater, "antrean pesan penuh")
+				return
+			}
+			data, merr := json.Marshal(msg)
+			if merr != nil {
+				log.Printf("Gagal mengodekan pesan realtime %s: %v", msg.ID, merr)
+				continue
+			}
+			err = conn.WriteText(data, eventWriteTimeout)
+		case <-heartbeat.C:
+			err = conn.Ping(eventWriteTimeout)
+		}
+		if err != nil {
+			conn.Close(realtime.CloseGoingAway, "")
+			return
+		}
+	}
+}
+
+// eventTopics menentukan topik langganan pengguna. Setiap kelas_id memerlukan hak meninjau
+// di kelas tersebut.
+func (s *Server) eventTopics(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	topics := []string{realtime.UserTopic(claims.UserID)}
+	for _, classID := range r.URL.Query()["kelas_id"] {
+		if claims.Peran == "student" {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akses ditolak: Memerlukan hak akses guru atau superadmin"})
+			return nil, false
+		}
+		if !s.checkClassAccess(w, r, classID, capReview, true) {
+			return nil, false
+		}
+		topics = append(topics, realtime.ClassTopic(classID))
+	}
+	return topics, true
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// Header tempat klien mengirim token sesi ujian
+const examTokenHeader = "X-Exam-Token"
+
+// --- Handlers Ujian (siswa) ---
+
+// Memulai ujian dan menerbitkan token sesi. Waktu berakhir dihitung server: durasi ujian
+// sejak mulai, tetapi tidak melewati batas akhir jadwal siswa. Jika sesi sudah berjalan
+// (mis. browser tertutup), token baru diterbitkan tanpa mengubah waktu berakhir.
+func (s *Server) handleStartExam(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	if !assignment.ModeUjian {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tugas ini bukan ujian"})
+		return
+	}
+
+	now := time.Now()
+	window := assignmentWindow(assignment, extension)
+	if !writeWindowClosed(w, window.Evaluate(now)) {
+		return
+	}
+
+	token, err := newExamToken()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai ujian"})
+		return
+	}
+	berakhir := now.Add(time.Duration(assignment.DurasiMenit) * time.Minute)
+	hardEnd := window.DitutupPada
+	if window.BatasTerlambat != nil {
+		hardEnd = *window.BatasTerlambat
+	}
+	if berakhir.After(hardEnd) {
+		berakhir = hardEnd
+	}
+
+	session := models.ExamSession{
+		AssignmentID: assignment.ID,
+		SiswaID:      claims.UserID,
+		DimulaiPada:  now,
+		BerakhirPada: berakhir,
+		IPTerakhir:   clientIP(r),
+	}
+	reissued, err := s.store.StartExamSession(&session, hashExamToken(token))
+	if err != nil {
+		if errors.Is(err, store.ErrExamFinished) {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Ujian sudah selesai"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai ujian"})
+		return
+	}
+	if reissued {
+		s.recordServerIntegrityEvent(session.ID, models.IntegrityTokenReissued, "Ujian dimulai ulang", clientIP(r))
+	}
+
+	session.Token = token
+	session.SisaDetik = remainingSeconds(&session, now)
+	localizeExamSession(&session, assignmentZone(assignment))
+	WriteJSON(w, http.StatusOK, session)
+}
+
+// Status sesi ujian dengan sisa waktu menurut server. Sesi yang waktunya habis langsung
+// diselesaikan sehingga klien tidak bergantung pada worker.
+func (s *Server) handleGetExamSession(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	session, _, err := s.store.GetExamSession(assignment.ID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Ujian belum dimulai"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi ujian"})
+		return
+	}
+
+	now := time.Now()
+	if session.SelesaiPada == nil && !now.Before(session.BerakhirPada) {
+		s.finishExam(session, assignment, extension, "waktu_habis")
+		if session, _, err = s.store.GetExamSession(assignment.ID, claims.UserID); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi ujian"})
+			return
+		}
+	}
+	session.SisaDetik = remainingSeconds(session, now)
+	localizeExamSession(session, assignmentZone(assignment))
+	WriteJSON(w, http.StatusOK, session)
+}
+
+// Menyelesaikan ujian lebih awal; draf terakhir setiap soal dikumpulkan
+func (s *Server) handleFinishExam(w http.ResponseWriter, r *http.Request) {
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	session, ok := s.examSessionForRequest(w, r, assignment)
+	if !ok {
+		return
+	}
+
+	submitted, err := s.finishExam(session, assignment, extension, "dikumpulkan")
+	if err != nil {
+		if errors.Is(err, store.ErrExamFinished) {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Ujian sudah selesai"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyelesaikan ujian"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "Ujian berhasil dikumpulkan", "jumlah_dikumpulkan": submitted})
+}
+
+// Mencatat peristiwa integritas yang dilaporkan klien (pindah tab, tempel teks, dsb.)
+func (s *Server) handleReportIntegrityEvent(w http.ResponseWriter, r *http.Request) {
+	assignment, _, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	session, ok := s.examSessionForRequest(w, r, assignment)
+	if !ok {
+		return
+	}
+
+	var req models.IntegrityEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Jenis peristiwa tidak dikenal"})
+		return
+	}
+
+	event := models.IntegrityEvent{
+		SessionID: session.ID,
+		Jenis:     req.Jenis,
+		Detail:    strings.TrimSpace(req.Detail),
+		IP:        clientIP(r),
+	}
+	if req.TerjadiPada != "" {
+		if t, err := time.Parse(time.RFC3339, req.TerjadiPada); err == nil {
+			event.TerjadiPada = &t
+		}
+	}
+	if err := s.store.RecordIntegrityEvent(&event); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mencatat peristiwa"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, event)
+}
+
+// --- Handlers Ujian (guru) ---
+
+func (s *Server) handleGetExamSessions(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capReview)
+	if !ok {
+		return
+	}
+
+	sessions, err := s.store.GetExamSessions(assignment.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar sesi ujian"})
+		return
+	}
+	now, loc := time.Now(), assignmentZone(assignment)
+	for _, session := range sessions {
+		session.SisaDetik = remainingSeconds(session, now)
+		localizeExamSession(session, loc)
+	}
+	WriteJSON(w, http.StatusOK, sessions)
+}
+
+// Sesi ujian seorang siswa beserta seluruh catatan integritasnya
+func (s *Server) handleGetStudentExamSession(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capReview)
+	if !ok {
+		return
+	}
+
+	session, _, err := s.store.GetExamSession(assignment.ID, mux.Vars(r)["siswaId"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Siswa belum memulai ujian"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi ujian"})
+		return
+	}
+	session.Peristiwa, err = s.store.GetIntegrityEvents(session.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil catatan integritas"})
+		return
+	}
+	session.SisaDetik = remainingSeconds(session, time.Now())
+	localizeExamSession(session, assignmentZone(assignment))
+	WriteJSON(w, http.StatusOK, session)
+}
+
+// AutoSubmitExpiredExams menyelesaikan semua sesi ujian yang waktunya sudah habis dan
+// mengumpulkan draf terakhirnya. Dipanggil berkala dari main.
+func (s *Server) AutoSubmitExpiredExams(now time.Time) (int, error) {
+	sessions, err := s.store.GetExpiredExamSessions(now)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, session := range sessions {
+		assignment, err := s.store.GetAssignmentByID(session.AssignmentID)
+		if err != nil {
+			log.Printf("Gagal memuat tugas %s untuk sesi ujian %s: %v", session.AssignmentID, session.ID, err)
+			continue
+		}
+		extension, err := s.store.GetAssignmentExtension(assignment.ID, session.SiswaID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Gagal memuat perpanjangan untuk sesi ujian %s: %v", session.ID, err)
+			continue
+		}
+		if _, err := s.finishExam(session, assignment, extension, "waktu_habis"); err != nil {
+			if !errors.Is(err, store.ErrExamFinished) {
+				log.Printf("Gagal menyelesaikan sesi ujian %s: %v", session.ID, err)
+			}
+			continue
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// --- Helper ---
+
+// examSessionForRequest memastikan request membawa token sesi ujian yang sah dan masih
+// berjalan. Perubahan IP dicatat sebagai peristiwa integritas. Untuk tugas biasa selalu lolos
+// dengan sesi nil.
+func (s *Server) examSessionForRequest(w http.ResponseWriter, r *http.Request, assignment *models.Assignment) (*models.ExamSession, bool) {
+	if !assignment.ModeUjian {
+		return nil, true
+	}
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	session, tokenHash, err := s.store.GetExamSession(assignment.ID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Ujian belum dimulai"})
+			return nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi ujian"})
+		return nil, false
+	}
+	token := r.Header.Get(examTokenHeader)
+	if token == "" || subtle.ConstantTimeCompare([]byte(hashExamToken(token)), []byte(tokenHash)) != 1 {
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Token ujian tidak valid"})
+		return nil, false
+	}
+	if session.SelesaiPada != nil {
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Ujian sudah selesai"})
+		return nil, false
+	}
+	if !time.Now().Before(session.BerakhirPada) {
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Waktu ujian telah habis"})
+		return nil, false
+	}
+
+	if ip := clientIP(r); ip != "" && ip != session.IPTerakhir {
+		s.recordServerIntegrityEvent(session.ID, models.IntegrityIPChange, session.IPTerakhir+" -> "+ip, ip)
+		if err := s.store.UpdateExamSessionIP(session.ID, ip); err != nil {
+			log.Printf("Gagal memperbarui IP sesi ujian %s: %v", session.ID, err)
+		}
+		session.IPTerakhir = ip
+	}
+	return session, true
+}
+
+// finishExam menutup sesi dan mengumpulkan draf dengan penalti keterlambatan yang berlaku
+// pada saat sesi berakhir (atau sekarang, jika lebih awal)
+func (s *Server) finishExam(session *models.ExamSession, assignment *models.Assignment, extension *models.AssignmentExtension, alasan string) (int, error) {
+	at := time.Now()
+	if at.After(session.BerakhirPada) {
+		at = session.BerakhirPada
+	}
+	result := assignmentWindow(assignment, extension).Evaluate(at)
+	submitted, err := s.store.FinishExamSession(session.ID, alasan, result.HariTerlambat, result.PenaltiPersen)
+	if err != nil {
+		return 0, err
+	}
+	for _, sub := range submitted {
+		s.publishSubmissionCreated(assignment.KelasID, sub)
+	}
+	return len(submitted), nil
+}
+
+func (s *Server) recordServerIntegrityEvent(sessionID string, jenis string, detail string, ip string) {
+	event := models.IntegrityEvent{SessionID: sessionID, Jenis: jenis, Detail: detail, IP: ip}
+	if err := s.store.RecordIntegrityEvent(&event); err != nil {
+		log.Printf("Gagal mencatat peristiwa integritas %s untuk sesi %s: %v", jenis, sessionID, err)
+	}
+}
+
+func remainingSeconds(session *models.ExamSession, now time.Time) int64 {
+	if session.SelesaiPada != nil || !now.Before(session.BerakhirPada) {
+		return 0
+	}
+	return int64(session.BerakhirPada.Sub(now).Seconds())
+}
+
+func localizeExamSession(session *models.ExamSession, loc *time.Location) {
+	session.DimulaiPada = session.DimulaiPada.In(loc)
+	session.BerakhirPada = session.BerakhirPada.In(loc)
+	if session.SelesaiPada != nil {
+		selesai := session.SelesaiPada.In(loc)
+		session.SelesaiPada = &selesai
+	}
+}
+
+func newExamToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashExamToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
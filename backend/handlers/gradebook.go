@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/gradebook"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/pdfwriter"
+	"sistem-skripsi/backend/xlsx"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Lebar kolom tabel rapor dalam point; jumlahnya sama dengan lebar isi halaman
+var (
+	reportSummaryWidths  = []float64{395, 100}
+	reportMasteryWidths  = []float64{95, 130, 130, 140}
+	reportQuestionWidths = []float64{25, 170, 45, 255}
+)
+
+// --- Handlers Buku Nilai ---
+
+// Ekspor buku nilai kelas (siswa × tugas) sebagai CSV (default) atau XLSX (?format=xlsx).
+// ?aspek=true menambahkan kolom nilai per soal dan per aspek rubrik. Baris ditulis per siswa
+// langsung ke respons sehingga kelas besar tidak dibangun di memori.
+func (s *Server) handleExportGradebook(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.checkClassAccess(w, r, classID, capReview, true) {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format harus csv atau xlsx"})
+		return
+	}
+
+	class, book, err := s.loadGradebook(classID, r.URL.Query().Get("aspek") == "true")
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyiapkan buku nilai"})
+		return
+	}
+	filename := "nilai-" + class.NamaKelas + "." + format
+	s.recordAudit(r, models.AuditEntry{
+		Aksi:        models.AuditGradebookExported,
+		TargetJenis: "class",
+		TargetID:    classID,
+		KelasID:     classID,
+		Metadata:    map[string]any{"format": format, "aspek": book.WithAspects},
+	}, nil, nil)
+
+	// Setelah header dikirim, kegagalan hanya dapat dicatat di log
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", attachmentDisposition(filename))
+		cw := csv.NewWriter(w)
+		err = cw.Write(csvRecord(book.Header()))
+		if err == nil {
+			err = s.store.StreamGradebook(classID, "", book.WithAspects, func(student *models.GradebookStudent) error {
+				return cw.Write(csvRecord(book.Row(student)))
+			})
+		}
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", attachmentDisposition(filename))
+		var xw *xlsx.Writer
+		xw, err = xlsx.NewWriter(w, class.NamaKelas)
+		if err == nil {
+			err = xw.WriteRow(spreadsheetRow(book.Header()))
+		}
+		if err == nil {
+			err = s.store.StreamGradebook(classID, "", book.WithAspects, func(student *models.GradebookStudent) error {
+				return xw.WriteRow(spreadsheetRow(book.Row(student)))
+			})
+		}
+		if err == nil {
+			err = xw.Close()
+		}
+	}
+	if err != nil {
+		log.Printf("Gagal mengekspor buku nilai kelas %s: %v", classID, err)
+	}
+}
+
+// Rapor PDF seorang siswa: nilai setiap tugas dan soal, penguasaan C1–C4, serta umpan balik
+// AI dan catatan guru
+func (s *Server) handleStudentReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	classID, siswaID := vars["id"], vars["siswaId"]
+	if !s.checkClassAccess(w, r, classID, capReview, true) {
+		return
+	}
+	member, err := s.store.IsClassMember(classID, siswaID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa anggota kelas"})
+		return
+	}
+	if !member {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Siswa tidak ditemukan di kelas ini"})
+		return
+	}
+
+	class, book, err := s.loadGradebook(classID, true)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyiapkan rapor"})
+		return
+	}
+	var student *models.GradebookStudent
+	err = s.store.StreamGradebook(classID, siswaID, true, func(st *models.GradebookStudent) error {
+		student = st
+		return nil
+	})
+	if err == nil && student == nil {
+		err = errors.New("data siswa tidak ditemukan")
+	}
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil nilai siswa"})
+		return
+	}
+	mastery, err := s.store.GetClassMastery(classID, siswaID, defaultBatasTuntas)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil analitik penguasaan"})
+		return
+	}
+
+	doc := studentReport(class, book, student, mastery)
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditReportExported, TargetJenis: "user", TargetID: siswaID, KelasID: classID}, nil, nil)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", attachmentDisposition("rapor-"+student.NamaSiswa+".pdf"))
+	if _, err := doc.WriteTo(w); err != nil {
+		log.Printf("Gagal mengirim rapor siswa %s: %v", siswaID, err)
+	}
+}
+
+// loadGradebook mengambil kelas, tugasnya (urut tenggat terlama), dan soal setiap tugas
+func (s *Server) loadGradebook(classID string, withAspects bool) (*models.Class, *gradebook.Book, error) {
+	class, err := s.store.GetClassByID(classID)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments, err := s.store.GetAssignmentsByClassID(classID)
+	if err != nil {
+		return nil, nil, err
+	}
+	book := &gradebook.Book{Questions: map[string]*models.Question{}, WithAspects: withAspects}
+	for i := len(assignments) - 1; i >= 0; i-- {
+		questions, err := s.store.GetAssignmentQuestions(assignments[i].ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, q := range questions {
+			book.Questions[q.ID] = q
+		}
+		book.Assignments = append(book.Assignments, assignments[i])
+	}
+	return class, book, nil
+}
+
+func studentReport(class *models.Class, book *gradebook.Book, student *models.GradebookStudent, mastery *models.ClassMastery) *pdfwriter.Document {
+	grades := book.Grades(student)
+	doc := pdfwriter.New("Rapor " + student.NamaSiswa)
+	doc.Heading("Rapor Siswa")
+	doc.Text("Nama: " + student.NamaSiswa)
+	if student.NomorIdentitas != "" {
+		doc.Text("Nomor identitas: " + student.NomorIdentitas)
+	}
+	doc.Text("Kelas: " + class.NamaKelas)
+	doc.Text("Dicetak: " + time.Now().In(usageZone()).Format("02-01-2006 15:04"))
+
+	doc.Subheading("Ringkasan Nilai")
+	doc.Row([]string{"Tugas", "Nilai"}, reportSummaryWidths, true)
+	for _, g := range grades {
+		doc.Row([]string{g.Assignment.Judul, formatScore(g.Nilai)}, reportSummaryWidths, false)
+	}
+	doc.Row([]string{"Rata-rata", formatScore(gradebook.Average(grades))}, reportSummaryWidths, true)
+
+	doc.Subheading("Penguasaan Level Kognitif")
+	levels := []*models.MasteryLevel{}
+	if len(mastery.Siswa) > 0 {
+		levels = mastery.Siswa[0].Level
+	}
+	if len(levels) == 0 {
+		doc.Text("Belum ada jawaban yang dinilai.")
+	} else {
+		doc.Row([]string{"Level", "Rata-rata", "Jumlah jawaban", "Status"}, reportMasteryWidths, true)
+		for _, l := range levels {
+			status := "Belum tuntas"
+			if l.RataRata >= mastery.BatasTuntas {
+				status = "Tuntas"
+			}
+			avg := l.RataRata
+			doc.Row([]string{l.LevelKognitif, formatScore(&avg), strconv.Itoa(l.JumlahJawaban), status}, reportMasteryWidths, false)
+		}
+		doc.Text(fmt.Sprintf("Batas tuntas %s; data diperbarui %s.", strconv.FormatFloat(mastery.BatasTuntas, 'f', -1, 64), mastery.DiperbaruiPada))
+	}
+
+	for _, g := range grades {
+		doc.Subheading(fmt.Sprintf("%s (nilai: %s)", g.Assignment.Judul, formatScore(g.Nilai)))
+		doc.Row([]string{"No", "Soal", "Skor", "Umpan balik"}, reportQuestionWidths, true)
+		for _, q := range g.Soal {
+			teks := ""
+			if q.Soal != nil {
+				teks = q.Soal.TeksSoal
+			}
+			skor := formatScore(q.Skor)
+			if !q.Dijawab {
+				skor = "Tidak dijawab"
+			}
+			var feedback []string
+			if q.CatatanGuru != "" {
+				feedback = append(feedback, "Guru: "+q.CatatanGuru)
+			}
+			if q.UmpanBalikAI != "" {
+				feedback = append(feedback, "AI: "+q.UmpanBalikAI)
+			}
+			doc.Row([]string{strconv.Itoa(q.Nomor), teks, skor, strings.Join(feedback, "\n")}, reportQuestionWidths, false)
+		}
+	}
+	return doc
+}
+
+func csvRecord(values []any) []string {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = spreadsheetText(fmt.Sprint(v))
+		}
+	}
+	return record
+}
+
+// spreadsheetRow menetralkan sel teks sebelum ditulis ke XLSX; angka tidak diubah
+func spreadsheetRow(values []any) []any {
+	row := make([]any, len(values))
+	for i, v := range values {
+		if text, ok := v.(string); ok {
+			v = spreadsheetText(text)
+		}
+		row[i] = v
+	}
+	return row
+}
+
+// spreadsheetText mencegah teks buatan pengguna (mis. nama siswa) dibaca sebagai formula
+// oleh Excel atau Sheets dengan menambahkan apostrof di depan karakter pemicu formula.
+func spreadsheetText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatScore(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/gradecache"
+	"sistem-skripsi/backend/grading"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/prompt"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	gradingBatchSize = 20
+	gradingTimeout   = 2 * time.Minute // Termasuk permintaan perbaikan keluaran
+	// Jeda percobaan ulang penilaian otomatis yang gagal; berlipat dua setiap kegagalan
+	gradingRetryBase = time.Minute
+	gradingRetryMax  = 6 * time.Hour
+)
+
+var (
+	errGraderUnavailable = errors.New("penilai AI belum dikonfigurasi")
+	errNoCognitiveLevel  = errors.New("soal belum memiliki level kognitif")
+	errBudgetExceeded    = errors.New("anggaran model bulan ini telah habis")
+)
+
+// Isi logs_rag: jejak lengkap satu penilaian, termasuk keluaran mentah setiap percobaan
+type gradingLog struct {
+	LevelKognitif string            `json:"level_kognitif"`
+	PromptVersi   int               `json:"prompt_versi"`
+	Percobaan     []grading.Attempt `json:"percobaan"`
+	Koreksi       []string          `json:"koreksi,omitempty"`
+	Galat         string            `json:"galat,omitempty"`
+	CacheDari     string            `json:"cache_dari,omitempty"` // Jawaban asal hasil cache
+}
+
+// --- Handlers Penilaian AI ---
+
+// Menilai ulang sebuah jawaban secara langsung, mis. setelah template prompt diperbarui
+// atau hasil sebelumnya gagal. Cache tidak dibaca agar model benar-benar menilai ulang.
+func (s *Server) handleGradeSubmission(w http.ResponseWriter, r *http.Request) {
+	submissionID := mux.Vars(r)["id"]
+	if !s.authorizeSubmission(w, r, submissionID, capReview) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), gradingTimeout)
+	defer cancel()
+	result, err := s.gradeSubmission(ctx, submissionID, false)
+	if err != nil {
+		switch {
+		case errors.Is(err, errGraderUnavailable):
+			WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Penilaian AI belum diaktifkan"})
+		case errors.Is(err, sql.ErrNoRows):
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Jawaban tidak ditemukan"})
+		case errors.Is(err, errNoCognitiveLevel):
+			WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Soal belum memiliki level kognitif"})
+		case errors.Is(err, errBudgetExceeded):
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Anggaran penilaian AI bulan ini telah habis"})
+		default:
+			log.Printf("Gagal menilai jawaban %s: %v", submissionID, err)
+			WriteJSON(w, http.StatusBadGateway, map[string]string{"message": "Layanan AI gagal menilai jawaban"})
+		}
+		return
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+// GradePendingSubmissions menilai jawaban yang belum memiliki hasil AI. Dipanggil berkala dari main.
+func (s *Server) GradePendingSubmissions(now time.Time) (int, error) {
+	if s.grader == nil {
+		return 0, nil
+	}
+	submissions, err := s.store.GetUngradedSubmissions(gradingBatchSize, now)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, sub := range submissions {
+		ctx, cancel := context.WithTimeout(context.Background(), gradingTimeout)
+		_, err := s.GradeSubmission(ctx, sub.ID)
+		cancel()
+		if errors.Is(err, errBudgetExceeded) {
+			// Ditandai agar tidak dicoba lagi; jawaban tetap ada di antrean tinjauan guru
+			err = s.pauseGrading(sub.ID)
+		}
+		if err != nil {
+			// Jawaban dicoba lagi setelah jedanya habis, tanpa menahan jawaban lain
+			attempts, rerr := s.store.RecordGradingFailure(sub.ID, err.Error(), now, gradingRetryBase, gradingRetryMax)
+			if rerr != nil {
+				log.Printf("Gagal mencatat percobaan penilaian jawaban %s: %v", sub.ID, rerr)
+			}
+			log.Printf("Gagal menilai jawaban %s (percobaan ke-%d): %v", sub.ID, attempts, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// GradeSubmission menilai jawaban dengan memakai hasil cache jika tersedia. Dipakai penilaian
+// otomatis; penilaian ulang oleh guru memakai gradeSubmission tanpa cache.
+func (s *Server) GradeSubmission(ctx context.Context, submissionID string) (*models.AIResult, error) {
+	return s.gradeSubmission(ctx, submissionID, true)
+}
+
+// gradeSubmission merender prompt aktif untuk level soal, meminta penilaian terstruktur dari
+// model, lalu menyimpan hasilnya. Keluaran yang tetap tidak valid setelah diperbaiki disimpan
+// sebagai hasil gagal tanpa skor; error lain (mis. layanan tidak dapat dihubungi) tidak disimpan.
+// Hasil berhasil disimpan ke cache; jika useCache, cache diperiksa sebelum memanggil model.
+func (s *Server) gradeSubmission(ctx context.Context, submissionID string, useCache bool) (*models.AIResult, error) {
+	if s.grader == nil {
+		return nil, errGraderUnavailable
+	}
+	input, err := s.store.GetGradingInput(submissionID)
+	if err != nil {
+		return nil, err
+	}
+	level := input.Question.LevelKognitif
+	if level == "" {
+		return nil, errNoCognitiveLevel
+	}
+	template, err := s.store.GetActivePromptTemplate(level)
+	if err != nil {
+		return nil, err
+	}
+	input.MaterialVersion, err = s.ensureVersionChunks(input.MaterialVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AIResult{
+		SubmissionID:      submissionID,
+		MaterialVersionID: input.MaterialVersion.ID,
+		PromptTemplateID:  template.ID,
+		PromptVersi:       template.Versi,
+	}
+	logs := gradingLog{LevelKognitif: level, PromptVersi: template.Versi}
+
+	var cacheKey string
+	if s.gradeCache != nil {
+		cacheKey = gradecache.Key(gradecache.KeyInput{
+			Question:          input.Question,
+			Jawaban:           input.Submission.TeksJawaban,
+			MaterialVersionID: input.MaterialVersion.ID,
+			PromptTemplateID:  template.ID,
+		})
+	}
+	if useCache && cacheKey != "" {
+		entry, err := s.gradeCache.Get(ctx, cacheKey)
+		if err != nil {
+			log.Printf("Gagal membaca cache penilaian: %v", err)
+		} else if entry != nil {
+			result.ChunkIDs = entry.ChunkIDs
+			logs.CacheDari = entry.SubmissionID
+			applyGradingResult(result, &logs, &entry.Result)
+			return result, s.saveGradingResult(input.Submission, result, logs)
+		}
+	}
+
+	exceeded, err := s.store.IsLLMBudgetExceeded(submissionID, monthStart(time.Now(), usageZone()))
+	if err != nil {
+		return nil, err
+	}
+	if exceeded {
+		return nil, errBudgetExceeded
+	}
+
+	data, chunks := gradingPromptData(input)
+	rendered, err := prompt.Render(template.Isi, data)
+	if err != nil {
+		return nil, err
+	}
+	spec := gradingSpec(input.Question, chunks)
+	result.ChunkIDs = spec.ChunkIDs
+
+	graded, attempts, err := grading.Grade(ctx, s.grader, rendered+"\n\n"+grading.Instructions(spec), spec, grading.DefaultMaxRepairs)
+	logs.Percobaan = attempts
+	s.recordUsage(submissionID, attempts)
+	switch {
+	case err == nil:
+		applyGradingResult(result, &logs, graded)
+		if cacheKey != "" {
+			entry := &gradecache.Entry{Result: *graded, ChunkIDs: spec.ChunkIDs, SubmissionID: submissionID, CreatedAt: time.Now()}
+			if err := s.gradeCache.Set(ctx, cacheKey, entry, s.gradeCacheTTL); err != nil {
+				log.Printf("Gagal menyimpan cache penilaian: %v", err)
+			}
+		}
+	case errors.Is(err, grading.ErrInvalidOutput):
+		result.Status = models.AIResultFailed
+		logs.Galat = err.Error()
+	default:
+		return nil, err
+	}
+	return result, s.saveGradingResult(input.Submission, result, logs)
+}
+
+func applyGradingResult(result *models.AIResult, logs *gradingLog, graded *grading.Result) {
+	result.Status = models.AIResultSucceeded
+	result.SkorAI = &graded.Skor
+	result.UmpanBalikAI = graded.UmpanBalik
+	logs.Koreksi = graded.Koreksi
+	for _, a := range graded.Aspek {
+		result.Aspek = append(result.Aspek, &models.AIAspectScore{
+			RubricID:  a.RubricID,
+			NamaAspek: a.NamaAspek,
+			Skor:      a.Skor,
+			Alasan:    a.Alasan,
+			ChunkIDs:  a.ChunkIDs,
+		})
+	}
+}
+
+// saveGradingResult menyimpan hasil beserta jejaknya lalu menerbitkan peristiwa penilaian
+func (s *Server) saveGradingResult(sub *models.Submission, result *models.AIResult, logs gradingLog) error {
+	encoded, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+	result.LogsRAG = string(encoded)
+	if err := s.store.SaveAIResult(result); err != nil {
+		return err
+	}
+	s.publishGradingResult(sub, result)
+	return nil
+}
+
+// recordUsage mencatat setiap panggilan model untuk laporan biaya dan anggaran. Kegagalan
+// pencatatan tidak membatalkan hasil penilaian.
+func (s *Server) recordUsage(submissionID string, attempts []grading.Attempt) {
+	if len(attempts) == 0 {
+		return
+	}
+	usage := make([]*models.LLMUsage, len(attempts))
+	for i, a := range attempts {
+		usage[i] = &models.LLMUsage{
+			SubmissionID:     submissionID,
+			Provider:         a.Provider,
+			Model:            a.Model,
+			PromptTokens:     a.PromptTokens,
+			CompletionTokens: a.CompletionTokens,
+			LatencyMS:        a.LatencyMS,
+			KeluaranValid:    a.Galat == "",
+		}
+	}
+	if err := s.store.RecordLLMUsage(usage); err != nil {
+		log.Printf("Gagal mencatat pemakaian model untuk jawaban %s: %v", submissionID, err)
+	}
+}
+
+// pauseGrading menyimpan hasil berstatus ditunda untuk jawaban yang tidak dinilai karena anggaran habis
+func (s *Server) pauseGrading(submissionID string) error {
+	encoded, err := json.Marshal(gradingLog{Galat: errBudgetExceeded.Error()})
+	if err != nil {
+		return err
+	}
+	return s.store.SaveAIResult(&models.AIResult{SubmissionID: submissionID, Status: models.AIResultPaused, LogsRAG: string(encoded)})
+}
+
+// gradingSpec menyusun aspek rubrik dan chunk yang boleh dikutip untuk validasi keluaran model
+func gradingSpec(question *models.Question, chunks []*models.MaterialChunk) grading.Spec {
+	spec := grading.Spec{ChunkIDs: []string{}}
+	for _, rubric := range question.Rubrik {
+		aspect := grading.Aspect{RubricID: rubric.ID, Nama: rubric.NamaAspek, Bobot: rubric.Bobot}
+		if rubric.SkorMin != nil && rubric.SkorMaks != nil {
+			aspect.Min, aspect.Max = *rubric.SkorMin, *rubric.SkorMaks
+		}
+		spec.Aspek = append(spec.Aspek, aspect)
+	}
+	for _, c := range chunks {
+		spec.ChunkIDs = append(spec.ChunkIDs, c.ID)
+	}
+	return spec
+}
+
+// Statistik cache penilaian sejak server berjalan
+func (s *Server) handleGetGradingCacheStats(w http.ResponseWriter, r *http.Request) {
+	if s.gradeCache == nil {
+		WriteJSON(w, http.StatusOK, gradecache.Stats{Backend: "off"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, s.gradeCache.Stats())
+}
+
+// Mengosongkan cache, mis. setelah penyedia atau model penilai diganti
+func (s *Server) handleFlushGradingCache(w http.ResponseWriter, r *http.Request) {
+	if s.gradeCache == nil {
+		WriteJSON(w, http.StatusOK, map[string]string{"message": "Cache penilaian tidak aktif"})
+		return
+	}
+	if err := s.gradeCache.Flush(r.Context()); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengosongkan cache penilaian"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Cache penilaian berhasil dikosongkan"})
+}
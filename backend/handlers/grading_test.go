@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sistem-skripsi/backend/gradecache"
+	"sistem-skripsi/backend/grading"
+	"sistem-skripsi/backend/llm"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+	"sistem-skripsi/backend/webhook"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Respons model direkam di testdata/llm; nama file adalah sha256 dari prompt lengkap
+const fixtureDir = "../testdata/llm"
+
+const testTemplate = `Nilai jawaban esai siswa (level {{.LevelKognitif}}).
+Soal: {{.Soal}}
+Kunci jawaban: {{.KunciJawaban}}
+{{range .Konteks}}[{{.ID}}] {{.Isi}}
+{{end}}Jawaban siswa: {{.Jawaban}}`
+
+// gradingStore hanya mengimplementasikan method yang dipakai alur penilaian; method lain
+// panik karena Store yang di-embed bernilai nil
+type gradingStore struct {
+	store.Store
+	inputs   map[string]*models.GradingInput
+	pending  []*models.Submission
+	saved    map[string]*models.AIResult
+	usage    []*models.LLMUsage
+	failures map[string]string
+	events   []string
+	// Anggaran model dianggap habis untuk semua jawaban
+	budgetExceeded bool
+}
+
+func newGradingStore(answers map[string]string) *gradingStore {
+	st := &gradingStore{
+		inputs:   map[string]*models.GradingInput{},
+		saved:    map[string]*models.AIResult{},
+		failures: map[string]string{},
+	}
+	for id, jawaban := range answers {
+		sub := &models.Submission{ID: id, SoalID: "q1", SiswaID: "siswa-1", TeksJawaban: jawaban, PercobaanKe: 1}
+		st.inputs[id] = &models.GradingInput{
+			Submission: sub,
+			Question: &models.Question{
+				ID:            "q1",
+				TeksSoal:      "Jelaskan proses fotosintesis.",
+				LevelKognitif: "C2",
+				KunciJawaban:  "Tumbuhan mengubah energi cahaya menjadi energi kimia berupa glukosa.",
+				Rubrik: []*models.Rubric{
+					{ID: "r1", NamaAspek: "Konsep", Bobot: 60},
+					{ID: "r2", NamaAspek: "Istilah", Bobot: 40},
+				},
+			},
+			MaterialVersion: &models.MaterialVersion{
+				ID:         "mv1",
+				MateriID:   "m1",
+				NomorVersi: 1,
+				IsiMateri:  "Fotosintesis terjadi di kloroplas.",
+				Chunks: []*models.MaterialChunk{
+					{ID: "c1", VersiID: "mv1", Urutan: 1, Isi: "Fotosintesis terjadi di kloroplas dengan bantuan klorofil yang menyerap cahaya matahari."},
+					{ID: "c2", VersiID: "mv1", Urutan: 2, Isi: "Hasil fotosintesis adalah glukosa dan oksigen."},
+				},
+			},
+		}
+		st.pending = append(st.pending, sub)
+	}
+	return st
+}
+
+func (st *gradingStore) GetGradingInput(submissionID string) (*models.GradingInput, error) {
+	input, ok := st.inputs[submissionID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return input, nil
+}
+
+func (st *gradingStore) GetActivePromptTemplate(level string) (*models.PromptTemplate, error) {
+	return &models.PromptTemplate{ID: "pt-" + level, LevelKognitif: level, Versi: 2, Isi: testTemplate, Aktif: true}, nil
+}
+
+func (st *gradingStore) IsLLMBudgetExceeded(submissionID string, since time.Time) (bool, error) {
+	return st.budgetExceeded, nil
+}
+
+func (st *gradingStore) RecordLLMUsage(usage []*models.LLMUsage) error {
+	st.usage = append(st.usage, usage...)
+	return nil
+}
+
+func (st *gradingStore) SaveAIResult(result *models.AIResult) error {
+	st.saved[result.SubmissionID] = result
+	return nil
+}
+
+func (st *gradingStore) GetUngradedSubmissions(limit int, now time.Time) ([]*models.Submission, error) {
+	return st.pending, nil
+}
+
+func (st *gradingStore) GetSubmissionClassID(submissionID string) (string, error) {
+	return "kelas-1", nil
+}
+
+func (st *gradingStore) EnqueueWebhookEvent(classID string, eventID string, event string, payload []byte) (int64, error) {
+	st.events = append(st.events, event)
+	return 1, nil
+}
+
+func (st *gradingStore) RecordGradingFailure(submissionID string, galat string, now time.Time, base time.Duration, maxDelay time.Duration) (int, error) {
+	st.failures[submissionID] = galat
+	return 1, nil
+}
+
+// countingClient menghitung panggilan ke model
+type countingClient struct {
+	llm.Client
+	calls int
+}
+
+func (c *countingClient) Generate(ctx context.Context, req llm.Request) (*llm.Response, error) {
+	c.calls++
+	return c.Client.Generate(ctx, req)
+}
+
+func TestGradeSubmissionSavesResult(t *testing.T) {
+	st := newGradingStore(map[string]string{"sub-1": "Tumbuhan mengubah cahaya matahari menjadi glukosa di kloroplas."})
+	s := NewServer(mux.NewRouter(), st, WithGrader(llm.NewReplay(fixtureDir)))
+
+	result, err := s.GradeSubmission(context.Background(), "sub-1")
+	if err != nil {
+		t.Fatalf("GradeSubmission: %v", err)
+	}
+	if st.saved["sub-1"] != result {
+		t.Fatal("hasil penilaian tidak disimpan")
+	}
+	if result.Status != models.AIResultSucceeded || result.SkorAI == nil || *result.SkorAI != 76 {
+		t.Fatalf("hasil = status %q, skor %v; ingin berhasil dengan skor 76", result.Status, result.SkorAI)
+	}
+	if result.PromptTemplateID != "pt-C2" || result.PromptVersi != 2 || result.MaterialVersionID != "mv1" {
+		t.Errorf("jejak template/materi = %q v%d, %q", result.PromptTemplateID, result.PromptVersi, result.MaterialVersionID)
+	}
+	if len(result.Aspek) != 2 || result.Aspek[0].RubricID != "r1" || len(result.Aspek[0].ChunkIDs) != 1 {
+		t.Errorf("aspek = %+v", result.Aspek)
+	}
+	var logs gradingLog
+	if err := json.Unmarshal([]byte(result.LogsRAG), &logs); err != nil {
+		t.Fatalf("logs_rag bukan JSON: %v", err)
+	}
+	if len(logs.Percobaan) != 1 || logs.PromptVersi != 2 {
+		t.Errorf("logs_rag = %+v", logs)
+	}
+	// Chunk yang dikutip model tetapi tidak diambil dari materi dicatat sebagai koreksi
+	if len(logs.Koreksi) != 1 || !strings.Contains(logs.Koreksi[0], "c9") {
+		t.Errorf("koreksi = %q", logs.Koreksi)
+	}
+	if len(st.usage) != 1 || !st.usage[0].KeluaranValid {
+		t.Errorf("pemakaian model = %+v", st.usage)
+	}
+	if len(st.events) != 1 || st.events[0] != webhook.EventGradingCompleted {
+		t.Errorf("peristiwa = %v, ingin [%s]", st.events, webhook.EventGradingCompleted)
+	}
+}
+
+func TestGradeSubmissionSavesFailedResult(t *testing.T) {
+	st := newGradingStore(map[string]string{"sub-2": "Saya tidak tahu."})
+	s := NewServer(mux.NewRouter(), st, WithGrader(llm.NewReplay(fixtureDir)))
+
+	result, err := s.GradeSubmission(context.Background(), "sub-2")
+	if err != nil {
+		t.Fatalf("keluaran tidak valid seharusnya disimpan sebagai hasil gagal, bukan error: %v", err)
+	}
+	saved := st.saved["sub-2"]
+	if saved != result || saved.Status != models.AIResultFailed || saved.SkorAI != nil {
+		t.Fatalf("hasil tersimpan = %+v, ingin status gagal tanpa skor", saved)
+	}
+	var logs gradingLog
+	if err := json.Unmarshal([]byte(saved.LogsRAG), &logs); err != nil {
+		t.Fatalf("logs_rag bukan JSON: %v", err)
+	}
+	// Keluaran pertama ditambah DefaultMaxRepairs permintaan perbaikan
+	if len(logs.Percobaan) != grading.DefaultMaxRepairs+1 || logs.Galat == "" {
+		t.Errorf("logs_rag = %d percobaan, galat %q; ingin %d percobaan dengan galat", len(logs.Percobaan), logs.Galat, grading.DefaultMaxRepairs+1)
+	}
+	if len(st.usage) != len(logs.Percobaan) {
+		t.Errorf("pemakaian model dicatat %d kali, ingin %d", len(st.usage), len(logs.Percobaan))
+	}
+	for _, u := range st.usage {
+		if u.KeluaranValid {
+			t.Error("percobaan dengan keluaran tidak valid tercatat valid")
+		}
+	}
+	if len(st.events) != 1 || st.events[0] != webhook.EventGradingFailed {
+		t.Errorf("peristiwa = %v, ingin [%s]", st.events, webhook.EventGradingFailed)
+	}
+}
+
+func TestGradeSubmissionUsesCache(t *testing.T) {
+	st := newGradingStore(map[string]string{
+		"sub-1": "Tumbuhan mengubah cahaya matahari menjadi glukosa di kloroplas.",
+		"sub-3": "tumbuhan mengubah cahaya matahari, menjadi GLUKOSA di kloroplas",
+	})
+	grader := &countingClient{Client: llm.NewReplay(fixtureDir)}
+	s := NewServer(mux.NewRouter(), st, WithGrader(grader), WithGradingCache(gradecache.NewMemory(10), time.Hour))
+
+	if _, err := s.GradeSubmission(context.Background(), "sub-1"); err != nil {
+		t.Fatalf("GradeSubmission: %v", err)
+	}
+	cached, err := s.GradeSubmission(context.Background(), "sub-3")
+	if err != nil {
+		t.Fatalf("GradeSubmission dari cache: %v", err)
+	}
+	if grader.calls != 1 {
+		t.Errorf("model dipanggil %d kali, ingin 1", grader.calls)
+	}
+	if cached.SkorAI == nil || *cached.SkorAI != 76 || !strings.Contains(cached.LogsRAG, `"cache_dari":"sub-1"`) {
+		t.Errorf("hasil cache = skor %v, logs %s", cached.SkorAI, cached.LogsRAG)
+	}
+
+	// Penilaian ulang oleh guru tidak membaca cache
+	if _, err := s.gradeSubmission(context.Background(), "sub-1", false); err != nil {
+		t.Fatalf("gradeSubmission tanpa cache: %v", err)
+	}
+	if grader.calls != 2 {
+		t.Errorf("model dipanggil %d kali setelah penilaian ulang, ingin 2", grader.calls)
+	}
+}
+
+func TestGradeSubmissionErrors(t *testing.T) {
+	st := newGradingStore(map[string]string{"sub-1": "Jawaban"})
+	st.inputs["sub-1"].Question.LevelKognitif = ""
+
+	if _, err := NewServer(mux.NewRouter(), st).GradeSubmission(context.Background(), "sub-1"); !errors.Is(err, errGraderUnavailable) {
+		t.Errorf("tanpa penilai: err = %v, ingin errGraderUnavailable", err)
+	}
+	s := NewServer(mux.NewRouter(), st, WithGrader(llm.NewReplay(fixtureDir)))
+	if _, err := s.GradeSubmission(context.Background(), "tidak-ada"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("jawaban tidak ada: err = %v, ingin sql.ErrNoRows", err)
+	}
+	if _, err := s.GradeSubmission(context.Background(), "sub-1"); !errors.Is(err, errNoCognitiveLevel) {
+		t.Errorf("soal tanpa level: err = %v, ingin errNoCognitiveLevel", err)
+	}
+	if len(st.saved) != 0 {
+		t.Errorf("hasil tersimpan = %v, ingin tidak ada", st.saved)
+	}
+}
+
+func TestGradePendingSubmissionsRecordsFailures(t *testing.T) {
+	st := newGradingStore(map[string]string{
+		"sub-1": "Tumbuhan mengubah cahaya matahari menjadi glukosa di kloroplas.",
+		"sub-2": "Saya tidak tahu.",
+		// Tidak ada rekaman untuk jawaban ini, sehingga klien model mengembalikan error
+		"sub-4": "Jawaban yang belum pernah dinilai model.",
+	})
+	s := NewServer(mux.NewRouter(), st, WithGrader(llm.NewReplay(fixtureDir)))
+
+	processed, err := s.GradePendingSubmissions(time.Now())
+	if err != nil {
+		t.Fatalf("GradePendingSubmissions: %v", err)
+	}
+	// Hasil gagal karena keluaran tidak valid tetap dihitung sebagai sudah diproses
+	if processed != 2 {
+		t.Errorf("diproses = %d, ingin 2", processed)
+	}
+	if _, ok := st.saved["sub-4"]; ok {
+		t.Error("kegagalan klien model tidak boleh disimpan sebagai hasil")
+	}
+	if len(st.failures) != 1 || !strings.Contains(st.failures["sub-4"], llm.ErrFixtureNotFound.Error()) {
+		t.Errorf("kegagalan tercatat = %v, ingin hanya sub-4", st.failures)
+	}
+}
+
+func TestGradePendingSubmissionsPausesWhenBudgetExceeded(t *testing.T) {
+	st := newGradingStore(map[string]string{
+		"sub-1": "Tumbuhan mengubah cahaya matahari menjadi glukosa di kloroplas.",
+		"sub-2": "Saya tidak tahu.",
+	})
+	st.budgetExceeded = true
+	grader := &countingClient{Client: llm.NewReplay(fixtureDir)}
+	s := NewServer(mux.NewRouter(), st, WithGrader(grader))
+
+	if _, err := s.GradeSubmission(context.Background(), "sub-1"); !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("err = %v, ingin errBudgetExceeded", err)
+	}
+	if len(st.saved) != 0 {
+		t.Errorf("penilaian langsung menyimpan %d hasil, ingin tidak ada", len(st.saved))
+	}
+
+	processed, err := s.GradePendingSubmissions(time.Now())
+	if err != nil {
+		t.Fatalf("GradePendingSubmissions: %v", err)
+	}
+	if processed != 2 || grader.calls != 0 || len(st.usage) != 0 {
+		t.Errorf("diproses = %d, model dipanggil %d kali, pemakaian %d; ingin 2 ditunda tanpa memanggil model", processed, grader.calls, len(st.usage))
+	}
+	for _, id := range []string{"sub-1", "sub-2"} {
+		saved := st.saved[id]
+		if saved == nil || saved.Status != models.AIResultPaused || saved.SkorAI != nil {
+			t.Errorf("hasil %s = %+v, ingin status ditunda tanpa skor", id, saved)
+			continue
+		}
+		if !strings.Contains(saved.LogsRAG, errBudgetExceeded.Error()) {
+			t.Errorf("logs_rag %s = %s, ingin alasan anggaran habis", id, saved.LogsRAG)
+		}
+	}
+	// Jawaban yang ditunda tidak masuk jeda percobaan ulang
+	if len(st.failures) != 0 {
+		t.Errorf("kegagalan tercatat = %v, ingin tidak ada", st.failures)
+	}
+}
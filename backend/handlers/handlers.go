@@ -2,51 +2,257 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sistem-skripsi/backend/blobstore"
+	"sistem-skripsi/backend/gradecache"
+	"sistem-skripsi/backend/llm"
+	"sistem-skripsi/backend/mail"
 	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/realtime"
+	"sistem-skripsi/backend/scheduler"
 	"sistem-skripsi/backend/store"
+	"sistem-skripsi/backend/webhook"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Server struct {
 	router *mux.Router
 	store  store.Store
+	blobs  blobstore.Store
+	grader llm.Client
+	// Cache hasil penilaian; nil berarti tanpa cache
+	gradeCache    *gradecache.Metered
+	gradeCacheTTL time.Duration
+	lti           *ltiConfig
+	webhookClient *http.Client
+	events        realtime.Broker
+	// Pengirim ringkasan email; nil berarti ringkasan tidak dikirim
+	mailer    mail.Sender
+	appURL    string
+	scheduler *scheduler.Scheduler
 }
 
-func NewServer(router *mux.Router, store store.Store) *Server {
-	return &Server{
-		router: router,
-		store:  store,
+// Option mengatur dependensi tambahan Server
+type Option func(*Server)
+
+// WithBlobStore mengatur tempat penyimpanan file yang diunggah
+func WithBlobStore(blobs blobstore.Store) Option {
+	return func(s *Server) {
+		s.blobs = blobs
+	}
+}
+
+// WithGrader mengatur model bahasa untuk penilaian otomatis. Tanpa opsi ini jawaban
+// tidak dinilai AI dan menunggu penilaian guru.
+func WithGrader(grader llm.Client) Option {
+	return func(s *Server) {
+		s.grader = grader
+	}
+}
+
+// WithGradingCache memakai ulang hasil penilaian untuk jawaban yang sama selama ttl
+func WithGradingCache(cache gradecache.Cache, ttl time.Duration) Option {
+	return func(s *Server) {
+		s.gradeCache = gradecache.NewMetered(cache)
+		s.gradeCacheTTL = ttl
+	}
+}
+
+// WithEventBroker mengatur pub/sub peristiwa realtime. Tanpa opsi ini dipakai broker dalam
+// proses yang hanya menjangkau klien yang terhubung ke instance yang sama.
+func WithEventBroker(broker realtime.Broker) Option {
+	return func(s *Server) {
+		s.events = broker
 	}
 }
 
+func NewServer(router *mux.Router, store store.Store, opts ...Option) *Server {
+	s := &Server{
+		router:        router,
+		store:         store,
+		webhookClient: webhook.NewClient(false),
+		events:        realtime.NewMemory(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 func (s *Server) RegisterRoutes() {
 	// Rute Publik
+	s.router.HandleFunc("/api/hello", s.handleHello).Methods("GET", "OPTIONS")
 	s.router.HandleFunc("/api/auth/register", s.handleRegister).Methods("POST", "OPTIONS")
 	s.router.HandleFunc("/api/auth/login", s.handleLogin).Methods("POST", "OPTIONS")
+	s.router.HandleFunc("/api/lti/login", s.handleLTILogin).Methods("GET", "POST", "OPTIONS")
+	s.router.HandleFunc("/api/lti/launch", s.handleLTILaunch).Methods("POST", "OPTIONS")
+	s.router.HandleFunc("/api/lti/jwks", s.handleLTIJWKS).Methods("GET", "OPTIONS")
+
+	// Rute Semua Pengguna Terautentikasi
+	meRouter := s.router.PathPrefix("/api/me").Subrouter()
+	meRouter.Use(s.JWTMiddleware)
+	meRouter.HandleFunc("", s.handleGetMe).Methods("GET", "OPTIONS")
+	meRouter.HandleFunc("/password", s.handleChangePassword).Methods("PUT", "OPTIONS")
+	meRouter.HandleFunc("/notifications", s.handleGetNotifications).Methods("GET", "OPTIONS")
+	meRouter.HandleFunc("/notifications/unread-count", s.handleGetUnreadNotificationCount).Methods("GET", "OPTIONS")
+	meRouter.HandleFunc("/notifications/read-all", s.handleMarkAllNotificationsRead).Methods("POST", "OPTIONS")
+	meRouter.HandleFunc("/notifications/{id}", s.handleMarkNotification).Methods("PUT", "OPTIONS")
+	meRouter.HandleFunc("/notification-preferences", s.handleGetNotificationPreferences).Methods("GET", "OPTIONS")
+	meRouter.HandleFunc("/notification-preferences", s.handleUpdateNotificationPreferences).Methods("PUT", "OPTIONS")
+
+	// Aliran peristiwa realtime; token boleh dikirim lewat ?access_token= karena EventSource
+	// dan WebSocket di browser tidak dapat mengatur header Authorization
+	eventsRouter := s.router.PathPrefix("/api/events").Subrouter()
+	eventsRouter.Use(TokenFromQuery, s.JWTMiddleware)
+	eventsRouter.HandleFunc("", s.handleEventStream).Methods("GET", "OPTIONS")
+	eventsRouter.HandleFunc("/ws", s.handleEventSocket).Methods("GET", "OPTIONS")
+
+	// Rute Siswa
+	studentRouter := s.router.PathPrefix("/api/student").Subrouter()
+	studentRouter.Use(s.JWTMiddleware, StudentRequired)
+	studentRouter.HandleFunc("/assignments/upcoming", s.handleGetUpcomingAssignments).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/classes/{id}/mastery", s.handleGetMyMastery).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}", s.handleGetStudentAssignment).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/drafts/{soalId}", s.handleGetDraft).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/drafts/{soalId}", s.handleSaveDraft).Methods("PUT", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/submissions", s.handleSubmitAssignmentAnswer).Methods("POST", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/attempts", s.handleGetMyAttempts).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/exam", s.handleGetExamSession).Methods("GET", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/exam/start", s.handleStartExam).Methods("POST", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/exam/finish", s.handleFinishExam).Methods("POST", "OPTIONS")
+	studentRouter.HandleFunc("/assignments/{id}/exam/events", s.handleReportIntegrityEvent).Methods("POST", "OPTIONS")
 
 	// Rute Guru & Admin
 	teacherRouter := s.router.PathPrefix("/api").Subrouter()
-	teacherRouter.Use(JWTMiddleware, TeacherOrAdminRequired)
+	teacherRouter.Use(s.JWTMiddleware, TeacherOrAdminRequired)
 	teacherRouter.HandleFunc("/classes", s.handleCreateClass).Methods("POST", "OPTIONS")
 	teacherRouter.HandleFunc("/classes", s.handleGetClasses).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}", s.handleGetClass).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}", s.handleUpdateClass).Methods("PATCH", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/archive", s.handleArchiveClass).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/unarchive", s.handleUnarchiveClass).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/clone", s.handleCloneClass).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/materials", s.handleGetMaterials).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/materials", s.handleCreateMaterial).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/materials/upload", s.handleUploadMaterial).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}", s.handleGetMaterial).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}", s.handleDeleteMaterial).Methods("DELETE", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}", s.handleUpdateMaterial).Methods("PATCH", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/file", s.handleDownloadMaterialFile).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/versions", s.handleGetMaterialVersions).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/versions/{nomor:[0-9]+}", s.handleGetMaterialVersion).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/diff", s.handleDiffMaterialVersions).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/submissions/{id}/grading-context", s.handleGetGradingContext).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/submissions/{id}/similarity", s.handleGetSimilarityFlags).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/submissions/{id}/review", s.handleReviewSubmission).Methods("PUT", "OPTIONS")
+	teacherRouter.HandleFunc("/submissions/{id}/grade", s.handleGradeSubmission).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/similarity-flags/{id}", s.handleUpdateSimilarityFlag).Methods("PATCH", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/review-queue", s.handleGetReviewQueue).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/analytics/mastery", s.handleGetClassMastery).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/analytics/trend", s.handleGetMasteryTrend).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/analytics/histogram", s.handleGetMasteryHistogram).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/analytics/agreement", s.handleGetClassAgreement).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/gradebook", s.handleExportGradebook).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/students/{siswaId}/report", s.handleStudentReport).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/lti/sync-members", s.handleLTISyncMembers).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/lti/grades", s.handleLTIPushGrades).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/lti/deep-link/{id}", s.handleLTIDeepLink).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/webhooks", s.handleGetClassWebhooks).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/webhooks", s.handleCreateClassWebhook).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}", s.handleUpdateWebhook).Methods("PUT", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}", s.handleDeleteWebhook).Methods("DELETE", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}/ping", s.handlePingWebhook).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}/deliveries", s.handleGetWebhookDeliveries).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}", s.handleGetWebhookDelivery).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}/redeliver", s.handleRedeliverWebhook).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/students/{siswaId}/mastery", s.handleGetStudentMastery).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/questions", s.handleGetQuestions).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/materials/{id}/questions", s.handleCreateQuestion).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/assignments", s.handleGetAssignments).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/assignments", s.handleCreateAssignment).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}", s.handleGetAssignment).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}", s.handleUpdateAssignment).Methods("PUT", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}", s.handleDeleteAssignment).Methods("DELETE", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/students/{siswaId}/attempts", s.handleGetStudentAttempts).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/exam-sessions", s.handleGetExamSessions).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/students/{siswaId}/exam", s.handleGetStudentExamSession).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/extensions", s.handleGetAssignmentExtensions).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/extensions/{siswaId}", s.handleSetAssignmentExtension).Methods("PUT", "OPTIONS")
+	teacherRouter.HandleFunc("/assignments/{id}/extensions/{siswaId}", s.handleDeleteAssignmentExtension).Methods("DELETE", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/staff", s.handleGetClassStaff).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/staff", s.handleInviteClassStaff).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/staff/{userId}", s.handleUpdateClassStaff).Methods("PATCH", "OPTIONS")
+	teacherRouter.HandleFunc("/classes/{id}/staff/{userId}", s.handleRemoveClassStaff).Methods("DELETE", "OPTIONS")
+	teacherRouter.HandleFunc("/staff-invitations", s.handleGetStaffInvitations).Methods("GET", "OPTIONS")
+	teacherRouter.HandleFunc("/staff-invitations/{id}/accept", s.handleAcceptStaffInvitation).Methods("POST", "OPTIONS")
+	teacherRouter.HandleFunc("/staff-invitations/{id}/decline", s.handleDeclineStaffInvitation).Methods("POST", "OPTIONS")
 
 	// Rute Admin
 	adminRouter := s.router.PathPrefix("/api/admin").Subrouter()
-	adminRouter.Use(JWTMiddleware, AdminRequired)
+	adminRouter.Use(s.JWTMiddleware, AdminRequired)
 	adminRouter.HandleFunc("/teachers", s.handleCreateTeacher).Methods("POST", "OPTIONS")
 	adminRouter.HandleFunc("/teachers", s.handleGetTeachers).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/teachers/bulk", s.handleBulkCreateTeachers).Methods("POST", "OPTIONS")
 	adminRouter.HandleFunc("/teachers/{id}", s.handleDeleteTeacher).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/teachers/{id}/classes", s.handleGetTeacherClasses).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/teachers/{id}/offboard", s.handleOffboardTeacher).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/users", s.handleListUsers).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/users/purge", s.handlePurgeDeletedUsers).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}", s.handleGetUser).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}", s.handleUpdateUser).Methods("PATCH", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}/role", s.handleUpdateUserRole).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}/disable", s.handleDisableUser).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}/enable", s.handleEnableUser).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}", s.handleDeleteUser).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}/reset-password", s.handleResetUserPassword).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/users/{id}/restore", s.handleRestoreUser).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/analytics/refresh", s.handleRefreshAnalytics).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/analytics/agreement", s.handleGetAgreement).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/lti/config", s.handleGetLTIToolConfig).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/lti/platforms", s.handleGetLTIPlatforms).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/lti/platforms", s.handleCreateLTIPlatform).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/lti/platforms/{id}", s.handleUpdateLTIPlatform).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/lti/platforms/{id}", s.handleDeleteLTIPlatform).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/webhooks", s.handleGetGlobalWebhooks).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/webhooks", s.handleCreateGlobalWebhook).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/audit-log", s.handleGetAuditLog).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/audit-log/verify", s.handleVerifyAuditLog).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/jobs", s.handleGetJobs).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/jobs/{name}/run", s.handleTriggerJob).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/jobs/{name}/runs", s.handleGetJobRuns).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/llm-usage", s.handleGetLLMUsageReport).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/llm-prices", s.handleGetLLMPrices).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/llm-prices", s.handleSetLLMPrice).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/llm-prices", s.handleDeleteLLMPrice).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/llm-budgets", s.handleGetLLMBudgets).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/llm-budgets/school", s.handleSetSchoolLLMBudget).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/llm-budgets/school", s.handleDeleteSchoolLLMBudget).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/llm-budgets/teachers/{id}", s.handleSetTeacherLLMBudget).Methods("PUT", "OPTIONS")
+	adminRouter.HandleFunc("/llm-budgets/teachers/{id}", s.handleDeleteTeacherLLMBudget).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/grading-cache", s.handleGetGradingCacheStats).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/grading-cache", s.handleFlushGradingCache).Methods("DELETE", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates", s.handleGetPromptTemplates).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates/preview", s.handlePreviewPrompt).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates/{level:C[1-4]}", s.handleCreatePromptTemplate).Methods("POST", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates/{level:C[1-4]}/versions", s.handleGetPromptTemplateVersions).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates/{level:C[1-4]}/versions/{versi:[0-9]+}", s.handleGetPromptTemplateVersion).Methods("GET", "OPTIONS")
+	adminRouter.HandleFunc("/prompt-templates/{level:C[1-4]}/versions/{versi:[0-9]+}/activate", s.handleActivatePromptTemplate).Methods("POST", "OPTIONS")
 }
 
 
 // --- Handlers ---
 
+func (s *Server) handleHello(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Hello from Go backend!"})
+}
+
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var creds models.LoginCredentials
 	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
@@ -56,30 +262,67 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	user, storedPasswordHash, err := s.store.GetUserByIdentifier(creds.Identifier)
 	if err != nil {
+		s.recordAudit(r, models.AuditEntry{
+			Aksi:     models.AuditLoginFailed,
+			Metadata: map[string]any{"identifier": creds.Identifier, "alasan": "pengguna_tidak_ditemukan"},
+		}, nil, nil)
 		WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Identifier atau password salah"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(storedPasswordHash), []byte(creds.Password)); err != nil {
+		s.recordAudit(r, models.AuditEntry{
+			Aksi:        models.AuditLoginFailed,
+			TargetJenis: "user",
+			TargetID:    user.ID,
+			Metadata:    map[string]any{"identifier": creds.Identifier, "alasan": "password_salah"},
+		}, nil, nil)
 		WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Identifier atau password salah"})
 		return
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &models.Claims{
-		UserID: user.ID,
-		Peran:  user.Peran,
-		RegisteredClaims: jwt.RegisteredClaims{ ExpiresAt: jwt.NewNumericDate(expirationTime) },
+	if !user.IsActive {
+		s.recordAudit(r, models.AuditEntry{
+			Aksi:        models.AuditLoginFailed,
+			TargetJenis: "user",
+			TargetID:    user.ID,
+			Metadata:    map[string]any{"identifier": creds.Identifier, "alasan": "akun_nonaktif"},
+		}, nil, nil)
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akun telah dinonaktifkan"})
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := issueToken(user)
 	if err != nil {
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat token"})
 		return
 	}
+	s.recordAudit(r, models.AuditEntry{
+		AktorID:     user.ID,
+		AktorPeran:  user.Peran,
+		Aksi:        models.AuditLogin,
+		TargetJenis: "user",
+		TargetID:    user.ID,
+	}, nil, nil)
 
-	WriteJSON(w, http.StatusOK, map[string]string{"token": tokenString})
+	WriteJSON(w, http.StatusOK, map[string]any{"token": tokenString, "must_change_password": user.MustChangePassword})
+}
+
+// issueToken membuat token sesi 24 jam untuk pengguna
+func issueToken(user *models.User) (string, error) {
+	expirationTime := time.Now().Add(24 * time.Hour)
+	claims := &models.Claims{
+		UserID:       user.ID,
+		Peran:        user.Peran,
+		TokenVersion: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtKey)
 }
 
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
@@ -105,15 +348,9 @@ func (s *Server) handleUserCreation(w http.ResponseWriter, r *http.Request, pera
 	
 	user.Peran = peran
 	if err := s.store.CreateUser(&user); err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Constraint {
-			case "users_username_key":
-				WriteJSON(w, http.StatusConflict, map[string]string{"message": "Username telah digunakan"})
-				return
-			case "users_email_key":
-				WriteJSON(w, http.StatusConflict, map[string]string{"message": "Email sudah terdaftar"})
-				return
-			}
+		if message, ok := userConflictMessage(err); ok {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": message})
+			return
 		}
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan pengguna"})
 		return
@@ -122,6 +359,19 @@ func (s *Server) handleUserCreation(w http.ResponseWriter, r *http.Request, pera
 	WriteJSON(w, http.StatusCreated, map[string]string{"message": "Akun berhasil dibuat!", "userID": user.ID})
 }
 
+// Menerjemahkan pelanggaran constraint unik pada tabel users menjadi pesan untuk klien
+func userConflictMessage(err error) (string, bool) {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Constraint {
+		case "users_username_key":
+			return "Username telah digunakan", true
+		case "users_email_key":
+			return "Email sudah terdaftar", true
+		}
+	}
+	return "", false
+}
+
 func (s *Server) handleGetTeachers(w http.ResponseWriter, r *http.Request) {
 	teachers, err := s.store.GetTeachers()
 	if err != nil {
@@ -134,8 +384,13 @@ func (s *Server) handleGetTeachers(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleDeleteTeacher(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	rowsAffected, err := s.store.DeleteUserByID(id, "teacher")
+	before, _ := s.store.GetUserByID(id)
+	rowsAffected, err := s.store.SoftDeleteUser(id, "teacher")
 	if err != nil {
+		if errors.Is(err, store.ErrActiveClassesRemain) {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Guru masih memiliki kelas aktif, lakukan offboarding terlebih dahulu"})
+			return
+		}
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus guru"})
 		return
 	}
@@ -143,7 +398,8 @@ func (s *Server) handleDeleteTeacher(w http.ResponseWriter, r *http.Request) {
 		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Guru tidak ditemukan"})
 		return
 	}
-	WriteJSON(w, http.StatusOK, map[string]string{"message": "Akun guru berhasil dihapus"})
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditUserDeleted, TargetJenis: "user", TargetID: id}, before, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Akun guru berhasil dihapus dan dapat dipulihkan selama masa retensi"})
 }
 
 func (s *Server) handleCreateClass(w http.ResponseWriter, r *http.Request) {
@@ -153,13 +409,20 @@ func (s *Server) handleCreateClass(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := validator.New().Struct(classData); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Nama kelas wajib diisi dan semester harus ganjil atau genap"})
+		return
+	}
+
 	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
 	classData.GuruID = claims.UserID
+	classData.PeranSaya = models.ClassRoleOwner
 
 	if err := s.store.CreateClass(&classData); err != nil {
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat kelas"})
 		return
 	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditClassCreated, TargetJenis: "class", TargetID: classData.ID, KelasID: classData.ID}, nil, classData)
 	
 	WriteJSON(w, http.StatusCreated, classData)
 }
@@ -167,7 +430,8 @@ func (s *Server) handleCreateClass(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetClasses(w http.ResponseWriter, r *http.Request) {
 	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
 	
-	classes, err := s.store.GetClassesByTeacherID(claims.UserID)
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	classes, err := s.store.GetClassesByTeacherID(claims.UserID, includeArchived)
 	if err != nil {
 		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar kelas"})
 		return
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/scheduler"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// WithScheduler mengaktifkan endpoint admin untuk melihat dan memicu job terjadwal
+func WithScheduler(sched *scheduler.Scheduler) Option {
+	return func(s *Server) {
+		s.scheduler = sched
+	}
+}
+
+// PruneJobRuns menghapus riwayat eksekusi job yang lebih lama dari retention
+func (s *Server) PruneJobRuns(now time.Time, retention time.Duration) (int, error) {
+	pruned, err := s.store.PruneJobRuns(now.Add(-retention))
+	return int(pruned), err
+}
+
+// --- Handlers Admin Job Terjadwal ---
+
+func (s *Server) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	if !s.schedulerEnabled(w) {
+		return
+	}
+	latest, err := s.store.GetLatestJobRuns()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil riwayat job"})
+		return
+	}
+	jobs := s.scheduler.Jobs(time.Now())
+	for _, job := range jobs {
+		job.Terakhir = latest[job.Nama]
+	}
+	WriteJSON(w, http.StatusOK, jobs)
+}
+
+// Menjalankan job di luar jadwal. Job berjalan di latar belakang; hasilnya dapat dipantau
+// lewat riwayat eksekusi.
+func (s *Server) handleTriggerJob(w http.ResponseWriter, r *http.Request) {
+	if !s.schedulerEnabled(w) {
+		return
+	}
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	run, err := s.scheduler.Trigger(r.Context(), mux.Vars(r)["name"], claims.UserID)
+	if errors.Is(err, scheduler.ErrUnknownJob) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Job tidak ditemukan"})
+		return
+	}
+	if errors.Is(err, scheduler.ErrJobRunning) {
+		WriteJSON(w, http.StatusConflict, map[string]string{"message": "Job sedang berjalan, coba lagi nanti"})
+		return
+	}
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menjalankan job"})
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, run)
+}
+
+// Riwayat eksekusi sebuah job; ?status= menyaring berjalan, berhasil, atau gagal
+func (s *Server) handleGetJobRuns(w http.ResponseWriter, r *http.Request) {
+	if !s.schedulerEnabled(w) {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if !s.scheduler.Has(name) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Job tidak ditemukan"})
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.JobRunRunning, models.JobRunSucceeded, models.JobRunFailed:
+	default:
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Status harus berjalan, berhasil, atau gagal"})
+		return
+	}
+	page, limit := parsePagination(r)
+	runs, total, err := s.store.GetJobRuns(name, status, page, limit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil riwayat job"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, models.PaginatedResponse{Data: runs, Page: page, Limit: limit, Total: total})
+}
+
+func (s *Server) schedulerEnabled(w http.ResponseWriter) bool {
+	if s.scheduler == nil {
+		WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Scheduler tidak aktif"})
+		return false
+	}
+	return true
+}
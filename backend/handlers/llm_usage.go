@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/deadline"
+	"sistem-skripsi/backend/models"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// Jumlah bulan pada laporan pemakaian jika parameter from tidak diisi
+const defaultUsageReportMonths = 6
+
+// --- Handlers Pemakaian & Anggaran Model (Superadmin) ---
+
+// Laporan pemakaian model per bulan, dikelompokkan per kelas (group_by=class), guru
+// (group_by=teacher), atau hanya per bulan (group_by=month, default). from dan to berformat
+// YYYY-MM dan keduanya inklusif.
+func (s *Server) handleGetLLMUsageReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := models.LLMUsageFilter{
+		GroupBy: q.Get("group_by"),
+		KelasID: q.Get("kelas_id"),
+		GuruID:  q.Get("guru_id"),
+	}
+	switch filter.GroupBy {
+	case "":
+		filter.GroupBy = "month"
+	case "month", "class", "teacher":
+	default:
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "group_by harus 'month', 'class', atau 'teacher'"})
+		return
+	}
+
+	loc := usageZone()
+	current := monthStart(time.Now(), loc)
+	filter.Sampai = current.AddDate(0, 1, 0)
+	filter.Dari = current.AddDate(0, 1-defaultUsageReportMonths, 0)
+	for param, target := range map[string]*time.Time{"from": &filter.Dari, "to": &filter.Sampai} {
+		value := q.Get(param)
+		if value == "" {
+			continue
+		}
+		month, err := time.ParseInLocation("2006-01", value, loc)
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter '" + param + "' harus berformat YYYY-MM"})
+			return
+		}
+		if param == "to" {
+			month = month.AddDate(0, 1, 0)
+		}
+		*target = month
+	}
+	if !filter.Dari.Before(filter.Sampai) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter 'from' harus sebelum 'to'"})
+		return
+	}
+
+	report, err := s.store.GetLLMUsageReport(filter, loc)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil laporan pemakaian model"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleGetLLMPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := s.store.GetLLMPrices()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil harga model"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, prices)
+}
+
+func (s *Server) handleSetLLMPrice(w http.ResponseWriter, r *http.Request) {
+	var price models.LLMPrice
+	if err := json.NewDecoder(r.Body).Decode(&price); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	price.Provider = strings.TrimSpace(price.Provider)
+	price.Model = strings.TrimSpace(price.Model)
+	if err := validator.New().Struct(price); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Provider, model, dan harga tidak negatif wajib diisi"})
+		return
+	}
+	if err := s.store.SetLLMPrice(&price); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan harga model"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, price)
+}
+
+// Nama model dapat mengandung "/", sehingga provider dan model dikirim sebagai query parameter
+func (s *Server) handleDeleteLLMPrice(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	affected, err := s.store.DeleteLLMPrice(q.Get("provider"), q.Get("model"))
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus harga model"})
+		return
+	}
+	if affected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Harga model tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Harga model berhasil dihapus"})
+}
+
+func (s *Server) handleGetLLMBudgets(w http.ResponseWriter, r *http.Request) {
+	budgets, err := s.store.GetLLMBudgets(monthStart(time.Now(), usageZone()))
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil anggaran model"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, budgets)
+}
+
+// Anggaran seluruh sekolah; berlaku untuk total pemakaian semua guru
+func (s *Server) handleSetSchoolLLMBudget(w http.ResponseWriter, r *http.Request) {
+	s.setLLMBudget(w, r, "")
+}
+
+func (s *Server) handleSetTeacherLLMBudget(w http.ResponseWriter, r *http.Request) {
+	teacherID := mux.Vars(r)["id"]
+	user, err := s.store.GetUserByID(teacherID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Guru tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data guru"})
+		return
+	}
+	if user.Peran != "teacher" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Anggaran hanya dapat diatur untuk guru"})
+		return
+	}
+	s.setLLMBudget(w, r, teacherID)
+}
+
+func (s *Server) setLLMBudget(w http.ResponseWriter, r *http.Request, guruID string) {
+	var req models.LLMBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "batas_usd wajib diisi dan tidak boleh negatif"})
+		return
+	}
+	if err := s.store.SetLLMBudget(guruID, *req.BatasUSD); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan anggaran model"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Anggaran model berhasil disimpan"})
+}
+
+func (s *Server) handleDeleteSchoolLLMBudget(w http.ResponseWriter, r *http.Request) {
+	s.deleteLLMBudget(w, "")
+}
+
+func (s *Server) handleDeleteTeacherLLMBudget(w http.ResponseWriter, r *http.Request) {
+	s.deleteLLMBudget(w, mux.Vars(r)["id"])
+}
+
+func (s *Server) deleteLLMBudget(w http.ResponseWriter, guruID string) {
+	affected, err := s.store.DeleteLLMBudget(guruID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus anggaran model"})
+		return
+	}
+	if affected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Anggaran tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Anggaran model berhasil dihapus"})
+}
+
+// Bulan anggaran dan laporan mengikuti zona waktu sekolah
+func usageZone() *time.Location {
+	loc, err := deadline.LoadZone("")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func monthStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,774 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sistem-skripsi/backend/lti"
+	"sistem-skripsi/backend/models"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+const (
+	ltiStateLifetime    = 10 * time.Minute
+	ltiDeepLinkLifetime = time.Hour
+	ltiScoreMaximum     = 100
+)
+
+// Konfigurasi tool LTI; nil berarti integrasi LTI tidak aktif
+type ltiConfig struct {
+	toolURL     string // URL publik backend, mis. https://sage.sekolah.id
+	frontendURL string // Tujuan pengalihan setelah peluncuran
+	jwks        *lti.JWKSCache
+}
+
+// WithLTI mengaktifkan integrasi LTI 1.3. toolURL adalah URL publik backend yang didaftarkan
+// di platform; frontendURL menerima token sesi setelah peluncuran.
+func WithLTI(toolURL string, frontendURL string) Option {
+	return func(s *Server) {
+		s.lti = &ltiConfig{
+			toolURL:     strings.TrimRight(toolURL, "/"),
+			frontendURL: strings.TrimRight(frontendURL, "/"),
+			jwks:        lti.NewJWKSCache(nil),
+		}
+	}
+}
+
+// --- Handlers LTI (dipanggil platform) ---
+
+// Kunci publik tool untuk memverifikasi respons deep linking dan permintaan token layanan
+func (s *Server) handleLTIJWKS(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	if _, err := s.ltiSigningKey(); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyiapkan kunci LTI"})
+		return
+	}
+	keys, err := s.store.GetLTIKeys()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil kunci LTI"})
+		return
+	}
+	set := lti.JWKSet{Keys: []lti.JWK{}}
+	for _, k := range keys {
+		private, err := lti.ParsePrivateKey(k.PrivateKey)
+		if err != nil {
+			log.Printf("Kunci LTI %s tidak dapat dibaca: %v", k.Kid, err)
+			continue
+		}
+		set.Keys = append(set.Keys, lti.PublicJWK(k.Kid, &private.PublicKey))
+	}
+	WriteJSON(w, http.StatusOK, set)
+}
+
+// Inisiasi login OIDC pihak ketiga: menyimpan state dan nonce lalu mengalihkan browser ke
+// endpoint otorisasi platform
+func (s *Server) handleLTILogin(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	issuer, loginHint := r.Form.Get("iss"), r.Form.Get("login_hint")
+	if issuer == "" || loginHint == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter iss dan login_hint wajib diisi"})
+		return
+	}
+	platform, err := s.store.FindLTIPlatform(issuer, r.Form.Get("client_id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Platform LTI tidak terdaftar"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data platform LTI"})
+		return
+	}
+
+	state, err := lti.RandomToken()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai login LTI"})
+		return
+	}
+	nonce, err := lti.RandomToken()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai login LTI"})
+		return
+	}
+	if err := s.store.SaveLTILoginState(state, nonce, platform.ID, time.Now().Add(ltiStateLifetime)); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai login LTI"})
+		return
+	}
+
+	target, err := url.Parse(platform.AuthLoginURL)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "URL login platform tidak valid"})
+		return
+	}
+	query := target.Query()
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", platform.ClientID)
+	query.Set("redirect_uri", s.lti.toolURL+"/api/lti/launch")
+	query.Set("login_hint", loginHint)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	if hint := r.Form.Get("lti_message_hint"); hint != "" {
+		query.Set("lti_message_hint", hint)
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// Peluncuran dari platform: memvalidasi id_token, memetakan pengguna dan kursus ke akun dan
+// kelas, lalu mengalihkan ke frontend dengan token sesi pada fragmen URL
+func (s *Server) handleLTILaunch(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if platformErr := r.PostForm.Get("error"); platformErr != "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Platform menolak login: " + platformErr})
+		return
+	}
+	nonce, platformID, err := s.store.ConsumeLTILoginState(r.PostForm.Get("state"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Sesi login LTI tidak valid atau kedaluwarsa"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa sesi login LTI"})
+		return
+	}
+	platform, err := s.store.GetLTIPlatform(platformID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data platform LTI"})
+		return
+	}
+	claims, err := lti.ValidateLaunch(r.Context(), s.lti.jwks, ltiPlatform(platform), r.PostForm.Get("id_token"), nonce)
+	if err != nil {
+		log.Printf("Peluncuran LTI dari %s ditolak: %v", platform.Issuer, err)
+		WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Token peluncuran LTI tidak valid"})
+		return
+	}
+	if claims.Context == nil || claims.Context.ID == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Peluncuran tanpa kursus tidak didukung"})
+		return
+	}
+
+	instructor := lti.IsInstructor(claims.Roles)
+	peran := "student"
+	if instructor {
+		peran = "teacher"
+	}
+	user, err := s.store.ResolveLTIUser(&models.LTIIdentity{
+		PlatformID:  platform.ID,
+		Sub:         claims.Subject,
+		NamaLengkap: claims.DisplayName(),
+		Email:       claims.Email,
+		Peran:       peran,
+	})
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyiapkan akun pengguna LTI"})
+		return
+	}
+	if !user.IsActive {
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akun telah dinonaktifkan"})
+		return
+	}
+	// Pengajar di platform hanya bertindak sebagai guru jika akunnya memang guru
+	instructor = instructor && (user.Peran == "teacher" || user.Peran == "superadmin")
+
+	ltiContext, ok := s.ltiClassForLaunch(w, platform, claims, user, instructor)
+	if !ok {
+		return
+	}
+	token, err := issueToken(user)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat token"})
+		return
+	}
+	fragment := url.Values{"token": {token}, "kelas_id": {ltiContext.KelasID}, "peran": {user.Peran}}
+
+	if claims.MessageType == lti.MessageDeepLinking {
+		if !instructor {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Hanya pengajar yang dapat memilih tugas"})
+			return
+		}
+		session := &models.LTIDeepLinkSession{
+			PlatformID:   platform.ID,
+			DeploymentID: claims.DeploymentID,
+			ReturnURL:    claims.DeepLinking.ReturnURL,
+			Data:         claims.DeepLinking.Data,
+			UserID:       user.ID,
+			KelasID:      ltiContext.KelasID,
+			ExpiresAt:    time.Now().Add(ltiDeepLinkLifetime),
+		}
+		if err := s.store.CreateLTIDeepLinkSession(session); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulai deep linking"})
+			return
+		}
+		fragment.Set("sesi", session.ID)
+		http.Redirect(w, r, s.lti.frontendURL+"/lti/deep-link#"+fragment.Encode(), http.StatusSeeOther)
+		return
+	}
+
+	if !instructor {
+		if _, err := s.store.AddClassMember(ltiContext.KelasID, user.ID); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mendaftarkan siswa ke kelas"})
+			return
+		}
+	}
+	link := &models.LTIResourceLink{ContextID: ltiContext.ID, ResourceLinkID: claims.ResourceLink.ID}
+	if claims.AGS != nil {
+		link.LineItemURL = claims.AGS.LineItem
+	}
+	if assignmentID := claims.Custom["assignment_id"]; assignmentID != "" {
+		assignment, err := s.store.GetAssignmentByID(assignmentID)
+		if err == nil && assignment.KelasID == ltiContext.KelasID {
+			link.AssignmentID = assignment.ID
+		} else {
+			log.Printf("Tugas %q pada tautan LTI %s tidak ditemukan di kelas %s", assignmentID, link.ResourceLinkID, ltiContext.KelasID)
+		}
+	}
+	if err := s.store.SaveLTIResourceLink(link); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan tautan LTI"})
+		return
+	}
+	if link.AssignmentID != "" {
+		fragment.Set("assignment_id", link.AssignmentID)
+	}
+	http.Redirect(w, r, s.lti.frontendURL+"/lti/launch#"+fragment.Encode(), http.StatusSeeOther)
+}
+
+// ltiClassForLaunch mengembalikan tautan kursus ke kelas. Kursus yang belum ditautkan dibuatkan
+// kelas baru milik pengajar yang meluncurkan; siswa tidak dapat meluncurkan kursus yang belum ditautkan.
+func (s *Server) ltiClassForLaunch(w http.ResponseWriter, platform *models.LTIPlatform, claims *lti.LaunchClaims, user *models.User, instructor bool) (*models.LTIContext, bool) {
+	ltiContext, err := s.store.GetLTIContext(platform.ID, claims.Context.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if !instructor {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Kursus ini belum ditautkan ke kelas; minta pengajar membuka tool terlebih dahulu"})
+			return nil, false
+		}
+		nama := claims.Context.Title
+		if nama == "" {
+			nama = claims.Context.Label
+		}
+		if nama == "" {
+			nama = "Kursus " + platform.Nama
+		}
+		class := &models.Class{GuruID: user.ID, NamaKelas: nama, Deskripsi: "Ditautkan dari " + platform.Nama}
+		if err := s.store.CreateClass(class); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat kelas untuk kursus LTI"})
+			return nil, false
+		}
+		ltiContext = &models.LTIContext{PlatformID: platform.ID, ContextID: claims.Context.ID, KelasID: class.ID}
+	case err != nil:
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tautan kursus LTI"})
+		return nil, false
+	case instructor && user.Peran != "superadmin":
+		access, err := s.store.GetClassAccess(ltiContext.KelasID, user.ID)
+		if err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa akses kelas"})
+			return nil, false
+		}
+		if access.Peran == "" {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Anda belum terdaftar sebagai pengajar kelas ini"})
+			return nil, false
+		}
+	}
+
+	ltiContext.Judul = claims.Context.Title
+	if claims.NRPS != nil {
+		ltiContext.MembershipsURL = claims.NRPS.ContextMembershipsURL
+	}
+	if claims.AGS != nil {
+		ltiContext.LineItemsURL = claims.AGS.LineItems
+	}
+	if err := s.store.SaveLTIContext(ltiContext); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan tautan kursus LTI"})
+		return nil, false
+	}
+	return ltiContext, true
+}
+
+// --- Handlers LTI (guru) ---
+
+// Menyelesaikan deep linking: membuat respons bertanda tangan berisi tugas yang dipilih.
+// Frontend mengirim jwt ke return_url sebagai field form "JWT".
+func (s *Server) handleLTIDeepLink(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	session, err := s.store.GetLTIDeepLinkSession(mux.Vars(r)["id"], claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Sesi deep linking tidak ditemukan atau kedaluwarsa"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil sesi deep linking"})
+		return
+	}
+
+	var req models.LTIDeepLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Pilih minimal satu tugas"})
+		return
+	}
+
+	items := make([]lti.ContentItem, 0, len(req.AssignmentIDs))
+	for _, id := range req.AssignmentIDs {
+		assignment, err := s.store.GetAssignmentByID(id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas tidak ditemukan"})
+				return
+			}
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data tugas"})
+			return
+		}
+		if session.KelasID != "" && assignment.KelasID != session.KelasID {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tugas bukan milik kelas yang ditautkan ke kursus ini"})
+			return
+		}
+		if !s.authorizeClass(w, r, assignment.KelasID, capManageContent) {
+			return
+		}
+		items = append(items, lti.ContentItem{
+			Type:     "ltiResourceLink",
+			Title:    assignment.Judul,
+			Text:     assignment.Deskripsi,
+			URL:      s.lti.toolURL + "/api/lti/launch",
+			Custom:   map[string]string{"assignment_id": assignment.ID},
+			LineItem: &lti.LineItem{ScoreMaximum: ltiScoreMaximum, Label: assignment.Judul, ResourceID: assignment.ID},
+		})
+	}
+
+	platform, err := s.store.GetLTIPlatform(session.PlatformID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data platform LTI"})
+		return
+	}
+	key, err := s.ltiSigningKey()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyiapkan kunci LTI"})
+		return
+	}
+	jwt, err := lti.DeepLinkingResponse(key, ltiPlatform(platform), session.DeploymentID, session.Data, items, "", time.Now())
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat respons deep linking"})
+		return
+	}
+	if err := s.store.DeleteLTIDeepLinkSession(session.ID); err != nil {
+		log.Printf("Gagal menghapus sesi deep linking %s: %v", session.ID, err)
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"return_url": session.ReturnURL, "jwt": jwt})
+}
+
+// Menyinkronkan anggota kelas dengan roster kursus platform (NRPS). Siswa aktif ditambahkan;
+// siswa platform yang tidak lagi aktif dikeluarkan. Siswa yang didaftarkan langsung tidak diubah.
+func (s *Server) handleLTISyncMembers(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageClass) {
+		return
+	}
+	contexts, err := s.store.GetLTIContextsByClass(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tautan kursus LTI"})
+		return
+	}
+
+	result := models.LTISyncResult{}
+	synced := false
+	for _, c := range contexts {
+		if c.MembershipsURL == "" {
+			continue
+		}
+		synced = true
+		if err := s.syncLTIMembers(r.Context(), classID, c, &result); err != nil {
+			log.Printf("Gagal sinkronisasi roster LTI kelas %s: %v", classID, err)
+			if errors.Is(err, lti.ErrServiceFailed) {
+				WriteJSON(w, http.StatusBadGateway, map[string]string{"message": "Platform LTI gagal mengirim roster"})
+				return
+			}
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyinkronkan anggota kelas"})
+			return
+		}
+	}
+	if !synced {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Kelas belum ditautkan ke kursus dengan layanan roster"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) syncLTIMembers(ctx context.Context, classID string, ltiContext *models.LTIContext, result *models.LTISyncResult) error {
+	platform, err := s.store.GetLTIPlatform(ltiContext.PlatformID)
+	if err != nil {
+		return err
+	}
+	client, err := s.ltiServiceClient(platform)
+	if err != nil {
+		return err
+	}
+	members, err := client.Members(ctx, ltiContext.MembershipsURL)
+	if err != nil {
+		return err
+	}
+
+	keep := []string{}
+	for _, m := range members {
+		if !m.Active() || !lti.IsLearner(m.Roles) || lti.IsInstructor(m.Roles) {
+			result.Dilewati++
+			continue
+		}
+		user, err := s.store.ResolveLTIUser(&models.LTIIdentity{
+			PlatformID:  platform.ID,
+			Sub:         m.UserID,
+			NamaLengkap: m.DisplayName(),
+			Email:       m.Email,
+			Peran:       "student",
+		})
+		if err != nil {
+			return err
+		}
+		if user.Peran != "student" {
+			result.Dilewati++
+			continue
+		}
+		keep = append(keep, user.ID)
+		added, err := s.store.AddClassMember(classID, user.ID)
+		if err != nil {
+			return err
+		}
+		if added {
+			result.Ditambahkan++
+		}
+	}
+	removed, err := s.store.RemoveLTIClassMembers(classID, platform.ID, keep)
+	if err != nil {
+		return err
+	}
+	result.Dihapus += int(removed)
+	return nil
+}
+
+// Mengirim nilai tugas setiap siswa ke kolom nilai platform (AGS). Kolom nilai dibuat jika
+// platform belum menyediakannya untuk tautan tugas.
+func (s *Server) handleLTIPushGrades(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	assignment, ok := s.assignmentForRequest(w, r, capManageClass)
+	if !ok {
+		return
+	}
+	links, err := s.store.GetLTIResourceLinksByAssignment(assignment.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tautan LTI tugas"})
+		return
+	}
+	if len(links) == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tugas belum ditautkan ke platform LTI"})
+		return
+	}
+
+	_, book, err := s.loadGradebook(assignment.KelasID, false)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghitung nilai tugas"})
+		return
+	}
+	grades := map[string]*float64{}
+	var userIDs []string
+	err = s.store.StreamGradebook(assignment.KelasID, "", false, func(student *models.GradebookStudent) error {
+		for _, g := range book.Grades(student) {
+			if g.Assignment.ID == assignment.ID {
+				grades[student.SiswaID] = g.Nilai
+				userIDs = append(userIDs, student.SiswaID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghitung nilai tugas"})
+		return
+	}
+
+	result := models.LTIGradePushResult{}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, link := range links {
+		if err := s.pushLTIGrades(r.Context(), assignment, link, grades, userIDs, now, &result); err != nil {
+			log.Printf("Gagal mengirim nilai tugas %s ke platform LTI: %v", assignment.ID, err)
+			result.Galat = append(result.Galat, err.Error())
+		}
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) pushLTIGrades(ctx context.Context, assignment *models.Assignment, link *models.LTIResourceLink, grades map[string]*float64, userIDs []string, timestamp string, result *models.LTIGradePushResult) error {
+	platform, err := s.store.GetLTIPlatform(link.PlatformID)
+	if err != nil {
+		return err
+	}
+	client, err := s.ltiServiceClient(platform)
+	if err != nil {
+		return err
+	}
+	if link.LineItemURL == "" {
+		if link.LineItemsURL == "" {
+			return errors.New("platform tidak menyediakan layanan nilai untuk tautan " + link.ResourceLinkID)
+		}
+		link.LineItemURL, err = client.EnsureLineItem(ctx, link.LineItemsURL, lti.LineItemResource{
+			ScoreMaximum:   ltiScoreMaximum,
+			Label:          assignment.Judul,
+			ResourceID:     assignment.ID,
+			ResourceLinkID: link.ResourceLinkID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.store.SaveLTIResourceLink(link); err != nil {
+			return err
+		}
+	}
+
+	subs, err := s.store.GetLTIUserSubs(platform.ID, userIDs)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		sub, ok := subs[userID]
+		nilai := grades[userID]
+		if !ok || nilai == nil {
+			result.Dilewati++
+			continue
+		}
+		err := client.PublishScore(ctx, link.LineItemURL, lti.Score{
+			UserID:           sub,
+			ScoreGiven:       *nilai,
+			ScoreMaximum:     ltiScoreMaximum,
+			Timestamp:        timestamp,
+			ActivityProgress: "Completed",
+			GradingProgress:  "FullyGraded",
+		})
+		if err != nil {
+			result.Gagal++
+			if len(result.Galat) == 0 {
+				result.Galat = append(result.Galat, err.Error())
+			}
+			continue
+		}
+		result.Terkirim++
+	}
+	return nil
+}
+
+// --- Handlers LTI (superadmin) ---
+
+// URL yang perlu didaftarkan di platform saat menambahkan tool
+func (s *Server) handleGetLTIToolConfig(w http.ResponseWriter, r *http.Request) {
+	if !s.ltiEnabled(w) {
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"login_url":     s.lti.toolURL + "/api/lti/login",
+		"launch_url":    s.lti.toolURL + "/api/lti/launch",
+		"deep_link_url": s.lti.toolURL + "/api/lti/launch",
+		"jwks_url":      s.lti.toolURL + "/api/lti/jwks",
+	})
+}
+
+func (s *Server) handleGetLTIPlatforms(w http.ResponseWriter, r *http.Request) {
+	platforms, err := s.store.GetLTIPlatforms()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar platform LTI"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, platforms)
+}
+
+func (s *Server) handleCreateLTIPlatform(w http.ResponseWriter, r *http.Request) {
+	platform, ok := decodeLTIPlatform(w, r)
+	if !ok {
+		return
+	}
+	if err := s.store.CreateLTIPlatform(platform); err != nil {
+		writeLTIPlatformError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusCreated, platform)
+}
+
+func (s *Server) handleUpdateLTIPlatform(w http.ResponseWriter, r *http.Request) {
+	platform, ok := decodeLTIPlatform(w, r)
+	if !ok {
+		return
+	}
+	platform.ID = mux.Vars(r)["id"]
+	n, err := s.store.UpdateLTIPlatform(platform)
+	if err != nil {
+		writeLTIPlatformError(w, err)
+		return
+	}
+	if n == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Platform LTI tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, platform)
+}
+
+func (s *Server) handleDeleteLTIPlatform(w http.ResponseWriter, r *http.Request) {
+	n, err := s.store.DeleteLTIPlatform(mux.Vars(r)["id"])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus platform LTI"})
+		return
+	}
+	if n == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Platform LTI tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Platform LTI berhasil dihapus"})
+}
+
+func decodeLTIPlatform(w http.ResponseWriter, r *http.Request) (*models.LTIPlatform, bool) {
+	var platform models.LTIPlatform
+	if err := json.NewDecoder(r.Body).Decode(&platform); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return nil, false
+	}
+	if err := validator.New().Struct(platform); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Data platform tidak lengkap atau URL tidak valid"})
+		return nil, false
+	}
+	return &platform, true
+}
+
+func writeLTIPlatformError(w http.ResponseWriter, err error) {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		WriteJSON(w, http.StatusConflict, map[string]string{"message": "Platform dengan issuer dan client_id ini sudah terdaftar"})
+		return
+	}
+	WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan platform LTI"})
+}
+
+func (s *Server) ltiEnabled(w http.ResponseWriter) bool {
+	if s.lti == nil {
+		WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"message": "Integrasi LTI belum diaktifkan"})
+		return false
+	}
+	return true
+}
+
+// ltiSigningKey mengembalikan kunci aktif terbaru; kunci pertama dibuat saat dibutuhkan
+func (s *Server) ltiSigningKey() (*lti.SigningKey, error) {
+	keys, err := s.store.GetLTIKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if !k.Aktif {
+			continue
+		}
+		private, err := lti.ParsePrivateKey(k.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return &lti.SigningKey{Kid: k.Kid, Key: private}, nil
+	}
+
+	// Peluncuran lain mungkin membuat kunci lebih dulu; kunci yang tersimpan yang dipakai
+	kid, privatePEM, err := lti.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.store.EnsureLTIKey(kid, privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	private, err := lti.ParsePrivateKey(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &lti.SigningKey{Kid: key.Kid, Key: private}, nil
+}
+
+// RotateLTIKeys membuat kunci penandatanganan baru jika kunci aktif terbaru sudah berumur every,
+// lalu menghapus kunci lama yang sudah digantikan lebih dari grace agar platform sempat memuat
+// ulang JWKS. Dijalankan oleh scheduler.
+func (s *Server) RotateLTIKeys(now time.Time, every time.Duration, grace time.Duration) (int, error) {
+	if s.lti == nil {
+		return 0, nil
+	}
+	keys, err := s.store.GetLTIKeys()
+	if err != nil {
+		return 0, err
+	}
+	rotated := 0
+	for _, k := range keys {
+		if !k.Aktif {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, k.CreatedAt)
+		if err != nil {
+			return 0, fmt.Errorf("waktu pembuatan kunci LTI %s tidak valid: %w", k.Kid, err)
+		}
+		if now.Sub(createdAt) >= every {
+			kid, privatePEM, err := lti.GenerateKey()
+			if err != nil {
+				return 0, err
+			}
+			if err := s.store.CreateLTIKey(kid, privatePEM); err != nil {
+				return 0, err
+			}
+			rotated++
+		}
+		break
+	}
+	deleted, err := s.store.DeleteRetiredLTIKeys(now.Add(-grace))
+	return rotated + int(deleted), err
+}
+
+func (s *Server) ltiServiceClient(platform *models.LTIPlatform) (*lti.ServiceClient, error) {
+	key, err := s.ltiSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	return lti.NewServiceClient(nil, key, ltiPlatform(platform)), nil
+}
+
+func ltiPlatform(p *models.LTIPlatform) *lti.Platform {
+	return &lti.Platform{
+		Issuer:        p.Issuer,
+		ClientID:      p.ClientID,
+		AuthLoginURL:  p.AuthLoginURL,
+		AuthTokenURL:  p.AuthTokenURL,
+		JWKSURL:       p.JWKSURL,
+		DeploymentIDs: p.DeploymentIDs,
+	}
+}
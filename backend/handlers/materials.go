@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"sistem-skripsi/backend/blobstore"
+	"sistem-skripsi/backend/extract"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/rag"
+	"sistem-skripsi/backend/textdiff"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxMaterialFileSize = 20 << 20 // 20 MB
+	// Batas body multipart sedikit di atas ukuran file untuk field lain dan boundary
+	maxMaterialUploadBody = maxMaterialFileSize + 1<<20
+)
+
+// --- Handlers Materi ---
+
+func (s *Server) handleGetMaterials(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capViewClass) {
+		return
+	}
+
+	materials, err := s.store.GetMaterialsByClassID(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar materi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, materials)
+}
+
+func (s *Server) handleCreateMaterial(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageContent) {
+		return
+	}
+
+	var material models.Material
+	if err := json.NewDecoder(r.Body).Decode(&material); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(material); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Judul materi wajib diisi"})
+		return
+	}
+
+	material.KelasID = classID
+	material.PengunggahID = claims.UserID
+	material.FileURL, material.NamaFile, material.TipeFile, material.UkuranFile = "", "", "", 0
+	if err := s.store.CreateMaterial(&material, materialChunks(material.IsiMateri)); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan materi"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, material)
+}
+
+// Mengunggah dokumen materi (PDF, DOCX, PPTX, TXT). Tipe file ditentukan dari isinya,
+// bukan dari ekstensi, lalu teksnya diekstrak ke isi_materi untuk pipeline RAG.
+func (s *Server) handleUploadMaterial(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageContent) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxMaterialUploadBody)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"message": "Ukuran file maksimal 20 MB"})
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "File tidak ditemukan pada field 'file'"})
+		return
+	}
+	defer file.Close()
+	if header.Size > maxMaterialFileSize {
+		WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"message": "Ukuran file maksimal 20 MB"})
+		return
+	}
+
+	mimeType, extension, err := detectMaterialType(file)
+	if err != nil {
+		WriteJSON(w, http.StatusUnsupportedMediaType, map[string]string{"message": "Tipe file tidak didukung, gunakan PDF, DOCX, PPTX, atau TXT"})
+		return
+	}
+
+	text, err := extract.Text(file, header.Size, mimeType)
+	if err != nil {
+		log.Printf("Gagal mengekstrak teks materi %q: %v", header.Filename, err)
+		WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Teks tidak dapat diekstrak dari dokumen"})
+		return
+	}
+
+	judul := strings.TrimSpace(r.FormValue("judul"))
+	if judul == "" {
+		judul = strings.TrimSuffix(header.Filename, path.Ext(header.Filename))
+	}
+
+	key, err := newBlobKey("materials/"+classID, extension)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan file"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan file"})
+		return
+	}
+	if err := s.blobs.Put(r.Context(), key, file, header.Size, mimeType); err != nil {
+		log.Printf("Gagal menyimpan file materi ke blobstore: %v", err)
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan file"})
+		return
+	}
+
+	material := models.Material{
+		KelasID:      classID,
+		PengunggahID: claims.UserID,
+		Judul:        judul,
+		IsiMateri:    text,
+		FileURL:      key,
+		NamaFile:     header.Filename,
+		TipeFile:     mimeType,
+		UkuranFile:   header.Size,
+	}
+	if err := s.store.CreateMaterial(&material, materialChunks(material.IsiMateri)); err != nil {
+		// Jangan tinggalkan file yatim di blobstore
+		if err := s.blobs.Delete(r.Context(), key); err != nil {
+			log.Printf("Gagal menghapus file materi %s: %v", key, err)
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan materi"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, material)
+}
+
+func (s *Server) handleGetMaterial(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, material)
+}
+
+func (s *Server) handleDownloadMaterialFile(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+	if material.FileURL == "" {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Materi ini tidak memiliki file"})
+		return
+	}
+
+	body, err := s.blobs.Get(r.Context(), material.FileURL)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "File materi tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil file materi"})
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", material.TipeFile)
+	w.Header().Set("Content-Disposition", attachmentDisposition(material.NamaFile))
+	if material.UkuranFile > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(material.UkuranFile, 10))
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("Gagal mengirim file materi %s: %v", material.ID, err)
+	}
+}
+
+func (s *Server) handleDeleteMaterial(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	_, orphaned, err := s.store.DeleteMaterial(material.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus materi"})
+		return
+	}
+	// File yang masih dirujuk salinan kelas atau riwayat versi lain tidak ikut dihapus
+	for _, key := range orphaned {
+		if err := s.blobs.Delete(r.Context(), key); err != nil {
+			log.Printf("Gagal menghapus file materi %s: %v", key, err)
+		}
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Materi berhasil dihapus"})
+}
+
+// Mengubah judul/isi materi. Perubahan konten disimpan sebagai versi baru; versi lama tetap utuh
+// agar konteks penilaian AI sebelumnya masih dapat ditelusuri.
+func (s *Server) handleUpdateMaterial(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	material, ok := s.materialForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMaterialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Judul materi tidak boleh kosong"})
+		return
+	}
+
+	judul, isi := material.Judul, material.IsiMateri
+	if req.Judul != nil {
+		judul = strings.TrimSpace(*req.Judul)
+	}
+	if req.IsiMateri != nil {
+		isi = *req.IsiMateri
+	}
+	if judul == "" {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Judul materi tidak boleh kosong"})
+		return
+	}
+
+	version, created, err := s.store.CreateMaterialVersion(material.ID, judul, isi, claims.UserID, materialChunks(isi))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Materi tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan perubahan materi"})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	WriteJSON(w, status, map[string]any{"versi_baru": created, "versi": version})
+}
+
+func (s *Server) handleGetMaterialVersions(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+
+	versions, err := s.store.GetMaterialVersions(material.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil riwayat versi materi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, versions)
+}
+
+func (s *Server) handleGetMaterialVersion(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+	nomor, err := strconv.Atoi(mux.Vars(r)["nomor"])
+	if err != nil || nomor < 1 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Nomor versi tidak valid"})
+		return
+	}
+
+	version, ok := s.loadMaterialVersion(w, material.ID, nomor)
+	if !ok {
+		return
+	}
+	WriteJSON(w, http.StatusOK, version)
+}
+
+// Perbandingan per baris antara dua versi materi. Default: versi terkini terhadap versi sebelumnya.
+func (s *Server) handleDiffMaterialVersions(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+
+	to := material.VersiTerkini
+	if v := r.URL.Query().Get("to"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter 'to' tidak valid"})
+			return
+		}
+		to = n
+	}
+	from := to - 1
+	if v := r.URL.Query().Get("from"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Parameter 'from' tidak valid"})
+			return
+		}
+		from = n
+	}
+	if from < 1 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Materi baru memiliki satu versi"})
+		return
+	}
+
+	fromVersion, ok := s.loadMaterialVersion(w, material.ID, from)
+	if !ok {
+		return
+	}
+	toVersion, ok := s.loadMaterialVersion(w, material.ID, to)
+	if !ok {
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"dari":       from,
+		"ke":         to,
+		"judul":      textdiff.Lines(fromVersion.Judul, toVersion.Judul),
+		"isi_materi": textdiff.Lines(fromVersion.IsiMateri, toVersion.IsiMateri),
+	})
+}
+
+// Konteks materi (versi dan chunk) yang dipakai AI saat menilai sebuah jawaban
+func (s *Server) handleGetGradingContext(w http.ResponseWriter, r *http.Request) {
+	submissionID := mux.Vars(r)["id"]
+	if !s.authorizeSubmission(w, r, submissionID, capReview) {
+		return
+	}
+
+	context, err := s.store.GetGradingContext(submissionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Jawaban ini belum dinilai AI"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil konteks penilaian"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, context)
+}
+
+// loadMaterialVersion mengambil satu versi materi. Versi hasil backfill migrasi belum memiliki
+// chunk, sehingga chunk-nya dibuat saat pertama kali diminta.
+func (s *Server) loadMaterialVersion(w http.ResponseWriter, materialID string, nomor int) (*models.MaterialVersion, bool) {
+	version, err := s.store.GetMaterialVersion(materialID, nomor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Versi materi tidak ditemukan"})
+			return nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil versi materi"})
+		return nil, false
+	}
+	version, err = s.ensureVersionChunks(version)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil versi materi"})
+		return nil, false
+	}
+	return version, true
+}
+
+// ensureVersionChunks membuat chunk untuk versi yang belum memilikinya lalu memuat ulang versi tersebut.
+// Kegagalan membuat chunk hanya dicatat; versi dikembalikan apa adanya.
+func (s *Server) ensureVersionChunks(version *models.MaterialVersion) (*models.MaterialVersion, error) {
+	if len(version.Chunks) > 0 || version.IsiMateri == "" {
+		return version, nil
+	}
+	if err := s.store.SaveVersionChunks(version.ID, materialChunks(version.IsiMateri)); err != nil {
+		log.Printf("Gagal membuat chunk untuk versi materi %s: %v", version.ID, err)
+		return version, nil
+	}
+	return s.store.GetMaterialVersion(version.MateriID, version.NomorVersi)
+}
+
+func materialChunks(text string) []string {
+	return rag.Chunk(text, rag.DefaultChunkSize, rag.DefaultChunkOverlap)
+}
+
+// materialForRequest memuat materi dari path {id} lalu memeriksa hak akses pada kelasnya
+func (s *Server) materialForRequest(w http.ResponseWriter, r *http.Request, capability classCapability) (*models.Material, bool) {
+	material, err := s.store.GetMaterialByID(mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Materi tidak ditemukan"})
+			return nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data materi"})
+		return nil, false
+	}
+	if !s.authorizeClass(w, r, material.KelasID, capability) {
+		return nil, false
+	}
+	return material, true
+}
+
+// detectMaterialType menentukan tipe MIME dari isi file dan memastikan tipe tersebut didukung
+func detectMaterialType(file multipart.File) (string, string, error) {
+	mtype, err := mimetype.DetectReader(file)
+	if err != nil {
+		return "", "", err
+	}
+	for _, supported := range []string{extract.MIMEPDF, extract.MIMEDOCX, extract.MIMEPPTX, extract.MIMEText} {
+		if mtype.Is(supported) {
+			return supported, mtype.Extension(), nil
+		}
+	}
+	return "", "", extract.ErrUnsupportedType
+}
+
+// newBlobKey membuat kunci objek acak di bawah prefix tertentu
+func newBlobKey(prefix string, extension string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + "/" + hex.EncodeToString(buf) + extension, nil
+}
+
+func attachmentDisposition(filename string) string {
+	if filename == "" {
+		filename = "materi"
+	}
+	return `attachment; filename="` + strings.NewReplacer(`"`, "", "\n", "", "\r", "").Replace(filename) + `"`
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"sistem-skripsi/backend/models"
 	"strings"
@@ -11,11 +13,17 @@ import (
 
 type contextKey string
 const userClaimsKey = contextKey("userClaims")
+const requestIDKey = contextKey("requestID")
 
 // NOTE: Kunci ini harus dipindahkan ke environment variable di production!
 var jwtKey = []byte("kunci_rahasia_super_aman_yang_harus_diganti")
 
-func JWTMiddleware(next http.Handler) http.Handler {
+// Path yang tetap boleh diakses ketika pengguna wajib mengganti password
+const changePasswordPath = "/api/me/password"
+
+// JWTMiddleware memvalidasi token lalu mencocokkannya dengan status akun di database,
+// sehingga akun yang dinonaktifkan atau token versi lama langsung ditolak.
+func (s *Server) JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -35,11 +43,63 @@ func JWTMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		user, err := s.store.GetUserByID(claims.UserID)
+		if err != nil || claims.TokenVersion != user.TokenVersion {
+			WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Token tidak valid"})
+			return
+		}
+		if !user.IsActive {
+			WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Akun telah dinonaktifkan"})
+			return
+		}
+		if user.MustChangePassword && r.URL.Path != changePasswordPath {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Anda wajib mengganti password terlebih dahulu"})
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), userClaimsKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// TokenFromQuery memakai parameter access_token sebagai header Authorization jika header
+// tidak dikirim. Hanya untuk rute yang klien browsernya tidak dapat mengatur header.
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequestID memakai header X-Request-ID dari proxy jika formatnya wajar atau membuat ID baru,
+// lalu mengembalikannya di respons agar laporan pengguna dapat dicocokkan dengan jejak audit
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			buf := make([]byte, 16)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 func AdminRequired(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		claims, ok := r.Context().Value(userClaimsKey).(*models.Claims)
@@ -74,11 +134,29 @@ func TeacherOrAdminRequired(next http.Handler) http.Handler {
 	})
 }
 
+func StudentRequired(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(userClaimsKey).(*models.Claims)
+		if !ok {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Tidak dapat memproses klaim pengguna"})
+			return
+		}
+
+		if claims.Peran != "student" {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akses ditolak: Memerlukan hak akses siswa"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func CorsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Exam-Token, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
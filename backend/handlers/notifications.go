@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/deadline"
+	"sistem-skripsi/backend/mail"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/realtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	eventNotificationCreated = "notification.created"
+	// Tugas yang dibuka dalam rentang ini tetap diumumkan, mis. setelah server sempat mati
+	assignmentNoticeWindow = 24 * time.Hour
+	digestMaxItems         = 50
+)
+
+// WithMailer mengaktifkan ringkasan notifikasi lewat email. appURL adalah alamat frontend
+// untuk tautan di dalam email.
+func WithMailer(sender mail.Sender, appURL string) Option {
+	return func(s *Server) {
+		s.mailer = sender
+		s.appURL = strings.TrimRight(appURL, "/")
+	}
+}
+
+// --- Pembuatan Notifikasi ---
+
+// notify menyimpan notifikasi lalu mengirimkannya ke klien realtime penerima. Kegagalan hanya
+// dicatat agar tidak membatalkan aksi yang memicu notifikasi.
+func (s *Server) notify(notifications []*models.Notification) int {
+	if len(notifications) == 0 {
+		return 0
+	}
+	created, err := s.store.CreateNotifications(notifications)
+	if err != nil {
+		log.Printf("Gagal membuat %d notifikasi %s: %v", len(notifications), notifications[0].Jenis, err)
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, n := range created {
+		msg, err := realtime.NewMessage(n.ID, eventNotificationCreated, n, n.CreatedAt)
+		if err != nil {
+			log.Printf("Gagal membuat pesan realtime notifikasi %s: %v", n.ID, err)
+			continue
+		}
+		if err := s.events.Publish(ctx, realtime.UserTopic(n.UserID), msg); err != nil {
+			log.Printf("Gagal menerbitkan notifikasi %s: %v", n.ID, err)
+		}
+	}
+	return len(created)
+}
+
+// notifyReviewPending memberi tahu pemilik dan staf kelas bahwa ada jawaban baru. Notifikasi
+// digabung menjadi satu per tugas per hari agar kotak masuk tidak dibanjiri.
+func (s *Server) notifyReviewPending(classID string, sub *models.Submission) {
+	reviewers, err := s.store.GetClassReviewerIDs(classID)
+	if err != nil {
+		log.Printf("Gagal mengambil staf kelas %s untuk notifikasi: %v", classID, err)
+		return
+	}
+	judul := "soal latihan"
+	ref := sub.SoalID
+	if sub.AssignmentID != "" {
+		ref = sub.AssignmentID
+		if assignment, err := s.store.GetAssignmentByID(sub.AssignmentID); err == nil {
+			judul = "tugas " + assignment.Judul
+		}
+	}
+	day := time.Now().In(usageZone()).Format("2006-01-02")
+
+	notifications := make([]*models.Notification, 0, len(reviewers))
+	for _, userID := range reviewers {
+		notifications = append(notifications, &models.Notification{
+			UserID:  userID,
+			Jenis:   models.NotificationReviewPending,
+			Judul:   "Jawaban menunggu tinjauan",
+			Isi:     fmt.Sprintf("Ada jawaban baru pada %s yang menunggu tinjauan.", judul),
+			Tautan:  "/dashboard/teacher/classes/" + classID + "/reviews",
+			KelasID: classID,
+			Kunci:   models.NotificationReviewPending + ":" + ref + ":" + day,
+		})
+	}
+	s.notify(notifications)
+}
+
+// notifyGradePublished memberi tahu siswa saat nilai akhir jawabannya pertama kali terbit
+func (s *Server) notifyGradePublished(classID string, sub *models.Submission, review *models.TeacherReview) {
+	judul := "soal latihan"
+	tautan := ""
+	if sub.AssignmentID != "" {
+		tautan = "/dashboard/student/assignments/" + sub.AssignmentID
+		if assignment, err := s.store.GetAssignmentByID(sub.AssignmentID); err == nil {
+			judul = "tugas " + assignment.Judul
+		}
+	}
+	s.notify([]*models.Notification{{
+		UserID:  sub.SiswaID,
+		Jenis:   models.NotificationGradePublished,
+		Judul:   "Nilai telah terbit",
+		Isi:     fmt.Sprintf("Jawaban Anda pada %s telah dinilai: %.2f.", judul, review.SkorFinal),
+		Tautan:  tautan,
+		KelasID: classID,
+		Kunci:   models.NotificationGradePublished + ":" + sub.ID,
+	}})
+}
+
+// NotifyAssignments dijalankan berkala: mengumumkan tugas yang baru dibuka ke siswa kelas dan
+// mengingatkan siswa yang belum mengumpulkan saat tenggat tinggal lead lagi
+func (s *Server) NotifyAssignments(now time.Time, lead time.Duration) (int, error) {
+	opened, err := s.store.GetOpenedAssignments(now.Add(-assignmentNoticeWindow), now)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	for _, a := range opened {
+		students, err := s.store.GetClassMemberIDs(a.KelasID)
+		if err != nil {
+			return created, err
+		}
+		notifications := make([]*models.Notification, 0, len(students))
+		for _, siswaID := range students {
+			notifications = append(notifications, &models.Notification{
+				UserID:  siswaID,
+				Jenis:   models.NotificationAssignmentPosted,
+				Judul:   "Tugas baru: " + a.Judul,
+				Isi:     fmt.Sprintf("Tugas %s di kelas %s telah dibuka. Tenggat: %s.", a.Judul, a.NamaKelas, formatLocal(a.DitutupPada, a.ZonaWaktu)),
+				Tautan:  "/dashboard/student/assignments/" + a.ID,
+				KelasID: a.KelasID,
+				Kunci:   models.NotificationAssignmentPosted + ":" + a.ID,
+			})
+		}
+		created += s.notify(notifications)
+	}
+
+	reminders, err := s.store.GetDeadlineReminders(now, lead)
+	if err != nil {
+		return created, err
+	}
+	notifications := make([]*models.Notification, 0, len(reminders))
+	for _, r := range reminders {
+		notifications = append(notifications, &models.Notification{
+			UserID:  r.SiswaID,
+			Jenis:   models.NotificationDeadlineSoon,
+			Judul:   "Tenggat segera berakhir: " + r.Judul,
+			Isi:     fmt.Sprintf("Tugas %s di kelas %s ditutup pada %s dan Anda belum mengumpulkan semua soal.", r.Judul, r.NamaKelas, formatLocal(r.DitutupPada, r.ZonaWaktu)),
+			Tautan:  "/dashboard/student/assignments/" + r.AssignmentID,
+			KelasID: r.KelasID,
+			Kunci:   models.NotificationDeadlineSoon + ":" + r.AssignmentID,
+		})
+	}
+	created += s.notify(notifications)
+	return created, nil
+}
+
+func formatLocal(t time.Time, zone string) string {
+	loc, err := deadline.LoadZone(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("02/01/2006 15:04 MST")
+}
+
+// SendNotificationDigests dijalankan berkala dan mengirim satu email per pengguna per hari
+// (zona waktu laporan) berisi notifikasi yang belum dibaca, mulai pukul hour. Ringkasan yang
+// gagal dikirim dicoba lagi pada putaran berikutnya.
+func (s *Server) SendNotificationDigests(now time.Time, hour int) (int, error) {
+	if s.mailer == nil {
+		return 0, nil
+	}
+	local := now.In(usageZone())
+	if local.Hour() < hour {
+		return 0, nil
+	}
+	date := local.Format("2006-01-02")
+
+	recipients, err := s.store.GetDigestRecipients(date)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, r := range recipients {
+		claimed, err := s.store.ClaimNotificationDigest(r.UserID, date)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+		ids, err := s.sendDigest(r, local)
+		if err != nil {
+			log.Printf("Gagal mengirim ringkasan notifikasi ke %s: %v", r.Email, err)
+			if err := s.store.ReleaseNotificationDigest(r.UserID, date); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := s.store.CompleteNotificationDigest(r.UserID, date, ids); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *Server) sendDigest(r *models.DigestRecipient, local time.Time) ([]string, error) {
+	notifications, err := s.store.GetDigestNotifications(r.UserID, digestMaxItems)
+	if err != nil {
+		return nil, err
+	}
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Halo %s,\n\nAnda memiliki %d notifikasi yang belum dibaca:\n\n", r.NamaLengkap, len(notifications))
+	ids := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		ids = append(ids, n.ID)
+		fmt.Fprintf(&body, "- %s\n  %s\n", n.Judul, n.Isi)
+		if n.Tautan != "" && s.appURL != "" {
+			fmt.Fprintf(&body, "  %s%s\n", s.appURL, n.Tautan)
+		}
+		body.WriteString("\n")
+	}
+	body.WriteString("Email ini dapat dimatikan per jenis notifikasi melalui pengaturan notifikasi akun Anda.\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err = s.mailer.Send(ctx, &mail.Message{
+		To:      r.Email,
+		ToName:  r.NamaLengkap,
+		Subject: fmt.Sprintf("Ringkasan notifikasi SAGE %s", local.Format("02/01/2006")),
+		Body:    body.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// --- Handlers Notifikasi ---
+
+// Kotak masuk pengguna; ?status=belum_dibaca hanya menampilkan yang belum dibaca
+func (s *Server) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	page, limit := parsePagination(r)
+	unreadOnly := false
+	switch r.URL.Query().Get("status") {
+	case "", "semua":
+	case "belum_dibaca":
+		unreadOnly = true
+	default:
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Status harus semua atau belum_dibaca"})
+		return
+	}
+
+	notifications, total, err := s.store.GetNotifications(claims.UserID, unreadOnly, page, limit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil notifikasi"})
+		return
+	}
+	unread, err := s.store.CountUnreadNotifications(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil notifikasi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, models.NotificationInbox{
+		PaginatedResponse: models.PaginatedResponse{Data: notifications, Page: page, Limit: limit, Total: total},
+		BelumDibaca:       unread,
+	})
+}
+
+func (s *Server) handleGetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	unread, err := s.store.CountUnreadNotifications(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghitung notifikasi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]int{"belum_dibaca": unread})
+}
+
+func (s *Server) handleMarkNotification(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	var req models.MarkNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Field dibaca wajib diisi"})
+		return
+	}
+
+	notification, err := s.store.MarkNotification(claims.UserID, mux.Vars(r)["id"], *req.Dibaca)
+	if err == sql.ErrNoRows {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Notifikasi tidak ditemukan"})
+		return
+	}
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui notifikasi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, notification)
+}
+
+func (s *Server) handleMarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	marked, err := s.store.MarkAllNotificationsRead(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui notifikasi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "Semua notifikasi ditandai dibaca", "jumlah_ditandai": marked})
+}
+
+func (s *Server) handleGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	prefs, err := s.store.GetNotificationPreferences(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil preferensi notifikasi"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, prefs)
+}
+
+func (s *Server) handleUpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	var req models.NotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Preferensi wajib diisi"})
+		return
+	}
+	for _, p := range req.Preferensi {
+		if !slices.Contains(models.NotificationKinds, p.Jenis) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Jenis notifikasi tidak dikenal: " + p.Jenis})
+			return
+		}
+	}
+
+	if err := s.store.SaveNotificationPreferences(claims.UserID, req.Preferensi); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan preferensi notifikasi"})
+		return
+	}
+	s.handleGetNotificationPreferences(w, r)
+}
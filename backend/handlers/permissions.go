@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/models"
+)
+
+// Kemampuan yang dibutuhkan sebuah aksi pada kelas
+type classCapability int
+
+const (
+	capViewClass     classCapability = iota // Melihat isi kelas
+	capReview                               // Meninjau dan menilai jawaban siswa
+	capManageContent                        // Mengelola materi, soal, dan rubrik
+	capManageClass                          // Mengelola staf dan pengaturan kelas
+)
+
+// Kemampuan tertinggi untuk setiap peran staf kelas
+var classRoleCapability = map[string]classCapability{
+	models.ClassRoleOwner:     capManageClass,
+	models.ClassRoleCoTeacher: capManageContent,
+	models.ClassRoleAssistant: capReview,
+}
+
+// authorizeClass memeriksa apakah pengguna pada request memiliki kemampuan yang diminta
+// di kelas tersebut. Superadmin selalu diizinkan. Kelas arsip hanya dapat dibaca. Jika tidak
+// diizinkan, respons error sudah ditulis dan fungsi mengembalikan false.
+func (s *Server) authorizeClass(w http.ResponseWriter, r *http.Request, classID string, capability classCapability) bool {
+	return s.checkClassAccess(w, r, classID, capability, false)
+}
+
+func (s *Server) checkClassAccess(w http.ResponseWriter, r *http.Request, classID string, capability classCapability, allowArchived bool) bool {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	access, err := s.store.GetClassAccess(classID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Kelas tidak ditemukan"})
+			return false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memeriksa hak akses kelas"})
+		return false
+	}
+
+	if claims.Peran != "superadmin" {
+		granted, ok := classRoleCapability[access.Peran]
+		if !ok {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akses ditolak: Anda bukan staf kelas ini"})
+			return false
+		}
+		if granted < capability {
+			WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Akses ditolak: Peran Anda di kelas ini tidak mengizinkan aksi tersebut"})
+			return false
+		}
+	}
+
+	if access.Archived && capability > capViewClass && !allowArchived {
+		WriteJSON(w, http.StatusConflict, map[string]string{"message": "Kelas telah diarsipkan dan hanya dapat dibaca"})
+		return false
+	}
+	return true
+}
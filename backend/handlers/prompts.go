@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sistem-skripsi/backend/grading"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/prompt"
+	"sistem-skripsi/backend/rag"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Template Prompt Penilaian ---
+
+func (s *Server) handleGetPromptTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.store.GetActivePromptTemplates()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil template prompt"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, templates)
+}
+
+func (s *Server) handleGetPromptTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.store.GetPromptTemplateVersions(mux.Vars(r)["level"])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil riwayat template prompt"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, templates)
+}
+
+func (s *Server) handleGetPromptTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	versi, ok := promptVersionParam(w, r)
+	if !ok {
+		return
+	}
+	template, err := s.store.GetPromptTemplate(mux.Vars(r)["level"], versi)
+	if err != nil {
+		writePromptTemplateError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, template)
+}
+
+// Menyimpan versi baru template sebuah level. Versi lama tetap tersimpan dan tidak berubah.
+func (s *Server) handleCreatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePromptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Isi template wajib diisi"})
+		return
+	}
+	if err := prompt.Validate(req.Isi); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Template tidak valid: " + err.Error()})
+		return
+	}
+
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	template := &models.PromptTemplate{
+		LevelKognitif: mux.Vars(r)["level"],
+		Isi:           req.Isi,
+		Catatan:       strings.TrimSpace(req.Catatan),
+		DibuatOleh:    claims.UserID,
+	}
+	if err := s.store.CreatePromptTemplate(template, req.Aktifkan); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan template prompt"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, template)
+}
+
+// Menjadikan versi tertentu sebagai template yang dipakai saat menilai (termasuk rollback)
+func (s *Server) handleActivatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	versi, ok := promptVersionParam(w, r)
+	if !ok {
+		return
+	}
+	template, err := s.store.ActivatePromptTemplate(mux.Vars(r)["level"], versi)
+	if err != nil {
+		writePromptTemplateError(w, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, template)
+}
+
+// Merender prompt untuk sebuah jawaban tanpa memanggil model, untuk memeriksa template
+func (s *Server) handlePreviewPrompt(w http.ResponseWriter, r *http.Request) {
+	var req models.PromptPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "submission_id wajib diisi"})
+		return
+	}
+
+	input, err := s.store.GetGradingInput(req.SubmissionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Jawaban tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data jawaban"})
+		return
+	}
+	level := input.Question.LevelKognitif
+	if level == "" {
+		WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Soal belum memiliki level kognitif"})
+		return
+	}
+
+	template := &models.PromptTemplate{LevelKognitif: level, Isi: req.Isi}
+	switch {
+	case req.Isi != "":
+	case req.Versi > 0:
+		template, err = s.store.GetPromptTemplate(level, req.Versi)
+	default:
+		template, err = s.store.GetActivePromptTemplate(level)
+	}
+	if err != nil {
+		writePromptTemplateError(w, err)
+		return
+	}
+
+	input.MaterialVersion, err = s.ensureVersionChunks(input.MaterialVersion)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil versi materi"})
+		return
+	}
+	data, chunks := gradingPromptData(input)
+	rendered, err := prompt.Render(template.Isi, data)
+	if err != nil {
+		WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Template gagal dirender: " + err.Error()})
+		return
+	}
+
+	// Prompt yang dikirim ke model juga memuat petunjuk format keluaran
+	spec := gradingSpec(input.Question, chunks)
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"level_kognitif":      level,
+		"versi":               template.Versi, // 0 untuk draf template
+		"material_version_id": input.MaterialVersion.ID,
+		"chunk_ids":           spec.ChunkIDs,
+		"prompt":              rendered + "\n\n" + grading.Instructions(spec),
+	})
+}
+
+// gradingPromptData menyusun data template dari bahan penilaian. Chunk materi dipilih dengan
+// retrieval terhadap soal, kunci jawaban, dan jawaban siswa.
+func gradingPromptData(input *models.GradingInput) (prompt.Data, []*models.MaterialChunk) {
+	q := input.Question
+	data := prompt.Data{
+		Soal:          q.TeksSoal,
+		LevelKognitif: q.LevelKognitif,
+		KunciJawaban:  q.KunciJawaban,
+		Rubrik:        []prompt.Aspect{},
+		Konteks:       []prompt.Context{},
+		Jawaban:       input.Submission.TeksJawaban,
+	}
+	for _, rubric := range q.Rubrik {
+		data.Rubrik = append(data.Rubrik, prompt.Aspect{NamaAspek: rubric.NamaAspek, Deskripsi: rubric.Deskripsi, Bobot: rubric.Bobot})
+	}
+
+	var selected []*models.MaterialChunk
+	if input.MaterialVersion != nil {
+		texts := make([]string, len(input.MaterialVersion.Chunks))
+		for i, c := range input.MaterialVersion.Chunks {
+			texts[i] = c.Isi
+		}
+		query := strings.Join([]string{q.TeksSoal, q.KunciJawaban, input.Submission.TeksJawaban}, "\n")
+		for _, i := range rag.TopK(query, texts, rag.DefaultTopK) {
+			c := input.MaterialVersion.Chunks[i]
+			selected = append(selected, c)
+			data.Konteks = append(data.Konteks, prompt.Context{ID: c.ID, Urutan: c.Urutan, Isi: c.Isi})
+		}
+	}
+	return data, selected
+}
+
+func promptVersionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	versi, err := strconv.Atoi(mux.Vars(r)["versi"])
+	if err != nil || versi < 1 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Nomor versi tidak valid"})
+		return 0, false
+	}
+	return versi, true
+}
+
+func writePromptTemplateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Template prompt tidak ditemukan"})
+		return
+	}
+	WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil template prompt"})
+}
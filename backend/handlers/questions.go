@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sistem-skripsi/backend/itemanalysis"
+	"sistem-skripsi/backend/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Jumlah jawaban terbaru per soal yang dipakai untuk mencari tema umpan balik
+const itemFeedbackLimit = 200
+
+// --- Handlers Soal ---
+
+// Bank soal materi. ?analisis=true menambahkan statistik butir (rata-rata, varians, daya beda,
+// tingkat koreksi guru, tema umpan balik, dan peringatan) agar soal bermasalah dapat direvisi.
+func (s *Server) handleGetQuestions(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capViewClass)
+	if !ok {
+		return
+	}
+	analyze := r.URL.Query().Get("analisis") == "true"
+	if analyze && !s.checkClassAccess(w, r, material.KelasID, capReview, true) {
+		return
+	}
+
+	questions, err := s.store.GetQuestionsByMaterialID(material.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar soal"})
+		return
+	}
+	if analyze {
+		if err := s.attachItemStatistics(material.ID, questions); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghitung analisis butir soal"})
+			return
+		}
+	}
+	WriteJSON(w, http.StatusOK, questions)
+}
+
+func (s *Server) attachItemStatistics(materialID string, questions []*models.Question) error {
+	observations, err := s.store.GetItemObservations(materialID)
+	if err != nil {
+		return err
+	}
+	reviews, err := s.store.GetItemReviewStats(materialID, itemanalysis.OverrideTolerance)
+	if err != nil {
+		return err
+	}
+	feedback, err := s.store.GetItemFeedback(materialID, itemFeedbackLimit)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(questions))
+	for i, q := range questions {
+		ids[i] = q.ID
+	}
+	stats := itemanalysis.Analyze(ids, observations, reviews, feedback)
+	for _, q := range questions {
+		q.Statistik = stats[q.ID]
+	}
+	return nil
+}
+
+func (s *Server) handleCreateQuestion(w http.ResponseWriter, r *http.Request) {
+	material, ok := s.materialForRequest(w, r, capManageContent)
+	if !ok {
+		return
+	}
+
+	var question models.Question
+	if err := json.NewDecoder(r.Body).Decode(&question); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(question); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Data soal tidak lengkap atau tidak valid"})
+		return
+	}
+
+	question.MateriID = material.ID
+	if err := s.store.CreateQuestion(&question); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan soal"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, question)
+}
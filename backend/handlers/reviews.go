@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Tinjauan Guru ---
+
+// Antrean jawaban yang belum ditinjau. ?ditandai=true hanya menampilkan jawaban dengan
+// tanda kemiripan yang belum ditindaklanjuti.
+func (s *Server) handleGetReviewQueue(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.checkClassAccess(w, r, classID, capReview, true) {
+		return
+	}
+
+	q := r.URL.Query()
+	filter := models.ReviewQueueFilter{
+		AssignmentID:  q.Get("assignment_id"),
+		HanyaDitandai: q.Get("ditandai") == "true",
+	}
+	filter.Page, filter.Limit = parsePagination(r)
+
+	items, total, err := s.store.GetReviewQueue(classID, filter)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil antrean tinjauan"})
+		return
+	}
+	for _, item := range items {
+		if item.KemiripanMaks == nil {
+			item.TandaKemiripan = []*models.SimilarityFlag{}
+			continue
+		}
+		if item.TandaKemiripan, err = s.store.GetSimilarityFlags(item.SubmissionID); err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tanda kemiripan"})
+			return
+		}
+	}
+	WriteJSON(w, http.StatusOK, models.PaginatedResponse{Data: items, Page: filter.Page, Limit: filter.Limit, Total: total})
+}
+
+// Menyimpan nilai akhir dan catatan guru untuk sebuah jawaban
+func (s *Server) handleReviewSubmission(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	submissionID := mux.Vars(r)["id"]
+	if !s.authorizeSubmission(w, r, submissionID, capReview) {
+		return
+	}
+
+	var review models.TeacherReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(review); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Skor final dan skor aspek harus antara 0 dan 100"})
+		return
+	}
+
+	review.SubmissionID = submissionID
+	review.ReviewerID = claims.UserID
+	before, err := s.store.GetSubmissionScores(submissionID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil nilai jawaban"})
+		return
+	}
+	if err := s.store.SaveTeacherReview(&review); err != nil {
+		if errors.Is(err, store.ErrRubricNotInQuestion) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Aspek rubrik bukan milik soal jawaban ini"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan tinjauan"})
+		return
+	}
+	s.auditScoreChange(r, submissionID, before)
+	s.publishReview(&review)
+	WriteJSON(w, http.StatusOK, review)
+}
+
+// auditScoreChange mencatat skor jawaban sebelum dan sesudah tinjauan guru disimpan
+func (s *Server) auditScoreChange(r *http.Request, submissionID string, before *models.SubmissionScores) {
+	after, err := s.store.GetSubmissionScores(submissionID)
+	if err != nil {
+		log.Printf("Gagal mengambil nilai jawaban %s untuk audit: %v", submissionID, err)
+		return
+	}
+	classID, err := s.store.GetSubmissionClassID(submissionID)
+	if err != nil {
+		log.Printf("Gagal mengambil kelas jawaban %s untuk audit: %v", submissionID, err)
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditScoreSet, TargetJenis: "submission", TargetID: submissionID, KelasID: classID}, before, after)
+}
+
+// authorizeSubmission memeriksa kemampuan pengguna pada kelas tempat jawaban berada
+func (s *Server) authorizeSubmission(w http.ResponseWriter, r *http.Request, submissionID string, capability classCapability) bool {
+	classID, err := s.store.GetSubmissionClassID(submissionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Jawaban tidak ditemukan"})
+			return false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data jawaban"})
+		return false
+	}
+	return s.authorizeClass(w, r, classID, capability)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/similarity"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// Jumlah jawaban yang diproses per putaran pemindaian kemiripan
+const similarityBatchSize = 200
+
+// --- Handlers Kemiripan Jawaban ---
+
+func (s *Server) handleGetSimilarityFlags(w http.ResponseWriter, r *http.Request) {
+	submissionID := mux.Vars(r)["id"]
+	if !s.authorizeSubmission(w, r, submissionID, capReview) {
+		return
+	}
+
+	flags, err := s.store.GetSimilarityFlags(submissionID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tanda kemiripan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, flags)
+}
+
+// Menandai tanda kemiripan sebagai dikonfirmasi (plagiat) atau diabaikan
+func (s *Server) handleUpdateSimilarityFlag(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	flagID := mux.Vars(r)["id"]
+
+	classID, err := s.store.GetSimilarityFlagClassID(flagID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Tanda kemiripan tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil tanda kemiripan"})
+		return
+	}
+	if !s.authorizeClass(w, r, classID, capReview) {
+		return
+	}
+
+	var req models.UpdateFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Status harus baru, dikonfirmasi, atau diabaikan"})
+		return
+	}
+
+	if _, err := s.store.UpdateSimilarityFlagStatus(flagID, req.Status, claims.UserID); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui tanda kemiripan"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Tanda kemiripan berhasil diperbarui"})
+}
+
+// ScanSimilarity membuat sidik jari untuk jawaban yang belum memilikinya, termasuk jawaban
+// lama dan jawaban yang dikumpulkan otomatis saat ujian berakhir. Dipanggil berkala dari main.
+func (s *Server) ScanSimilarity() (int, error) {
+	submissions, err := s.store.GetUnfingerprintedSubmissions(similarityBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	materials := map[string]map[uint64]struct{}{}
+	processed := 0
+	for _, sub := range submissions {
+		if err := s.fingerprintSubmission(sub, materials); err != nil {
+			log.Printf("Gagal memeriksa kemiripan jawaban %s: %v", sub.ID, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// fingerprintSubmission menghitung sidik jari jawaban lalu membandingkannya dengan jawaban
+// siswa lain pada soal yang sama dan dengan isi materi soal. materials menyimpan shingle
+// materi per soal agar tidak dihitung ulang dalam satu putaran.
+func (s *Server) fingerprintSubmission(sub *models.Submission, materials map[string]map[uint64]struct{}) error {
+	config := similarity.DefaultConfig
+	fp := similarity.Compute(sub.TeksJawaban)
+	fingerprint := &models.SubmissionFingerprint{
+		SubmissionID:  sub.ID,
+		SiswaID:       sub.SiswaID,
+		MinHash:       fp.MinHash,
+		SimHash:       fp.SimHash,
+		JumlahShingle: fp.Shingles,
+	}
+
+	var flags []*models.SimilarityFlag
+	if fp.Comparable() {
+		others, err := s.store.GetQuestionFingerprints(sub.SoalID, sub.SiswaID)
+		if err != nil {
+			return err
+		}
+		for _, other := range others {
+			candidate := similarity.Fingerprint{MinHash: other.MinHash, SimHash: other.SimHash, Shingles: other.JumlahShingle}
+			if !candidate.Comparable() {
+				continue
+			}
+			flag := &models.SimilarityFlag{SubmissionID: sub.ID, PembandingID: other.SubmissionID, Jenis: "antar_jawaban"}
+			if jaccard := similarity.Jaccard(fp.MinHash, other.MinHash); jaccard >= config.MinHashThreshold {
+				flag.Metode, flag.Skor = "minhash", jaccard
+			} else if distance := similarity.HammingDistance(fp.SimHash, other.SimHash); distance <= config.SimHashMaxDistance {
+				flag.Metode, flag.Skor = "simhash", 1-float64(distance)/64
+			} else {
+				continue
+			}
+			flags = append(flags, flag)
+		}
+
+		materialShingles, ok := materials[sub.SoalID]
+		if !ok {
+			text, err := s.store.GetQuestionMaterialText(sub.SoalID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			materialShingles = similarity.Shingles(text)
+			materials[sub.SoalID] = materialShingles
+		}
+		if containment := similarity.Containment(similarity.Shingles(sub.TeksJawaban), materialShingles); containment >= config.MaterialThreshold {
+			flags = append(flags, &models.SimilarityFlag{SubmissionID: sub.ID, Jenis: "materi", Metode: "containment", Skor: containment})
+		}
+	}
+
+	return s.store.SaveFingerprint(fingerprint, flags)
+}
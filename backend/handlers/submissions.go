@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/deadline"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/scoring"
+	"sistem-skripsi/backend/store"
+	"slices"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+// --- Handlers Draf & Pengumpulan (siswa) ---
+
+func (s *Server) handleGetDraft(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, _, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	soalID := mux.Vars(r)["soalId"]
+	if !slices.Contains(assignment.SoalIDs, soalID) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Soal tidak termasuk dalam tugas ini"})
+		return
+	}
+
+	draft, err := s.store.GetDraft(assignment.ID, soalID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusOK, models.Draft{AssignmentID: assignment.ID, SoalID: soalID, SiswaID: claims.UserID})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil draf"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, draft)
+}
+
+// Autosave draf. Klien mengirim versi draf yang terakhir diterimanya; jika draf sudah diubah
+// dari tab atau perangkat lain, respons 409 berisi draf terbaru agar klien dapat menggabungkan.
+func (s *Server) handleSaveDraft(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	soalID := mux.Vars(r)["soalId"]
+	if !slices.Contains(assignment.SoalIDs, soalID) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Soal tidak termasuk dalam tugas ini"})
+		return
+	}
+
+	var req models.SaveDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Versi draf tidak valid"})
+		return
+	}
+	if !writeWindowClosed(w, assignmentWindow(assignment, extension).Evaluate(time.Now())) {
+		return
+	}
+	if _, ok := s.examSessionForRequest(w, r, assignment); !ok {
+		return
+	}
+
+	draft := models.Draft{AssignmentID: assignment.ID, SoalID: soalID, SiswaID: claims.UserID, TeksJawaban: req.TeksJawaban}
+	if err := s.store.SaveDraft(&draft, req.Versi); err != nil {
+		if errors.Is(err, store.ErrDraftVersionConflict) {
+			current, err := s.store.GetDraft(assignment.ID, soalID, claims.UserID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil draf"})
+				return
+			}
+			WriteJSON(w, http.StatusConflict, map[string]any{"message": "Draf telah diubah di tempat lain", "draf": current})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan draf"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, draft)
+}
+
+// Mengumpulkan draf pada versi tertentu sebagai jawaban final. Jawaban ditolak di luar jadwal
+// atau jika batas pengumpulan ulang tercapai; jawaban terlambat dicatat beserta penaltinya.
+func (s *Server) handleSubmitAssignmentAnswer(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, extension, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SubmitAnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Soal dan versi draf wajib diisi"})
+		return
+	}
+	if !slices.Contains(assignment.SoalIDs, req.SoalID) {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Soal tidak termasuk dalam tugas ini"})
+		return
+	}
+
+	result := assignmentWindow(assignment, extension).Evaluate(time.Now())
+	if !writeWindowClosed(w, result) {
+		return
+	}
+	if _, ok := s.examSessionForRequest(w, r, assignment); !ok {
+		return
+	}
+
+	submission := models.Submission{
+		SoalID:        req.SoalID,
+		SiswaID:       claims.UserID,
+		AssignmentID:  assignment.ID,
+		HariTerlambat: result.HariTerlambat,
+		PenaltiPersen: result.PenaltiPersen,
+	}
+	err := s.store.SubmitDraft(&submission, req.Versi, assignment.MaksPercobaan)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Draf jawaban tidak ditemukan"})
+		case errors.Is(err, store.ErrDraftVersionConflict):
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Draf telah berubah, muat ulang sebelum mengumpulkan"})
+		case errors.Is(err, store.ErrEmptyAnswer):
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Jawaban masih kosong"})
+		case errors.Is(err, store.ErrAttemptLimitReached):
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Batas jumlah pengumpulan untuk soal ini telah tercapai"})
+		default:
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan jawaban"})
+		}
+		return
+	}
+	// Jawaban yang gagal diperiksa di sini akan diproses ulang oleh pemindaian berkala
+	if err := s.fingerprintSubmission(&submission, map[string]map[uint64]struct{}{}); err != nil {
+		log.Printf("Gagal memeriksa kemiripan jawaban %s: %v", submission.ID, err)
+	}
+	s.publishSubmissionCreated(assignment.KelasID, &submission)
+	WriteJSON(w, http.StatusCreated, submission)
+}
+
+func (s *Server) handleGetMyAttempts(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	assignment, _, ok := s.studentAssignmentForRequest(w, r)
+	if !ok {
+		return
+	}
+	s.writeAttempts(w, assignment, claims.UserID)
+}
+
+// --- Handlers Percobaan (guru) ---
+
+func (s *Server) handleGetStudentAttempts(w http.ResponseWriter, r *http.Request) {
+	assignment, ok := s.assignmentForRequest(w, r, capReview)
+	if !ok {
+		return
+	}
+	s.writeAttempts(w, assignment, mux.Vars(r)["siswaId"])
+}
+
+// writeAttempts menulis percobaan siswa per soal (sesuai urutan tugas) beserta nilai yang
+// dihitung menurut kebijakan nilai tugas
+func (s *Server) writeAttempts(w http.ResponseWriter, assignment *models.Assignment, siswaID string) {
+	submissions, err := s.store.GetSubmissionAttempts(assignment.ID, siswaID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil riwayat pengumpulan"})
+		return
+	}
+
+	bySoal := map[string][]*models.Submission{}
+	for _, sub := range submissions {
+		sub.Skor = scoring.Attempt(sub.SkorAI, sub.SkorGuru, sub.PenaltiPersen)
+		bySoal[sub.SoalID] = append(bySoal[sub.SoalID], sub)
+	}
+
+	result := make([]*models.QuestionAttempts, 0, len(assignment.SoalIDs))
+	for _, soalID := range assignment.SoalIDs {
+		attempts := bySoal[soalID]
+		scores := make([]*float64, len(attempts))
+		for i, sub := range attempts {
+			scores[i] = sub.Skor
+		}
+		if attempts == nil {
+			attempts = []*models.Submission{}
+		}
+		result = append(result, &models.QuestionAttempts{
+			SoalID:         soalID,
+			KebijakanNilai: assignment.KebijakanNilai,
+			MaksPercobaan:  assignment.MaksPercobaan,
+			Percobaan:      attempts,
+			SkorDihitung:   scoring.Counted(assignment.KebijakanNilai, scores),
+		})
+	}
+	WriteJSON(w, http.StatusOK, result)
+}
+
+// writeWindowClosed menulis respons 403 jika tugas belum dibuka atau sudah ditutup,
+// dan mengembalikan true jika jawaban masih dapat diterima
+func writeWindowClosed(w http.ResponseWriter, result deadline.Result) bool {
+	switch result.Status {
+	case deadline.StatusBelumDibuka:
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Tugas belum dibuka"})
+		return false
+	case deadline.StatusDitutup:
+		WriteJSON(w, http.StatusForbidden, map[string]string{"message": "Tugas sudah ditutup"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,514 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/store"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxBulkCSVSize = 2 << 20 // 2 MB
+	maxBulkCSVRows = 1000
+
+	// Lama akun yang di-soft delete masih dapat dipulihkan sebelum dianonimkan
+	userRetentionPeriod = 30 * 24 * time.Hour
+)
+
+// --- Handlers Profil Sendiri ---
+
+func (s *Server) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	user, err := s.store.GetUserByID(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data pengguna"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Password baru minimal 8 karakter"})
+		return
+	}
+
+	storedHash, err := s.store.GetUserPasswordHash(claims.UserID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memverifikasi password"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(req.PasswordLama)); err != nil {
+		WriteJSON(w, http.StatusUnauthorized, map[string]string{"message": "Password lama salah"})
+		return
+	}
+
+	if _, err := s.store.SetUserPassword(claims.UserID, req.PasswordBaru, false); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengganti password"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditPasswordChanged, TargetJenis: "user", TargetID: claims.UserID}, nil, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Password berhasil diganti, silakan login kembali"})
+}
+
+// --- Handlers Manajemen Pengguna (Superadmin) ---
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, limit := parsePagination(r)
+	filter := models.UserFilter{
+		Query: strings.TrimSpace(q.Get("q")),
+		Peran: q.Get("peran"),
+		Page:  page,
+		Limit: limit,
+	}
+
+	switch q.Get("status") {
+	case "active":
+		active := true
+		filter.IsActive = &active
+	case "disabled":
+		active := false
+		filter.IsActive = &active
+	case "deleted":
+		filter.Deleted = true
+	case "":
+	default:
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Status harus 'active', 'disabled', atau 'deleted'"})
+		return
+	}
+
+	users, total, err := s.store.ListUsers(filter)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar pengguna"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, models.PaginatedResponse{Data: users, Page: page, Limit: limit, Total: total})
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := s.store.GetUserByID(mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data pengguna"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Data tidak lengkap atau tidak valid"})
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	before, _ := s.store.GetUserByID(id)
+	user, err := s.store.UpdateUserProfile(id, req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+			return
+		}
+		if message, ok := userConflictMessage(err); ok {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": message})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui pengguna"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditUserUpdated, TargetJenis: "user", TargetID: id}, before, user)
+	WriteJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) handleUpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	id := mux.Vars(r)["id"]
+
+	var req models.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Peran harus student, teacher, atau superadmin"})
+		return
+	}
+	if id == claims.UserID {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tidak dapat mengubah peran akun sendiri"})
+		return
+	}
+
+	before, _ := s.store.GetUserByID(id)
+	rowsAffected, err := s.store.UpdateUserRole(id, req.Peran)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengubah peran pengguna"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+		return
+	}
+	var previous map[string]string
+	if before != nil {
+		previous = map[string]string{"peran": before.Peran}
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditRoleChanged, TargetJenis: "user", TargetID: id}, previous, map[string]string{"peran": req.Peran})
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Peran pengguna berhasil diubah"})
+}
+
+func (s *Server) handleDisableUser(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	if mux.Vars(r)["id"] == claims.UserID {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tidak dapat menonaktifkan akun sendiri"})
+		return
+	}
+	s.setUserActive(w, r, false, "Akun berhasil dinonaktifkan")
+}
+
+func (s *Server) handleEnableUser(w http.ResponseWriter, r *http.Request) {
+	s.setUserActive(w, r, true, "Akun berhasil diaktifkan kembali")
+}
+
+func (s *Server) setUserActive(w http.ResponseWriter, r *http.Request, active bool, successMessage string) {
+	id := mux.Vars(r)["id"]
+	rowsAffected, err := s.store.SetUserActive(id, active)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengubah status akun"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+		return
+	}
+	action := models.AuditUserDisabled
+	if active {
+		action = models.AuditUserEnabled
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: action, TargetJenis: "user", TargetID: id},
+		map[string]bool{"is_active": !active}, map[string]bool{"is_active": active})
+	WriteJSON(w, http.StatusOK, map[string]string{"message": successMessage})
+}
+
+// Mengatur password sementara; pengguna wajib menggantinya pada login berikutnya
+func (s *Server) handleResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	// Body bersifat opsional; jika kosong password sementara dibuat otomatis
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Password minimal 8 karakter"})
+		return
+	}
+
+	password := req.Password
+	if password == "" {
+		generated, err := generateTemporaryPassword()
+		if err != nil {
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat password sementara"})
+			return
+		}
+		password = generated
+	}
+
+	id := mux.Vars(r)["id"]
+	rowsAffected, err := s.store.SetUserPassword(id, password, true)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mereset password"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{
+		Aksi:        models.AuditPasswordReset,
+		TargetJenis: "user",
+		TargetID:    id,
+		Metadata:    map[string]any{"dibuat_otomatis": req.Password == ""},
+	}, nil, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{
+		"message":            "Password berhasil direset",
+		"password_sementara": password,
+	})
+}
+
+// Membuat akun guru secara massal dari file CSV. Baris pertama wajib berupa header dengan
+// kolom nama_lengkap, username, email, serta opsional password dan nomor_identitas.
+func (s *Server) handleBulkCreateTeachers(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkCSVSize)
+
+	var source io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "File CSV tidak ditemukan pada field 'file'"})
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	reader := csv.NewReader(source)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Format CSV tidak valid"})
+		return
+	}
+	if len(records) < 2 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "CSV harus berisi header dan minimal satu baris data"})
+		return
+	}
+	if len(records)-1 > maxBulkCSVRows {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Jumlah baris melebihi batas " + strconv.Itoa(maxBulkCSVRows)})
+		return
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"nama_lengkap", "username", "email"} {
+		if _, ok := columns[required]; !ok {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Kolom wajib tidak ditemukan: " + required})
+			return
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	validate := validator.New()
+	result := models.BulkCreateResult{Baris: []models.BulkCreateRowResult{}}
+	for i, record := range records[1:] {
+		row := models.BulkCreateRowResult{Baris: i + 2, Username: field(record, "username")}
+		user := models.User{
+			NamaLengkap:    field(record, "nama_lengkap"),
+			Username:       row.Username,
+			Email:          field(record, "email"),
+			Password:       field(record, "password"),
+			NomorIdentitas: field(record, "nomor_identitas"),
+			Peran:          "teacher",
+		}
+		// Tanpa password di CSV, buat password sementara yang wajib diganti saat login
+		if user.Password == "" {
+			generated, err := generateTemporaryPassword()
+			if err != nil {
+				WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat password sementara"})
+				return
+			}
+			user.Password = generated
+			user.MustChangePassword = true
+			row.PasswordSementara = generated
+		}
+
+		if err := validate.Struct(user); err != nil {
+			row.Message = "Data tidak lengkap atau tidak valid"
+		} else if err := s.store.CreateUser(&user); err != nil {
+			if message, ok := userConflictMessage(err); ok {
+				row.Message = message
+			} else {
+				row.Message = "Gagal menyimpan pengguna"
+			}
+		}
+
+		if row.Message != "" {
+			row.PasswordSementara = ""
+			result.Gagal++
+		} else {
+			row.UserID = user.ID
+			result.Berhasil++
+		}
+		result.Baris = append(result.Baris, row)
+	}
+
+	status := http.StatusCreated
+	if result.Berhasil == 0 {
+		status = http.StatusBadRequest
+	}
+	WriteJSON(w, status, result)
+}
+
+// --- Handlers Penghapusan & Offboarding ---
+
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	id := mux.Vars(r)["id"]
+	if id == claims.UserID {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Tidak dapat menghapus akun sendiri"})
+		return
+	}
+
+	before, _ := s.store.GetUserByID(id)
+	rowsAffected, err := s.store.SoftDeleteUser(id, "")
+	if err != nil {
+		if errors.Is(err, store.ErrActiveClassesRemain) {
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Pengguna masih memiliki kelas aktif, lakukan offboarding terlebih dahulu"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus pengguna"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna tidak ditemukan"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditUserDeleted, TargetJenis: "user", TargetID: id}, before, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Akun berhasil dihapus dan dapat dipulihkan selama masa retensi"})
+}
+
+func (s *Server) handleRestoreUser(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	rowsAffected, err := s.store.RestoreUser(id, time.Now().Add(-userRetentionPeriod))
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memulihkan pengguna"})
+		return
+	}
+	if rowsAffected == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengguna terhapus tidak ditemukan atau masa retensi telah berakhir"})
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{Aksi: models.AuditUserRestored, TargetJenis: "user", TargetID: id}, nil, nil)
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Akun berhasil dipulihkan"})
+}
+
+// PurgeDeletedUsers menganonimkan akun yang masa retensinya sudah lewat. Jawaban dan nilai
+// tetap tersimpan atas id yang sama. Dijalankan oleh scheduler.
+func (s *Server) PurgeDeletedUsers(now time.Time) (int, error) {
+	return s.purgeDeletedUsers(nil, now)
+}
+
+// purgeDeletedUsers mencatat pembersihan atas nama pemohon r, atau sebagai aksi sistem jika r nil
+func (s *Server) purgeDeletedUsers(r *http.Request, now time.Time) (int, error) {
+	deletedBefore := now.Add(-userRetentionPeriod)
+	purged, err := s.store.PurgeDeletedUsers(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		s.recordAudit(r, models.AuditEntry{
+			Aksi:     models.AuditUsersPurged,
+			Metadata: map[string]any{"jumlah": purged, "dihapus_sebelum": deletedBefore},
+		}, nil, nil)
+	}
+	return int(purged), nil
+}
+
+func (s *Server) handlePurgeDeletedUsers(w http.ResponseWriter, r *http.Request) {
+	purged, err := s.purgeDeletedUsers(r, time.Now())
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menganonimkan pengguna"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"message": "Pembersihan selesai", "jumlah_dihapus": purged})
+}
+
+// Daftar kelas aktif milik guru yang harus diserahterimakan sebelum akunnya dihapus
+func (s *Server) handleGetTeacherClasses(w http.ResponseWriter, r *http.Request) {
+	classes, err := s.store.GetOwnedClassesByTeacherID(mux.Vars(r)["id"])
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar kelas"})
+		return
+	}
+	if classes == nil {
+		classes = []*models.Class{}
+	}
+	WriteJSON(w, http.StatusOK, classes)
+}
+
+func (s *Server) handleOffboardTeacher(w http.ResponseWriter, r *http.Request) {
+	teacherID := mux.Vars(r)["id"]
+
+	var req models.OffboardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Aksi harus 'transfer' (dengan guru_tujuan_id) atau 'archive'"})
+		return
+	}
+
+	classes, err := s.store.GetOwnedClassesByTeacherID(teacherID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar kelas"})
+		return
+	}
+
+	// Keputusan per kelas menimpa aksi default
+	overrides := map[string]models.ClassHandover{}
+	for _, h := range req.Kelas {
+		overrides[h.KelasID] = h
+	}
+	handovers := make([]models.ClassHandover, 0, len(classes))
+	for _, c := range classes {
+		h, ok := overrides[c.ID]
+		if !ok {
+			h = models.ClassHandover{KelasID: c.ID, Aksi: req.Aksi, GuruTujuanID: req.GuruTujuanID}
+		}
+		delete(overrides, c.ID)
+		handovers = append(handovers, h)
+	}
+	if len(overrides) > 0 {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Terdapat kelas yang bukan milik guru ini"})
+		return
+	}
+
+	if err := s.store.OffboardTeacher(teacherID, handovers); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Guru tidak ditemukan"})
+		case errors.Is(err, store.ErrInvalidTransferTarget):
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Guru tujuan harus akun guru lain yang aktif"})
+		case errors.Is(err, store.ErrIncompleteHandover):
+			WriteJSON(w, http.StatusConflict, map[string]string{"message": "Daftar kelas berubah, muat ulang dan coba lagi"})
+		default:
+			WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal melakukan offboarding guru"})
+		}
+		return
+	}
+	s.recordAudit(r, models.AuditEntry{
+		Aksi:        models.AuditTeacherOffboarded,
+		TargetJenis: "user",
+		TargetID:    teacherID,
+		Metadata:    map[string]any{"kelas": handovers},
+	}, nil, nil)
+
+	WriteJSON(w, http.StatusOK, map[string]any{
+		"message":      "Offboarding guru berhasil",
+		"kelas_diurus": len(handovers),
+	})
+}
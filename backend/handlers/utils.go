@@ -1,8 +1,17 @@
 package handlers
 
 import (
+	"crypto/rand"
 	"encoding/json"
+	"math/big"
+	"net"
 	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
 )
 
 func WriteJSON(w http.ResponseWriter, status int, v any) {
@@ -10,3 +19,43 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(v)
 }
+
+// Membaca parameter query page & limit dengan nilai default dan batas atas
+func parsePagination(r *http.Request) (int, int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return page, limit
+}
+
+// Alamat IP klien dari koneksi langsung
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Karakter yang mudah dibaca (tanpa 0/O, 1/l/I) untuk password sementara
+const passwordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz23456789"
+
+func generateTemporaryPassword() (string, error) {
+	buf := make([]byte, 12)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/webhook"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	webhookBatchSize = 20
+	webhookLease     = 2 * time.Minute // Lebih lama dari batas waktu satu pengiriman
+)
+
+// WithWebhookClient mengganti klien HTTP pengiriman webhook, mis. klien yang mengizinkan
+// jaringan privat untuk pengembangan lokal
+func WithWebhookClient(client *http.Client) Option {
+	return func(s *Server) {
+		s.webhookClient = client
+	}
+}
+
+// --- Pengiriman ---
+
+// DeliverWebhooks mengirim pengiriman webhook yang jatuh tempo secara paralel dan menjadwalkan
+// ulang yang gagal. Dipanggil berkala dari main.
+func (s *Server) DeliverWebhooks(now time.Time) (int, error) {
+	deliveries, err := s.store.ClaimWebhookDeliveries(now, webhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	delivered := 0
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func(d *models.WebhookDelivery) {
+			defer wg.Done()
+			if s.deliverWebhook(d) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(d)
+	}
+	wg.Wait()
+	return delivered, nil
+}
+
+func (s *Server) deliverWebhook(d *models.WebhookDelivery) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), webhook.RequestTimeout)
+	defer cancel()
+	started := time.Now()
+	result := webhook.Deliver(ctx, s.webhookClient, webhook.Request{
+		URL:        d.URL,
+		Secret:     d.Secret,
+		DeliveryID: d.ID,
+		Event:      d.Event,
+		Body:       d.Payload,
+	}, started)
+
+	attempt := &models.WebhookAttempt{
+		PercobaanKe: d.Percobaan + 1,
+		Respons:     result.Response,
+		DurasiMS:    int(result.Duration.Milliseconds()),
+		DicobaPada:  started,
+	}
+	if result.StatusCode != 0 {
+		attempt.StatusCode = &result.StatusCode
+	}
+	if result.Err != nil {
+		attempt.Galat = result.Err.Error()
+	} else if !result.Success() {
+		attempt.Galat = http.StatusText(result.StatusCode)
+	}
+
+	status, next := models.WebhookDeliverySucceeded, started
+	switch {
+	case result.Success():
+	case !result.Retryable() || attempt.PercobaanKe >= webhook.MaxAttempts:
+		status = models.WebhookDeliveryFailed
+	default:
+		status, next = models.WebhookDeliveryPending, started.Add(webhook.Backoff(attempt.PercobaanKe))
+	}
+	if err := s.store.RecordWebhookAttempt(d.ID, attempt, status, next); err != nil {
+		log.Printf("Gagal mencatat pengiriman webhook %s: %v", d.ID, err)
+	}
+	return status == models.WebhookDeliverySucceeded
+}
+
+// --- Handlers Webhook ---
+
+// Langganan webhook sebuah kelas
+func (s *Server) handleGetClassWebhooks(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.checkClassAccess(w, r, classID, capManageClass, true) {
+		return
+	}
+	s.writeWebhooks(w, classID)
+}
+
+func (s *Server) handleCreateClassWebhook(w http.ResponseWriter, r *http.Request) {
+	classID := mux.Vars(r)["id"]
+	if !s.authorizeClass(w, r, classID, capManageClass) {
+		return
+	}
+	s.createWebhook(w, r, classID)
+}
+
+// Langganan global menerima peristiwa dari semua kelas
+func (s *Server) handleGetGlobalWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.writeWebhooks(w, "")
+}
+
+func (s *Server) handleCreateGlobalWebhook(w http.ResponseWriter, r *http.Request) {
+	s.createWebhook(w, r, "")
+}
+
+func (s *Server) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+	req.ID = existing.ID
+	req.KelasID = existing.KelasID
+	n, err := s.store.UpdateWebhook(req)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal memperbarui webhook"})
+		return
+	}
+	if n == 0 {
+		WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Webhook tidak ditemukan"})
+		return
+	}
+	updated, err := s.store.GetWebhook(existing.ID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data webhook"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.store.DeleteWebhook(existing.ID); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menghapus webhook"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]string{"message": "Webhook berhasil dihapus"})
+}
+
+// Mengirim peristiwa ping untuk menguji URL dan verifikasi tanda tangan di penerima
+func (s *Server) handlePingWebhook(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	payload, err := webhook.NewPayload(webhook.EventPing, existing.KelasID, map[string]string{"webhook_id": existing.ID}, time.Now())
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat ping"})
+		return
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat ping"})
+		return
+	}
+	delivery, err := s.store.CreateWebhookDelivery(existing.ID, payload.ID, webhook.EventPing, body)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menjadwalkan ping"})
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+// Log pengiriman sebuah langganan. ?status=menunggu|berhasil|gagal|dibatalkan menyaring hasil.
+func (s *Server) handleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	page, limit := parsePagination(r)
+	deliveries, total, err := s.store.GetWebhookDeliveries(existing.ID, r.URL.Query().Get("status"), page, limit)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil log pengiriman webhook"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, models.PaginatedResponse{Data: deliveries, Page: page, Limit: limit, Total: total})
+}
+
+func (s *Server) handleGetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	delivery, err := s.store.GetWebhookDelivery(existing.ID, mux.Vars(r)["deliveryId"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengiriman webhook tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil pengiriman webhook"})
+		return
+	}
+	// Isi respons penerima hanya ditampilkan ke superadmin; pengelola kelas cukup melihat status
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	if claims.Peran != "superadmin" {
+		for _, attempt := range delivery.RiwayatPercobaan {
+			attempt.Respons = ""
+		}
+	}
+	WriteJSON(w, http.StatusOK, delivery)
+}
+
+// Mengirim ulang payload pengiriman lama sebagai pengiriman baru dengan ID peristiwa yang sama
+func (s *Server) handleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	existing, ok := s.webhookForRequest(w, r)
+	if !ok {
+		return
+	}
+	delivery, err := s.store.RedeliverWebhookDelivery(existing.ID, mux.Vars(r)["deliveryId"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Pengiriman webhook tidak ditemukan"})
+			return
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menjadwalkan pengiriman ulang"})
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+// --- Helper ---
+
+func (s *Server) writeWebhooks(w http.ResponseWriter, classID string) {
+	webhooks, err := s.store.GetWebhooks(classID)
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil daftar webhook"})
+		return
+	}
+	WriteJSON(w, http.StatusOK, webhooks)
+}
+
+// createWebhook menyimpan langganan dengan secret baru. Secret hanya ditampilkan sekali pada
+// respons ini untuk disalin ke penerima.
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request, classID string) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	req, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal membuat secret webhook"})
+		return
+	}
+	req.KelasID = classID
+	req.Secret = secret
+	req.DibuatOleh = claims.UserID
+	if err := s.store.CreateWebhook(req); err != nil {
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal menyimpan webhook"})
+		return
+	}
+	WriteJSON(w, http.StatusCreated, req)
+}
+
+func decodeWebhook(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	req := models.Webhook{Aktif: true}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Request body tidak valid"})
+		return nil, false
+	}
+	if err := validator.New().Struct(req); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "URL webhook tidak valid atau belum ada peristiwa yang dipilih"})
+		return nil, false
+	}
+	for _, event := range req.Events {
+		if !webhook.IsEvent(event) {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Peristiwa tidak dikenal: " + event})
+			return nil, false
+		}
+	}
+	return &req, true
+}
+
+// webhookForRequest memuat langganan {id}. Langganan kelas memerlukan hak mengelola kelas;
+// langganan global hanya dapat diakses superadmin.
+func (s *Server) webhookForRequest(w http.ResponseWriter, r *http.Request) (*models.Webhook, bool) {
+	claims, _ := r.Context().Value(userClaimsKey).(*models.Claims)
+	existing, err := s.store.GetWebhook(mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Webhook tidak ditemukan"})
+			return nil, false
+		}
+		WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Gagal mengambil data webhook"})
+		return nil, false
+	}
+	if existing.KelasID == "" {
+		if claims.Peran != "superadmin" {
+			WriteJSON(w, http.StatusNotFound, map[string]string{"message": "Webhook tidak ditemukan"})
+			return nil, false
+		}
+		return existing, true
+	}
+	if !s.checkClassAccess(w, r, existing.KelasID, capManageClass, r.Method == http.MethodGet) {
+		return nil, false
+	}
+	return existing, true
+}
@@ -0,0 +1,240 @@
+// Package itemanalysis menghitung statistik butir soal esai: rata-rata dan varians skor,
+// indeks daya beda terhadap skor total tugas, tingkat koreksi guru atas skor AI, dan tema
+// umpan balik yang sering muncul.
+package itemanalysis
+
+import (
+	"math"
+	"sistem-skripsi/backend/models"
+	"sistem-skripsi/backend/similarity"
+	"sort"
+	"strings"
+)
+
+const (
+	// Proporsi kelompok atas dan bawah untuk indeks daya beda (Kelley)
+	groupFraction = 0.27
+	// Jumlah siswa minimum per tugas agar daya beda dihitung
+	MinDiscriminationStudents = 5
+	// Skor guru yang berbeda lebih dari ini dari skor AI dihitung sebagai koreksi
+	OverrideTolerance = 0.5
+	// Jumlah tema umpan balik yang ditampilkan per soal
+	MaxThemes = 5
+)
+
+// Ambang peringatan agar butir yang perlu direvisi mudah dikenali
+const (
+	easyMean          = 85
+	hardMean          = 30
+	lowDiscrimination = 0.2
+	highOverrideRate  = 0.3
+	minWarningSample  = 5
+)
+
+// Kata umum bahasa Indonesia yang tidak bermakna sebagai tema
+var stopwords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`yang dan di ke dari untuk dengan pada dalam ini itu atau juga
+		tidak belum sudah sangat lebih kurang agar karena sebagai oleh adalah akan dapat bisa
+		ada tetapi namun serta para jika maka telah masih hanya saja harus perlu secara
+		lagi baik cukup siswa jawaban jawabanmu kamu anda mu nya sebuah suatu tersebut
+		the a an of to is are and`) {
+		stopwords[w] = true
+	}
+}
+
+// Analyze menyusun statistik untuk setiap soal pada soalIDs. observations berisi skor akhir
+// percobaan terakhir per siswa, soal, dan tugas, termasuk soal lain pada tugas yang sama
+// agar skor total tugas dapat dihitung.
+func Analyze(soalIDs []string, observations []*models.ItemObservation, reviews []*models.ItemReviewStats, feedback []*models.ItemFeedback) map[string]*models.ItemStatistics {
+	stats := make(map[string]*models.ItemStatistics, len(soalIDs))
+	for _, id := range soalIDs {
+		stats[id] = &models.ItemStatistics{Tema: []*models.FeedbackTheme{}, Peringatan: []string{}}
+	}
+
+	scores := map[string][]float64{}
+	totals := map[[2]string]float64{} // (tugas, siswa) -> skor total
+	for _, o := range observations {
+		if _, ok := stats[o.SoalID]; ok {
+			scores[o.SoalID] = append(scores[o.SoalID], o.Skor)
+		}
+		if o.AssignmentID != "" {
+			totals[[2]string{o.AssignmentID, o.SiswaID}] += o.Skor
+		}
+	}
+	for id, values := range scores {
+		st := stats[id]
+		st.Jumlah = len(values)
+		st.RataRata, st.Varians = meanVariance(values)
+	}
+
+	for id, d := range discrimination(stats, observations, totals) {
+		d := round(d)
+		stats[id].DayaBeda = &d
+	}
+
+	for _, r := range reviews {
+		st, ok := stats[r.SoalID]
+		if !ok || r.Ditinjau == 0 {
+			continue
+		}
+		st.JumlahDitinjau = r.Ditinjau
+		st.JumlahDikoreksi = r.Dikoreksi
+		rate := round(float64(r.Dikoreksi) / float64(r.Ditinjau))
+		st.TingkatKoreksi = &rate
+	}
+
+	texts := map[string][]string{}
+	for _, f := range feedback {
+		texts[f.SoalID] = append(texts[f.SoalID], f.Teks)
+	}
+	for id, st := range stats {
+		st.Tema = Themes(texts[id], MaxThemes)
+		st.Peringatan = warnings(st)
+	}
+	return stats
+}
+
+// discrimination menghitung indeks daya beda per soal: selisih rata-rata skor soal antara 27%
+// siswa dengan skor total tugas tertinggi dan terendah, dibagi skor maksimum. Soal yang dipakai
+// di beberapa tugas digabung dengan rata-rata berbobot jumlah siswa.
+func discrimination(stats map[string]*models.ItemStatistics, observations []*models.ItemObservation, totals map[[2]string]float64) map[string]float64 {
+	type scored struct{ skor, total float64 }
+	groups := map[[2]string][]scored{} // (soal, tugas)
+	for _, o := range observations {
+		if _, ok := stats[o.SoalID]; !ok || o.AssignmentID == "" {
+			continue
+		}
+		key := [2]string{o.SoalID, o.AssignmentID}
+		groups[key] = append(groups[key], scored{o.Skor, totals[[2]string{o.AssignmentID, o.SiswaID}]})
+	}
+
+	sums := map[string]float64{}
+	weights := map[string]int{}
+	for key, group := range groups {
+		n := len(group)
+		if n < MinDiscriminationStudents {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].total < group[j].total })
+		size := int(math.Round(groupFraction * float64(n)))
+		if size < 1 {
+			size = 1
+		}
+		var lower, upper float64
+		for i := 0; i < size; i++ {
+			lower += group[i].skor
+			upper += group[n-1-i].skor
+		}
+		d := (upper - lower) / float64(size) / 100
+		sums[key[0]] += d * float64(n)
+		weights[key[0]] += n
+	}
+
+	result := map[string]float64{}
+	for id, w := range weights {
+		result[id] = sums[id] / float64(w)
+	}
+	return result
+}
+
+// Themes mengembalikan frasa (dua kata atau satu kata) yang muncul di minimal dua teks umpan
+// balik, diurutkan dari yang paling sering. Frasa dua kata diutamakan; kata tunggal yang sudah
+// tercakup frasa terpilih dengan frekuensi sama tidak ditampilkan lagi.
+func Themes(texts []string, limit int) []*models.FeedbackTheme {
+	counts := map[string]int{}
+	for _, text := range texts {
+		seen := map[string]bool{}
+		var prev string
+		for _, w := range similarity.Words(text) {
+			if stopwords[w] || len([]rune(w)) < 3 {
+				prev = ""
+				continue
+			}
+			seen[w] = true
+			if prev != "" {
+				seen[prev+" "+w] = true
+			}
+			prev = w
+		}
+		for phrase := range seen {
+			counts[phrase]++
+		}
+	}
+
+	candidates := make([]string, 0, len(counts))
+	for phrase, n := range counts {
+		if n >= 2 {
+			candidates = append(candidates, phrase)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		if wa, wb := strings.Count(a, " "), strings.Count(b, " "); wa != wb {
+			return wa > wb
+		}
+		return a < b
+	})
+
+	themes := []*models.FeedbackTheme{}
+	covered := map[string]int{}
+	for _, phrase := range candidates {
+		if len(themes) >= limit {
+			break
+		}
+		if n, ok := covered[phrase]; ok && n >= counts[phrase] {
+			continue
+		}
+		themes = append(themes, &models.FeedbackTheme{Frasa: phrase, Jumlah: counts[phrase]})
+		for _, w := range strings.Fields(phrase) {
+			if counts[phrase] > covered[w] {
+				covered[w] = counts[phrase]
+			}
+		}
+	}
+	return themes
+}
+
+func warnings(st *models.ItemStatistics) []string {
+	result := []string{}
+	if st.Jumlah >= minWarningSample {
+		if st.RataRata >= easyMean {
+			result = append(result, "Terlalu mudah: rata-rata skor sangat tinggi")
+		}
+		if st.RataRata <= hardMean {
+			result = append(result, "Terlalu sulit atau ambigu: rata-rata skor sangat rendah")
+		}
+	}
+	if st.DayaBeda != nil && *st.DayaBeda < lowDiscrimination {
+		result = append(result, "Daya beda rendah: soal kurang membedakan siswa berkemampuan tinggi dan rendah")
+	}
+	if st.TingkatKoreksi != nil && st.JumlahDitinjau >= minWarningSample && *st.TingkatKoreksi >= highOverrideRate {
+		result = append(result, "Sering dikoreksi guru: periksa kunci jawaban dan rubrik")
+	}
+	return result
+}
+
+// meanVariance menghitung rata-rata dan varians populasi
+func meanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return round(mean), round(sq / float64(len(values)))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,129 @@
+package itemanalysis
+
+import (
+	"sistem-skripsi/backend/models"
+	"strings"
+	"testing"
+)
+
+func observations() []*models.ItemObservation {
+	var obs []*models.ItemObservation
+	students := []string{"s1", "s2", "s3", "s4", "s5"}
+	for i, siswa := range students {
+		// q1 membedakan siswa, q2 dijawab sama oleh semua siswa
+		obs = append(obs,
+			&models.ItemObservation{SoalID: "q1", AssignmentID: "t1", SiswaID: siswa, Skor: float64(100 - 20*i)},
+			&models.ItemObservation{SoalID: "q2", AssignmentID: "t1", SiswaID: siswa, Skor: 50},
+			&models.ItemObservation{SoalID: "q3", SiswaID: siswa, Skor: 90},
+		)
+	}
+	return obs
+}
+
+func TestAnalyzeScoresAndDiscrimination(t *testing.T) {
+	stats := Analyze([]string{"q1", "q2", "q3", "q4"}, observations(), nil, nil)
+
+	q1 := stats["q1"]
+	if q1.Jumlah != 5 || q1.RataRata != 60 || q1.Varians != 800 {
+		t.Errorf("q1 = jumlah %d, rata-rata %v, varians %v; ingin 5, 60, 800", q1.Jumlah, q1.RataRata, q1.Varians)
+	}
+	if q1.DayaBeda == nil || *q1.DayaBeda != 0.8 {
+		t.Errorf("daya beda q1 = %v, ingin 0.8", q1.DayaBeda)
+	}
+	if len(q1.Peringatan) != 0 {
+		t.Errorf("q1 tidak seharusnya diberi peringatan: %v", q1.Peringatan)
+	}
+
+	q2 := stats["q2"]
+	if q2.DayaBeda == nil || *q2.DayaBeda != 0 {
+		t.Errorf("daya beda q2 = %v, ingin 0", q2.DayaBeda)
+	}
+	if !hasWarning(q2, "Daya beda rendah") {
+		t.Errorf("q2 seharusnya diberi peringatan daya beda rendah: %v", q2.Peringatan)
+	}
+
+	// q3 tidak terkait tugas sehingga daya beda tidak dihitung
+	q3 := stats["q3"]
+	if q3.DayaBeda != nil {
+		t.Errorf("daya beda q3 = %v, ingin nil", *q3.DayaBeda)
+	}
+	if !hasWarning(q3, "Terlalu mudah") {
+		t.Errorf("q3 seharusnya diberi peringatan terlalu mudah: %v", q3.Peringatan)
+	}
+
+	if q4 := stats["q4"]; q4.Jumlah != 0 || q4.DayaBeda != nil || q4.Tema == nil || q4.Peringatan == nil {
+		t.Errorf("soal tanpa jawaban = %+v, ingin statistik kosong dengan slice tidak nil", q4)
+	}
+}
+
+func TestAnalyzeSkipsSmallAssignments(t *testing.T) {
+	obs := observations()[:3*(MinDiscriminationStudents-1)]
+	if d := Analyze([]string{"q1"}, obs, nil, nil)["q1"].DayaBeda; d != nil {
+		t.Errorf("daya beda dengan %d siswa = %v, ingin nil", MinDiscriminationStudents-1, *d)
+	}
+}
+
+func TestAnalyzeOverrideRate(t *testing.T) {
+	reviews := []*models.ItemReviewStats{
+		{SoalID: "q1", Ditinjau: 5, Dikoreksi: 2},
+		{SoalID: "q2", Ditinjau: 0},
+		{SoalID: "lain", Ditinjau: 3, Dikoreksi: 3},
+	}
+	stats := Analyze([]string{"q1", "q2"}, nil, reviews, nil)
+	q1 := stats["q1"]
+	if q1.TingkatKoreksi == nil || *q1.TingkatKoreksi != 0.4 {
+		t.Errorf("tingkat koreksi q1 = %v, ingin 0.4", q1.TingkatKoreksi)
+	}
+	if !hasWarning(q1, "Sering dikoreksi") {
+		t.Errorf("q1 seharusnya diberi peringatan sering dikoreksi: %v", q1.Peringatan)
+	}
+	if stats["q2"].TingkatKoreksi != nil {
+		t.Error("tingkat koreksi soal yang belum ditinjau seharusnya nil")
+	}
+	if _, ok := stats["lain"]; ok {
+		t.Error("soal di luar soalIDs tidak boleh muncul")
+	}
+}
+
+func TestThemes(t *testing.T) {
+	texts := []string{
+		"Jelaskan peran klorofil lebih rinci.",
+		"Peran klorofil belum disebutkan, sebutkan hasil fotosintesis.",
+		"Sebutkan hasil fotosintesis dan peran klorofil.",
+		"Tulisan rapi.",
+	}
+	themes := Themes(texts, MaxThemes)
+	want := []models.FeedbackTheme{{Frasa: "peran klorofil", Jumlah: 3}, {Frasa: "hasil fotosintesis", Jumlah: 2}, {Frasa: "sebutkan hasil", Jumlah: 2}}
+	if len(themes) != len(want) {
+		t.Fatalf("Themes = %v, ingin %v", themeList(themes), want)
+	}
+	for i := range want {
+		if *themes[i] != want[i] {
+			t.Fatalf("Themes = %v, ingin %v", themeList(themes), want)
+		}
+	}
+
+	if got := Themes(texts, 1); len(got) != 1 || got[0].Frasa != "peran klorofil" {
+		t.Errorf("Themes dengan batas 1 = %v", themeList(got))
+	}
+	if got := Themes([]string{"Jawaban sudah baik."}, MaxThemes); len(got) != 0 {
+		t.Errorf("Themes satu teks = %v, ingin kosong", themeList(got))
+	}
+}
+
+func hasWarning(st *models.ItemStatistics, prefix string) bool {
+	for _, w := range st.Peringatan {
+		if strings.HasPrefix(w, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func themeList(themes []*models.FeedbackTheme) []models.FeedbackTheme {
+	result := make([]models.FeedbackTheme, len(themes))
+	for i, th := range themes {
+		result[i] = *th
+	}
+	return result
+}
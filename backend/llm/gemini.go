@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultGeminiEndpoint = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultGeminiModel    = "gemini-1.5-flash"
+)
+
+// Konfigurasi Gemini API
+type GeminiConfig struct {
+	APIKey   string
+	Model    string
+	Endpoint string // Dapat diganti untuk proxy atau pengujian
+	Timeout  time.Duration
+}
+
+// Gemini memanggil endpoint generateContent dengan keluaran JSON
+type Gemini struct {
+	cfg    GeminiConfig
+	client *http.Client
+}
+
+func NewGemini(cfg GeminiConfig) *Gemini {
+	if cfg.Model == "" {
+		cfg.Model = DefaultGeminiModel
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultGeminiEndpoint
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &Gemini{cfg: cfg, client: &http.Client{}}
+}
+
+type geminiRequest struct {
+	Contents         []geminiContent        `json:"contents"`
+	GenerationConfig geminiGenerationConfig `json:"generationConfig"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      float64 `json:"temperature"`
+	ResponseMimeType string  `json:"responseMimeType"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+	ModelVersion string `json:"modelVersion"`
+}
+
+func (g *Gemini) Name() string { return "gemini" }
+
+func (g *Gemini) Generate(ctx context.Context, req Request) (*Response, error) {
+	ctx, cancel := withTimeout(ctx, g.cfg.Timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(geminiRequest{
+		Contents:         []geminiContent{{Role: "user", Parts: []geminiPart{{Text: req.Prompt}}}},
+		GenerationConfig: geminiGenerationConfig{Temperature: 0, ResponseMimeType: "application/json"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent?key=%s", g.cfg.Endpoint, url.PathEscape(g.cfg.Model), url.QueryEscape(g.cfg.APIKey))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		// Jangan sertakan URL (berisi API key) di pesan error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("gemini: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var body geminiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+	var text strings.Builder
+	if len(body.Candidates) > 0 {
+		for _, part := range body.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return nil, ErrEmptyResponse
+	}
+	model := body.ModelVersion
+	if model == "" {
+		model = g.cfg.Model
+	}
+	return &Response{
+		Text:             text.String(),
+		Provider:         g.Name(),
+		Model:            model,
+		PromptTokens:     body.UsageMetadata.PromptTokenCount,
+		CompletionTokens: body.UsageMetadata.CandidatesTokenCount,
+		Latency:          time.Since(start),
+	}, nil
+}
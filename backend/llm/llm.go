@@ -0,0 +1,83 @@
+// Package llm berisi klien model bahasa yang dipakai untuk menilai jawaban esai: Gemini,
+// server kompatibel OpenAI (mis. llama.cpp atau Ollama lokal), rantai fallback antar
+// penyedia, serta klien rekam/putar ulang agar penilaian dapat diuji tanpa jaringan.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrEmptyResponse = errors.New("model tidak mengembalikan teks")
+
+// Request adalah satu permintaan ke model. Keluaran selalu diminta dalam format JSON
+// dengan temperatur 0 agar penilaian jawaban yang sama sedapat mungkin konsisten.
+type Request struct {
+	Prompt string
+}
+
+// Response adalah teks keluaran model beserta data pemakaiannya
+type Response struct {
+	Text             string        `json:"text"`
+	Provider         string        `json:"provider"`
+	Model            string        `json:"model"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	Latency          time.Duration `json:"latency"`
+}
+
+// Client adalah kontrak penyedia model bahasa
+type Client interface {
+	Generate(ctx context.Context, req Request) (*Response, error)
+	// Nama penyedia untuk log, mis. "gemini" atau "openai"
+	Name() string
+}
+
+// Fallback mencoba setiap klien sesuai urutan sampai salah satu berhasil. Setiap klien
+// memakai batas waktunya sendiri, sehingga penyedia yang lambat tidak menghabiskan waktu
+// penyedia berikutnya.
+type Fallback struct {
+	clients []Client
+}
+
+func NewFallback(clients ...Client) *Fallback {
+	return &Fallback{clients: clients}
+}
+
+func (f *Fallback) Name() string {
+	names := make([]string, len(f.clients))
+	for i, c := range f.clients {
+		names[i] = c.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *Fallback) Generate(ctx context.Context, req Request) (*Response, error) {
+	var errs []error
+	for _, client := range f.clients {
+		resp, err := client.Generate(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", client.Name(), err))
+		// Permintaan dibatalkan pemanggil, penyedia lain tidak perlu dicoba
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("tidak ada penyedia model yang dikonfigurasi")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// withTimeout membatasi satu panggilan penyedia tanpa memperpanjang tenggat pemanggil
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFallback(t *testing.T) {
+	gemini := &scriptedClient{name: "gemini", err: errors.New("503")}
+	openai := &scriptedClient{name: "openai", text: "ok"}
+	unused := &scriptedClient{name: "lokal", text: "tidak dipakai"}
+	f := NewFallback(gemini, openai, unused)
+	if f.Name() != "gemini,openai,lokal" {
+		t.Errorf("Name = %q", f.Name())
+	}
+	resp, err := f.Generate(context.Background(), Request{Prompt: "p"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Provider != "openai" || unused.calls != 0 {
+		t.Errorf("respons dari %q, penyedia ketiga dipanggil %d kali", resp.Provider, unused.calls)
+	}
+}
+
+func TestFallbackAllFail(t *testing.T) {
+	sentinel := errors.New("timeout")
+	f := NewFallback(&scriptedClient{name: "gemini", err: errors.New("503")}, &scriptedClient{name: "openai", err: sentinel})
+	_, err := f.Generate(context.Background(), Request{Prompt: "p"})
+	if !errors.Is(err, sentinel) || !strings.Contains(err.Error(), "gemini: 503") {
+		t.Errorf("err = %v, ingin gabungan error semua penyedia", err)
+	}
+
+	if _, err := NewFallback().Generate(context.Background(), Request{Prompt: "p"}); err == nil {
+		t.Error("Fallback tanpa penyedia seharusnya gagal")
+	}
+}
+
+// Pemanggil yang membatalkan permintaan tidak membuat penyedia berikutnya ikut dicoba
+func TestFallbackStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	next := &scriptedClient{name: "openai", text: "ok"}
+	f := NewFallback(&scriptedClient{name: "gemini", err: context.Canceled}, next)
+	if _, err := f.Generate(ctx, Request{Prompt: "p"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, ingin context.Canceled", err)
+	}
+	if next.calls != 0 {
+		t.Errorf("penyedia berikutnya dipanggil %d kali, ingin 0", next.calls)
+	}
+}
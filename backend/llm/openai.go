@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Endpoint default server Ollama lokal; llama.cpp server memakai http://localhost:8080/v1
+const DefaultOpenAIBaseURL = "http://localhost:11434/v1"
+
+// Konfigurasi server kompatibel OpenAI (Chat Completions API)
+type OpenAIConfig struct {
+	BaseURL string
+	APIKey  string // Opsional untuk server lokal
+	Model   string
+	Timeout time.Duration
+}
+
+// OpenAI memanggil endpoint /chat/completions pada server kompatibel OpenAI
+type OpenAI struct {
+	cfg    OpenAIConfig
+	client *http.Client
+}
+
+func NewOpenAI(cfg OpenAIConfig) *OpenAI {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultOpenAIBaseURL
+	}
+	if cfg.Timeout <= 0 {
+		// Model lokal umumnya lebih lambat daripada API terkelola
+		cfg.Timeout = 120 * time.Second
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &OpenAI{cfg: cfg, client: &http.Client{}}
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []openAIMessage `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat struct {
+		Type string `json:"type"`
+	} `json:"response_format"`
+}
+
+type openAIResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (o *OpenAI) Name() string { return "openai" }
+
+func (o *OpenAI) Generate(ctx context.Context, req Request) (*Response, error) {
+	ctx, cancel := withTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	body := openAIRequest{
+		Model:       o.cfg.Model,
+		Messages:    []openAIMessage{{Role: "user", Content: req.Prompt}},
+		Temperature: 0,
+	}
+	body.ResponseFormat.Type = "json_object"
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.cfg.BaseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.cfg.APIKey)
+	}
+
+	start := time.Now()
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("openai: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var decoded openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	if len(decoded.Choices) == 0 || decoded.Choices[0].Message.Content == "" {
+		return nil, ErrEmptyResponse
+	}
+	model := decoded.Model
+	if model == "" {
+		model = o.cfg.Model
+	}
+	return &Response{
+		Text:             decoded.Choices[0].Message.Content,
+		Provider:         o.Name(),
+		Model:            model,
+		PromptTokens:     decoded.Usage.PromptTokens,
+		CompletionTokens: decoded.Usage.CompletionTokens,
+		Latency:          time.Since(start),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrFixtureNotFound = errors.New("fixture respons model tidak ditemukan")
+
+// Fixture adalah satu pasangan prompt dan respons yang direkam. Nama filenya adalah
+// sha256 hex dari prompt, sehingga prompt yang sama selalu memutar respons yang sama.
+type Fixture struct {
+	Prompt   string   `json:"prompt"`
+	Response Response `json:"response"`
+}
+
+func fixturePath(dir string, prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Recorder meneruskan permintaan ke klien lain lalu menyimpan setiap respons berhasil
+// sebagai fixture untuk Replay
+type Recorder struct {
+	next Client
+	dir  string
+}
+
+func NewRecorder(next Client, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Recorder{next: next, dir: dir}, nil
+}
+
+func (r *Recorder) Name() string { return r.next.Name() }
+
+func (r *Recorder) Generate(ctx context.Context, req Request) (*Response, error) {
+	resp, err := r.next.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(Fixture{Prompt: req.Prompt, Response: *resp}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(fixturePath(r.dir, req.Prompt), data, 0o644); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// Replay memutar respons dari fixture hasil Recorder tanpa akses jaringan
+type Replay struct {
+	dir string
+}
+
+func NewReplay(dir string) *Replay {
+	return &Replay{dir: dir}
+}
+
+func (r *Replay) Name() string { return "replay" }
+
+func (r *Replay) Generate(ctx context.Context, req Request) (*Response, error) {
+	data, err := os.ReadFile(fixturePath(r.dir, req.Prompt))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrFixtureNotFound
+		}
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("fixture rusak: %w", err)
+	}
+	resp := fixture.Response
+	resp.Latency = 0
+	return &resp, nil
+}
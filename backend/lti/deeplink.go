@@ -0,0 +1,75 @@
+package lti
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const messageLifetime = 5 * time.Minute
+
+// LineItem meminta platform membuat kolom nilai untuk tautan yang dibuat
+type LineItem struct {
+	ScoreMaximum float64 `json:"scoreMaximum"`
+	Label        string  `json:"label,omitempty"`
+	ResourceID   string  `json:"resourceId,omitempty"`
+	Tag          string  `json:"tag,omitempty"`
+}
+
+// ContentItem adalah konten yang dikembalikan ke platform melalui deep linking
+type ContentItem struct {
+	Type     string            `json:"type"` // ltiResourceLink
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Custom   map[string]string `json:"custom,omitempty"`
+	LineItem *LineItem         `json:"lineItem,omitempty"`
+}
+
+type deepLinkingResponse struct {
+	jwt.RegisteredClaims
+	Nonce        string        `json:"nonce"`
+	MessageType  string        `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string        `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID string        `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	ContentItems []ContentItem `json:"https://purl.imsglobal.org/spec/lti-dl/claim/content_items"`
+	Message      string        `json:"https://purl.imsglobal.org/spec/lti-dl/claim/msg,omitempty"`
+	Data         string        `json:"https://purl.imsglobal.org/spec/lti-dl/claim/data,omitempty"`
+}
+
+// DeepLinkingResponse membuat JWT LtiDeepLinkingResponse yang ditandatangani kunci tool.
+// JWT dikirim ke deep_link_return_url sebagai field form "JWT".
+func DeepLinkingResponse(key *SigningKey, platform *Platform, deploymentID string, data string, items []ContentItem, message string, now time.Time) (string, error) {
+	nonce, err := RandomToken()
+	if err != nil {
+		return "", err
+	}
+	if items == nil {
+		items = []ContentItem{}
+	}
+	claims := deepLinkingResponse{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    platform.ClientID,
+			Audience:  jwt.ClaimStrings{platform.Issuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(messageLifetime)),
+		},
+		Nonce:        nonce,
+		MessageType:  MessageDeepLinkingResponse,
+		Version:      Version,
+		DeploymentID: deploymentID,
+		ContentItems: items,
+		Message:      message,
+		Data:         data,
+	}
+	return sign(key, claims)
+}
+
+func sign(key *SigningKey, claims jwt.Claims) (string, error) {
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.Key)
+}
@@ -0,0 +1,96 @@
+package lti
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Platform memverifikasi respons deep linking dengan JWKS tool
+func TestDeepLinkingResponse(t *testing.T) {
+	p := newTestPlatform(t)
+	key := toolKey(t)
+	items := []ContentItem{{
+		Type:     "ltiResourceLink",
+		Title:    "Esai Fotosintesis",
+		URL:      "https://tool.sekolah.test/lti/launch",
+		Custom:   map[string]string{"assignment_id": "assignment-1"},
+		LineItem: &LineItem{ScoreMaximum: 100, Label: "Esai Fotosintesis", ResourceID: "assignment-1"},
+	}}
+	now := time.Now()
+	signed, err := DeepLinkingResponse(key, p.config(), "dep-1", "data-platform", items, "Tugas ditautkan", now)
+	if err != nil {
+		t.Fatalf("DeepLinkingResponse: %v", err)
+	}
+
+	// Platform membaca JWKS tool lalu memverifikasi JWT
+	data, err := json.Marshal(JWKSet{Keys: []JWK{PublicJWK(key.Kid, &key.Key.PublicKey)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("ParseJWKS: %v", err)
+	}
+	claims := &deepLinkingResponse{}
+	_, err = jwt.ParseWithClaims(signed, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys[kid], nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer("tool-client"), jwt.WithAudience("https://lms.sekolah.test"), jwt.WithExpirationRequired())
+	if err != nil {
+		t.Fatalf("platform menolak respons deep linking: %v", err)
+	}
+	if claims.MessageType != MessageDeepLinkingResponse || claims.Version != Version || claims.DeploymentID != "dep-1" {
+		t.Errorf("klaim pesan = %+v", claims)
+	}
+	if claims.Data != "data-platform" || claims.Message != "Tugas ditautkan" || claims.Nonce == "" {
+		t.Errorf("data/pesan/nonce = %q, %q, %q", claims.Data, claims.Message, claims.Nonce)
+	}
+	if len(claims.ContentItems) != 1 || claims.ContentItems[0].Custom["assignment_id"] != "assignment-1" || claims.ContentItems[0].LineItem.ScoreMaximum != 100 {
+		t.Errorf("content items = %+v", claims.ContentItems)
+	}
+	if !claims.ExpiresAt.After(now) || claims.ExpiresAt.Sub(now) > messageLifetime {
+		t.Errorf("exp = %v, ingin paling lama %v setelah dibuat", claims.ExpiresAt, messageLifetime)
+	}
+}
+
+func TestDeepLinkingResponseWithoutItems(t *testing.T) {
+	p := newTestPlatform(t)
+	signed, err := DeepLinkingResponse(toolKey(t), p.config(), "dep-1", "", nil, "", time.Now())
+	if err != nil {
+		t.Fatalf("DeepLinkingResponse: %v", err)
+	}
+	claims := &deepLinkingResponse{}
+	if _, _, err := jwt.NewParser().ParseUnverified(signed, claims); err != nil {
+		t.Fatal(err)
+	}
+	// Platform mewajibkan klaim content_items tetap ada walau kosong
+	if claims.ContentItems == nil {
+		t.Error("content_items seharusnya array kosong, bukan null")
+	}
+
+	if _, err := DeepLinkingResponse(nil, p.config(), "dep-1", "", nil, "", time.Now()); err != ErrNoSigningKey {
+		t.Errorf("tanpa kunci: err = %v, ingin ErrNoSigningKey", err)
+	}
+}
+
+// Deep linking divalidasi sebagai jenis pesan tersendiri saat peluncuran
+func TestValidateDeepLinkingLaunch(t *testing.T) {
+	p := newTestPlatform(t)
+	claims := launchClaims("nonce-1")
+	claims.MessageType = MessageDeepLinking
+	claims.ResourceLink = nil
+	claims.Roles = []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"}
+	claims.DeepLinking = &DeepLinkingSettings{ReturnURL: p.server.URL + "/deep-link-return", AcceptTypes: []string{"ltiResourceLink"}, Data: "data-platform"}
+
+	got, err := ValidateLaunch(context.Background(), NewJWKSCache(p.server.Client()), p.config(), signLaunch(t, platformKey, "platform-1", claims), "nonce-1")
+	if err != nil {
+		t.Fatalf("ValidateLaunch: %v", err)
+	}
+	if got.DeepLinking.ReturnURL != p.server.URL+"/deep-link-return" || got.DeepLinking.Data != "data-platform" || !IsInstructor(got.Roles) {
+		t.Errorf("klaim deep linking = %+v", got.DeepLinking)
+	}
+}
@@ -0,0 +1,109 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+const keyBits = 2048
+
+// SigningKey adalah kunci privat tool untuk menandatangani pesan ke platform
+type SigningKey struct {
+	Kid string
+	Key *rsa.PrivateKey
+}
+
+// JWK adalah kunci publik RSA dalam format JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSet adalah dokumen JWKS
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// GenerateKey membuat kunci RSA baru dan mengembalikan kid serta kunci privat dalam PEM
+func GenerateKey() (kid string, privatePEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", "", err
+	}
+	kid, err = RandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	der := x509.MarshalPKCS1PrivateKey(key)
+	return kid, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKey membaca kunci privat RSA PEM (PKCS#1 atau PKCS#8)
+func ParsePrivateKey(privatePEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("kunci privat bukan PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("kunci privat bukan RSA")
+	}
+	return key, nil
+}
+
+// PublicJWK mengubah kunci publik RSA menjadi JWK untuk RS256
+func PublicJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// ParseJWKS membaca dokumen JWKS dan mengembalikan kunci RSA berdasarkan kid
+func ParseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var set JWKSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() < 3 {
+			return nil, errors.New("eksponen kunci tidak valid")
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	}
+	return keys, nil
+}
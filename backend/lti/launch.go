@@ -0,0 +1,141 @@
+package lti
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	jwksTTL          = time.Hour
+	jwksMinRefresh   = time.Minute // kid yang tidak dikenal memicu unduh ulang paling sering sekali per menit
+	maxJWKSBytes     = 1 << 20
+	clockSkewAllowed = time.Minute
+)
+
+// JWKSCache mengunduh dan menyimpan sementara JWKS platform
+type JWKSCache struct {
+	client  *http.Client
+	mu      sync.Mutex
+	entries map[string]*jwksEntry
+}
+
+type jwksEntry struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func NewJWKSCache(client *http.Client) *JWKSCache {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &JWKSCache{client: client, entries: map[string]*jwksEntry{}}
+}
+
+// Key mengembalikan kunci publik dengan kid tertentu dari JWKS di url
+func (c *JWKSCache) Key(ctx context.Context, url string, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	entry := c.entries[url]
+	c.mu.Unlock()
+
+	if entry != nil {
+		key, ok := entry.keys[kid]
+		age := time.Since(entry.fetchedAt)
+		if ok && age < jwksTTL {
+			return key, nil
+		}
+		if !ok && age < jwksMinRefresh {
+			return nil, ErrUnknownKey
+		}
+	}
+
+	keys, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[url] = &jwksEntry{keys: keys, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func (c *JWKSCache) fetch(ctx context.Context, url string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: JWKS %s: status %d", ErrServiceFailed, url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ValidateLaunch memverifikasi tanda tangan id_token terhadap JWKS platform lalu memeriksa
+// issuer, audience, masa berlaku, nonce, deployment, versi, dan klaim wajib jenis pesannya
+func ValidateLaunch(ctx context.Context, jwks *JWKSCache, platform *Platform, idToken string, nonce string) (*LaunchClaims, error) {
+	claims := &LaunchClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return jwks.Key(ctx, platform.JWKSURL, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(platform.Issuer),
+		jwt.WithAudience(platform.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkewAllowed),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	switch {
+	case len(claims.Audience) > 1 && claims.AuthorizedBy != platform.ClientID:
+		return nil, fmt.Errorf("%w: azp tidak sesuai", ErrInvalidToken)
+	case claims.Nonce == "" || claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce tidak sesuai", ErrInvalidToken)
+	case claims.Version != Version:
+		return nil, fmt.Errorf("%w: versi LTI %q tidak didukung", ErrInvalidToken, claims.Version)
+	case claims.DeploymentID == "":
+		return nil, fmt.Errorf("%w: deployment_id kosong", ErrInvalidToken)
+	case len(platform.DeploymentIDs) > 0 && !slices.Contains(platform.DeploymentIDs, claims.DeploymentID):
+		return nil, fmt.Errorf("%w: deployment %q tidak terdaftar", ErrInvalidToken, claims.DeploymentID)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: sub kosong", ErrInvalidToken)
+	}
+
+	switch claims.MessageType {
+	case MessageResourceLink:
+		if claims.ResourceLink == nil || claims.ResourceLink.ID == "" {
+			return nil, fmt.Errorf("%w: resource_link wajib ada", ErrInvalidToken)
+		}
+	case MessageDeepLinking:
+		if claims.DeepLinking == nil || claims.DeepLinking.ReturnURL == "" {
+			return nil, fmt.Errorf("%w: deep_linking_settings wajib ada", ErrInvalidToken)
+		}
+	default:
+		return nil, fmt.Errorf("%w: jenis pesan %q tidak didukung", ErrInvalidToken, claims.MessageType)
+	}
+	return claims, nil
+}
@@ -0,0 +1,162 @@
+package lti
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestValidateLaunch(t *testing.T) {
+	p := newTestPlatform(t)
+	cache := NewJWKSCache(p.server.Client())
+
+	claims, err := ValidateLaunch(context.Background(), cache, p.config(), signLaunch(t, platformKey, "platform-1", launchClaims("nonce-1")), "nonce-1")
+	if err != nil {
+		t.Fatalf("ValidateLaunch: %v", err)
+	}
+	if claims.Subject != "user-42" || claims.ResourceLink.ID != "rl-1" || claims.Context.ID != "course-1" || claims.DisplayName() != "Budi Santoso" {
+		t.Errorf("klaim = %+v", claims)
+	}
+}
+
+func TestValidateLaunchRejects(t *testing.T) {
+	p := newTestPlatform(t)
+	cache := NewJWKSCache(p.server.Client())
+
+	tests := []struct {
+		name   string
+		kid    string // Diisi: ditandatangani kunci lain dengan kid ini
+		mutate func(c *LaunchClaims)
+		nonce  string
+	}{
+		{name: "tanda tangan kunci lain", kid: "platform-1"},
+		{name: "kid tidak dikenal", kid: "platform-9"},
+		{name: "issuer lain", mutate: func(c *LaunchClaims) { c.Issuer = "https://lms.lain.test" }},
+		{name: "aud tool lain", mutate: func(c *LaunchClaims) { c.Audience = jwt.ClaimStrings{"tool-lain"} }},
+		{name: "banyak aud tanpa azp", mutate: func(c *LaunchClaims) { c.Audience = jwt.ClaimStrings{"tool-client", "tool-lain"} }},
+		{name: "azp tool lain", mutate: func(c *LaunchClaims) {
+			c.Audience = jwt.ClaimStrings{"tool-client", "tool-lain"}
+			c.AuthorizedBy = "tool-lain"
+		}},
+		{name: "kedaluwarsa", mutate: func(c *LaunchClaims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Minute))
+		}},
+		{name: "tanpa exp", mutate: func(c *LaunchClaims) { c.ExpiresAt = nil }},
+		{name: "nonce login lain", nonce: "nonce-2"},
+		{name: "nonce kosong", mutate: func(c *LaunchClaims) { c.Nonce = "" }},
+		{name: "deployment tidak terdaftar", mutate: func(c *LaunchClaims) { c.DeploymentID = "dep-9" }},
+		{name: "deployment kosong", mutate: func(c *LaunchClaims) { c.DeploymentID = "" }},
+		{name: "versi lain", mutate: func(c *LaunchClaims) { c.Version = "1.1" }},
+		{name: "sub kosong", mutate: func(c *LaunchClaims) { c.Subject = "" }},
+		{name: "tanpa resource link", mutate: func(c *LaunchClaims) { c.ResourceLink = nil }},
+		{name: "deep linking tanpa return url", mutate: func(c *LaunchClaims) {
+			c.MessageType = MessageDeepLinking
+			c.DeepLinking = &DeepLinkingSettings{}
+		}},
+		{name: "jenis pesan lain", mutate: func(c *LaunchClaims) { c.MessageType = "LtiSubmissionReviewRequest" }},
+	}
+	for _, tt := range tests {
+		claims := launchClaims("nonce-1")
+		if tt.mutate != nil {
+			tt.mutate(claims)
+		}
+		key, kid := platformKey, "platform-1"
+		if tt.kid != "" {
+			key, kid = otherKey, tt.kid
+		}
+		nonce := "nonce-1"
+		if tt.nonce != "" {
+			nonce = tt.nonce
+		}
+		_, err := ValidateLaunch(context.Background(), cache, p.config(), signLaunch(t, key, kid, claims), nonce)
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: err = %v, ingin ErrInvalidToken", tt.name, err)
+		}
+	}
+}
+
+// Nonce dari state login hanya berlaku sekali: id_token yang diputar ulang pada login baru
+// membawa nonce lama sehingga ditolak
+func TestValidateLaunchNonceReplay(t *testing.T) {
+	p := newTestPlatform(t)
+	cache := NewJWKSCache(p.server.Client())
+	token := signLaunch(t, platformKey, "platform-1", launchClaims("nonce-1"))
+
+	if _, err := ValidateLaunch(context.Background(), cache, p.config(), token, "nonce-1"); err != nil {
+		t.Fatalf("peluncuran pertama: %v", err)
+	}
+	if _, err := ValidateLaunch(context.Background(), cache, p.config(), token, "nonce-baru"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("peluncuran ulang: err = %v, ingin ErrInvalidToken", err)
+	}
+}
+
+func TestValidateLaunchAnyDeployment(t *testing.T) {
+	p := newTestPlatform(t)
+	platform := p.config()
+	platform.DeploymentIDs = nil
+	claims := launchClaims("nonce-1")
+	claims.DeploymentID = "dep-baru"
+	if _, err := ValidateLaunch(context.Background(), NewJWKSCache(p.server.Client()), platform, signLaunch(t, platformKey, "platform-1", claims), "nonce-1"); err != nil {
+		t.Errorf("platform tanpa daftar deployment seharusnya menerima deployment apa pun: %v", err)
+	}
+}
+
+func TestJWKSCache(t *testing.T) {
+	p := newTestPlatform(t)
+	cache := NewJWKSCache(p.server.Client())
+	ctx := context.Background()
+	url := p.server.URL + "/jwks"
+
+	if _, err := cache.Key(ctx, url, "platform-1"); err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if _, err := cache.Key(ctx, url, "platform-1"); err != nil {
+		t.Fatalf("Key dari cache: %v", err)
+	}
+	if n := p.jwksFetches.Load(); n != 1 {
+		t.Errorf("JWKS diunduh %d kali, ingin 1", n)
+	}
+
+	// Platform merotasi kunci; kid baru yang belum lama dicari tidak memicu unduh ulang
+	p.mu.Lock()
+	p.jwks.Keys = append(p.jwks.Keys, PublicJWK("platform-2", &otherKey.PublicKey))
+	p.mu.Unlock()
+	if _, err := cache.Key(ctx, url, "platform-2"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("kid baru sebelum jeda unduh ulang: err = %v, ingin ErrUnknownKey", err)
+	}
+	if n := p.jwksFetches.Load(); n != 1 {
+		t.Errorf("JWKS diunduh %d kali sebelum jeda habis, ingin 1", n)
+	}
+
+	// Setelah jeda minimum, kid yang tidak dikenal memicu unduh ulang
+	cache.entries[url].fetchedAt = time.Now().Add(-2 * jwksMinRefresh)
+	if _, err := cache.Key(ctx, url, "platform-2"); err != nil {
+		t.Errorf("kid baru setelah jeda: %v", err)
+	}
+	if n := p.jwksFetches.Load(); n != 2 {
+		t.Errorf("JWKS diunduh %d kali, ingin 2", n)
+	}
+
+	// Kunci yang dikenal tetap diunduh ulang setelah TTL habis
+	cache.entries[url].fetchedAt = time.Now().Add(-jwksTTL - time.Second)
+	if _, err := cache.Key(ctx, url, "platform-1"); err != nil {
+		t.Errorf("Key setelah TTL: %v", err)
+	}
+	if n := p.jwksFetches.Load(); n != 3 {
+		t.Errorf("JWKS diunduh %d kali setelah TTL, ingin 3", n)
+	}
+}
+
+func TestJWKSCacheFetchError(t *testing.T) {
+	p := newTestPlatform(t)
+	p.jwksStatus = http.StatusServiceUnavailable
+	_, err := NewJWKSCache(p.server.Client()).Key(context.Background(), p.server.URL+"/jwks", "platform-1")
+	if !errors.Is(err, ErrServiceFailed) {
+		t.Errorf("err = %v, ingin ErrServiceFailed", err)
+	}
+}
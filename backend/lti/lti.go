@@ -0,0 +1,165 @@
+// Package lti mengimplementasikan sisi tool LTI 1.3: validasi id_token peluncuran dari
+// platform (Moodle, Canvas, dsb.) memakai JWKS platform, respons deep linking, serta klien
+// layanan Names and Role Provisioning (NRPS) dan Assignment and Grade Services (AGS).
+package lti
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const Version = "1.3.0"
+
+// Jenis pesan LTI
+const (
+	MessageResourceLink        = "LtiResourceLinkRequest"
+	MessageDeepLinking         = "LtiDeepLinkingRequest"
+	MessageDeepLinkingResponse = "LtiDeepLinkingResponse"
+)
+
+// Nama klaim LTI pada id_token
+const (
+	claimPrefix         = "https://purl.imsglobal.org/spec/lti/claim/"
+	ClaimMessageType    = claimPrefix + "message_type"
+	ClaimVersion        = claimPrefix + "version"
+	ClaimDeploymentID   = claimPrefix + "deployment_id"
+	ClaimTargetLinkURI  = claimPrefix + "target_link_uri"
+	ClaimRoles          = claimPrefix + "roles"
+	ClaimContext        = claimPrefix + "context"
+	ClaimResourceLink   = claimPrefix + "resource_link"
+	ClaimCustom         = claimPrefix + "custom"
+	ClaimNRPS           = "https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice"
+	ClaimAGS            = "https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"
+	ClaimDeepLinking    = "https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings"
+	ClaimContentItems   = "https://purl.imsglobal.org/spec/lti-dl/claim/content_items"
+	ClaimDeepLinkingMsg = "https://purl.imsglobal.org/spec/lti-dl/claim/msg"
+	ClaimDeepLinkData   = "https://purl.imsglobal.org/spec/lti-dl/claim/data"
+)
+
+// Scope OAuth2 untuk layanan platform
+const (
+	ScopeMembershipReadonly = "https://purl.imsglobal.org/spec/lti-nrps/scope/contextmembership.readonly"
+	ScopeLineItem           = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem"
+	ScopeLineItemReadonly   = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem.readonly"
+	ScopeScore              = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+)
+
+var (
+	ErrInvalidToken  = errors.New("id_token LTI tidak valid")
+	ErrUnknownKey    = errors.New("kunci penanda tangan tidak ditemukan di JWKS platform")
+	ErrNoSigningKey  = errors.New("tool belum memiliki kunci penanda tangan")
+	ErrServiceFailed = errors.New("layanan platform LTI gagal")
+)
+
+// Platform adalah konfigurasi platform yang terdaftar di tool
+type Platform struct {
+	Issuer        string
+	ClientID      string
+	AuthLoginURL  string // Endpoint otorisasi OIDC platform
+	AuthTokenURL  string // Endpoint token OAuth2 untuk layanan
+	JWKSURL       string
+	DeploymentIDs []string // Kosong berarti semua deployment diterima
+}
+
+// Context adalah kursus di platform
+type Context struct {
+	ID    string   `json:"id"`
+	Label string   `json:"label,omitempty"`
+	Title string   `json:"title,omitempty"`
+	Type  []string `json:"type,omitempty"`
+}
+
+// ResourceLink adalah tautan aktivitas di kursus platform
+type ResourceLink struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+// NamesRoleService berisi URL roster kursus
+type NamesRoleService struct {
+	ContextMembershipsURL string   `json:"context_memberships_url"`
+	ServiceVersions       []string `json:"service_versions,omitempty"`
+}
+
+// GradeService berisi URL kolom nilai (line item) dan scope yang diizinkan
+type GradeService struct {
+	Scope     []string `json:"scope,omitempty"`
+	LineItems string   `json:"lineitems,omitempty"`
+	LineItem  string   `json:"lineitem,omitempty"`
+}
+
+// DeepLinkingSettings dikirim platform saat guru memilih konten untuk ditautkan
+type DeepLinkingSettings struct {
+	ReturnURL      string   `json:"deep_link_return_url"`
+	AcceptTypes    []string `json:"accept_types,omitempty"`
+	AcceptMultiple bool     `json:"accept_multiple,omitempty"`
+	Title          string   `json:"title,omitempty"`
+	Data           string   `json:"data,omitempty"`
+}
+
+// LaunchClaims adalah isi id_token peluncuran
+type LaunchClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string               `json:"nonce"`
+	AuthorizedBy  string               `json:"azp,omitempty"`
+	Name          string               `json:"name,omitempty"`
+	GivenName     string               `json:"given_name,omitempty"`
+	FamilyName    string               `json:"family_name,omitempty"`
+	Email         string               `json:"email,omitempty"`
+	MessageType   string               `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version       string               `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID  string               `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkURI string               `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri,omitempty"`
+	Roles         []string             `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Context       *Context             `json:"https://purl.imsglobal.org/spec/lti/claim/context,omitempty"`
+	ResourceLink  *ResourceLink        `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link,omitempty"`
+	Custom        map[string]string    `json:"https://purl.imsglobal.org/spec/lti/claim/custom,omitempty"`
+	NRPS          *NamesRoleService    `json:"https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice,omitempty"`
+	AGS           *GradeService        `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint,omitempty"`
+	DeepLinking   *DeepLinkingSettings `json:"https://purl.imsglobal.org/spec/lti-dl/claim/deep_linking_settings,omitempty"`
+}
+
+// DisplayName mengembalikan nama lengkap pengguna dari klaim yang tersedia
+func (c *LaunchClaims) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	if name := strings.TrimSpace(c.GivenName + " " + c.FamilyName); name != "" {
+		return name
+	}
+	return c.Subject
+}
+
+// IsInstructor melaporkan apakah peran LTI mencakup pengajar atau administrator
+func IsInstructor(roles []string) bool {
+	for _, role := range roles {
+		if strings.HasSuffix(role, "#Instructor") || strings.HasSuffix(role, "membership#Administrator") ||
+			strings.HasSuffix(role, "institution/person#Administrator") || strings.HasSuffix(role, "#ContentDeveloper") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLearner melaporkan apakah peran LTI mencakup peserta didik
+func IsLearner(roles []string) bool {
+	for _, role := range roles {
+		if strings.HasSuffix(role, "membership#Learner") || strings.HasSuffix(role, "institution/person#Student") || role == "Learner" {
+			return true
+		}
+	}
+	return false
+}
+
+// RandomToken menghasilkan string acak heksadesimal untuk state, nonce, dan jti
+func RandomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
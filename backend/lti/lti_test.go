@@ -0,0 +1,249 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Kunci RSA dibuat sekali karena pembuatannya lambat
+var (
+	keysOnce     sync.Once
+	platformKey  *rsa.PrivateKey
+	otherKey     *rsa.PrivateKey
+	toolKeyValue *rsa.PrivateKey
+)
+
+func testKeys(t *testing.T) {
+	t.Helper()
+	keysOnce.Do(func() {
+		var err error
+		for _, k := range []**rsa.PrivateKey{&platformKey, &otherKey, &toolKeyValue} {
+			if *k, err = rsa.GenerateKey(rand.Reader, keyBits); err != nil {
+				panic(err)
+			}
+		}
+	})
+}
+
+// testPlatform adalah platform LTI tiruan: JWKS, endpoint token OAuth2, roster NRPS, dan
+// kolom nilai AGS. Setiap permintaan dicatat agar uji dapat memeriksa alurnya.
+type testPlatform struct {
+	t      *testing.T
+	server *httptest.Server
+
+	jwksFetches  atomic.Int32
+	jwksStatus   int
+	jwks         JWKSet
+	tokenScopes  []string
+	lineItems    []LineItemResource
+	createdItems []LineItemResource
+	scores       map[string][]Score // Berdasarkan path kolom nilai
+	mu           sync.Mutex
+}
+
+func newTestPlatform(t *testing.T) *testPlatform {
+	testKeys(t)
+	p := &testPlatform{t: t, jwksStatus: http.StatusOK, scores: map[string][]Score{}}
+	p.jwks = JWKSet{Keys: []JWK{PublicJWK("platform-1", &platformKey.PublicKey)}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksFetches.Add(1)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.jwksStatus != http.StatusOK {
+			w.WriteHeader(p.jwksStatus)
+			return
+		}
+		json.NewEncoder(w).Encode(p.jwks)
+	})
+	mux.HandleFunc("POST /token", p.handleToken)
+	mux.HandleFunc("GET /courses/1/members", func(w http.ResponseWriter, r *http.Request) {
+		if !p.authorized(w, r, ScopeMembershipReadonly) {
+			return
+		}
+		w.Header().Set("Content-Type", mediaMembership)
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode(map[string]any{"members": []Member{
+				{UserID: "u3", Status: "Inactive", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"}},
+			}})
+			return
+		}
+		w.Header().Set("Link", `<`+p.server.URL+`/courses/1/members?page=2>; rel="next"`)
+		json.NewEncoder(w).Encode(map[string]any{"members": []Member{
+			{UserID: "u1", GivenName: "Budi", FamilyName: "Santoso", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"}},
+			{UserID: "u2", Name: "Ibu Sari", Roles: []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"}},
+		}})
+	})
+	mux.HandleFunc("GET /courses/1/lineitems", func(w http.ResponseWriter, r *http.Request) {
+		if !p.authorized(w, r, ScopeLineItem) {
+			return
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		var found []LineItemResource
+		for _, li := range p.lineItems {
+			if li.ResourceLinkID == r.URL.Query().Get("resource_link_id") {
+				found = append(found, li)
+			}
+		}
+		json.NewEncoder(w).Encode(found)
+	})
+	mux.HandleFunc("POST /courses/1/lineitems", func(w http.ResponseWriter, r *http.Request) {
+		if !p.authorized(w, r, ScopeLineItem) {
+			return
+		}
+		if r.Header.Get("Content-Type") != mediaLineItem {
+			http.Error(w, "tipe media salah", http.StatusUnsupportedMediaType)
+			return
+		}
+		var item LineItemResource
+		json.NewDecoder(r.Body).Decode(&item)
+		p.mu.Lock()
+		item.ID = p.server.URL + "/courses/1/lineitems/" + item.ResourceLinkID
+		p.lineItems = append(p.lineItems, item)
+		p.createdItems = append(p.createdItems, item)
+		p.mu.Unlock()
+		json.NewEncoder(w).Encode(item)
+	})
+	mux.HandleFunc("POST /courses/1/lineitems/{id}/scores", func(w http.ResponseWriter, r *http.Request) {
+		if !p.authorized(w, r, ScopeScore) {
+			return
+		}
+		if r.Header.Get("Content-Type") != mediaScore {
+			http.Error(w, "tipe media salah", http.StatusUnsupportedMediaType)
+			return
+		}
+		var score Score
+		json.NewDecoder(r.Body).Decode(&score)
+		p.mu.Lock()
+		p.scores[r.PathValue("id")] = append(p.scores[r.PathValue("id")], score)
+		p.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// handleToken memverifikasi client_assertion bertanda tangan kunci tool lalu memberikan
+// access token yang menyebut scope-nya
+func (p *testPlatform) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		http.Error(w, "grant_type salah", http.StatusBadRequest)
+		return
+	}
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), claims, func(token *jwt.Token) (any, error) {
+		if token.Header["kid"] != "tool-1" {
+			return nil, ErrUnknownKey
+		}
+		return &toolKeyValue.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer("tool-client"), jwt.WithAudience(p.server.URL+"/token"))
+	if err != nil || claims.Subject != "tool-client" || claims.ID == "" {
+		http.Error(w, "assertion tidak valid", http.StatusUnauthorized)
+		return
+	}
+	scope := r.PostForm.Get("scope")
+	p.mu.Lock()
+	p.tokenScopes = append(p.tokenScopes, scope)
+	p.mu.Unlock()
+	json.NewEncoder(w).Encode(map[string]any{"access_token": "token " + scope, "token_type": "Bearer", "expires_in": 3600})
+}
+
+func (p *testPlatform) authorized(w http.ResponseWriter, r *http.Request, scope string) bool {
+	if r.Header.Get("Authorization") != "Bearer token "+scope {
+		http.Error(w, "token tidak valid untuk scope ini", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (p *testPlatform) config() *Platform {
+	return &Platform{
+		Issuer:        "https://lms.sekolah.test",
+		ClientID:      "tool-client",
+		AuthLoginURL:  p.server.URL + "/auth",
+		AuthTokenURL:  p.server.URL + "/token",
+		JWKSURL:       p.server.URL + "/jwks",
+		DeploymentIDs: []string{"dep-1"},
+	}
+}
+
+func toolKey(t *testing.T) *SigningKey {
+	testKeys(t)
+	return &SigningKey{Kid: "tool-1", Key: toolKeyValue}
+}
+
+// launchClaims adalah klaim peluncuran resource link yang valid untuk platform uji
+func launchClaims(nonce string) *LaunchClaims {
+	now := time.Now()
+	return &LaunchClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://lms.sekolah.test",
+			Subject:   "user-42",
+			Audience:  jwt.ClaimStrings{"tool-client"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+		Nonce:        nonce,
+		Email:        "budi@sekolah.test",
+		GivenName:    "Budi",
+		FamilyName:   "Santoso",
+		MessageType:  MessageResourceLink,
+		Version:      Version,
+		DeploymentID: "dep-1",
+		Roles:        []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"},
+		Context:      &Context{ID: "course-1", Title: "Biologi X"},
+		ResourceLink: &ResourceLink{ID: "rl-1", Title: "Esai Fotosintesis"},
+	}
+}
+
+func signLaunch(t *testing.T, key *rsa.PrivateKey, kid string, claims *LaunchClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestRoles(t *testing.T) {
+	instructor := []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"}
+	learner := []string{"http://purl.imsglobal.org/vocab/lis/v2/institution/person#Student", "Learner"}
+	if !IsInstructor(instructor) || IsLearner(instructor) {
+		t.Errorf("peran pengajar salah dikenali")
+	}
+	if IsInstructor(learner) || !IsLearner(learner) {
+		t.Errorf("peran siswa salah dikenali")
+	}
+	if IsInstructor([]string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Mentor"}) {
+		t.Error("mentor tidak boleh dianggap pengajar")
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	c := &LaunchClaims{GivenName: "Budi", FamilyName: "Santoso"}
+	if got := c.DisplayName(); got != "Budi Santoso" {
+		t.Errorf("DisplayName = %q", got)
+	}
+	c.Name = "Budi S."
+	if got := c.DisplayName(); got != "Budi S." {
+		t.Errorf("DisplayName = %q, ingin klaim name", got)
+	}
+	c = &LaunchClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-42"}}
+	if got := c.DisplayName(); got != "user-42" {
+		t.Errorf("DisplayName tanpa nama = %q, ingin sub", got)
+	}
+}
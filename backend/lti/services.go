@@ -0,0 +1,297 @@
+package lti
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tipe media layanan LTI Advantage
+const (
+	mediaMembership = "application/vnd.ims.lti-nrps.v2.membershipcontainer+json"
+	mediaLineItem   = "application/vnd.ims.lis.v2.lineitem+json"
+	mediaLineItems  = "application/vnd.ims.lis.v2.lineitemcontainer+json"
+	mediaScore      = "application/vnd.ims.lis.v1.score+json"
+	maxServiceBytes = 10 << 20
+)
+
+var nextLink = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// Member adalah anggota kursus dari layanan NRPS
+type Member struct {
+	UserID     string   `json:"user_id"`
+	Status     string   `json:"status,omitempty"` // Active, Inactive, atau Deleted
+	Name       string   `json:"name,omitempty"`
+	GivenName  string   `json:"given_name,omitempty"`
+	FamilyName string   `json:"family_name,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	Roles      []string `json:"roles"`
+}
+
+// Active melaporkan apakah anggota masih aktif di kursus
+func (m *Member) Active() bool {
+	return m.Status == "" || m.Status == "Active"
+}
+
+// DisplayName mengembalikan nama lengkap anggota
+func (m *Member) DisplayName() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	if name := strings.TrimSpace(m.GivenName + " " + m.FamilyName); name != "" {
+		return name
+	}
+	return m.UserID
+}
+
+// LineItemResource adalah kolom nilai di platform
+type LineItemResource struct {
+	ID             string  `json:"id,omitempty"`
+	ScoreMaximum   float64 `json:"scoreMaximum"`
+	Label          string  `json:"label"`
+	ResourceID     string  `json:"resourceId,omitempty"`
+	ResourceLinkID string  `json:"resourceLinkId,omitempty"`
+	Tag            string  `json:"tag,omitempty"`
+}
+
+// Score adalah nilai yang dikirim ke kolom nilai platform
+type Score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	Comment          string  `json:"comment,omitempty"`
+	Timestamp        string  `json:"timestamp"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+}
+
+// ServiceClient memanggil layanan platform dengan access token OAuth2 client_credentials
+// yang diminta memakai JWT assertion bertanda tangan kunci tool
+type ServiceClient struct {
+	http     *http.Client
+	key      *SigningKey
+	platform *Platform
+
+	mu     sync.Mutex
+	tokens map[string]accessToken // Berdasarkan scope
+}
+
+type accessToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+func NewServiceClient(client *http.Client, key *SigningKey, platform *Platform) *ServiceClient {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ServiceClient{http: client, key: key, platform: platform, tokens: map[string]accessToken{}}
+}
+
+// AccessToken meminta (atau memakai ulang) access token untuk scope tertentu
+func (c *ServiceClient) AccessToken(ctx context.Context, scopes ...string) (string, error) {
+	scope := strings.Join(scopes, " ")
+	c.mu.Lock()
+	cached, ok := c.tokens[scope]
+	c.mu.Unlock()
+	if ok && time.Until(cached.expiresAt) > 30*time.Second {
+		return cached.value, nil
+	}
+
+	jti, err := RandomToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	assertion, err := sign(c.key, jwt.RegisteredClaims{
+		Issuer:    c.platform.ClientID,
+		Subject:   c.platform.ClientID,
+		Audience:  jwt.ClaimStrings{c.platform.AuthTokenURL},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(messageLifetime)),
+		ID:        jti,
+	})
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+		"scope":                 {scope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.platform.AuthTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if _, err := c.doJSON(req, &body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("%w: respons token tanpa access_token", ErrServiceFailed)
+	}
+	if body.ExpiresIn <= 0 {
+		body.ExpiresIn = 3600
+	}
+	c.mu.Lock()
+	c.tokens[scope] = accessToken{value: body.AccessToken, expiresAt: now.Add(time.Duration(body.ExpiresIn) * time.Second)}
+	c.mu.Unlock()
+	return body.AccessToken, nil
+}
+
+// Members mengambil seluruh roster kursus dari layanan NRPS, mengikuti halaman berikutnya
+func (c *ServiceClient) Members(ctx context.Context, membershipsURL string) ([]*Member, error) {
+	var members []*Member
+	next := membershipsURL
+	for next != "" {
+		req, err := c.newRequest(ctx, http.MethodGet, next, nil, mediaMembership, ScopeMembershipReadonly)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", mediaMembership)
+		var page struct {
+			Members []*Member `json:"members"`
+		}
+		resp, err := c.doJSON(req, &page)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, page.Members...)
+
+		next = ""
+		if m := nextLink.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next = m[1]
+		}
+	}
+	return members, nil
+}
+
+// EnsureLineItem mencari kolom nilai untuk resource link; jika belum ada, kolom dibuat
+func (c *ServiceClient) EnsureLineItem(ctx context.Context, lineItemsURL string, item LineItemResource) (string, error) {
+	query, err := withQuery(lineItemsURL, "resource_link_id", item.ResourceLinkID)
+	if err != nil {
+		return "", err
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, query, nil, "", ScopeLineItem)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", mediaLineItems)
+	var existing []LineItemResource
+	if _, err := c.doJSON(req, &existing); err != nil {
+		return "", err
+	}
+	for _, li := range existing {
+		if li.ResourceLinkID == item.ResourceLinkID && li.ID != "" {
+			return li.ID, nil
+		}
+	}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+	req, err = c.newRequest(ctx, http.MethodPost, lineItemsURL, body, mediaLineItem, ScopeLineItem)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", mediaLineItem)
+	var created LineItemResource
+	if _, err := c.doJSON(req, &created); err != nil {
+		return "", err
+	}
+	if created.ID == "" {
+		return "", fmt.Errorf("%w: kolom nilai dibuat tanpa id", ErrServiceFailed)
+	}
+	return created.ID, nil
+}
+
+// PublishScore mengirim nilai ke endpoint scores milik kolom nilai
+func (c *ServiceClient) PublishScore(ctx context.Context, lineItemURL string, score Score) error {
+	target, err := url.Parse(lineItemURL)
+	if err != nil {
+		return err
+	}
+	target.Path = strings.TrimRight(target.Path, "/") + "/scores"
+
+	body, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, target.String(), body, mediaScore, ScopeScore)
+	if err != nil {
+		return err
+	}
+	_, err = c.doJSON(req, nil)
+	return err
+}
+
+func (c *ServiceClient) newRequest(ctx context.Context, method string, target string, body []byte, contentType string, scope string) (*http.Request, error) {
+	token, err := c.AccessToken(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// doJSON menjalankan request dan mendekode respons JSON ke out (jika tidak nil)
+func (c *ServiceClient) doJSON(req *http.Request, out any) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxServiceBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		detail := data
+		if len(detail) > 512 {
+			detail = detail[:512]
+		}
+		return nil, fmt.Errorf("%w: %s %s: status %d: %s", ErrServiceFailed, req.Method, req.URL.Path, resp.StatusCode, detail)
+	}
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("%w: respons tidak valid: %v", ErrServiceFailed, err)
+		}
+	}
+	return resp, nil
+}
+
+func withQuery(raw string, key string, value string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,87 @@
+package lti
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestServiceClientMembers(t *testing.T) {
+	p := newTestPlatform(t)
+	client := NewServiceClient(p.server.Client(), toolKey(t), p.config())
+
+	members, err := client.Members(context.Background(), p.server.URL+"/courses/1/members")
+	if err != nil {
+		t.Fatalf("Members: %v", err)
+	}
+	// Halaman kedua diikuti dari header Link
+	if len(members) != 3 {
+		t.Fatalf("jumlah anggota = %d, ingin 3", len(members))
+	}
+	if members[0].DisplayName() != "Budi Santoso" || !members[0].Active() || !IsLearner(members[0].Roles) {
+		t.Errorf("anggota pertama = %+v", members[0])
+	}
+	if members[1].DisplayName() != "Ibu Sari" || !IsInstructor(members[1].Roles) {
+		t.Errorf("anggota kedua = %+v", members[1])
+	}
+	if members[2].Active() {
+		t.Errorf("anggota berstatus Inactive dianggap aktif")
+	}
+	// Access token dipakai ulang untuk halaman berikutnya
+	if len(p.tokenScopes) != 1 || p.tokenScopes[0] != ScopeMembershipReadonly {
+		t.Errorf("permintaan token = %q, ingin satu untuk scope NRPS", p.tokenScopes)
+	}
+}
+
+func TestServiceClientGradePassback(t *testing.T) {
+	p := newTestPlatform(t)
+	client := NewServiceClient(p.server.Client(), toolKey(t), p.config())
+	ctx := context.Background()
+	lineItems := p.server.URL + "/courses/1/lineitems"
+	item := LineItemResource{ScoreMaximum: 100, Label: "Esai Fotosintesis", ResourceLinkID: "rl-1", ResourceID: "assignment-1"}
+
+	id, err := client.EnsureLineItem(ctx, lineItems, item)
+	if err != nil {
+		t.Fatalf("EnsureLineItem: %v", err)
+	}
+	if id != lineItems+"/rl-1" || len(p.createdItems) != 1 || p.createdItems[0].Label != "Esai Fotosintesis" {
+		t.Fatalf("kolom nilai = %q, dibuat %+v", id, p.createdItems)
+	}
+	// Kolom nilai yang sudah ada untuk resource link dipakai ulang
+	again, err := client.EnsureLineItem(ctx, lineItems, item)
+	if err != nil || again != id || len(p.createdItems) != 1 {
+		t.Errorf("EnsureLineItem kedua = %q (%v), dibuat %d kolom; ingin kolom yang sama", again, err, len(p.createdItems))
+	}
+
+	score := Score{UserID: "u1", ScoreGiven: 82.5, ScoreMaximum: 100, Timestamp: time.Now().Format(time.RFC3339),
+		ActivityProgress: "Completed", GradingProgress: "FullyGraded"}
+	if err := client.PublishScore(ctx, id, score); err != nil {
+		t.Fatalf("PublishScore: %v", err)
+	}
+	if got := p.scores["rl-1"]; len(got) != 1 || got[0] != score {
+		t.Errorf("nilai diterima platform = %+v", got)
+	}
+	if len(p.tokenScopes) != 2 {
+		t.Errorf("permintaan token = %q, ingin satu per scope", p.tokenScopes)
+	}
+}
+
+func TestServiceClientErrors(t *testing.T) {
+	p := newTestPlatform(t)
+	ctx := context.Background()
+
+	// Kunci tool yang tidak dikenal platform ditolak di endpoint token
+	stranger := NewServiceClient(p.server.Client(), &SigningKey{Kid: "tool-9", Key: otherKey}, p.config())
+	if _, err := stranger.Members(ctx, p.server.URL+"/courses/1/members"); !errors.Is(err, ErrServiceFailed) {
+		t.Errorf("assertion ditolak: err = %v, ingin ErrServiceFailed", err)
+	}
+	if _, err := NewServiceClient(p.server.Client(), nil, p.config()).AccessToken(ctx, ScopeScore); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("tanpa kunci: err = %v, ingin ErrNoSigningKey", err)
+	}
+
+	client := NewServiceClient(p.server.Client(), toolKey(t), p.config())
+	if err := client.PublishScore(ctx, p.server.URL+"/courses/2/lineitems/rl-1", Score{UserID: "u1"}); !errors.Is(err, ErrServiceFailed) {
+		t.Errorf("kolom nilai tidak ada: err = %v, ingin ErrServiceFailed", err)
+	}
+}
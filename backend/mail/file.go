@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileSender menulis setiap email sebagai file .eml di bawah direktori dir. Dipakai saat
+// pengembangan agar email dapat dibuka dengan klien email tanpa server SMTP.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir string, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (s *FileSender) Name() string {
+	return "file"
+}
+
+// Send menulis ke file sementara lalu me-rename agar pembaca tidak melihat file setengah jadi
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	data, id, err := Format(msg, s.from, now)
+	if err != nil {
+		return err
+	}
+	name := now.UTC().Format("20060102T150405") + "-" + strings.Trim(strings.SplitN(id, "@", 2)[0], "<") + ".eml"
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
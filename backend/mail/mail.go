@@ -0,0 +1,90 @@
+// Package mail mengirim email keluar, mis. ringkasan notifikasi harian. Sender dapat diganti
+// sesuai lingkungan: FileSender menulis file .eml ke direktori lokal untuk pengembangan, dan
+// SMTPSender mengirim lewat server SMTP.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message adalah email teks biasa untuk satu penerima
+type Message struct {
+	From    string // Alamat pengirim; kosong berarti alamat default Sender
+	To      string
+	ToName  string
+	Subject string
+	Body    string
+}
+
+// Sender mengirim satu email
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+	Name() string
+}
+
+// Format menyusun pesan RFC 5322 berkodekan UTF-8 quoted-printable
+func Format(msg *Message, from string, now time.Time) ([]byte, string, error) {
+	if msg.From != "" {
+		from = msg.From
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, "", fmt.Errorf("alamat pengirim tidak valid: %w", err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, "", fmt.Errorf("alamat penerima tidak valid: %w", err)
+	}
+	if msg.ToName != "" {
+		recipient.Name = msg.ToName
+	}
+	id, err := messageID(sender.Address)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	header("From", sender.String())
+	header("To", recipient.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", id)
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, "", err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), id, nil
+}
+
+func messageID(from string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
@@ -0,0 +1,206 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+
+func testMessage() *Message {
+	return &Message{
+		To:      "budi@sekolah.test",
+		ToName:  "Budi Santoso",
+		Subject: "Ringkasan notifikasi: 2 tugas mendekati tenggat",
+		Body:    "Halo Budi,\n\nTugas \"Esai Fotosintesis\" berakhir besok pukul 23.59 — jangan lupa dikumpulkan.\n",
+	}
+}
+
+// parseFormatted membaca pesan hasil Format dan mendekode subjek serta isinya
+func parseFormatted(t *testing.T, data []byte) (*mail.Message, string, string) {
+	t.Helper()
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("pesan tidak valid: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed, subject, string(body)
+}
+
+func TestFormat(t *testing.T) {
+	data, id, err := Format(testMessage(), "Sistem Penilaian <noreply@sekolah.test>", testNow)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if strings.Contains(strings.ReplaceAll(string(data), "\r\n", ""), "\n") {
+		t.Error("baris pesan harus diakhiri CRLF")
+	}
+	parsed, subject, body := parseFormatted(t, data)
+
+	from, _ := parsed.Header.AddressList("From")
+	to, _ := parsed.Header.AddressList("To")
+	if len(from) != 1 || from[0].Address != "noreply@sekolah.test" || from[0].Name != "Sistem Penilaian" {
+		t.Errorf("From = %v", from)
+	}
+	if len(to) != 1 || to[0].Address != "budi@sekolah.test" || to[0].Name != "Budi Santoso" {
+		t.Errorf("To = %v", to)
+	}
+	if subject != testMessage().Subject {
+		t.Errorf("Subject = %q", subject)
+	}
+	if date, _ := parsed.Header.Date(); !date.Equal(testNow) {
+		t.Errorf("Date = %v, ingin %v", date, testNow)
+	}
+	if parsed.Header.Get("Message-ID") != id || !strings.HasSuffix(id, "@sekolah.test>") {
+		t.Errorf("Message-ID = %q, dikembalikan %q", parsed.Header.Get("Message-ID"), id)
+	}
+	if want := strings.ReplaceAll(testMessage().Body, "\n", "\r\n"); body != want {
+		t.Errorf("isi = %q, ingin %q", body, want)
+	}
+}
+
+func TestFormatAddresses(t *testing.T) {
+	msg := testMessage()
+	msg.From = "guru@sekolah.test"
+	data, _, err := Format(msg, "noreply@sekolah.test", testNow)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	parsed, _, _ := parseFormatted(t, data)
+	if from, _ := parsed.Header.AddressList("From"); len(from) != 1 || from[0].Address != "guru@sekolah.test" {
+		t.Errorf("From = %v, ingin alamat pengirim pesan menggantikan default", from)
+	}
+
+	if _, _, err := Format(testMessage(), "bukan alamat", testNow); err == nil {
+		t.Error("pengirim tidak valid seharusnya ditolak")
+	}
+	msg = testMessage()
+	msg.To = "budi"
+	if _, _, err := Format(msg, "noreply@sekolah.test", testNow); err == nil {
+		t.Error("penerima tidak valid seharusnya ditolak")
+	}
+	// Baris header tidak dapat disisipkan lewat subjek
+	msg = testMessage()
+	msg.Subject = "Halo\r\nBcc: penyusup@luar.test"
+	data, _, err = Format(msg, "noreply@sekolah.test", testNow)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if parsed, _, _ := parseFormatted(t, data); parsed.Header.Get("Bcc") != "" {
+		t.Error("subjek menyisipkan header Bcc")
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	sender, err := NewFileSender(dir, "noreply@sekolah.test")
+	if err != nil {
+		t.Fatalf("NewFileSender: %v", err)
+	}
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Hanya file .eml jadi yang tersisa, tanpa file sementara
+	if len(entries) != 1 || filepath.Ext(entries[0].Name()) != ".eml" {
+		t.Fatalf("isi direktori = %v, ingin satu file .eml", entries)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, subject, _ := parseFormatted(t, data); subject != testMessage().Subject {
+		t.Errorf("Subject = %q", subject)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sender.Send(ctx, testMessage()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Send dengan context dibatalkan: err = %v", err)
+	}
+}
+
+// fakeSMTP adalah server SMTP minimal tanpa STARTTLS/AUTH yang merekam satu transaksi
+func fakeSMTP(t *testing.T) (string, chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	lines := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var got []string
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 smtp.sekolah.test siap")
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				lines <- got
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			got = append(got, line)
+			switch {
+			case inData && line == ".":
+				inData = false
+				reply("250 diterima")
+			case inData:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 smtp.sekolah.test")
+			case line == "DATA":
+				inData = true
+				reply("354 lanjutkan")
+			case line == "QUIT":
+				reply("221 sampai jumpa")
+				lines <- got
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), lines
+}
+
+func TestSMTPSender(t *testing.T) {
+	addr, lines := fakeSMTP(t)
+	sender := NewSMTPSender(SMTPConfig{Addr: addr, From: "Sistem Penilaian <noreply@sekolah.test>", Timeout: 5 * time.Second})
+	if err := sender.Send(context.Background(), testMessage()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	got := strings.Join(<-lines, "\n")
+	for _, want := range []string{"MAIL FROM:<noreply@sekolah.test>", "RCPT TO:<budi@sekolah.test>", "Message-ID: <", "QUIT"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("transaksi SMTP tidak memuat %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPConfig berisi alamat server dan kredensial. Tanpa Username, email dikirim tanpa AUTH.
+type SMTPConfig struct {
+	Addr     string // host:port, mis. smtp.example.com:587
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTPSender mengirim email lewat server SMTP. STARTTLS dipakai otomatis jika server
+// mendukungnya.
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+func (s *SMTPSender) Name() string {
+	return "smtp"
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	data, _, err := Format(msg, s.cfg.From, time.Now())
+	if err != nil {
+		return err
+	}
+	from := s.cfg.From
+	if msg.From != "" {
+		from = msg.From
+	}
+	sender, err := netmail.ParseAddress(from)
+	if err != nil {
+		return err
+	}
+	recipient, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	// smtp.SendMail tidak menerima context, sehingga batas waktu diterapkan pada koneksi
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, err := net.SplitHostPort(s.cfg.Addr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("STARTTLS gagal: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
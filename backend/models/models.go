@@ -1,6 +1,11 @@
 package models
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
 
 // Digunakan untuk request body saat login
 type LoginCredentials struct {
@@ -10,26 +15,1063 @@ type LoginCredentials struct {
 
 // Representasi data pengguna
 type User struct {
-	ID          string `json:"id,omitempty"`
-	NamaLengkap string `json:"nama_lengkap"`
-	Username    string `json:"username"`
-	Email       string `json:"email"`
-	Password    string `json:"password,omitempty"`
-	Peran       string `json:"peran"`
+	ID                 string `json:"id,omitempty"`
+	NamaLengkap        string `json:"nama_lengkap" validate:"required"`
+	Username           string `json:"username" validate:"required"`
+	Email              string `json:"email" validate:"required,email"`
+	Password           string `json:"password,omitempty" validate:"required"`
+	Peran              string `json:"peran"`
+	NomorIdentitas     string `json:"nomor_identitas,omitempty"`
+	IsActive           bool   `json:"is_active"`
+	MustChangePassword bool   `json:"must_change_password"`
+	TokenVersion       int    `json:"-"`
+	CreatedAt          string `json:"created_at,omitempty"`
+	UpdatedAt          string `json:"updated_at,omitempty"`
+	DeletedAt          string `json:"deleted_at,omitempty"`
+}
+
+// Request body untuk mengubah profil pengguna oleh superadmin
+type UpdateUserRequest struct {
+	NamaLengkap    *string `json:"nama_lengkap" validate:"omitempty,min=1"`
+	Username       *string `json:"username" validate:"omitempty,min=1"`
+	Email          *string `json:"email" validate:"omitempty,email"`
+	NomorIdentitas *string `json:"nomor_identitas"`
+}
+
+// Request body untuk mengganti peran pengguna
+type UpdateRoleRequest struct {
+	Peran string `json:"peran" validate:"required,oneof=student teacher superadmin"`
+}
+
+// Request body untuk mengganti password milik sendiri
+type ChangePasswordRequest struct {
+	PasswordLama string `json:"password_lama" validate:"required"`
+	PasswordBaru string `json:"password_baru" validate:"required,min=8"`
+}
+
+// Request body reset password oleh superadmin. Password kosong berarti dibuat otomatis.
+type ResetPasswordRequest struct {
+	Password string `json:"password" validate:"omitempty,min=8"`
+}
+
+// Filter dan paginasi untuk daftar pengguna
+type UserFilter struct {
+	Query    string // Cocok dengan nama, username, email, atau nomor identitas
+	Peran    string
+	IsActive *bool
+	Deleted  bool // true: hanya akun yang sudah di-soft delete
+	Page     int
+	Limit    int
+}
+
+// Hasil daftar berhalaman
+type PaginatedResponse struct {
+	Data  any `json:"data"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+	Total int `json:"total"`
+}
+
+// Ringkasan hasil pembuatan akun guru secara massal dari CSV
+type BulkCreateResult struct {
+	Berhasil int                   `json:"berhasil"`
+	Gagal    int                   `json:"gagal"`
+	Baris    []BulkCreateRowResult `json:"baris"`
+}
+
+type BulkCreateRowResult struct {
+	Baris             int    `json:"baris"`
+	Username          string `json:"username,omitempty"`
+	UserID            string `json:"user_id,omitempty"`
+	PasswordSementara string `json:"password_sementara,omitempty"`
+	Message           string `json:"message,omitempty"`
+}
+
+// Request body offboarding guru. Aksi berlaku untuk semua kelas aktif milik guru,
+// kecuali kelas yang diatur tersendiri melalui Kelas.
+type OffboardRequest struct {
+	Aksi         string          `json:"aksi" validate:"required,oneof=transfer archive"`
+	GuruTujuanID string          `json:"guru_tujuan_id" validate:"required_if=Aksi transfer"`
+	Kelas        []ClassHandover `json:"kelas" validate:"dive"`
+}
+
+// Keputusan serah terima untuk satu kelas
+type ClassHandover struct {
+	KelasID      string `json:"kelas_id" validate:"required"`
+	Aksi         string `json:"aksi" validate:"required,oneof=transfer archive"`
+	GuruTujuanID string `json:"guru_tujuan_id,omitempty" validate:"required_if=Aksi transfer"`
 }
 
 // Representasi data kelas
 type Class struct {
+	ID            string `json:"id,omitempty"`
+	GuruID        string `json:"guru_id"`
+	NamaKelas     string `json:"nama_kelas" validate:"required"`
+	Deskripsi     string `json:"deskripsi,omitempty"`
+	MataPelajaran string `json:"mata_pelajaran,omitempty"`
+	TahunAjaran   string `json:"tahun_ajaran,omitempty" validate:"max=20"`
+	Semester      string `json:"semester,omitempty" validate:"omitempty,oneof=ganjil genap"`
+	ClonedFrom    string `json:"cloned_from,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+	ArchivedAt    string `json:"archived_at,omitempty"`
+	PeranSaya     string `json:"peran_saya,omitempty"` // Peran pengguna yang meminta di kelas ini
+}
+
+// Request body untuk mengubah data kelas; field kosong (nil) tidak diubah
+type UpdateClassRequest struct {
+	NamaKelas     *string `json:"nama_kelas" validate:"omitempty,min=1"`
+	Deskripsi     *string `json:"deskripsi"`
+	MataPelajaran *string `json:"mata_pelajaran"`
+	TahunAjaran   *string `json:"tahun_ajaran" validate:"omitempty,max=20"`
+	Semester      *string `json:"semester" validate:"omitempty,oneof=ganjil genap"`
+}
+
+// Request body untuk menyalin kelas ke periode baru
+type CloneClassRequest struct {
+	NamaKelas   string `json:"nama_kelas"` // Default: nama kelas asal
+	TahunAjaran string `json:"tahun_ajaran" validate:"max=20"`
+	Semester    string `json:"semester" validate:"omitempty,oneof=ganjil genap"`
+}
+
+// Hak akses pengguna terhadap satu kelas
+type ClassAccess struct {
+	Peran    string // owner, co_teacher, assistant, atau kosong jika bukan staf
+	Archived bool
+}
+
+// Peran staf pada sebuah kelas
+const (
+	ClassRoleOwner     = "owner"
+	ClassRoleCoTeacher = "co_teacher"
+	ClassRoleAssistant = "assistant" // Hanya dapat meninjau jawaban siswa
+)
+
+// Representasi staf pengajar (pemilik, guru pendamping, atau asisten) pada kelas
+type ClassStaff struct {
 	ID          string `json:"id,omitempty"`
-	GuruID      string `json:"guru_id"`
-	NamaKelas   string `json:"nama_kelas"`
-	Deskripsi   string `json:"deskripsi,omitempty"`
-	CreatedAt   string `json:"created_at,omitempty"`
+	KelasID     string `json:"kelas_id"`
+	UserID      string `json:"user_id"`
+	NamaLengkap string `json:"nama_lengkap,omitempty"`
+	Username    string `json:"username,omitempty"`
+	NamaKelas   string `json:"nama_kelas,omitempty"`
+	Peran       string `json:"peran"`
+	Status      string `json:"status"`
+	InvitedBy   string `json:"invited_by,omitempty"`
+	InvitedAt   string `json:"invited_at,omitempty"`
+	AcceptedAt  string `json:"accepted_at,omitempty"`
+}
+
+// Request body untuk mengundang staf ke kelas
+type InviteStaffRequest struct {
+	Identifier string `json:"identifier" validate:"required"` // Username atau email guru
+	Peran      string `json:"peran" validate:"required,oneof=co_teacher assistant"`
+}
+
+// Request body untuk mengubah peran staf
+type UpdateStaffRoleRequest struct {
+	Peran string `json:"peran" validate:"required,oneof=co_teacher assistant"`
 }
 
 // Payload untuk JWT
 type Claims struct {
-	UserID string `json:"user_id"`
-	Peran  string `json:"peran"`
+	UserID       string `json:"user_id"`
+	Peran        string `json:"peran"`
+	TokenVersion int    `json:"token_version"`
 	jwt.RegisteredClaims
 }
+
+// Representasi materi pembelajaran pada kelas
+type Material struct {
+	ID           string `json:"id,omitempty"`
+	KelasID      string `json:"kelas_id"`
+	PengunggahID string `json:"pengunggah_id,omitempty"`
+	Judul        string `json:"judul" validate:"required"`
+	IsiMateri    string `json:"isi_materi,omitempty"`
+	FileURL      string `json:"file_url,omitempty"` // Kunci objek di blobstore
+	NamaFile     string `json:"nama_file,omitempty"`
+	TipeFile     string `json:"tipe_file,omitempty"`
+	UkuranFile   int64  `json:"ukuran_file,omitempty"`
+	VersiTerkini int    `json:"versi_terkini,omitempty"`
+	CreatedAt    string `json:"created_at,omitempty"`
+	UpdatedAt    string `json:"updated_at,omitempty"`
+}
+
+// Request body untuk mengubah materi; setiap perubahan konten membuat versi baru
+type UpdateMaterialRequest struct {
+	Judul     *string `json:"judul" validate:"omitempty,min=1"`
+	IsiMateri *string `json:"isi_materi"`
+}
+
+// Versi materi yang tidak dapat diubah
+type MaterialVersion struct {
+	ID          string           `json:"id"`
+	MateriID    string           `json:"materi_id"`
+	NomorVersi  int              `json:"nomor_versi"`
+	Judul       string           `json:"judul"`
+	IsiMateri   string           `json:"isi_materi,omitempty"`
+	FileURL     string           `json:"file_url,omitempty"`
+	ContentHash string           `json:"content_hash"`
+	EditorID    string           `json:"editor_id,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+	Chunks      []*MaterialChunk `json:"chunks,omitempty"`
+}
+
+// Potongan konteks RAG dari satu versi materi
+type MaterialChunk struct {
+	ID      string `json:"id"`
+	VersiID string `json:"versi_id"`
+	Urutan  int    `json:"urutan"`
+	Isi     string `json:"isi"`
+}
+
+// Hasil penilaian AI untuk satu jawaban beserta jejak konteks yang dipakai
+type AIResult struct {
+	ID                string           `json:"id,omitempty"`
+	SubmissionID      string           `json:"submission_id"`
+	Status            string           `json:"status"`
+	SkorAI            *float64         `json:"skor_ai"`
+	UmpanBalikAI      string           `json:"umpan_balik_ai,omitempty"`
+	LogsRAG           string           `json:"logs_rag,omitempty"`
+	MaterialVersionID string           `json:"material_version_id,omitempty"`
+	ChunkIDs          []string         `json:"chunk_ids"`
+	PromptTemplateID  string           `json:"prompt_template_id,omitempty"`
+	PromptVersi       int              `json:"prompt_versi,omitempty"`
+	Aspek             []*AIAspectScore `json:"aspek,omitempty"`
+	GeneratedAt       string           `json:"generated_at,omitempty"`
+}
+
+const (
+	AIResultSucceeded = "berhasil"
+	AIResultFailed    = "gagal"   // Keluaran model tidak valid; menunggu penilaian guru
+	AIResultPaused    = "ditunda" // Anggaran bulanan habis; menunggu penilaian guru
+)
+
+// Satu panggilan model saat menilai jawaban
+type LLMUsage struct {
+	SubmissionID     string
+	Provider         string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	LatencyMS        int64
+	KeluaranValid    bool
+}
+
+// Harga model per satu juta token dalam USD
+type LLMPrice struct {
+	Provider               string  `json:"provider" validate:"required"`
+	Model                  string  `json:"model" validate:"required"`
+	HargaPromptPerJuta     float64 `json:"harga_prompt_per_juta" validate:"gte=0"`
+	HargaCompletionPerJuta float64 `json:"harga_completion_per_juta" validate:"gte=0"`
+	UpdatedAt              string  `json:"updated_at,omitempty"`
+}
+
+// Anggaran bulanan pemakaian model. GuruID kosong berarti anggaran seluruh sekolah.
+type LLMBudget struct {
+	ID          string  `json:"id"`
+	GuruID      string  `json:"guru_id,omitempty"`
+	NamaGuru    string  `json:"nama_guru,omitempty"`
+	BatasUSD    float64 `json:"batas_usd"`
+	TerpakaiUSD float64 `json:"terpakai_usd"` // Pemakaian bulan berjalan
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+type LLMBudgetRequest struct {
+	BatasUSD *float64 `json:"batas_usd" validate:"required,gte=0"`
+}
+
+// Filter laporan pemakaian; GroupBy salah satu class, teacher, atau month
+type LLMUsageFilter struct {
+	GroupBy string
+	Dari    time.Time
+	Sampai  time.Time // Eksklusif
+	KelasID string
+	GuruID  string
+}
+
+// Satu baris laporan pemakaian model per bulan (dan per kelas atau guru)
+type LLMUsageReport struct {
+	Bulan              string  `json:"bulan"` // YYYY-MM
+	KelasID            string  `json:"kelas_id,omitempty"`
+	NamaKelas          string  `json:"nama_kelas,omitempty"`
+	GuruID             string  `json:"guru_id,omitempty"`
+	NamaGuru           string  `json:"nama_guru,omitempty"`
+	JumlahPanggilan    int     `json:"jumlah_panggilan"`
+	JumlahJawaban      int     `json:"jumlah_jawaban"`
+	PromptTokens       int64   `json:"prompt_tokens"`
+	CompletionTokens   int64   `json:"completion_tokens"`
+	BiayaUSD           float64 `json:"biaya_usd"`
+	RataLatencyMS      float64 `json:"rata_latency_ms"`
+	KeluaranTidakValid int     `json:"keluaran_tidak_valid"`
+}
+
+// Skor AI untuk satu aspek rubrik
+type AIAspectScore struct {
+	RubricID  string   `json:"rubric_id,omitempty"`
+	NamaAspek string   `json:"nama_aspek"`
+	Skor      float64  `json:"skor"`
+	Alasan    string   `json:"alasan,omitempty"`
+	ChunkIDs  []string `json:"chunk_ids"`
+}
+
+// Konteks lengkap yang dilihat AI saat menilai sebuah jawaban, untuk audit nilai
+type GradingContext struct {
+	AIResult        *AIResult        `json:"ai_result"`
+	MaterialVersion *MaterialVersion `json:"material_version,omitempty"`
+}
+
+// Satu versi template prompt penilaian untuk sebuah level kognitif
+type PromptTemplate struct {
+	ID            string `json:"id"`
+	LevelKognitif string `json:"level_kognitif"`
+	Versi         int    `json:"versi"`
+	Isi           string `json:"isi"`
+	Catatan       string `json:"catatan,omitempty"`
+	DibuatOleh    string `json:"dibuat_oleh,omitempty"`
+	Aktif         bool   `json:"aktif"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type CreatePromptTemplateRequest struct {
+	Isi      string `json:"isi" validate:"required"`
+	Catatan  string `json:"catatan"`
+	Aktifkan bool   `json:"aktifkan"` // Langsung jadikan versi aktif
+}
+
+// Merender prompt untuk sebuah jawaban. Tanpa versi dan isi, template aktif yang dipakai;
+// isi dipakai untuk mencoba draf template sebelum disimpan.
+type PromptPreviewRequest struct {
+	SubmissionID string `json:"submission_id" validate:"required"`
+	Versi        int    `json:"versi" validate:"gte=0"`
+	Isi          string `json:"isi"`
+}
+
+// Bahan yang dibutuhkan untuk menilai satu jawaban
+type GradingInput struct {
+	Submission      *Submission      `json:"submission"`
+	Question        *Question        `json:"question"`
+	MaterialVersion *MaterialVersion `json:"material_version"`
+}
+
+// Representasi soal esai pada materi
+type Question struct {
+	ID            string          `json:"id,omitempty"`
+	MateriID      string          `json:"materi_id"`
+	TeksSoal      string          `json:"teks_soal" validate:"required"`
+	LevelKognitif string          `json:"level_kognitif,omitempty" validate:"omitempty,oneof=C1 C2 C3 C4"`
+	KunciJawaban  string          `json:"kunci_jawaban,omitempty"`
+	Rubrik        []*Rubric       `json:"rubrik,omitempty" validate:"dive"`
+	Statistik     *ItemStatistics `json:"statistik,omitempty" validate:"-"` // Hanya pada bank soal dengan ?analisis=true
+}
+
+// Aspek penilaian untuk sebuah soal
+type Rubric struct {
+	ID        string  `json:"id,omitempty"`
+	SoalID    string  `json:"soal_id,omitempty"`
+	NamaAspek string  `json:"nama_aspek" validate:"required"`
+	Deskripsi string  `json:"deskripsi,omitempty"`
+	Bobot     float64 `json:"bobot" validate:"gte=0"`
+	// Rentang skor aspek, mis. 1-4 untuk rubrik analitik. Kosong berarti 0-100.
+	SkorMin  *float64 `json:"skor_min,omitempty" validate:"required_with=SkorMaks"`
+	SkorMaks *float64 `json:"skor_maks,omitempty" validate:"required_with=SkorMin,omitempty,gtfield=SkorMin"`
+}
+
+// Tugas yang mengelompokkan soal esai dengan jadwal pengerjaan. Waktu disimpan dalam UTC
+// dan ditampilkan pada ZonaWaktu tugas.
+type Assignment struct {
+	ID             string     `json:"id,omitempty"`
+	KelasID        string     `json:"kelas_id"`
+	Judul          string     `json:"judul"`
+	Deskripsi      string     `json:"deskripsi,omitempty"`
+	DibukaPada     time.Time  `json:"dibuka_pada"`
+	DitutupPada    time.Time  `json:"ditutup_pada"`
+	BatasTerlambat *time.Time `json:"batas_terlambat,omitempty"`
+	PenaltiPerHari float64    `json:"penalti_per_hari"`
+	ZonaWaktu      string     `json:"zona_waktu"`
+	MaksPercobaan  int        `json:"maks_percobaan"`
+	KebijakanNilai string     `json:"kebijakan_nilai"` // best, latest, atau average
+	ModeUjian      bool       `json:"mode_ujian"`
+	DurasiMenit    int        `json:"durasi_menit,omitempty"`
+	SoalIDs        []string   `json:"soal_ids"`
+	DibuatOleh     string     `json:"dibuat_oleh,omitempty"`
+	CreatedAt      string     `json:"created_at,omitempty"`
+	UpdatedAt      string     `json:"updated_at,omitempty"`
+
+	// Hanya untuk tampilan siswa
+	NamaKelas     string               `json:"nama_kelas,omitempty"`
+	Perpanjangan  *AssignmentExtension `json:"perpanjangan,omitempty"`
+	Status        string               `json:"status,omitempty"`
+	HariTerlambat int                  `json:"hari_terlambat,omitempty"`
+	PenaltiPersen float64              `json:"penalti_persen,omitempty"`
+	SesiUjian     *ExamSession         `json:"sesi_ujian,omitempty"`
+	Soal          []*Question          `json:"soal,omitempty"`
+}
+
+// Request body untuk membuat atau mengganti tugas. Waktu dalam RFC 3339, atau waktu lokal
+// tanpa offset (mis. "2025-08-01T07:00") yang ditafsirkan pada ZonaWaktu. Default: zona waktu
+// Asia/Jakarta, satu kali pengumpulan, dan kebijakan nilai latest.
+type AssignmentRequest struct {
+	Judul          string   `json:"judul" validate:"required"`
+	Deskripsi      string   `json:"deskripsi"`
+	DibukaPada     string   `json:"dibuka_pada" validate:"required"`
+	DitutupPada    string   `json:"ditutup_pada" validate:"required"`
+	BatasTerlambat string   `json:"batas_terlambat"`
+	PenaltiPerHari float64  `json:"penalti_per_hari" validate:"gte=0,lte=100"`
+	ZonaWaktu      string   `json:"zona_waktu"`
+	MaksPercobaan  int      `json:"maks_percobaan" validate:"omitempty,gte=1,lte=20"`
+	KebijakanNilai string   `json:"kebijakan_nilai" validate:"omitempty,oneof=best latest average"`
+	ModeUjian      bool     `json:"mode_ujian"`
+	DurasiMenit    int      `json:"durasi_menit" validate:"required_if=ModeUjian true,omitempty,gte=1,lte=600"`
+	SoalIDs        []string `json:"soal_ids" validate:"required,min=1,dive,uuid"`
+}
+
+// Perpanjangan tenggat tugas untuk seorang siswa
+type AssignmentExtension struct {
+	ID             string     `json:"id,omitempty"`
+	AssignmentID   string     `json:"assignment_id"`
+	SiswaID        string     `json:"siswa_id"`
+	NamaSiswa      string     `json:"nama_siswa,omitempty"`
+	DitutupPada    time.Time  `json:"ditutup_pada"`
+	BatasTerlambat *time.Time `json:"batas_terlambat,omitempty"`
+	Alasan         string     `json:"alasan,omitempty"`
+	DiberikanOleh  string     `json:"diberikan_oleh,omitempty"`
+	CreatedAt      string     `json:"created_at,omitempty"`
+}
+
+// Request body untuk memberikan perpanjangan
+type ExtensionRequest struct {
+	DitutupPada    string `json:"ditutup_pada" validate:"required"`
+	BatasTerlambat string `json:"batas_terlambat"`
+	Alasan         string `json:"alasan"`
+}
+
+// Jawaban esai siswa. Pada tugas, setiap pengumpulan adalah satu percobaan.
+type Submission struct {
+	ID            string   `json:"id,omitempty"`
+	SoalID        string   `json:"soal_id"`
+	SiswaID       string   `json:"siswa_id"`
+	AssignmentID  string   `json:"assignment_id,omitempty"`
+	TeksJawaban   string   `json:"teks_jawaban"`
+	PercobaanKe   int      `json:"percobaan_ke"`
+	HariTerlambat int      `json:"hari_terlambat"`
+	PenaltiPersen float64  `json:"penalti_persen"`
+	SkorAI        *float64 `json:"skor_ai,omitempty"`
+	SkorGuru      *float64 `json:"skor_guru,omitempty"`
+	Skor          *float64 `json:"skor,omitempty"` // Nilai percobaan setelah penalti
+	SubmittedAt   string   `json:"submitted_at,omitempty"`
+}
+
+// Draf jawaban yang disimpan otomatis
+type Draft struct {
+	AssignmentID string `json:"assignment_id"`
+	SoalID       string `json:"soal_id"`
+	SiswaID      string `json:"siswa_id"`
+	TeksJawaban  string `json:"teks_jawaban"`
+	Versi        int    `json:"versi"` // 0 jika draf belum pernah disimpan
+	UpdatedAt    string `json:"updated_at,omitempty"`
+}
+
+// Request body autosave. Versi adalah versi draf yang terakhir diketahui klien (0 untuk draf baru).
+type SaveDraftRequest struct {
+	TeksJawaban string `json:"teks_jawaban"`
+	Versi       int    `json:"versi" validate:"gte=0"`
+}
+
+// Request body untuk mengumpulkan draf sebagai jawaban final
+type SubmitAnswerRequest struct {
+	SoalID string `json:"soal_id" validate:"required,uuid"`
+	Versi  int    `json:"versi" validate:"gte=1"` // Versi draf yang dikumpulkan
+}
+
+// Seluruh percobaan seorang siswa untuk satu soal beserta nilai yang dihitung
+type QuestionAttempts struct {
+	SoalID         string        `json:"soal_id"`
+	KebijakanNilai string        `json:"kebijakan_nilai"`
+	MaksPercobaan  int           `json:"maks_percobaan"`
+	Percobaan      []*Submission `json:"percobaan"`
+	SkorDihitung   *float64      `json:"skor_dihitung"`
+}
+
+// Sesi ujian seorang siswa. Sisa waktu selalu dihitung oleh server.
+type ExamSession struct {
+	ID              string            `json:"id"`
+	AssignmentID    string            `json:"assignment_id"`
+	SiswaID         string            `json:"siswa_id"`
+	NamaSiswa       string            `json:"nama_siswa,omitempty"`
+	Token           string            `json:"token,omitempty"` // Hanya dikirim saat ujian dimulai
+	DimulaiPada     time.Time         `json:"dimulai_pada"`
+	BerakhirPada    time.Time         `json:"berakhir_pada"`
+	SelesaiPada     *time.Time        `json:"selesai_pada,omitempty"`
+	AlasanSelesai   string            `json:"alasan_selesai,omitempty"` // dikumpulkan atau waktu_habis
+	SisaDetik       int64             `json:"sisa_detik"`
+	IPAwal          string            `json:"ip_awal,omitempty"`
+	IPTerakhir      string            `json:"ip_terakhir,omitempty"`
+	JumlahPeristiwa int               `json:"jumlah_peristiwa"`
+	Peristiwa       []*IntegrityEvent `json:"peristiwa,omitempty"`
+}
+
+// Peristiwa integritas selama ujian
+type IntegrityEvent struct {
+	ID          string     `json:"id"`
+	SessionID   string     `json:"session_id"`
+	Jenis       string     `json:"jenis"`
+	Detail      string     `json:"detail,omitempty"`
+	IP          string     `json:"ip,omitempty"`
+	TerjadiPada *time.Time `json:"terjadi_pada,omitempty"`
+	DicatatPada time.Time  `json:"dicatat_pada"`
+}
+
+// Jenis peristiwa integritas
+const (
+	IntegrityTabBlur        = "tab_blur"
+	IntegrityTabFocus       = "tab_focus"
+	IntegrityPaste          = "paste"
+	IntegrityCopy           = "copy"
+	IntegrityFullscreenExit = "fullscreen_exit"
+	IntegrityIPChange       = "ip_change"      // Dideteksi server
+	IntegrityTokenReissued  = "token_reissued" // Ujian dimulai ulang dari perangkat/tab lain
+)
+
+// Request body laporan peristiwa integritas dari klien
+type IntegrityEventRequest struct {
+	Jenis       string `json:"jenis" validate:"required,oneof=tab_blur tab_focus paste copy fullscreen_exit"`
+	Detail      string `json:"detail" validate:"max=1000"`
+	TerjadiPada string `json:"terjadi_pada"` // RFC 3339, opsional
+}
+
+// Sidik jari teks jawaban untuk deteksi kemiripan
+type SubmissionFingerprint struct {
+	SubmissionID  string
+	SiswaID       string
+	MinHash       []uint64
+	SimHash       uint64
+	JumlahShingle int
+}
+
+// Tanda kemiripan pada jawaban
+type SimilarityFlag struct {
+	ID              string  `json:"id"`
+	SubmissionID    string  `json:"submission_id"`
+	PembandingID    string  `json:"pembanding_id,omitempty"`
+	SiswaPembanding string  `json:"siswa_pembanding,omitempty"` // Nama pemilik jawaban pembanding
+	Jenis           string  `json:"jenis"`                      // antar_jawaban atau materi
+	Metode          string  `json:"metode"`                     // minhash, simhash, atau containment
+	Skor            float64 `json:"skor"`
+	Status          string  `json:"status"`
+	DitinjauOleh    string  `json:"ditinjau_oleh,omitempty"`
+	CreatedAt       string  `json:"created_at,omitempty"`
+}
+
+// Request body untuk menindaklanjuti tanda kemiripan
+type UpdateFlagRequest struct {
+	Status string `json:"status" validate:"required,oneof=baru dikonfirmasi diabaikan"`
+}
+
+// Filter antrean tinjauan guru
+type ReviewQueueFilter struct {
+	AssignmentID  string
+	HanyaDitandai bool // Hanya jawaban yang memiliki tanda kemiripan berstatus baru
+	Page          int
+	Limit         int
+}
+
+// Jawaban yang menunggu tinjauan guru
+type ReviewQueueItem struct {
+	SubmissionID   string            `json:"submission_id"`
+	AssignmentID   string            `json:"assignment_id,omitempty"`
+	SoalID         string            `json:"soal_id"`
+	TeksSoal       string            `json:"teks_soal"`
+	SiswaID        string            `json:"siswa_id"`
+	NamaSiswa      string            `json:"nama_siswa"`
+	TeksJawaban    string            `json:"teks_jawaban"`
+	PercobaanKe    int               `json:"percobaan_ke"`
+	PenaltiPersen  float64           `json:"penalti_persen"`
+	SkorAI         *float64          `json:"skor_ai"`
+	StatusAI       string            `json:"status_ai,omitempty"` // Kosong jika belum dinilai AI
+	SubmittedAt    string            `json:"submitted_at"`
+	KemiripanMaks  *float64          `json:"kemiripan_maks"`
+	TandaKemiripan []*SimilarityFlag `json:"tanda_kemiripan"`
+}
+
+// Tinjauan dan nilai akhir dari guru
+type TeacherReview struct {
+	ID           string                `json:"id,omitempty"`
+	SubmissionID string                `json:"submission_id"`
+	SkorFinal    float64               `json:"skor_final" validate:"gte=0,lte=100"`
+	CatatanGuru  string                `json:"catatan_guru,omitempty"`
+	ReviewerID   string                `json:"reviewer_id,omitempty"`
+	Aspek        []*TeacherAspectScore `json:"aspek,omitempty" validate:"dive"` // Opsional
+	ReviewedAt   string                `json:"reviewed_at,omitempty"`
+}
+
+// Skor guru untuk satu aspek rubrik soal
+type TeacherAspectScore struct {
+	RubricID string  `json:"rubric_id" validate:"required"`
+	Skor     float64 `json:"skor" validate:"gte=0,lte=100"`
+}
+
+// Pasangan skor AI dan skor guru untuk satu jawaban (atau satu aspek jawaban)
+type AgreementPair struct {
+	SubmissionID  string
+	SoalID        string
+	TeksSoal      string
+	LevelKognitif string
+	PromptVersi   int // 0 jika hasil AI tidak mencatat versi prompt
+	RubricID      string
+	NamaAspek     string
+	SkorAI        float64
+	SkorGuru      float64
+}
+
+// Filter data kesepakatan; kosong berarti semua kelas
+type AgreementFilter struct {
+	KelasID      string
+	AssignmentID string
+}
+
+// Penguasaan satu level kognitif (C1–C4)
+type MasteryLevel struct {
+	LevelKognitif string  `json:"level_kognitif"`
+	RataRata      float64 `json:"rata_rata"`
+	JumlahJawaban int     `json:"jumlah_jawaban"`
+	JumlahSiswa   int     `json:"jumlah_siswa,omitempty"`
+	SiswaTuntas   int     `json:"siswa_tuntas,omitempty"` // Siswa dengan rata-rata >= batas tuntas
+}
+
+type StudentMastery struct {
+	SiswaID   string          `json:"siswa_id"`
+	NamaSiswa string          `json:"nama_siswa"`
+	Level     []*MasteryLevel `json:"level"`
+}
+
+// Ringkasan penguasaan kelas beserta rincian per siswa
+type ClassMastery struct {
+	BatasTuntas    float64           `json:"batas_tuntas"`
+	DiperbaruiPada string            `json:"diperbarui_pada"`
+	Kelas          []*MasteryLevel   `json:"kelas"`
+	Siswa          []*StudentMastery `json:"siswa"`
+}
+
+// Rata-rata satu level kognitif pada satu periode (minggu atau bulan)
+type MasteryTrendPoint struct {
+	Periode       string  `json:"periode"` // Tanggal awal periode, YYYY-MM-DD
+	LevelKognitif string  `json:"level_kognitif"`
+	RataRata      float64 `json:"rata_rata"`
+	JumlahJawaban int     `json:"jumlah_jawaban"`
+}
+
+// Jumlah jawaban pada satu rentang nilai [Dari, Sampai); rentang terakhir termasuk 100
+type HistogramBucket struct {
+	LevelKognitif string `json:"level_kognitif"`
+	Dari          int    `json:"dari"`
+	Sampai        int    `json:"sampai"`
+	Jumlah        int    `json:"jumlah"`
+}
+
+// Analisis butir soal untuk menemukan soal yang perlu direvisi
+type ItemStatistics struct {
+	Jumlah          int              `json:"jumlah"` // Jawaban yang sudah bernilai (percobaan terakhir)
+	RataRata        float64          `json:"rata_rata"`
+	Varians         float64          `json:"varians"`
+	DayaBeda        *float64         `json:"daya_beda"` // Nil jika belum ada tugas dengan cukup siswa
+	JumlahDitinjau  int              `json:"jumlah_ditinjau"`
+	JumlahDikoreksi int              `json:"jumlah_dikoreksi"`
+	TingkatKoreksi  *float64         `json:"tingkat_koreksi"` // Proporsi skor AI yang diubah guru
+	Tema            []*FeedbackTheme `json:"tema_umpan_balik"`
+	Peringatan      []string         `json:"peringatan"`
+}
+
+type FeedbackTheme struct {
+	Frasa  string `json:"frasa"`
+	Jumlah int    `json:"jumlah"` // Jumlah umpan balik yang memuat frasa
+}
+
+// Skor akhir satu siswa untuk satu soal; AssignmentID kosong untuk jawaban di luar tugas
+type ItemObservation struct {
+	SoalID       string
+	AssignmentID string
+	SiswaID      string
+	Skor         float64
+}
+
+// Jumlah jawaban yang ditinjau guru dan yang skor AI-nya diubah
+type ItemReviewStats struct {
+	SoalID    string
+	Ditinjau  int
+	Dikoreksi int
+}
+
+// Teks umpan balik AI atau catatan guru untuk sebuah soal
+type ItemFeedback struct {
+	SoalID string
+	Teks   string
+}
+
+// Satu percobaan jawaban untuk ekspor buku nilai. Kolom aspek dan umpan balik hanya terisi
+// jika detail diminta.
+type GradebookAttempt struct {
+	AssignmentID  string
+	SoalID        string
+	PercobaanKe   int
+	PenaltiPersen float64
+	SkorAI        *float64
+	SkorGuru      *float64
+	AspekAI       map[string]float64 // rubric_id -> skor
+	AspekGuru     map[string]float64
+	UmpanBalikAI  string
+	CatatanGuru   string
+}
+
+// Siswa kelas beserta seluruh percobaannya pada tugas kelas, urut per tugas, soal, dan percobaan
+type GradebookStudent struct {
+	SiswaID        string
+	NamaSiswa      string
+	NomorIdentitas string
+	Percobaan      []*GradebookAttempt
+}
+
+// Platform LMS yang terdaftar sebagai pengguna tool LTI 1.3
+type LTIPlatform struct {
+	ID            string   `json:"id,omitempty"`
+	Nama          string   `json:"nama" validate:"required"`
+	Issuer        string   `json:"issuer" validate:"required"`
+	ClientID      string   `json:"client_id" validate:"required"`
+	AuthLoginURL  string   `json:"auth_login_url" validate:"required,url"`
+	AuthTokenURL  string   `json:"auth_token_url" validate:"required,url"`
+	JWKSURL       string   `json:"jwks_url" validate:"required,url"`
+	DeploymentIDs []string `json:"deployment_ids"` // Kosong: semua deployment diterima
+	TrustEmail    bool     `json:"trust_email"`    // Email terverifikasi platform boleh menautkan akun yang ada
+	CreatedAt     string   `json:"created_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at,omitempty"`
+}
+
+// Kunci RSA tool untuk menandatangani pesan LTI
+type LTIKey struct {
+	Kid        string `json:"kid"`
+	PrivateKey string `json:"-"`
+	Aktif      bool   `json:"aktif"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// Identitas pengguna dari peluncuran atau roster platform
+type LTIIdentity struct {
+	PlatformID  string
+	Sub         string
+	NamaLengkap string
+	Email       string
+	Peran       string // Peran untuk akun baru: teacher atau student
+}
+
+// Kursus platform yang ditautkan ke kelas
+type LTIContext struct {
+	ID             string
+	PlatformID     string
+	ContextID      string
+	KelasID        string
+	Judul          string
+	MembershipsURL string
+	LineItemsURL   string
+}
+
+// Tautan aktivitas platform yang mengarah ke tugas
+type LTIResourceLink struct {
+	ID             string
+	ContextID      string // lti_contexts.id
+	ResourceLinkID string
+	AssignmentID   string
+	LineItemURL    string
+	// Diisi saat mengambil tautan per tugas
+	PlatformID   string
+	LineItemsURL string
+}
+
+// Sesi deep linking antara peluncuran guru dan pemilihan tugas
+type LTIDeepLinkSession struct {
+	ID           string
+	PlatformID   string
+	DeploymentID string
+	ReturnURL    string
+	Data         string
+	UserID       string
+	KelasID      string
+	ExpiresAt    time.Time
+}
+
+// Request body untuk memilih tugas yang ditautkan lewat deep linking
+type LTIDeepLinkRequest struct {
+	AssignmentIDs []string `json:"assignment_ids" validate:"required,min=1,dive,required"`
+}
+
+// Ringkasan sinkronisasi roster NRPS
+type LTISyncResult struct {
+	Ditambahkan int `json:"ditambahkan"`
+	Dihapus     int `json:"dihapus"`
+	Dilewati    int `json:"dilewati"` // Anggota non-siswa atau tidak aktif
+}
+
+// Ringkasan pengiriman nilai ke platform
+type LTIGradePushResult struct {
+	Terkirim int      `json:"terkirim"`
+	Dilewati int      `json:"dilewati"` // Siswa tanpa nilai atau tanpa akun platform
+	Gagal    int      `json:"gagal"`
+	Galat    []string `json:"galat,omitempty"`
+}
+
+// Langganan webhook. KelasID kosong berarti langganan global (superadmin).
+type Webhook struct {
+	ID         string    `json:"id,omitempty"`
+	KelasID    string    `json:"kelas_id,omitempty"`
+	URL        string    `json:"url" validate:"required,url,startswith=http"`
+	Events     []string  `json:"events" validate:"required,min=1,dive,required"`
+	Deskripsi  string    `json:"deskripsi,omitempty"`
+	Aktif      bool      `json:"aktif"`
+	Secret     string    `json:"secret,omitempty"` // Hanya dikembalikan saat langganan dibuat
+	DibuatOleh string    `json:"dibuat_oleh,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+const (
+	WebhookDeliveryPending   = "menunggu"
+	WebhookDeliverySucceeded = "berhasil"
+	WebhookDeliveryFailed    = "gagal"      // Percobaan habis atau penerima menolak payload
+	WebhookDeliveryCancelled = "dibatalkan" // Langganan dinonaktifkan sebelum terkirim
+)
+
+// Pengiriman satu peristiwa ke satu langganan
+type WebhookDelivery struct {
+	ID                 string            `json:"id"`
+	WebhookID          string            `json:"webhook_id"`
+	EventID            string            `json:"event_id"`
+	Event              string            `json:"event"`
+	Payload            json.RawMessage   `json:"payload,omitempty"`
+	Status             string            `json:"status"`
+	Percobaan          int               `json:"percobaan"`
+	NextAttemptAt      *time.Time        `json:"next_attempt_at,omitempty"` // Hanya untuk status menunggu
+	StatusCodeTerakhir *int              `json:"status_code_terakhir,omitempty"`
+	GalatTerakhir      string            `json:"galat_terakhir,omitempty"`
+	DikirimUlangDari   string            `json:"dikirim_ulang_dari,omitempty"`
+	CreatedAt          time.Time         `json:"created_at"`
+	DeliveredAt        *time.Time        `json:"delivered_at,omitempty"`
+	RiwayatPercobaan   []*WebhookAttempt `json:"riwayat_percobaan,omitempty"`
+	// Diisi saat pengiriman diambil oleh pekerja
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// Log satu percobaan pengiriman
+type WebhookAttempt struct {
+	PercobaanKe int       `json:"percobaan_ke"`
+	StatusCode  *int      `json:"status_code,omitempty"`
+	Respons     string    `json:"respons,omitempty"`
+	Galat       string    `json:"galat,omitempty"`
+	DurasiMS    int       `json:"durasi_ms"`
+	DicobaPada  time.Time `json:"dicoba_pada"`
+}
+
+// Isi data peristiwa jawaban pada payload webhook
+type SubmissionEvent struct {
+	SubmissionID  string   `json:"submission_id"`
+	AssignmentID  string   `json:"assignment_id,omitempty"`
+	SoalID        string   `json:"soal_id"`
+	SiswaID       string   `json:"siswa_id"`
+	PercobaanKe   int      `json:"percobaan_ke,omitempty"`
+	HariTerlambat int      `json:"hari_terlambat,omitempty"`
+	StatusAI      string   `json:"status_ai,omitempty"`
+	SkorAI        *float64 `json:"skor_ai,omitempty"`
+	SkorFinal     *float64 `json:"skor_final,omitempty"`
+	ReviewerID    string   `json:"reviewer_id,omitempty"`
+}
+
+// Jenis notifikasi
+const (
+	NotificationAssignmentPosted = "tugas_baru"
+	NotificationDeadlineSoon     = "tenggat_dekat"
+	NotificationGradePublished   = "nilai_terbit"
+	NotificationReviewPending    = "tinjauan_menunggu"
+)
+
+// NotificationKinds berisi semua jenis notifikasi sesuai urutan tampilan pengaturan
+var NotificationKinds = []string{
+	NotificationAssignmentPosted,
+	NotificationDeadlineSoon,
+	NotificationGradePublished,
+	NotificationReviewPending,
+}
+
+// Notifikasi di kotak masuk pengguna
+type Notification struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Jenis      string     `json:"jenis"`
+	Judul      string     `json:"judul"`
+	Isi        string     `json:"isi"`
+	Tautan     string     `json:"tautan,omitempty"` // Path halaman frontend terkait
+	KelasID    string     `json:"kelas_id,omitempty"`
+	Kunci      string     `json:"-"` // Kunci deduplikasi; kosong berarti selalu dibuat
+	Dibaca     bool       `json:"dibaca"`
+	DibacaPada *time.Time `json:"dibaca_pada,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Kotak masuk beserta jumlah notifikasi yang belum dibaca
+type NotificationInbox struct {
+	PaginatedResponse
+	BelumDibaca int `json:"belum_dibaca"`
+}
+
+// Request body untuk menandai notifikasi
+type MarkNotificationRequest struct {
+	Dibaca *bool `json:"dibaca" validate:"required"`
+}
+
+// Preferensi satu jenis notifikasi. Aktif=false berarti notifikasi tidak dibuat; Email=false
+// berarti notifikasi tetap muncul di kotak masuk tetapi tidak masuk ringkasan email.
+type NotificationPreference struct {
+	Jenis string `json:"jenis" validate:"required"`
+	Aktif bool   `json:"aktif"`
+	Email bool   `json:"email"`
+}
+
+// Request body untuk mengganti preferensi notifikasi; jenis yang tidak disebut tidak berubah
+type NotificationPreferencesRequest struct {
+	Preferensi []NotificationPreference `json:"preferensi" validate:"required,min=1,dive"`
+}
+
+// Pengingat tenggat untuk satu siswa pada satu tugas
+type DeadlineReminder struct {
+	AssignmentID string
+	KelasID      string
+	NamaKelas    string
+	Judul        string
+	SiswaID      string
+	DitutupPada  time.Time // Sudah memperhitungkan perpanjangan siswa
+	ZonaWaktu    string
+}
+
+// Penerima ringkasan email harian
+type DigestRecipient struct {
+	UserID      string
+	NamaLengkap string
+	Email       string
+}
+
+// Status dan pemicu eksekusi job terjadwal
+const (
+	JobRunRunning   = "berjalan"
+	JobRunSucceeded = "berhasil"
+	JobRunFailed    = "gagal"
+
+	JobTriggerSchedule = "jadwal"
+	JobTriggerManual   = "manual"
+)
+
+// Riwayat satu eksekusi job terjadwal
+type JobRun struct {
+	ID             string     `json:"id"`
+	Job            string     `json:"job"`
+	Pemicu         string     `json:"pemicu"`
+	DipicuOleh     string     `json:"dipicu_oleh,omitempty"`
+	Jadwal         *time.Time `json:"jadwal,omitempty"` // Slot jadwal; kosong untuk pemicu manual
+	Status         string     `json:"status"`
+	JumlahDiproses int        `json:"jumlah_diproses"`
+	Galat          string     `json:"galat,omitempty"`
+	Instance       string     `json:"instance"`
+	MulaiPada      time.Time  `json:"mulai_pada"`
+	SelesaiPada    *time.Time `json:"selesai_pada,omitempty"`
+	DurasiMS       int        `json:"durasi_ms,omitempty"`
+}
+
+// Job terdaftar beserta jadwal berikutnya dan eksekusi terakhirnya
+type JobInfo struct {
+	Nama       string    `json:"nama"`
+	Deskripsi  string    `json:"deskripsi"`
+	Jadwal     string    `json:"jadwal"`
+	Berikutnya time.Time `json:"berikutnya"`
+	Terakhir   *JobRun   `json:"terakhir,omitempty"`
+}
+
+// Aksi yang dicatat di jejak audit
+const (
+	AuditLogin             = "auth.login"
+	AuditLoginFailed       = "auth.login_failed"
+	AuditPasswordChanged   = "user.password_changed"
+	AuditPasswordReset     = "user.password_reset"
+	AuditUserUpdated       = "user.updated"
+	AuditRoleChanged       = "user.role_changed"
+	AuditUserDisabled      = "user.disabled"
+	AuditUserEnabled       = "user.enabled"
+	AuditUserDeleted       = "user.deleted"
+	AuditUserRestored      = "user.restored"
+	AuditUsersPurged       = "user.purged"
+	AuditTeacherOffboarded = "user.offboarded"
+	AuditClassCreated      = "class.created"
+	AuditClassUpdated      = "class.updated"
+	AuditClassArchived     = "class.archived"
+	AuditClassUnarchived   = "class.unarchived"
+	AuditClassCloned       = "class.cloned"
+	AuditStaffInvited      = "class.staff_invited"
+	AuditStaffRoleChanged  = "class.staff_role_changed"
+	AuditStaffRemoved      = "class.staff_removed"
+	AuditScoreSet          = "grade.score_set"
+	AuditGradebookExported = "grade.gradebook_exported"
+	AuditReportExported    = "grade.report_exported"
+)
+
+// Perubahan satu field; Sebelum atau Sesudah nil jika field tidak ada pada keadaan tersebut
+type AuditChange struct {
+	Sebelum any `json:"sebelum"`
+	Sesudah any `json:"sesudah"`
+}
+
+// Entri jejak audit. Entri tidak dapat diubah atau dihapus; Hash merangkai entri dengan entri
+// sebelumnya agar manipulasi langsung di database dapat dideteksi.
+type AuditEntry struct {
+	ID             int64                  `json:"id"`
+	AktorID        string                 `json:"aktor_id,omitempty"` // Kosong untuk aksi sistem atau login gagal
+	AktorPeran     string                 `json:"aktor_peran,omitempty"`
+	Aksi           string                 `json:"aksi"`
+	TargetJenis    string                 `json:"target_jenis,omitempty"`
+	TargetID       string                 `json:"target_id,omitempty"`
+	KelasID        string                 `json:"kelas_id,omitempty"`
+	Perubahan      map[string]AuditChange `json:"perubahan,omitempty"`
+	Metadata       map[string]any         `json:"metadata,omitempty"`
+	IP             string                 `json:"ip,omitempty"`
+	RequestID      string                 `json:"request_id,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+	HashSebelumnya string                 `json:"hash_sebelumnya"`
+	Hash           string                 `json:"hash"`
+}
+
+// Filter daftar jejak audit; Aksi yang diakhiri titik (mis. "user.") mencocokkan awalan
+type AuditFilter struct {
+	AktorID     string
+	Aksi        string
+	TargetJenis string
+	TargetID    string
+	KelasID     string
+	RequestID   string
+	IP          string
+	Dari        *time.Time
+	Sampai      *time.Time
+	Page        int
+	Limit       int
+}
+
+// Hasil pemeriksaan rantai hash jejak audit
+type AuditVerification struct {
+	Valid           bool   `json:"valid"`
+	JumlahDiperiksa int    `json:"jumlah_diperiksa"`
+	RusakPadaID     *int64 `json:"rusak_pada_id,omitempty"`
+	Alasan          string `json:"alasan,omitempty"`
+}
+
+// Nilai sebuah jawaban, dicatat sebelum dan sesudah guru mengubah skor
+type SubmissionScores struct {
+	SkorAI      *float64           `json:"skor_ai"`
+	SkorFinal   *float64           `json:"skor_final"`
+	CatatanGuru string             `json:"catatan_guru"`
+	ReviewerID  string             `json:"reviewer_id"`
+	Aspek       map[string]float64 `json:"aspek"`
+}
@@ -0,0 +1,270 @@
+// Package pdfwriter membuat dokumen PDF sederhana berisi teks dan tabel memakai font standar
+// Helvetica, tanpa pustaka eksternal. Cukup untuk laporan seperti rapor siswa.
+package pdfwriter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ukuran A4 dalam point dan tata letak halaman
+const (
+	PageWidth    = 595.0
+	PageHeight   = 842.0
+	Margin       = 50.0
+	ContentWidth = PageWidth - 2*Margin
+	lineSpacing  = 1.35
+	cellPadding  = 4.0
+)
+
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// Document menampung halaman yang sudah disusun; ditulis dengan WriteTo
+type Document struct {
+	title string
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// New membuat dokumen kosong dengan satu halaman
+func New(title string) *Document {
+	d := &Document{title: title}
+	d.newPage()
+	return d
+}
+
+// Heading menulis judul tebal berukuran besar
+func (d *Document) Heading(text string) {
+	d.paragraph(text, fontBold, 15, ContentWidth, Margin)
+	d.Space(4)
+}
+
+// Subheading menulis judul bagian
+func (d *Document) Subheading(text string) {
+	d.Space(6)
+	d.paragraph(text, fontBold, 11.5, ContentWidth, Margin)
+	d.Space(2)
+}
+
+// Text menulis paragraf biasa yang dibungkus sesuai lebar halaman
+func (d *Document) Text(text string) {
+	d.paragraph(text, fontRegular, 10, ContentWidth, Margin)
+}
+
+// Space menambah jarak vertikal
+func (d *Document) Space(points float64) {
+	d.y -= points
+}
+
+// Row menulis satu baris tabel. Lebar kolom dalam point; isi sel dibungkus dan tinggi baris
+// mengikuti sel tertinggi. Baris dipindahkan utuh ke halaman baru jika tidak muat.
+func (d *Document) Row(cells []string, widths []float64, bold bool) {
+	const size = 9.5
+	font := fontRegular
+	if bold {
+		font = fontBold
+	}
+	lines := make([][]string, len(cells))
+	height := 0.0
+	for i, c := range cells {
+		lines[i] = wrap(c, font, size, widths[i]-2*cellPadding)
+		if h := float64(len(lines[i])) * size * lineSpacing; h > height {
+			height = h
+		}
+	}
+	height += 2 * cellPadding
+	if d.y-height < Margin && d.y < PageHeight-Margin {
+		d.newPage()
+	}
+
+	page := d.pages[len(d.pages)-1]
+	x := Margin
+	for i := range cells {
+		fmt.Fprintf(page, "0.6 G %.2f %.2f %.2f %.2f re S 0 G\n", x, d.y-height, widths[i], height)
+		lineY := d.y - cellPadding - size
+		for _, line := range lines[i] {
+			writeText(page, font, size, x+cellPadding, lineY, line)
+			lineY -= size * lineSpacing
+		}
+		x += widths[i]
+	}
+	d.y -= height
+}
+
+func (d *Document) paragraph(text string, font string, size float64, width float64, x float64) {
+	for _, line := range wrap(text, font, size, width) {
+		if d.y-size < Margin {
+			d.newPage()
+		}
+		d.y -= size
+		writeText(d.pages[len(d.pages)-1], font, size, x, d.y, line)
+		d.y -= size * (lineSpacing - 1)
+	}
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = PageHeight - Margin
+}
+
+// WriteTo menulis dokumen lengkap beserta nomor halaman
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &countingWriter{w: bufio.NewWriter(w)}
+	var offsets []int64
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	io.WriteString(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objek 1–5 tetap; setiap halaman memakai dua objek (halaman dan isi) mulai dari objek 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (sistem-skripsi) >>", escape(d.title)))
+
+	for i, page := range d.pages {
+		content := bytes.NewBuffer(page.Bytes())
+		footer := fmt.Sprintf("Halaman %d dari %d", i+1, len(d.pages))
+		writeText(content, fontRegular, 8, PageWidth-Margin-textWidth(footer, fontRegular, 8), Margin/2, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, fontRegular, fontBold, 7+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, out.w.Flush()
+}
+
+func writeText(buf *bytes.Buffer, font string, size float64, x, y float64, text string) {
+	fmt.Fprintf(buf, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(text))
+}
+
+// wrap memecah teks menjadi baris yang muat pada lebar tertentu; baris baru pada teks dipertahankan
+func wrap(text string, font string, size float64, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, font, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Kata yang lebih panjang dari satu baris dipotong paksa
+			for textWidth(word, font, size) > width && len([]rune(word)) > 1 {
+				runes := []rune(word)
+				cut := len(runes) - 1
+				for cut > 1 && textWidth(string(runes[:cut]), font, size) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				word = string(runes[cut:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth memakai metrik Helvetica; huruf tebal diperkirakan 7% lebih lebar
+func textWidth(text string, font string, size float64) float64 {
+	var units float64
+	for _, r := range text {
+		if r >= 32 && r <= 126 {
+			units += float64(helveticaWidths[r-32])
+		} else {
+			units += 556
+		}
+	}
+	if font == fontBold {
+		units *= 1.07
+	}
+	return units * size / 1000
+}
+
+// escape mengubah teks ke WinAnsiEncoding dan meng-escape karakter khusus string PDF.
+// Karakter di luar encoding diganti "?".
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteByte(' ')
+		case r < 32:
+			continue
+		case r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			if c, ok := winAnsi[r]; ok {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// Lebar karakter ASCII 32–126 Helvetica dalam satuan 1/1000 em (AFM Adobe)
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,64 @@
+// Package prompt merender template prompt penilaian (text/template) yang disimpan di database.
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// Data yang tersedia untuk template, mis. {{.Soal}} atau {{range .Rubrik}}{{.NamaAspek}}{{end}}
+type Data struct {
+	Soal          string
+	LevelKognitif string
+	KunciJawaban  string
+	Rubrik        []Aspect
+	Konteks       []Context // Chunk materi hasil retrieval, dari yang paling relevan
+	Jawaban       string
+}
+
+// Context adalah satu chunk materi; ID dirujuk model saat mengutip sumber penilaian
+type Context struct {
+	ID     string
+	Urutan int
+	Isi    string
+}
+
+type Aspect struct {
+	NamaAspek string
+	Deskripsi string
+	Bobot     float64
+}
+
+// Contoh data untuk memvalidasi template sebelum disimpan
+var sampleData = Data{
+	Soal:          "Jelaskan proses fotosintesis.",
+	LevelKognitif: "C2",
+	KunciJawaban:  "Fotosintesis mengubah energi cahaya menjadi energi kimia.",
+	Rubrik:        []Aspect{{NamaAspek: "Ketepatan konsep", Deskripsi: "Konsep dijelaskan dengan benar", Bobot: 1}},
+	Konteks:       []Context{{ID: "00000000-0000-0000-0000-000000000000", Urutan: 1, Isi: "Fotosintesis terjadi di kloroplas."}},
+	Jawaban:       "Tumbuhan membuat makanan dari cahaya matahari.",
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=error").Parse(text)
+}
+
+// Validate memastikan template dapat di-parse dan dirender dengan data contoh
+func Validate(text string) error {
+	_, err := Render(text, sampleData)
+	return err
+}
+
+// Render menghasilkan teks prompt dari template dan data
+func Render(text string, data Data) (string, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,61 @@
+package prompt
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	text := `Soal ({{.LevelKognitif}}): {{.Soal}}
+{{range .Rubrik}}- {{.NamaAspek}} (bobot {{.Bobot}})
+{{end}}{{range .Konteks}}[{{.ID}}] {{.Isi}}
+{{end}}Jawaban: {{.Jawaban}}
+`
+	got, err := Render(text, Data{
+		Soal:          "Apa itu sel?",
+		LevelKognitif: "C1",
+		Rubrik:        []Aspect{{NamaAspek: "Konsep", Bobot: 60}, {NamaAspek: "Istilah", Bobot: 40}},
+		Konteks:       []Context{{ID: "c1", Urutan: 1, Isi: "Sel adalah unit terkecil kehidupan."}},
+		Jawaban:       "Unit terkecil makhluk hidup.",
+	})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	want := "Soal (C1): Apa itu sel?\n- Konsep (bobot 60)\n- Istilah (bobot 40)\n[c1] Sel adalah unit terkecil kehidupan.\nJawaban: Unit terkecil makhluk hidup."
+	if got != want {
+		t.Errorf("Render = %q, ingin %q", got, want)
+	}
+}
+
+// Field yang kosong dirender sebagai string kosong, sedangkan variabel yang tidak dikenal
+// harus menggagalkan render alih-alih menghasilkan "<no value>" di dalam prompt
+func TestRenderMissingVariables(t *testing.T) {
+	got, err := Render("Kunci: {{.KunciJawaban}}|{{range .Konteks}}x{{end}}", Data{})
+	if err != nil {
+		t.Fatalf("Render dengan data kosong: %v", err)
+	}
+	if got != "Kunci: |" {
+		t.Errorf("Render = %q, ingin %q", got, "Kunci: |")
+	}
+
+	for _, text := range []string{"{{.Nilai}}", "{{range .Rubrik}}{{.Skor}}{{end}}"} {
+		data := Data{Rubrik: []Aspect{{NamaAspek: "Konsep"}}}
+		if out, err := Render(text, data); err == nil {
+			t.Errorf("Render(%q) = %q, ingin error variabel tidak dikenal", text, out)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate("{{.Soal}}\n{{range .Rubrik}}{{.NamaAspek}}: {{.Deskripsi}}{{end}}\n{{.Jawaban}}"); err != nil {
+		t.Errorf("Validate template valid: %v", err)
+	}
+	invalid := map[string]string{
+		"sintaks":              "{{.Soal",
+		"blok tidak ditutup":   "{{range .Rubrik}}{{.NamaAspek}}",
+		"field tidak dikenal":  "{{.NilaiAkhir}}",
+		"fungsi tidak dikenal": "{{upper .Soal}}",
+	}
+	for name, text := range invalid {
+		if err := Validate(text); err == nil {
+			t.Errorf("%s: Validate(%q) tidak mengembalikan error", name, text)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package rag berisi bagian pipeline Retrieval-Augmented Generation: pemotongan materi
+// menjadi chunk dan pemilihan chunk yang relevan untuk penilaian.
+package rag
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Ukuran chunk default dalam karakter (rune)
+const (
+	DefaultChunkSize    = 1200
+	DefaultChunkOverlap = 200
+)
+
+// Chunk memotong teks menjadi potongan berukuran maksimal size rune dengan mengutamakan
+// batas paragraf. Setiap chunk baru diawali overlap rune terakhir dari chunk sebelumnya
+// agar konteks di perbatasan tidak hilang.
+func Chunk(text string, size int, overlap int) []string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var pieces []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		pieces = append(pieces, splitLong(paragraph, size-overlap)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() == 0 {
+			return
+		}
+		chunk := current.String()
+		chunks = append(chunks, chunk)
+		current.Reset()
+		if overlap > 0 {
+			current.WriteString(tail(chunk, overlap))
+		}
+	}
+
+	for _, piece := range pieces {
+		separator := 0
+		if current.Len() > 0 {
+			separator = 2
+		}
+		if utf8.RuneCountInString(current.String())+separator+utf8.RuneCountInString(piece) > size {
+			flush()
+			separator = 0
+			if current.Len() > 0 {
+				separator = 2
+			}
+		}
+		if separator > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	// Sisa yang hanya berisi overlap tidak perlu menjadi chunk tersendiri
+	if last := current.String(); last != "" && (len(chunks) == 0 || last != tail(chunks[len(chunks)-1], overlap)) {
+		chunks = append(chunks, last)
+	}
+	return chunks
+}
+
+// splitLong memecah paragraf yang lebih panjang dari limit pada batas kata
+func splitLong(paragraph string, limit int) []string {
+	if utf8.RuneCountInString(paragraph) <= limit {
+		return []string{paragraph}
+	}
+	var parts []string
+	var current strings.Builder
+	count := 0
+	for _, word := range strings.Fields(paragraph) {
+		n := utf8.RuneCountInString(word)
+		if count > 0 && count+1+n > limit {
+			parts = append(parts, current.String())
+			current.Reset()
+			count = 0
+		}
+		if count > 0 {
+			current.WriteByte(' ')
+			count++
+		}
+		current.WriteString(word)
+		count += n
+	}
+	if count > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// tail mengambil sekitar n rune terakhir, dimulai dari awal kata
+func tail(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	start := len(runes) - n
+	for start < len(runes) && runes[start-1] != ' ' && runes[start-1] != '\n' {
+		start++
+	}
+	return strings.TrimSpace(string(runes[start:]))
+}
@@ -0,0 +1,89 @@
+package rag
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Jumlah chunk default yang disertakan ke prompt penilaian
+const DefaultTopK = 4
+
+// Parameter BM25
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// TopK memberi peringkat chunk terhadap query dengan BM25 lalu mengembalikan indeks k chunk
+// teratas, dari yang paling relevan. Chunk tanpa kata yang cocok tidak disertakan.
+func TopK(query string, chunks []string, k int) []int {
+	queryTerms := terms(query)
+	if len(queryTerms) == 0 || len(chunks) == 0 || k <= 0 {
+		return nil
+	}
+
+	docs := make([]map[string]int, len(chunks))
+	lengths := make([]int, len(chunks))
+	df := map[string]int{}
+	total := 0
+	for i, chunk := range chunks {
+		docs[i] = map[string]int{}
+		words := terms(chunk)
+		for _, w := range words {
+			docs[i][w]++
+		}
+		for w := range docs[i] {
+			df[w]++
+		}
+		lengths[i] = len(words)
+		total += len(words)
+	}
+	avgLength := float64(total) / float64(len(chunks))
+	if avgLength == 0 {
+		return nil
+	}
+
+	unique := map[string]bool{}
+	for _, w := range queryTerms {
+		unique[w] = true
+	}
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var results []scored
+	n := float64(len(chunks))
+	for i, doc := range docs {
+		var score float64
+		for w := range unique {
+			tf := float64(doc[w])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (n-float64(df[w])+0.5)/(float64(df[w])+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(lengths[i])/avgLength))
+		}
+		if score > 0 {
+			results = append(results, scored{i, score})
+		}
+	}
+
+	sort.SliceStable(results, func(a, b int) bool { return results[a].score > results[b].score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	indexes := make([]int, len(results))
+	for i, r := range results {
+		indexes[i] = r.index
+	}
+	return indexes
+}
+
+func terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,125 @@
+// Package realtime menyalurkan peristiwa ke klien yang sedang terhubung (SSE atau WebSocket)
+// melalui pub/sub bertopik. Implementasi Memory cukup untuk satu instance; Redis meneruskan
+// pesan antar-instance sehingga klien menerima peristiwa dari instance mana pun.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Kapasitas antrean pesan setiap langganan. Langganan yang antreannya penuh ditutup agar
+// klien tersambung ulang dan memuat ulang data, bukan diam-diam kehilangan pesan.
+const subscriptionBuffer = 64
+
+// Message adalah peristiwa yang dikirim ke klien
+type Message struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NewMessage membuat pesan dari data yang dapat dikodekan ke JSON
+func NewMessage(id string, event string, data any, now time.Time) (*Message, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{ID: id, Event: event, Data: encoded, CreatedAt: now.UTC()}, nil
+}
+
+// UserTopic menerima peristiwa milik seorang pengguna, mis. status penilaian jawaban siswa
+func UserTopic(userID string) string { return "user:" + userID }
+
+// ClassTopic menerima peristiwa kelas untuk staf, mis. perubahan antrean tinjauan
+func ClassTopic(classID string) string { return "class:" + classID }
+
+// Broker adalah kontrak pub/sub. Publish tidak menunggu pelanggan membaca pesan.
+type Broker interface {
+	Publish(ctx context.Context, topic string, msg *Message) error
+	Subscribe(topics ...string) *Subscription
+	Name() string
+}
+
+// Subscription menerima pesan dari satu atau beberapa topik sampai Close dipanggil.
+// C ditutup saat langganan berakhir, termasuk karena antrean penuh.
+type Subscription struct {
+	C <-chan *Message
+
+	ch     chan *Message
+	topics []string
+	hub    *Memory
+	once   sync.Once
+}
+
+// Close menghentikan langganan; aman dipanggil lebih dari sekali
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Memory adalah broker dalam proses
+type Memory struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription]struct{}
+}
+
+func NewMemory() *Memory {
+	return &Memory{topics: map[string]map[*Subscription]struct{}{}}
+}
+
+func (m *Memory) Name() string { return "memory" }
+
+func (m *Memory) Subscribe(topics ...string) *Subscription {
+	ch := make(chan *Message, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, topics: topics, hub: m}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, topic := range topics {
+		if m.topics[topic] == nil {
+			m.topics[topic] = map[*Subscription]struct{}{}
+		}
+		m.topics[topic][sub] = struct{}{}
+	}
+	return sub
+}
+
+func (m *Memory) Publish(ctx context.Context, topic string, msg *Message) error {
+	var overflow []*Subscription
+	m.mu.RLock()
+	for sub := range m.topics[topic] {
+		select {
+		case sub.ch <- msg:
+		default:
+			overflow = append(overflow, sub)
+		}
+	}
+	m.mu.RUnlock()
+	for _, sub := range overflow {
+		sub.Close()
+	}
+	return nil
+}
+
+// Subscribers mengembalikan jumlah langganan aktif pada sebuah topik
+func (m *Memory) Subscribers(topic string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.topics[topic])
+}
+
+func (m *Memory) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		m.mu.Lock()
+		for _, topic := range sub.topics {
+			delete(m.topics[topic], sub)
+			if len(m.topics[topic]) == 0 {
+				delete(m.topics, topic)
+			}
+		}
+		m.mu.Unlock()
+		close(sub.ch)
+	})
+}
@@ -0,0 +1,88 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func testMessage(t *testing.T, id string) *Message {
+	t.Helper()
+	msg, err := NewMessage(id, "grading.completed", map[string]string{"submission_id": id}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestMemoryPublishSubscribe(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	user := m.Subscribe(UserTopic("siswa-1"), ClassTopic("kelas-1"))
+	defer user.Close()
+	other := m.Subscribe(UserTopic("siswa-2"))
+	defer other.Close()
+
+	m.Publish(ctx, UserTopic("siswa-1"), testMessage(t, "ev-1"))
+	m.Publish(ctx, ClassTopic("kelas-1"), testMessage(t, "ev-2"))
+	for _, want := range []string{"ev-1", "ev-2"} {
+		select {
+		case msg := <-user.C:
+			if msg.ID != want {
+				t.Errorf("pesan = %s, ingin %s", msg.ID, want)
+			}
+		default:
+			t.Fatalf("pesan %s tidak diterima", want)
+		}
+	}
+	// Pengguna lain tidak menerima peristiwa topik yang tidak dilangganinya
+	select {
+	case msg := <-other.C:
+		t.Errorf("langganan siswa-2 menerima %s", msg.ID)
+	default:
+	}
+}
+
+func TestMemoryClose(t *testing.T) {
+	m := NewMemory()
+	sub := m.Subscribe(UserTopic("siswa-1"), ClassTopic("kelas-1"))
+	if n := m.Subscribers(UserTopic("siswa-1")); n != 1 {
+		t.Fatalf("pelanggan = %d, ingin 1", n)
+	}
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("C seharusnya ditutup")
+	}
+	// Topik tanpa pelanggan dibersihkan dan publish berikutnya tidak panik
+	if len(m.topics) != 0 {
+		t.Errorf("topik tersisa = %v", m.topics)
+	}
+	if err := m.Publish(context.Background(), UserTopic("siswa-1"), testMessage(t, "ev-1")); err != nil {
+		t.Errorf("Publish tanpa pelanggan: %v", err)
+	}
+}
+
+// Langganan yang antreannya penuh ditutup, bukan kehilangan pesan diam-diam
+func TestMemoryOverflowClosesSubscription(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	slow := m.Subscribe(ClassTopic("kelas-1"))
+	fast := m.Subscribe(ClassTopic("kelas-1"))
+	defer fast.Close()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		m.Publish(ctx, ClassTopic("kelas-1"), testMessage(t, "ev"))
+		<-fast.C
+	}
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != subscriptionBuffer {
+		t.Errorf("pesan sebelum ditutup = %d, ingin %d", received, subscriptionBuffer)
+	}
+	if n := m.Subscribers(ClassTopic("kelas-1")); n != 1 {
+		t.Errorf("pelanggan tersisa = %d, ingin hanya yang membaca tepat waktu", n)
+	}
+}
@@ -0,0 +1,126 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sistem-skripsi/backend/redis"
+	"strings"
+	"time"
+)
+
+const (
+	redisPingInterval = 30 * time.Second
+	redisMaxBackoff   = 30 * time.Second
+)
+
+// Redis menerbitkan pesan ke channel Redis "<prefix>:<topik>". Setiap instance memegang satu
+// koneksi PSUBSCRIBE ke "<prefix>:*" dan meneruskan pesan yang diterima ke pelanggan lokalnya,
+// termasuk pesan yang diterbitkan instance itu sendiri.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	local  *Memory
+	cancel context.CancelFunc
+}
+
+// NewRedis memulai koneksi langganan di latar belakang; koneksi yang terputus dibuka ulang
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	if prefix == "" {
+		prefix = "events"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Redis{client: client, prefix: prefix, local: NewMemory(), cancel: cancel}
+	go r.run(ctx)
+	return r
+}
+
+func (r *Redis) Name() string { return "redis" }
+
+func (r *Redis) Publish(ctx context.Context, topic string, msg *Message) error {
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Publish(ctx, r.prefix+":"+topic, string(encoded))
+	return err
+}
+
+func (r *Redis) Subscribe(topics ...string) *Subscription {
+	return r.local.Subscribe(topics...)
+}
+
+// Close menghentikan koneksi langganan
+func (r *Redis) Close() {
+	r.cancel()
+}
+
+func (r *Redis) run(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		started := time.Now()
+		err := r.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > redisMaxBackoff {
+			backoff = time.Second
+		}
+		log.Printf("Langganan Redis realtime terputus, mencoba lagi dalam %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, redisMaxBackoff)
+	}
+}
+
+func (r *Redis) listen(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	pubsub, err := r.client.PSubscribe(dialCtx, r.prefix+":*")
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer pubsub.Close()
+
+	// Ping berkala memastikan selalu ada balasan sebelum batas waktu baca, sehingga koneksi
+	// yang mati terdeteksi oleh Receive
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(redisPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				pubsub.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+				err := pubsub.Ping(pingCtx)
+				cancel()
+				if err != nil {
+					pubsub.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		m, err := pubsub.Receive(2 * redisPingInterval)
+		if err != nil {
+			return err
+		}
+		var msg Message
+		if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+			log.Printf("Pesan realtime tidak valid di %s: %v", m.Channel, err)
+			continue
+		}
+		r.local.Publish(ctx, strings.TrimPrefix(m.Channel, r.prefix+":"), &msg)
+	}
+}
@@ -0,0 +1,199 @@
+package realtime
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Implementasi WebSocket (RFC 6455) sisi server yang minimal: server hanya mengirim pesan teks
+// dan frame kontrol; pesan dari klien dibaca untuk menangani ping dan penutupan saja.
+
+const (
+	wsGUID           = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxClientFrame = 64 << 10
+
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+
+	// Kode penutupan
+	CloseNormal    = 1000
+	CloseGoingAway = 1001
+	CloseTooBig    = 1009
+	CloseTryLater  = 1013
+)
+
+var (
+	ErrNotWebSocket = errors.New("request bukan permintaan upgrade WebSocket")
+	ErrFrameTooBig  = errors.New("frame WebSocket terlalu besar")
+)
+
+// WSConn adalah koneksi WebSocket hasil Upgrade
+type WSConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	wmu  sync.Mutex
+}
+
+// Upgrade menjalankan handshake WebSocket dan mengambil alih koneksi HTTP
+func Upgrade(w http.ResponseWriter, r *http.Request) (*WSConn, error) {
+	if r.Method != http.MethodGet || !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, ErrNotWebSocket
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, ErrNotWebSocket
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return &WSConn{conn: conn, rw: rw}, nil
+}
+
+// WriteText mengirim satu pesan teks
+func (c *WSConn) WriteText(data []byte, timeout time.Duration) error {
+	return c.writeFrame(opText, data, timeout)
+}
+
+// Ping mengirim frame ping; klien wajib membalas pong
+func (c *WSConn) Ping(timeout time.Duration) error {
+	return c.writeFrame(opPing, nil, timeout)
+}
+
+// Close mengirim frame penutupan lalu menutup koneksi
+func (c *WSConn) Close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	c.writeFrame(opClose, payload, time.Second)
+	return c.conn.Close()
+}
+
+// ReadLoop membaca frame dari klien sampai koneksi ditutup, membalas ping dengan pong.
+// Pesan data dari klien diabaikan. Setiap frame (termasuk pong) memperpanjang batas waktu
+// baca sebesar idle; mengembalikan nil jika klien menutup koneksi dengan normal.
+func (c *WSConn) ReadLoop(idle time.Duration) error {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(idle))
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			if errors.Is(err, ErrFrameTooBig) {
+				c.Close(CloseTooBig, "")
+			}
+			return err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload, 5*time.Second); err != nil {
+				return err
+			}
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.Close(code, "")
+			return nil
+		}
+	}
+}
+
+func (c *WSConn) writeFrame(opcode byte, payload []byte, timeout time.Duration) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readFrame membaca satu frame klien (wajib bertopeng) dan membuka topengnya
+func (c *WSConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	size := uint64(head[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if !masked {
+		return 0, nil, errors.New("frame WebSocket dari klien harus bertopeng")
+	}
+	if size > wsMaxClientFrame {
+		return 0, nil, ErrFrameTooBig
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
+
+func headerContains(h http.Header, name string, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Contoh kunci dan jawaban handshake dari RFC 6455 bagian 1.3
+const (
+	testKey    = "dGhlIHNhbXBsZSBub25jZQ=="
+	testAccept = "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+)
+
+// wsServer menjalankan Upgrade, mengirim satu pesan teks, lalu ReadLoop sampai klien menutup
+func wsServer(t *testing.T) (*httptest.Server, chan error) {
+	t.Helper()
+	done := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := conn.WriteText([]byte(`{"event":"halo"}`), time.Second); err != nil {
+			done <- err
+			return
+		}
+		done <- conn.ReadLoop(time.Second)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, done
+}
+
+// dial melakukan handshake sebagai klien dan mengembalikan koneksi beserta pembacanya
+func dial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\nConnection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: "+testKey+"\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != testAccept {
+		t.Fatalf("handshake = %d, accept %q", resp.StatusCode, resp.Header.Get("Sec-WebSocket-Accept"))
+	}
+	return conn, br
+}
+
+// readServerFrame membaca satu frame server yang tidak bertopeng
+func readServerFrame(t *testing.T, br *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatal("frame server tidak boleh bertopeng")
+	}
+	payload := make([]byte, head[1]&0x7F)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+// writeClientFrame mengirim frame bertopeng seperti klien WebSocket
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte, masked bool) {
+	t.Helper()
+	frame := []byte{0x80 | opcode, byte(len(payload))}
+	if masked {
+		mask := [4]byte{1, 2, 3, 4}
+		frame[1] |= 0x80
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebSocketSession(t *testing.T) {
+	srv, done := wsServer(t)
+	conn, br := dial(t, srv)
+
+	if op, payload := readServerFrame(t, br); op != opText || string(payload) != `{"event":"halo"}` {
+		t.Errorf("frame pertama = %x %q, ingin teks", op, payload)
+	}
+	writeClientFrame(t, conn, opPing, []byte("cek"), true)
+	if op, payload := readServerFrame(t, br); op != opPong || string(payload) != "cek" {
+		t.Errorf("balasan ping = %x %q, ingin pong dengan isi yang sama", op, payload)
+	}
+
+	closing := make([]byte, 2)
+	binary.BigEndian.PutUint16(closing, CloseNormal)
+	writeClientFrame(t, conn, opClose, closing, true)
+	if op, payload := readServerFrame(t, br); op != opClose || binary.BigEndian.Uint16(payload) != CloseNormal {
+		t.Errorf("balasan penutupan = %x %v", op, payload)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ReadLoop setelah penutupan normal = %v, ingin nil", err)
+	}
+}
+
+func TestWebSocketRejectsUnmaskedFrame(t *testing.T) {
+	srv, done := wsServer(t)
+	conn, br := dial(t, srv)
+	readServerFrame(t, br)
+
+	writeClientFrame(t, conn, opText, []byte("hai"), false)
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "bertopeng") {
+		t.Errorf("ReadLoop = %v, ingin galat frame tanpa topeng", err)
+	}
+}
+
+func TestWebSocketFrameTooBig(t *testing.T) {
+	srv, done := wsServer(t)
+	conn, br := dial(t, srv)
+	readServerFrame(t, br)
+
+	// Header frame 127 (panjang 64-bit) melebihi batas frame klien
+	header := []byte{0x80 | opText, 0x80 | 127}
+	header = binary.BigEndian.AppendUint64(header, wsMaxClientFrame+1)
+	conn.Write(header)
+	if op, payload := readServerFrame(t, br); op != opClose || binary.BigEndian.Uint16(payload) != CloseTooBig {
+		t.Errorf("frame = %x %v, ingin penutupan %d", op, payload, CloseTooBig)
+	}
+	if err := <-done; !errors.Is(err, ErrFrameTooBig) {
+		t.Errorf("ReadLoop = %v, ingin ErrFrameTooBig", err)
+	}
+}
+
+func TestUpgradeRejectsPlainRequest(t *testing.T) {
+	srv, _ := wsServer(t)
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, ingin 400 untuk request biasa", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "bukan-base64")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, ingin 400 untuk kunci tidak valid", resp.StatusCode)
+	}
+}
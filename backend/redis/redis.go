@@ -0,0 +1,289 @@
+// Package redis adalah klien Redis minimal (protokol RESP2) untuk cache dan pub/sub,
+// sehingga tidak memerlukan library tambahan.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNil dikembalikan untuk balasan nil, mis. GET pada kunci yang tidak ada
+var ErrNil = errors.New("redis: nil")
+
+// Error adalah balasan error dari server
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+type Config struct {
+	Addr        string // host:port
+	Password    string
+	DB          int
+	DialTimeout time.Duration
+}
+
+// Client memakai satu koneksi yang dipakai bergantian dan dibuka ulang jika terputus
+type Client struct {
+	cfg  Config
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func NewClient(cfg Config) *Client {
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	return &Client{cfg: cfg}
+}
+
+// Do mengirim satu perintah dan mengembalikan balasannya: string (simple/bulk string),
+// int64, []any (array), atau ErrNil
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, rd, err := c.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.conn, c.rd = conn, rd
+	}
+	reply, err := roundTrip(ctx, c.conn, c.rd, args)
+	var serverErr Error
+	if err != nil && !errors.Is(err, ErrNil) && !errors.As(err, &serverErr) {
+		// Koneksi mungkin rusak di tengah balasan; buka ulang pada perintah berikutnya
+		c.conn.Close()
+		c.conn, c.rd = nil, nil
+	}
+	return reply, err
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+// dial membuka koneksi baru lalu menjalankan AUTH dan SELECT sesuai konfigurasi
+func (c *Client) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: c.cfg.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	rd := bufio.NewReader(conn)
+	var setup [][]string
+	if c.cfg.Password != "" {
+		setup = append(setup, []string{"AUTH", c.cfg.Password})
+	}
+	if c.cfg.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(c.cfg.DB)})
+	}
+	for _, args := range setup {
+		if _, err := roundTrip(ctx, conn, rd, args); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+	}
+	return conn, rd, nil
+}
+
+func roundTrip(ctx context.Context, conn net.Conn, rd *bufio.Reader, args []string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+	if err := writeCommand(conn, args); err != nil {
+		return nil, err
+	}
+	return readReply(rd)
+}
+
+func writeCommand(w io.Writer, args []string) error {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readReply(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: balasan tidak valid %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, ErrNil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, ErrNil
+		}
+		items := make([]any, count)
+		for i := range items {
+			item, err := readReply(rd)
+			var serverErr Error
+			switch {
+			case errors.As(err, &serverErr):
+				items[i] = serverErr
+			case err != nil && !errors.Is(err, ErrNil):
+				return nil, err
+			default:
+				items[i] = item
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: jenis balasan tidak dikenal %q", kind)
+}
+
+// Publish mengirim pesan ke channel dan mengembalikan jumlah pelanggan yang menerimanya
+func (c *Client) Publish(ctx context.Context, channel string, message string) (int64, error) {
+	reply, err := c.Do(ctx, "PUBLISH", channel, message)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return n, nil
+}
+
+// Message adalah pesan pub/sub yang diterima. Pattern terisi untuk langganan PSUBSCRIBE.
+type Message struct {
+	Pattern string
+	Channel string
+	Payload string
+}
+
+// PubSub adalah koneksi khusus langganan. Selama berlangganan, Redis hanya menerima perintah
+// langganan dan PING pada koneksi tersebut, sehingga koneksi tidak dibagi dengan Client.
+type PubSub struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+// Subscribe membuka koneksi baru dan berlangganan ke channel
+func (c *Client) Subscribe(ctx context.Context, channels ...string) (*PubSub, error) {
+	return c.subscribe(ctx, "SUBSCRIBE", channels)
+}
+
+// PSubscribe membuka koneksi baru dan berlangganan ke pola channel, mis. "events:*"
+func (c *Client) PSubscribe(ctx context.Context, patterns ...string) (*PubSub, error) {
+	return c.subscribe(ctx, "PSUBSCRIBE", patterns)
+}
+
+func (c *Client) subscribe(ctx context.Context, command string, names []string) (*PubSub, error) {
+	if len(names) == 0 {
+		return nil, errors.New("redis: channel langganan kosong")
+	}
+	conn, rd, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p := &PubSub{conn: conn, rd: rd}
+	if err := p.send(ctx, append([]string{command}, names...)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Receive menunggu pesan berikutnya. Balasan konfirmasi langganan dan PONG dilewati, tetapi
+// tetap memperpanjang batas waktu baca; timeout 0 berarti menunggu tanpa batas. Error berarti
+// koneksi tidak dapat dipakai lagi dan harus ditutup.
+func (p *PubSub) Receive(timeout time.Duration) (*Message, error) {
+	for {
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		p.conn.SetReadDeadline(deadline)
+		reply, err := readReply(p.rd)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := reply.([]any)
+		if !ok || len(items) == 0 {
+			return nil, fmt.Errorf("redis: balasan pub/sub tidak valid %v", reply)
+		}
+		kind, _ := items[0].(string)
+		switch {
+		case kind == "message" && len(items) == 3:
+			channel, _ := items[1].(string)
+			payload, _ := items[2].(string)
+			return &Message{Channel: channel, Payload: payload}, nil
+		case kind == "pmessage" && len(items) == 4:
+			pattern, _ := items[1].(string)
+			channel, _ := items[2].(string)
+			payload, _ := items[3].(string)
+			return &Message{Pattern: pattern, Channel: channel, Payload: payload}, nil
+		}
+		// subscribe, psubscribe, pong, dsb.
+	}
+}
+
+// Ping menjaga koneksi tetap hidup; balasannya dibaca dan dilewati oleh Receive
+func (p *PubSub) Ping(ctx context.Context) error {
+	return p.send(ctx, []string{"PING"})
+}
+
+func (p *PubSub) Close() error {
+	return p.conn.Close()
+}
+
+func (p *PubSub) send(ctx context.Context, args []string) error {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	p.conn.SetWriteDeadline(deadline)
+	return writeCommand(p.conn, args)
+}
@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule menentukan waktu jalan berikutnya sebuah job
+type Schedule struct {
+	expr   string
+	every  time.Duration
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// Jika hari-dalam-bulan dan hari-dalam-minggu sama-sama dibatasi, salah satu cukup cocok
+	domStar bool
+	dowStar bool
+	loc     *time.Location
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse membaca ekspresi cron lima kolom (menit jam tanggal bulan hari), misalnya
+// "*/15 * * * *" atau "0 2 * * 1-5", yang ditafsirkan pada zona loc. Tersedia juga singkatan
+// @hourly, @daily, @weekly, @monthly, @yearly, serta "@every 30s" untuk interval tetap yang
+// diselaraskan ke kelipatan interval sejak epoch Unix agar semua instance sepakat soal slot.
+func Parse(expr string, loc *time.Location) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if loc == nil {
+		loc = time.UTC
+	}
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("interval %q tidak valid: %w", rest, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("interval minimal 1 detik: %s", every)
+		}
+		return &Schedule{expr: expr, every: every, loc: loc}, nil
+	}
+
+	fields := strings.Fields(expr)
+	if full, ok := descriptors[expr]; ok {
+		fields = strings.Fields(full)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("ekspresi cron harus berisi 5 kolom: %q", expr)
+	}
+	s := &Schedule{expr: expr, loc: loc}
+	var err error
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("kolom menit: %w", err)
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("kolom jam: %w", err)
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("kolom tanggal: %w", err)
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("kolom bulan: %w", err)
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("kolom hari: %w", err)
+	}
+	// 7 juga berarti Minggu
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = fields[2] == "*" || fields[2] == "?"
+	s.dowStar = fields[4] == "*" || fields[4] == "?"
+	return s, nil
+}
+
+// parseField membaca daftar dipisah koma berisi *, angka, rentang a-b, dan langkah /n
+func parseField(field string, min int, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("langkah %q tidak valid", stepPart)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			lo, hi = min, max
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("rentang %q tidak valid", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("nilai %q tidak valid", rangePart)
+			}
+			lo, hi = n, n
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("nilai %q di luar rentang %d-%d", rangePart, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next mengembalikan waktu jalan pertama setelah t, atau waktu nol jika tidak ada dalam lima
+// tahun ke depan (mis. "0 0 30 2 *")
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return time.Unix(0, 0).Add((t.Sub(time.Unix(0, 0))/s.every + 1) * s.every).In(s.loc)
+	}
+
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
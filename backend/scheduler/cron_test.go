@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Selasa, 10 Maret 2026
+	base := time.Date(2026, 3, 10, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", base, time.Date(2026, 3, 10, 10, 15, 0, 0, time.UTC)},
+		{"7 * * * *", base, time.Date(2026, 3, 10, 11, 7, 0, 0, time.UTC)},
+		{"0 2 * * 1-5", base, time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 1-5", time.Date(2026, 3, 13, 10, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 2, 0, 0, 0, time.UTC)},
+		{"30 8,17 * * *", base, time.Date(2026, 3, 10, 17, 30, 0, 0, time.UTC)},
+		{"@daily", base, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", base, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC), time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Tanggal dan hari sama-sama dibatasi: salah satu cukup cocok (Jumat, 13 Maret)
+		{"0 0 20 * 5", base, time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@every 30s", base, time.Date(2026, 3, 10, 10, 8, 0, 0, time.UTC)},
+		{"@every 30s", time.Date(2026, 3, 10, 10, 8, 0, 0, time.UTC), time.Date(2026, 3, 10, 10, 8, 30, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, ingin %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextUsesLocation(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	s, err := Parse("0 2 * * *", wib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10:07 UTC = 17:07 WIB, sehingga jadwal berikutnya pukul 02:00 WIB esok hari
+	got := s.Next(time.Date(2026, 3, 10, 10, 7, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 10, 19, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %s, ingin %s", got, want)
+	}
+}
+
+func TestScheduleNextImpossible(t *testing.T) {
+	s, err := Parse("0 0 30 2 *", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next untuk 30 Februari = %s, ingin waktu nol", got)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 500ms",
+		"@every sebentar",
+		"@sometimes",
+	} {
+		if _, err := Parse(expr, time.UTC); err == nil {
+			t.Errorf("Parse(%q) seharusnya gagal", expr)
+		}
+	}
+}
+
+func TestScheduleString(t *testing.T) {
+	s, err := Parse("  @hourly ", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "@hourly" {
+		t.Errorf("String = %q, ingin %q", s.String(), "@hourly")
+	}
+}
@@ -0,0 +1,219 @@
+// Package scheduler menjalankan job pemeliharaan berkala (menyelesaikan ujian, pengingat
+// tenggat, pembersihan akun, rotasi kunci) menurut ekspresi cron. Setiap eksekusi memegang
+// advisory lock Postgres per job sehingga job yang sama tidak berjalan bersamaan di beberapa
+// instance backend, dan setiap slot jadwal diklaim sekali sehingga instance yang terlambat
+// bangun tidak mengulang slot yang sudah dijalankan instance lain.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sistem-skripsi/backend/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownJob = errors.New("job tidak dikenal")
+	ErrJobRunning = errors.New("job sedang berjalan")
+)
+
+// Job adalah pekerjaan berkala. Run mengembalikan jumlah item yang diproses.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         func(now time.Time) (int, error)
+}
+
+// Store menyimpan kunci, klaim slot, dan riwayat eksekusi job
+type Store interface {
+	// TryJobLock mengambil kunci eksklusif job tanpa menunggu. release wajib dipanggil jika
+	// acquired bernilai true.
+	TryJobLock(ctx context.Context, job string) (release func(), acquired bool, err error)
+	// ClaimJobSlot mengembalikan false jika slot ini (atau yang lebih baru) sudah dijalankan
+	ClaimJobSlot(job string, slot time.Time) (bool, error)
+	StartJobRun(run *models.JobRun) error
+	FinishJobRun(run *models.JobRun) error
+}
+
+type entry struct {
+	job      Job
+	schedule *Schedule
+}
+
+type Scheduler struct {
+	store    Store
+	loc      *time.Location
+	instance string
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+}
+
+// New membuat scheduler yang menafsirkan ekspresi cron pada zona loc
+func New(store Store, loc *time.Location) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		store:    store,
+		loc:      loc,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		jobs:     map[string]*entry{},
+	}
+}
+
+// Add mendaftarkan job; jadwal yang tidak valid atau nama ganda ditolak
+func (s *Scheduler) Add(job Job) error {
+	schedule, err := Parse(job.Schedule, s.loc)
+	if err != nil {
+		return fmt.Errorf("jadwal job %s: %w", job.Name, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s sudah terdaftar", job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Jobs mengembalikan job terdaftar urut nama beserta waktu jalan berikutnya
+func (s *Scheduler) Jobs(now time.Time) []*models.JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*models.JobInfo, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		jobs = append(jobs, &models.JobInfo{
+			Nama:       e.job.Name,
+			Deskripsi:  e.job.Description,
+			Jadwal:     e.schedule.String(),
+			Berikutnya: e.schedule.Next(now),
+		})
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Nama < jobs[j].Nama })
+	return jobs
+}
+
+// Has melaporkan apakah job dengan nama tersebut terdaftar
+func (s *Scheduler) Has(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	return ok
+}
+
+// Start menjalankan setiap job pada slot jadwalnya sampai ctx dibatalkan
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.jobs {
+		go s.loop(ctx, e)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		slot := e.schedule.Next(time.Now())
+		if slot.IsZero() {
+			log.Printf("Job %s tidak memiliki jadwal berikutnya", e.job.Name)
+			return
+		}
+		timer := time.NewTimer(time.Until(slot))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if _, err := s.run(ctx, e, models.JobTriggerSchedule, "", &slot); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("Gagal menjalankan job %s: %v", e.job.Name, err)
+		}
+	}
+}
+
+// Trigger menjalankan job di luar jadwal atas permintaan actorID. Eksekusi berjalan di latar
+// belakang; riwayat yang dikembalikan masih berstatus berjalan.
+func (s *Scheduler) Trigger(ctx context.Context, name string, actorID string) (*models.JobRun, error) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	return s.run(ctx, e, models.JobTriggerManual, actorID, nil)
+}
+
+// run mengambil kunci job lalu menjalankannya. Eksekusi terjadwal menunggu job selesai;
+// eksekusi manual langsung kembali setelah riwayat tercatat.
+func (s *Scheduler) run(ctx context.Context, e *entry, trigger string, actorID string, slot *time.Time) (*models.JobRun, error) {
+	release, acquired, err := s.store.TryJobLock(ctx, e.job.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrJobRunning
+	}
+	if slot != nil {
+		claimed, err := s.store.ClaimJobSlot(e.job.Name, *slot)
+		if err != nil || !claimed {
+			release()
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	run := &models.JobRun{
+		Job:        e.job.Name,
+		Pemicu:     trigger,
+		DipicuOleh: actorID,
+		Jadwal:     slot,
+		Status:     models.JobRunRunning,
+		Instance:   s.instance,
+		MulaiPada:  now,
+	}
+	if err := s.store.StartJobRun(run); err != nil {
+		release()
+		return nil, err
+	}
+
+	execute := func() {
+		defer release()
+		processed, err := s.execute(e, now)
+		finished := time.Now()
+		run.SelesaiPada = &finished
+		run.DurasiMS = int(finished.Sub(now).Milliseconds())
+		run.JumlahDiproses = processed
+		run.Status = models.JobRunSucceeded
+		if err != nil {
+			run.Status = models.JobRunFailed
+			run.Galat = err.Error()
+			log.Printf("Job %s gagal: %v", e.job.Name, err)
+		} else if processed > 0 {
+			log.Printf("Job %s: %d item diproses", e.job.Name, processed)
+		}
+		if err := s.store.FinishJobRun(run); err != nil {
+			log.Printf("Gagal mencatat hasil job %s: %v", e.job.Name, err)
+		}
+	}
+	if trigger == models.JobTriggerManual {
+		started := *run
+		go execute()
+		return &started, nil
+	}
+	execute()
+	return run, nil
+}
+
+// execute menjalankan job dan mengubah panic menjadi galat agar scheduler tetap hidup
+func (s *Scheduler) execute(e *entry, now time.Time) (processed int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return e.job.Run(now)
+}
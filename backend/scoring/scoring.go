@@ -0,0 +1,58 @@
+// Package scoring menentukan nilai yang dihitung dari satu atau beberapa percobaan jawaban.
+package scoring
+
+import "sistem-skripsi/backend/deadline"
+
+// Kebijakan nilai untuk tugas yang boleh dikumpulkan ulang
+const (
+	PolicyBest    = "best"    // Nilai tertinggi
+	PolicyLatest  = "latest"  // Nilai percobaan terakhir
+	PolicyAverage = "average" // Rata-rata percobaan yang sudah dinilai
+)
+
+// Attempt menghasilkan nilai satu percobaan: nilai guru menggantikan nilai AI, lalu
+// dikurangi penalti keterlambatan. nil berarti percobaan belum dinilai.
+func Attempt(skorAI *float64, skorGuru *float64, penaltiPersen float64) *float64 {
+	score := skorAI
+	if skorGuru != nil {
+		score = skorGuru
+	}
+	if score == nil {
+		return nil
+	}
+	final := deadline.ApplyPenalty(*score, penaltiPersen)
+	return &final
+}
+
+// Counted memilih nilai yang dihitung dari percobaan yang diurutkan dari yang terlama.
+// Untuk PolicyLatest, percobaan terakhir yang belum dinilai menghasilkan nil.
+func Counted(policy string, scores []*float64) *float64 {
+	if len(scores) == 0 {
+		return nil
+	}
+	if policy == PolicyLatest {
+		return scores[len(scores)-1]
+	}
+
+	var result *float64
+	var sum float64
+	var graded int
+	for _, score := range scores {
+		if score == nil {
+			continue
+		}
+		graded++
+		sum += *score
+		if result == nil || *score > *result {
+			result = score
+		}
+	}
+	if graded == 0 {
+		return nil
+	}
+	if policy == PolicyAverage {
+		average := sum / float64(graded)
+		return &average
+	}
+	return result
+}
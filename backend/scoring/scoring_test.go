@@ -0,0 +1,68 @@
+package scoring
+
+import "testing"
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestAttempt(t *testing.T) {
+	tests := []struct {
+		name          string
+		skorAI        *float64
+		skorGuru      *float64
+		penaltiPersen float64
+		want          *float64
+	}{
+		{"belum dinilai", nil, nil, 0, nil},
+		{"nilai AI", ptr(80), nil, 0, ptr(80)},
+		{"nilai guru menggantikan AI", ptr(80), ptr(90), 0, ptr(90)},
+		{"nilai guru tanpa nilai AI", nil, ptr(70), 0, ptr(70)},
+		{"penalti keterlambatan", ptr(80), ptr(90), 10, ptr(81)},
+	}
+	for _, tt := range tests {
+		got := Attempt(tt.skorAI, tt.skorGuru, tt.penaltiPersen)
+		if !equal(got, tt.want) {
+			t.Errorf("%s: Attempt = %v, ingin %v", tt.name, format(got), format(tt.want))
+		}
+	}
+}
+
+func TestCounted(t *testing.T) {
+	scores := []*float64{ptr(60), ptr(90), nil, ptr(75)}
+	tests := []struct {
+		policy string
+		scores []*float64
+		want   *float64
+	}{
+		{PolicyBest, scores, ptr(90)},
+		{PolicyAverage, scores, ptr(75)},
+		{PolicyLatest, scores, ptr(75)},
+		// Percobaan terakhir belum dinilai
+		{PolicyLatest, []*float64{ptr(60), nil}, nil},
+		{PolicyBest, []*float64{ptr(60), nil}, ptr(60)},
+		{PolicyAverage, []*float64{nil, nil}, nil},
+		{PolicyBest, nil, nil},
+		// Kebijakan kosong diperlakukan sebagai nilai tertinggi
+		{"", scores, ptr(90)},
+	}
+	for _, tt := range tests {
+		if got := Counted(tt.policy, tt.scores); !equal(got, tt.want) {
+			t.Errorf("Counted(%q, %d percobaan) = %v, ingin %v", tt.policy, len(tt.scores), format(got), format(tt.want))
+		}
+	}
+}
+
+func equal(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func format(v *float64) any {
+	if v == nil {
+		return "nil"
+	}
+	return *v
+}
@@ -0,0 +1,149 @@
+// Package similarity membuat sidik jari teks (MinHash dan SimHash atas shingle kata) untuk
+// mendeteksi jawaban yang hampir sama dan salinan langsung dari teks materi.
+package similarity
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	ShingleSize  = 3   // Jumlah kata per shingle
+	MinHashSize  = 128 // Panjang signature MinHash
+	MinShingles  = 5   // Jawaban yang lebih pendek tidak dibandingkan (terlalu banyak positif palsu)
+	minHashSeed  = 0x9e3779b97f4a7c15
+	simHashWidth = 64
+)
+
+// Ambang yang dipakai untuk menandai jawaban
+type Config struct {
+	MinHashThreshold   float64 // Estimasi Jaccard minimum antar jawaban
+	SimHashMaxDistance int     // Jarak Hamming maksimum SimHash antar jawaban
+	MaterialThreshold  float64 // Proporsi shingle jawaban yang ada di teks materi
+}
+
+var DefaultConfig = Config{
+	MinHashThreshold:   0.7,
+	SimHashMaxDistance: 3,
+	MaterialThreshold:  0.5,
+}
+
+// Fingerprint adalah sidik jari sebuah teks
+type Fingerprint struct {
+	MinHash  []uint64
+	SimHash  uint64
+	Shingles int
+}
+
+// Words menormalkan teks menjadi kata huruf kecil tanpa tanda baca
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Shingles mengembalikan himpunan hash shingle kata. Teks yang lebih pendek dari satu
+// shingle menghasilkan satu shingle berisi seluruh kata.
+func Shingles(text string) map[uint64]struct{} {
+	words := Words(text)
+	set := map[uint64]struct{}{}
+	if len(words) == 0 {
+		return set
+	}
+	if len(words) < ShingleSize {
+		set[hashString(strings.Join(words, " "))] = struct{}{}
+		return set
+	}
+	for i := 0; i+ShingleSize <= len(words); i++ {
+		set[hashString(strings.Join(words[i:i+ShingleSize], " "))] = struct{}{}
+	}
+	return set
+}
+
+// Compute membuat sidik jari MinHash dan SimHash dari teks
+func Compute(text string) Fingerprint {
+	shingles := Shingles(text)
+	fp := Fingerprint{MinHash: make([]uint64, MinHashSize), Shingles: len(shingles)}
+	for i := range fp.MinHash {
+		fp.MinHash[i] = ^uint64(0)
+	}
+
+	var weights [simHashWidth]int
+	for shingle := range shingles {
+		for i := range fp.MinHash {
+			if h := mix(shingle ^ (minHashSeed * uint64(i+1))); h < fp.MinHash[i] {
+				fp.MinHash[i] = h
+			}
+		}
+		for bit := 0; bit < simHashWidth; bit++ {
+			if shingle&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	for bit, weight := range weights {
+		if weight > 0 {
+			fp.SimHash |= 1 << bit
+		}
+	}
+	return fp
+}
+
+// Comparable melaporkan apakah teks cukup panjang untuk dibandingkan
+func (f Fingerprint) Comparable() bool {
+	return f.Shingles >= MinShingles && len(f.MinHash) == MinHashSize
+}
+
+// Jaccard mengestimasi kemiripan Jaccard dari dua signature MinHash
+func Jaccard(a, b []uint64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+// HammingDistance menghitung jumlah bit yang berbeda antara dua SimHash
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Containment menghitung proporsi shingle teks yang juga ada di referensi, yaitu seberapa
+// banyak jawaban disalin dari teks referensi (mis. isi materi)
+func Containment(text map[uint64]struct{}, reference map[uint64]struct{}) float64 {
+	if len(text) == 0 {
+		return 0
+	}
+	found := 0
+	for shingle := range text {
+		if _, ok := reference[shingle]; ok {
+			found++
+		}
+	}
+	return float64(found) / float64(len(text))
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix adalah finalizer splitmix64 untuk menurunkan fungsi hash MinHash yang berbeda
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,97 @@
+package similarity
+
+import "testing"
+
+const answer = "Fotosintesis adalah proses tumbuhan hijau mengubah energi cahaya matahari menjadi energi kimia dalam bentuk glukosa dengan bantuan klorofil"
+
+func TestWordsNormalizesCaseAndPunctuation(t *testing.T) {
+	got := Words("Air, CO2 & cahaya-matahari!")
+	want := []string{"air", "co2", "cahaya", "matahari"}
+	if len(got) != len(want) {
+		t.Fatalf("Words = %q, ingin %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Words = %q, ingin %q", got, want)
+		}
+	}
+}
+
+func TestShingles(t *testing.T) {
+	if n := len(Shingles("")); n != 0 {
+		t.Errorf("teks kosong menghasilkan %d shingle, ingin 0", n)
+	}
+	if n := len(Shingles("dua kata")); n != 1 {
+		t.Errorf("teks pendek menghasilkan %d shingle, ingin 1", n)
+	}
+	// 5 kata -> 3 shingle berukuran 3
+	if n := len(Shingles("satu dua tiga empat lima")); n != 3 {
+		t.Errorf("lima kata menghasilkan %d shingle, ingin 3", n)
+	}
+	// Shingle berulang hanya dihitung sekali
+	if n := len(Shingles("a b c a b c")); n != 3 {
+		t.Errorf("shingle berulang menghasilkan %d shingle, ingin 3", n)
+	}
+}
+
+func TestComputeIdenticalTexts(t *testing.T) {
+	a, b := Compute(answer), Compute("  "+answer+"!!")
+	if !a.Comparable() {
+		t.Fatalf("jawaban %d shingle seharusnya dapat dibandingkan", a.Shingles)
+	}
+	if j := Jaccard(a.MinHash, b.MinHash); j != 1 {
+		t.Errorf("Jaccard teks sama = %v, ingin 1", j)
+	}
+	if d := HammingDistance(a.SimHash, b.SimHash); d != 0 {
+		t.Errorf("jarak SimHash teks sama = %d, ingin 0", d)
+	}
+}
+
+func TestComputeNearDuplicateScoresHigherThanUnrelated(t *testing.T) {
+	base := Compute(answer)
+	near := Compute(answer + " di dalam kloroplas")
+	unrelated := Compute("Perang Diponegoro berlangsung dari tahun 1825 sampai 1830 di tanah Jawa melawan pemerintah kolonial Belanda")
+
+	nearScore := Jaccard(base.MinHash, near.MinHash)
+	unrelatedScore := Jaccard(base.MinHash, unrelated.MinHash)
+	if nearScore < DefaultConfig.MinHashThreshold {
+		t.Errorf("Jaccard jawaban hampir sama = %v, ingin >= %v", nearScore, DefaultConfig.MinHashThreshold)
+	}
+	if unrelatedScore >= nearScore {
+		t.Errorf("Jaccard jawaban berbeda (%v) tidak lebih kecil dari jawaban hampir sama (%v)", unrelatedScore, nearScore)
+	}
+	if HammingDistance(base.SimHash, near.SimHash) >= HammingDistance(base.SimHash, unrelated.SimHash) {
+		t.Error("SimHash jawaban hampir sama tidak lebih dekat daripada jawaban berbeda")
+	}
+}
+
+func TestComparableRequiresMinimumShingles(t *testing.T) {
+	if Compute("jawaban singkat saja").Comparable() {
+		t.Error("jawaban pendek seharusnya tidak dibandingkan")
+	}
+}
+
+func TestJaccardMismatchedSignatures(t *testing.T) {
+	if j := Jaccard([]uint64{1, 2}, []uint64{1}); j != 0 {
+		t.Errorf("Jaccard signature berbeda panjang = %v, ingin 0", j)
+	}
+	if j := Jaccard(nil, nil); j != 0 {
+		t.Errorf("Jaccard signature kosong = %v, ingin 0", j)
+	}
+	if j := Jaccard([]uint64{1, 2, 3, 4}, []uint64{1, 2, 0, 0}); j != 0.5 {
+		t.Errorf("Jaccard = %v, ingin 0.5", j)
+	}
+}
+
+func TestContainment(t *testing.T) {
+	material := Shingles(answer + ". Reaksi terang terjadi di membran tilakoid.")
+	if c := Containment(Shingles(answer), material); c != 1 {
+		t.Errorf("containment salinan materi = %v, ingin 1", c)
+	}
+	if c := Containment(Shingles("siswa menulis jawaban dengan kata-katanya sendiri"), material); c != 0 {
+		t.Errorf("containment jawaban asli = %v, ingin 0", c)
+	}
+	if c := Containment(map[uint64]struct{}{}, material); c != 0 {
+		t.Errorf("containment teks kosong = %v, ingin 0", c)
+	}
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk kesepakatan skor AI dan guru ---
+
+// Sumber pasangan skor: hasil AI yang berhasil dan sudah ditinjau guru
+const agreementFrom = `FROM ai_results ar
+              JOIN teacher_reviews tr ON tr.submission_id = ar.submission_id
+              JOIN essay_submissions es ON es.id = ar.submission_id
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              LEFT JOIN prompt_templates pt ON pt.id = ar.prompt_template_id`
+
+const agreementWhere = `ar.status = 'berhasil' AND ar.skor_ai IS NOT NULL
+              AND ($1 = '' OR m.kelas_id::text = $1)
+              AND ($2 = '' OR es.assignment_id::text = $2)`
+
+// GetAgreementPairs mengambil skor AI dan skor final guru untuk setiap jawaban yang sudah ditinjau
+func (s *PostgresStore) GetAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error) {
+	query := `SELECT es.id, q.id, q.teks_soal, COALESCE(q.level_kognitif::text, ''), COALESCE(pt.versi, 0),
+                     ar.skor_ai, tr.skor_final
+              ` + agreementFrom + `
+              WHERE ` + agreementWhere + `
+              ORDER BY es.submitted_at`
+	rows, err := s.db.Query(query, filter.KelasID, filter.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := []*models.AgreementPair{}
+	for rows.Next() {
+		var p models.AgreementPair
+		if err := rows.Scan(&p.SubmissionID, &p.SoalID, &p.TeksSoal, &p.LevelKognitif, &p.PromptVersi, &p.SkorAI, &p.SkorGuru); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, &p)
+	}
+	return pairs, rows.Err()
+}
+
+// GetAspectAgreementPairs mengambil skor AI dan skor guru per aspek rubrik. Hanya aspek
+// yang dinilai oleh keduanya yang disertakan. Skor aspek AI mengikuti rentang rubrik
+// sehingga diubah ke skala 0-100 seperti skor aspek guru.
+func (s *PostgresStore) GetAspectAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error) {
+	query := `SELECT es.id, q.id, q.teks_soal, COALESCE(q.level_kognitif::text, ''), COALESCE(pt.versi, 0),
+                     rb.id, rb.nama_aspek,
+                     (aas.skor - COALESCE(rb.skor_min, 0)) / (COALESCE(rb.skor_maks, 100) - COALESCE(rb.skor_min, 0)) * 100,
+                     tas.skor
+              ` + agreementFrom + `
+              JOIN ai_aspect_scores aas ON aas.ai_result_id = ar.id
+              JOIN teacher_aspect_scores tas ON tas.review_id = tr.id AND tas.rubric_id = aas.rubric_id
+              JOIN rubrics rb ON rb.id = tas.rubric_id
+              WHERE ` + agreementWhere + `
+              ORDER BY es.submitted_at, rb.nama_aspek`
+	rows, err := s.db.Query(query, filter.KelasID, filter.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := []*models.AgreementPair{}
+	for rows.Next() {
+		var p models.AgreementPair
+		if err := rows.Scan(&p.SubmissionID, &p.SoalID, &p.TeksSoal, &p.LevelKognitif, &p.PromptVersi,
+			&p.RubricID, &p.NamaAspek, &p.SkorAI, &p.SkorGuru); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, &p)
+	}
+	return pairs, rows.Err()
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk AI Result ---
+
+// SaveAIResult menyimpan (atau menimpa) hasil penilaian AI untuk sebuah jawaban beserta skor per aspeknya
+func (s *PostgresStore) SaveAIResult(result *models.AIResult) error {
+	if result.ChunkIDs == nil {
+		result.ChunkIDs = []string{}
+	}
+	if result.Status == "" {
+		result.Status = models.AIResultSucceeded
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO ai_results (submission_id, status, skor_ai, umpan_balik_ai, logs_rag, material_version_id, chunk_ids, prompt_template_id)
+              VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7::uuid[], NULLIF($8, '')::uuid)
+              ON CONFLICT (submission_id) DO UPDATE SET
+                status = EXCLUDED.status,
+                skor_ai = EXCLUDED.skor_ai,
+                umpan_balik_ai = EXCLUDED.umpan_balik_ai,
+                logs_rag = EXCLUDED.logs_rag,
+                material_version_id = EXCLUDED.material_version_id,
+                chunk_ids = EXCLUDED.chunk_ids,
+                prompt_template_id = EXCLUDED.prompt_template_id,
+                generated_at = NOW()
+              RETURNING id, generated_at`
+	err = tx.QueryRow(
+		query,
+		result.SubmissionID,
+		result.Status,
+		result.SkorAI,
+		result.UmpanBalikAI,
+		result.LogsRAG,
+		result.MaterialVersionID,
+		pq.Array(result.ChunkIDs),
+		result.PromptTemplateID,
+	).Scan(&result.ID, &result.GeneratedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM ai_aspect_scores WHERE ai_result_id = $1`, result.ID); err != nil {
+		return err
+	}
+	for _, aspect := range result.Aspek {
+		if aspect.ChunkIDs == nil {
+			aspect.ChunkIDs = []string{}
+		}
+		_, err := tx.Exec(
+			`INSERT INTO ai_aspect_scores (ai_result_id, rubric_id, nama_aspek, skor, alasan, chunk_ids)
+             VALUES ($1, NULLIF($2, '')::uuid, $3, $4, NULLIF($5, ''), $6::uuid[])`,
+			result.ID, aspect.RubricID, aspect.NamaAspek, aspect.Skor, aspect.Alasan, pq.Array(aspect.ChunkIDs),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetGradingContext mengembalikan hasil AI beserta versi materi dan chunk yang dipakai saat menilai
+func (s *PostgresStore) GetGradingContext(submissionID string) (*models.GradingContext, error) {
+	var result models.AIResult
+	var skor sql.NullFloat64
+	var chunkIDs pq.StringArray
+	var promptVersi sql.NullInt64
+	query := `SELECT ar.id, ar.submission_id, ar.status::text, ar.skor_ai, COALESCE(ar.umpan_balik_ai, ''), COALESCE(ar.logs_rag, ''),
+                     COALESCE(ar.material_version_id::text, ''), ar.chunk_ids::text[],
+                     COALESCE(ar.prompt_template_id::text, ''), pt.versi, ar.generated_at
+              FROM ai_results ar
+              LEFT JOIN prompt_templates pt ON pt.id = ar.prompt_template_id
+              WHERE ar.submission_id = $1`
+	err := s.db.QueryRow(query, submissionID).Scan(
+		&result.ID, &result.SubmissionID, &result.Status, &skor, &result.UmpanBalikAI, &result.LogsRAG,
+		&result.MaterialVersionID, &chunkIDs, &result.PromptTemplateID, &promptVersi, &result.GeneratedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if skor.Valid {
+		result.SkorAI = &skor.Float64
+	}
+	result.ChunkIDs = []string(chunkIDs)
+	result.PromptVersi = int(promptVersi.Int64)
+	result.Aspek, err = s.getAIAspectScores(result.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	context := &models.GradingContext{AIResult: &result}
+	if result.MaterialVersionID == "" {
+		return context, nil
+	}
+
+	query = `SELECT ` + materialVersionColumns + ` FROM material_versions WHERE id = $1`
+	version, err := scanMaterialVersion(s.db.QueryRow(query, result.MaterialVersionID))
+	if err != nil {
+		return nil, err
+	}
+	version.Chunks, err = s.getVersionChunks(version.ID, result.ChunkIDs)
+	if err != nil {
+		return nil, err
+	}
+	context.MaterialVersion = version
+	return context, nil
+}
+
+func (s *PostgresStore) getAIAspectScores(resultID string) ([]*models.AIAspectScore, error) {
+	rows, err := s.db.Query(
+		`SELECT COALESCE(rubric_id::text, ''), nama_aspek, skor, COALESCE(alasan, ''), chunk_ids::text[]
+         FROM ai_aspect_scores WHERE ai_result_id = $1 ORDER BY nama_aspek`,
+		resultID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aspects := []*models.AIAspectScore{}
+	for rows.Next() {
+		var a models.AIAspectScore
+		var chunkIDs pq.StringArray
+		if err := rows.Scan(&a.RubricID, &a.NamaAspek, &a.Skor, &a.Alasan, &chunkIDs); err != nil {
+			return nil, err
+		}
+		a.ChunkIDs = []string(chunkIDs)
+		aspects = append(aspects, &a)
+	}
+	return aspects, rows.Err()
+}
+
+// Jawaban yang belum memiliki hasil AI. Soal tanpa level kognitif dilewati karena tidak
+// memiliki template prompt; jawaban yang gagal dinilai dilewati sampai jeda percobaannya
+// habis, dan jawaban yang lebih jarang gagal didahulukan.
+func (s *PostgresStore) GetUngradedSubmissions(limit int, now time.Time) ([]*models.Submission, error) {
+	query := `SELECT es.id, es.soal_id, es.siswa_id, COALESCE(es.assignment_id::text, ''), es.teks_jawaban, es.submitted_at
+              FROM essay_submissions es
+              JOIN essay_questions q ON q.id = es.soal_id
+              LEFT JOIN grading_attempts ga ON ga.submission_id = es.id
+              WHERE q.level_kognitif IS NOT NULL
+                AND NOT EXISTS (SELECT 1 FROM ai_results ar WHERE ar.submission_id = es.id)
+                AND (ga.next_attempt_at IS NULL OR ga.next_attempt_at <= $2)
+              ORDER BY COALESCE(ga.percobaan, 0), es.submitted_at
+              LIMIT $1`
+	rows, err := s.db.Query(query, limit, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []*models.Submission{}
+	for rows.Next() {
+		var sub models.Submission
+		if err := rows.Scan(&sub.ID, &sub.SoalID, &sub.SiswaID, &sub.AssignmentID, &sub.TeksJawaban, &sub.SubmittedAt); err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, &sub)
+	}
+	return submissions, rows.Err()
+}
+
+// Mencatat penilaian otomatis yang gagal. Jeda sebelum percobaan berikutnya berlipat dua
+// setiap kegagalan, mulai dari base dan paling lama maxDelay. Mengembalikan jumlah percobaan.
+func (s *PostgresStore) RecordGradingFailure(submissionID string, galat string, now time.Time, base time.Duration, maxDelay time.Duration) (int, error) {
+	query := `INSERT INTO grading_attempts (submission_id, percobaan, galat_terakhir, next_attempt_at, updated_at)
+              VALUES ($1, 1, $2, $3 + make_interval(secs => $4), $3)
+              ON CONFLICT (submission_id) DO UPDATE SET
+                  percobaan = grading_attempts.percobaan + 1,
+                  galat_terakhir = EXCLUDED.galat_terakhir,
+                  next_attempt_at = $3 + make_interval(secs => LEAST($4 * POWER(2, LEAST(grading_attempts.percobaan, 20)), $5)),
+                  updated_at = $3
+              RETURNING percobaan`
+	var attempts int
+	err := s.db.QueryRow(query, submissionID, galat, now, base.Seconds(), maxDelay.Seconds()).Scan(&attempts)
+	return attempts, err
+}
+
+// Kelas tempat sebuah jawaban berada, untuk pemeriksaan hak akses
+func (s *PostgresStore) GetSubmissionClassID(submissionID string) (string, error) {
+	query := `SELECT m.kelas_id FROM essay_submissions es
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              WHERE es.id = $1`
+	var classID string
+	err := s.db.QueryRow(query, submissionID).Scan(&classID)
+	return classID, err
+}
+
+// GetSubmissionWithClass mengambil data ringkas jawaban beserta kelasnya, mis. untuk payload peristiwa
+func (s *PostgresStore) GetSubmissionWithClass(submissionID string) (*models.Submission, string, error) {
+	query := `SELECT es.id, es.soal_id, es.siswa_id, COALESCE(es.assignment_id::text, ''), es.percobaan_ke,
+                     es.hari_terlambat, es.submitted_at, m.kelas_id
+              FROM essay_submissions es
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              WHERE es.id = $1`
+	var sub models.Submission
+	var classID string
+	err := s.db.QueryRow(query, submissionID).Scan(
+		&sub.ID, &sub.SoalID, &sub.SiswaID, &sub.AssignmentID, &sub.PercobaanKe, &sub.HariTerlambat, &sub.SubmittedAt, &classID,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+	return &sub, classID, nil
+}
+
+// GetGradingInput mengumpulkan jawaban, soal beserta rubrik, dan versi terkini materinya
+func (s *PostgresStore) GetGradingInput(submissionID string) (*models.GradingInput, error) {
+	var sub models.Submission
+	var materiID string
+	var versi int
+	query := `SELECT es.id, es.soal_id, es.siswa_id, COALESCE(es.assignment_id::text, ''), es.teks_jawaban,
+                     es.percobaan_ke, es.submitted_at, m.id, m.versi_terkini
+              FROM essay_submissions es
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              WHERE es.id = $1`
+	err := s.db.QueryRow(query, submissionID).Scan(
+		&sub.ID, &sub.SoalID, &sub.SiswaID, &sub.AssignmentID, &sub.TeksJawaban, &sub.PercobaanKe, &sub.SubmittedAt,
+		&materiID, &versi,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.queryQuestions(`SELECT `+questionColumns+` FROM essay_questions q WHERE q.id = $1`, sub.SoalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	version, err := s.GetMaterialVersion(materiID, versi)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GradingInput{Submission: &sub, Question: questions[0], MaterialVersion: version}, nil
+}
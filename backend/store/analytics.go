@@ -0,0 +1,142 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk analitik penguasaan level kognitif ---
+
+// RefreshMasteryRollup memperbarui materialized view rekap penguasaan tanpa mengunci pembaca
+func (s *PostgresStore) RefreshMasteryRollup() error {
+	if _, err := s.db.Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY mastery_rollup`); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO analytics_refreshes (nama, refreshed_at) VALUES ('mastery_rollup', NOW())
+         ON CONFLICT (nama) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at`,
+	)
+	return err
+}
+
+// GetClassMastery menghitung rata-rata per level untuk kelas dan setiap siswa. siswaID
+// mempersempit hasil ke satu siswa. Siswa tuntas jika rata-ratanya minimal batasTuntas.
+func (s *PostgresStore) GetClassMastery(classID string, siswaID string, batasTuntas float64) (*models.ClassMastery, error) {
+	mastery := &models.ClassMastery{BatasTuntas: batasTuntas, Kelas: []*models.MasteryLevel{}, Siswa: []*models.StudentMastery{}}
+	err := s.db.QueryRow(`SELECT refreshed_at FROM analytics_refreshes WHERE nama = 'mastery_rollup'`).Scan(&mastery.DiperbaruiPada)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT r.siswa_id, u.nama_lengkap, r.level_kognitif::text, AVG(r.skor), COUNT(*)
+         FROM mastery_rollup r
+         JOIN users u ON u.id = r.siswa_id
+         WHERE r.kelas_id = $1 AND ($2 = '' OR r.siswa_id::text = $2)
+         GROUP BY r.siswa_id, u.nama_lengkap, r.level_kognitif
+         ORDER BY u.nama_lengkap, r.siswa_id, r.level_kognitif`,
+		classID, siswaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Rekap kelas dihitung dari rekap siswa agar tidak perlu query kedua
+	classLevels := map[string]*models.MasteryLevel{}
+	classTotals := map[string]float64{}
+	var current *models.StudentMastery
+	for rows.Next() {
+		var id, nama string
+		var level models.MasteryLevel
+		if err := rows.Scan(&id, &nama, &level.LevelKognitif, &level.RataRata, &level.JumlahJawaban); err != nil {
+			return nil, err
+		}
+		if current == nil || current.SiswaID != id {
+			current = &models.StudentMastery{SiswaID: id, NamaSiswa: nama}
+			mastery.Siswa = append(mastery.Siswa, current)
+		}
+		current.Level = append(current.Level, &level)
+
+		summary, ok := classLevels[level.LevelKognitif]
+		if !ok {
+			summary = &models.MasteryLevel{LevelKognitif: level.LevelKognitif}
+			classLevels[level.LevelKognitif] = summary
+		}
+		summary.JumlahJawaban += level.JumlahJawaban
+		summary.JumlahSiswa++
+		if level.RataRata >= batasTuntas {
+			summary.SiswaTuntas++
+		}
+		classTotals[level.LevelKognitif] += level.RataRata * float64(level.JumlahJawaban)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, level := range []string{"C1", "C2", "C3", "C4"} {
+		if summary, ok := classLevels[level]; ok {
+			summary.RataRata = classTotals[level] / float64(summary.JumlahJawaban)
+			mastery.Kelas = append(mastery.Kelas, summary)
+		}
+	}
+	return mastery, nil
+}
+
+// GetMasteryTrend mengembalikan rata-rata per level untuk setiap minggu atau bulan (interval).
+// Batas periode dihitung pada zona waktu zone (nama IANA).
+func (s *PostgresStore) GetMasteryTrend(classID string, siswaID string, interval string, zone string) ([]*models.MasteryTrendPoint, error) {
+	rows, err := s.db.Query(
+		`SELECT to_char(date_trunc($3, submitted_at AT TIME ZONE $4), 'YYYY-MM-DD') AS periode, level_kognitif::text,
+                AVG(skor), COUNT(*)
+         FROM mastery_rollup
+         WHERE kelas_id = $1 AND ($2 = '' OR siswa_id::text = $2)
+         GROUP BY periode, level_kognitif
+         ORDER BY periode, level_kognitif`,
+		classID, siswaID, interval, zone,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []*models.MasteryTrendPoint{}
+	for rows.Next() {
+		var p models.MasteryTrendPoint
+		if err := rows.Scan(&p.Periode, &p.LevelKognitif, &p.RataRata, &p.JumlahJawaban); err != nil {
+			return nil, err
+		}
+		points = append(points, &p)
+	}
+	return points, rows.Err()
+}
+
+// GetMasteryHistogram menghitung sebaran nilai per level dalam 10 rentang selebar 10 poin.
+// Rentang tanpa jawaban tetap dikembalikan dengan jumlah 0.
+func (s *PostgresStore) GetMasteryHistogram(classID string, level string) ([]*models.HistogramBucket, error) {
+	rows, err := s.db.Query(
+		`SELECT l.level::text, b.rentang, COUNT(r.soal_id)
+         FROM unnest(enum_range(NULL::cognitive_level)) AS l(level)
+         CROSS JOIN generate_series(1, 10) AS b(rentang)
+         LEFT JOIN mastery_rollup r ON r.kelas_id = $1 AND r.level_kognitif = l.level AND r.rentang = b.rentang
+         WHERE $2 = '' OR l.level::text = $2
+         GROUP BY l.level, b.rentang
+         ORDER BY l.level, b.rentang`,
+		classID, level,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []*models.HistogramBucket{}
+	for rows.Next() {
+		var b models.HistogramBucket
+		var rentang int
+		if err := rows.Scan(&b.LevelKognitif, &rentang, &b.Jumlah); err != nil {
+			return nil, err
+		}
+		b.Dari, b.Sampai = (rentang-1)*10, rentang*10
+		buckets = append(buckets, &b)
+	}
+	return buckets, rows.Err()
+}
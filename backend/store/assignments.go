@@ -0,0 +1,327 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk Assignment ---
+
+// Kolom tugas yang dipilih dengan alias tabel "a"
+const assignmentColumns = `a.id, a.kelas_id, a.judul, COALESCE(a.deskripsi, ''), a.dibuka_pada, a.ditutup_pada,
+                           a.batas_terlambat, a.penalti_per_hari, a.zona_waktu, a.maks_percobaan, a.kebijakan_nilai,
+                           a.mode_ujian, COALESCE(a.durasi_menit, 0),
+                           COALESCE((SELECT array_agg(aq.soal_id::text ORDER BY aq.urutan)
+                                     FROM assignment_questions aq WHERE aq.assignment_id = a.id), '{}'),
+                           COALESCE(a.dibuat_oleh::text, ''), a.created_at, COALESCE(a.updated_at::text, '')`
+
+type assignmentScan struct {
+	a              models.Assignment
+	batasTerlambat sql.NullTime
+	soalIDs        pq.StringArray
+}
+
+func (t *assignmentScan) targets() []any {
+	return []any{
+		&t.a.ID, &t.a.KelasID, &t.a.Judul, &t.a.Deskripsi, &t.a.DibukaPada, &t.a.DitutupPada,
+		&t.batasTerlambat, &t.a.PenaltiPerHari, &t.a.ZonaWaktu, &t.a.MaksPercobaan, &t.a.KebijakanNilai,
+		&t.a.ModeUjian, &t.a.DurasiMenit,
+		&t.soalIDs,
+		&t.a.DibuatOleh, &t.a.CreatedAt, &t.a.UpdatedAt,
+	}
+}
+
+func (t *assignmentScan) result() *models.Assignment {
+	a := t.a
+	if t.batasTerlambat.Valid {
+		a.BatasTerlambat = &t.batasTerlambat.Time
+	}
+	a.SoalIDs = []string(t.soalIDs)
+	return &a
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func (s *PostgresStore) CreateAssignment(assignment *models.Assignment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO assignments (kelas_id, judul, deskripsi, dibuka_pada, ditutup_pada, batas_terlambat,
+                                       penalti_per_hari, zona_waktu, maks_percobaan, kebijakan_nilai, mode_ujian,
+                                       durasi_menit, dibuat_oleh)
+              VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, 0), $13)
+              RETURNING id, created_at, updated_at`
+	err = tx.QueryRow(
+		query,
+		assignment.KelasID,
+		assignment.Judul,
+		assignment.Deskripsi,
+		assignment.DibukaPada,
+		assignment.DitutupPada,
+		nullTime(assignment.BatasTerlambat),
+		assignment.PenaltiPerHari,
+		assignment.ZonaWaktu,
+		assignment.MaksPercobaan,
+		assignment.KebijakanNilai,
+		assignment.ModeUjian,
+		assignment.DurasiMenit,
+		assignment.DibuatOleh,
+	).Scan(&assignment.ID, &assignment.CreatedAt, &assignment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := setAssignmentQuestions(tx, assignment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setAssignmentQuestions mengganti daftar soal tugas. Semua soal harus berasal dari materi
+// di kelas yang sama; jika tidak, ErrQuestionNotInClass dikembalikan.
+func setAssignmentQuestions(tx *sql.Tx, assignment *models.Assignment) error {
+	seen := map[string]bool{}
+	var ids []string
+	for _, id := range assignment.SoalIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	assignment.SoalIDs = ids
+
+	if _, err := tx.Exec(`DELETE FROM assignment_questions WHERE assignment_id = $1`, assignment.ID); err != nil {
+		return err
+	}
+	result, err := tx.Exec(
+		`INSERT INTO assignment_questions (assignment_id, soal_id, urutan)
+         SELECT $1, q.id, t.urutan
+         FROM unnest($3::uuid[]) WITH ORDINALITY AS t(soal_id, urutan)
+         JOIN essay_questions q ON q.id = t.soal_id
+         JOIN materials m ON m.id = q.materi_id AND m.kelas_id = $2`,
+		assignment.ID, assignment.KelasID, pq.Array(ids),
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if int(n) != len(ids) {
+		return ErrQuestionNotInClass
+	}
+	return nil
+}
+
+func (s *PostgresStore) GetAssignmentByID(id string) (*models.Assignment, error) {
+	var t assignmentScan
+	query := `SELECT ` + assignmentColumns + ` FROM assignments a WHERE a.id = $1`
+	if err := s.db.QueryRow(query, id).Scan(t.targets()...); err != nil {
+		return nil, err
+	}
+	return t.result(), nil
+}
+
+func (s *PostgresStore) GetAssignmentsByClassID(classID string) ([]*models.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + ` FROM assignments a WHERE a.kelas_id = $1 ORDER BY a.ditutup_pada DESC`
+	rows, err := s.db.Query(query, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*models.Assignment{}
+	for rows.Next() {
+		var t assignmentScan
+		if err := rows.Scan(t.targets()...); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, t.result())
+	}
+	return assignments, rows.Err()
+}
+
+// Mengganti seluruh data tugas beserta daftar soalnya
+func (s *PostgresStore) UpdateAssignment(assignment *models.Assignment) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE assignments SET
+                judul = $2, deskripsi = NULLIF($3, ''), dibuka_pada = $4, ditutup_pada = $5,
+                batas_terlambat = $6, penalti_per_hari = $7, zona_waktu = $8, maks_percobaan = $9,
+                kebijakan_nilai = $10, mode_ujian = $11, durasi_menit = NULLIF($12, 0), updated_at = NOW()
+              WHERE id = $1
+              RETURNING kelas_id, COALESCE(dibuat_oleh::text, ''), created_at, updated_at`
+	err = tx.QueryRow(
+		query,
+		assignment.ID,
+		assignment.Judul,
+		assignment.Deskripsi,
+		assignment.DibukaPada,
+		assignment.DitutupPada,
+		nullTime(assignment.BatasTerlambat),
+		assignment.PenaltiPerHari,
+		assignment.ZonaWaktu,
+		assignment.MaksPercobaan,
+		assignment.KebijakanNilai,
+		assignment.ModeUjian,
+		assignment.DurasiMenit,
+	).Scan(&assignment.KelasID, &assignment.DibuatOleh, &assignment.CreatedAt, &assignment.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := setAssignmentQuestions(tx, assignment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) DeleteAssignment(id string) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM assignments WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// --- Perpanjangan tenggat ---
+
+// Membuat atau mengganti perpanjangan tenggat seorang siswa
+func (s *PostgresStore) SetAssignmentExtension(extension *models.AssignmentExtension) error {
+	query := `INSERT INTO assignment_extensions (assignment_id, siswa_id, ditutup_pada, batas_terlambat, alasan, diberikan_oleh)
+              VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+              ON CONFLICT (assignment_id, siswa_id) DO UPDATE SET
+                ditutup_pada = EXCLUDED.ditutup_pada,
+                batas_terlambat = EXCLUDED.batas_terlambat,
+                alasan = EXCLUDED.alasan,
+                diberikan_oleh = EXCLUDED.diberikan_oleh,
+                created_at = NOW()
+              RETURNING id, created_at`
+	return s.db.QueryRow(
+		query,
+		extension.AssignmentID,
+		extension.SiswaID,
+		extension.DitutupPada,
+		nullTime(extension.BatasTerlambat),
+		extension.Alasan,
+		extension.DiberikanOleh,
+	).Scan(&extension.ID, &extension.CreatedAt)
+}
+
+const extensionColumns = `e.id, e.assignment_id, e.siswa_id, u.nama_lengkap, e.ditutup_pada, e.batas_terlambat,
+                          COALESCE(e.alasan, ''), COALESCE(e.diberikan_oleh::text, ''), e.created_at`
+
+func scanExtension(row rowScanner) (*models.AssignmentExtension, error) {
+	var e models.AssignmentExtension
+	var batasTerlambat sql.NullTime
+	err := row.Scan(&e.ID, &e.AssignmentID, &e.SiswaID, &e.NamaSiswa, &e.DitutupPada, &batasTerlambat, &e.Alasan, &e.DiberikanOleh, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if batasTerlambat.Valid {
+		e.BatasTerlambat = &batasTerlambat.Time
+	}
+	return &e, nil
+}
+
+func (s *PostgresStore) GetAssignmentExtension(assignmentID string, siswaID string) (*models.AssignmentExtension, error) {
+	query := `SELECT ` + extensionColumns + ` FROM assignment_extensions e JOIN users u ON u.id = e.siswa_id
+              WHERE e.assignment_id = $1 AND e.siswa_id = $2`
+	return scanExtension(s.db.QueryRow(query, assignmentID, siswaID))
+}
+
+func (s *PostgresStore) GetAssignmentExtensions(assignmentID string) ([]*models.AssignmentExtension, error) {
+	query := `SELECT ` + extensionColumns + ` FROM assignment_extensions e JOIN users u ON u.id = e.siswa_id
+              WHERE e.assignment_id = $1 ORDER BY u.nama_lengkap`
+	rows, err := s.db.Query(query, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	extensions := []*models.AssignmentExtension{}
+	for rows.Next() {
+		e, err := scanExtension(rows)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, e)
+	}
+	return extensions, rows.Err()
+}
+
+func (s *PostgresStore) DeleteAssignmentExtension(assignmentID string, siswaID string) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM assignment_extensions WHERE assignment_id = $1 AND siswa_id = $2`, assignmentID, siswaID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// --- Tampilan siswa ---
+
+// Tugas di kelas aktif yang diikuti siswa dan masih dapat dikerjakan (termasuk masa
+// terlambat) pada waktu now, diurutkan dari tenggat terdekat. Perpanjangan ikut dimuat.
+func (s *PostgresStore) GetUpcomingAssignments(siswaID string, now time.Time) ([]*models.Assignment, error) {
+	query := `SELECT ` + assignmentColumns + `, c.nama_kelas,
+                     e.id, e.ditutup_pada, e.batas_terlambat, COALESCE(e.alasan, '')
+              FROM assignments a
+              JOIN class_members cm ON cm.kelas_id = a.kelas_id AND cm.siswa_id = $1
+              JOIN classes c ON c.id = a.kelas_id AND c.archived_at IS NULL
+              LEFT JOIN assignment_extensions e ON e.assignment_id = a.id AND e.siswa_id = $1
+              WHERE CASE WHEN e.id IS NULL THEN COALESCE(a.batas_terlambat, a.ditutup_pada)
+                         ELSE COALESCE(e.batas_terlambat, e.ditutup_pada) END >= $2
+              ORDER BY COALESCE(e.ditutup_pada, a.ditutup_pada)`
+	rows, err := s.db.Query(query, siswaID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*models.Assignment{}
+	for rows.Next() {
+		var t assignmentScan
+		var namaKelas, extensionID, alasan sql.NullString
+		var extensionDitutup, extensionBatas sql.NullTime
+		if err := rows.Scan(append(t.targets(), &namaKelas, &extensionID, &extensionDitutup, &extensionBatas, &alasan)...); err != nil {
+			return nil, err
+		}
+		a := t.result()
+		a.NamaKelas = namaKelas.String
+		if extensionID.Valid {
+			a.Perpanjangan = &models.AssignmentExtension{
+				ID:           extensionID.String,
+				AssignmentID: a.ID,
+				SiswaID:      siswaID,
+				DitutupPada:  extensionDitutup.Time,
+				Alasan:       alasan.String,
+			}
+			if extensionBatas.Valid {
+				a.Perpanjangan.BatasTerlambat = &extensionBatas.Time
+			}
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+func (s *PostgresStore) IsClassMember(classID string, siswaID string) (bool, error) {
+	var member bool
+	query := `SELECT EXISTS(SELECT 1 FROM class_members WHERE kelas_id = $1 AND siswa_id = $2)`
+	err := s.db.QueryRow(query, classID, siswaID).Scan(&member)
+	return member, err
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sistem-skripsi/backend/audit"
+	"sistem-skripsi/backend/models"
+	"time"
+)
+
+// --- Implementasi method untuk jejak audit ---
+
+// AppendAuditEntry menambahkan entri di ujung rantai hash. Penambahan diserialkan dengan
+// advisory lock transaksi agar setiap entri merujuk hash entri tepat sebelumnya.
+func (s *PostgresStore) AppendAuditEntry(e *models.AuditEntry) error {
+	perubahan, err := nullableJSON(len(e.Perubahan) > 0, e.Perubahan)
+	if err != nil {
+		return err
+	}
+	metadata, err := nullableJSON(len(e.Metadata) > 0, e.Metadata)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('audit_log'))`); err != nil {
+		return err
+	}
+	err = tx.QueryRow(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&e.HashSebelumnya)
+	if err == sql.ErrNoRows {
+		e.HashSebelumnya = audit.GenesisHash
+	} else if err != nil {
+		return err
+	}
+	e.CreatedAt = audit.Timestamp(time.Now())
+	if e.Hash, err = audit.Hash(e); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(
+		`INSERT INTO audit_log (aktor_id, aktor_peran, aksi, target_jenis, target_id, kelas_id, perubahan, metadata,
+                                ip, request_id, created_at, hash_sebelumnya, hash)
+         VALUES (NULLIF($1, '')::uuid, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, '')::uuid,
+                 $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13)
+         RETURNING id`,
+		e.AktorID, e.AktorPeran, e.Aksi, e.TargetJenis, e.TargetID, e.KelasID, perubahan, metadata,
+		e.IP, e.RequestID, e.CreatedAt, e.HashSebelumnya, e.Hash,
+	).Scan(&e.ID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func nullableJSON(present bool, v any) (sql.NullString, error) {
+	if !present {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+const auditColumns = `id, COALESCE(aktor_id::text, ''), COALESCE(aktor_peran, ''), aksi, COALESCE(target_jenis, ''),
+                      COALESCE(target_id, ''), COALESCE(kelas_id::text, ''), perubahan, metadata, COALESCE(ip, ''),
+                      COALESCE(request_id, ''), created_at, hash_sebelumnya, hash`
+
+func scanAuditEntry(row rowScanner) (*models.AuditEntry, error) {
+	var e models.AuditEntry
+	var perubahan, metadata []byte
+	err := row.Scan(&e.ID, &e.AktorID, &e.AktorPeran, &e.Aksi, &e.TargetJenis, &e.TargetID, &e.KelasID,
+		&perubahan, &metadata, &e.IP, &e.RequestID, &e.CreatedAt, &e.HashSebelumnya, &e.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if perubahan != nil {
+		if err := json.Unmarshal(perubahan, &e.Perubahan); err != nil {
+			return nil, err
+		}
+	}
+	if metadata != nil {
+		if err := json.Unmarshal(metadata, &e.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
+
+const auditFilterClause = `($1 = '' OR aktor_id::text = $1)
+                           AND ($2 = '' OR aksi = $2 OR (RIGHT($2, 1) = '.' AND aksi LIKE $2 || '%'))
+                           AND ($3 = '' OR target_jenis = $3)
+                           AND ($4 = '' OR target_id = $4)
+                           AND ($5 = '' OR kelas_id::text = $5)
+                           AND ($6 = '' OR request_id = $6)
+                           AND ($7 = '' OR ip = $7)
+                           AND ($8::timestamptz IS NULL OR created_at >= $8)
+                           AND ($9::timestamptz IS NULL OR created_at < $9)`
+
+func (s *PostgresStore) GetAuditLog(filter models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	args := []any{
+		filter.AktorID, filter.Aksi, filter.TargetJenis, filter.TargetID, filter.KelasID,
+		filter.RequestID, filter.IP, sql.NullTime{Time: derefTime(filter.Dari), Valid: filter.Dari != nil},
+		sql.NullTime{Time: derefTime(filter.Sampai), Valid: filter.Sampai != nil},
+	}
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE `+auditFilterClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+auditColumns+` FROM audit_log WHERE `+auditFilterClause+`
+         ORDER BY id DESC
+         LIMIT $10 OFFSET $11`,
+		append(args, filter.Limit, (filter.Page-1)*filter.Limit)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+func derefTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// StreamAuditLog memanggil fn untuk setiap entri sesuai urutan rantai tanpa memuat seluruh
+// jejak ke memori
+func (s *PostgresStore) StreamAuditLog(fn func(*models.AuditEntry) error) error {
+	rows, err := s.db.Query(`SELECT ` + auditColumns + ` FROM audit_log ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetSubmissionScores mengembalikan skor AI dan tinjauan guru sebuah jawaban saat ini
+func (s *PostgresStore) GetSubmissionScores(submissionID string) (*models.SubmissionScores, error) {
+	var scores models.SubmissionScores
+	var skorAI, skorFinal sql.NullFloat64
+	var reviewID sql.NullString
+	err := s.db.QueryRow(
+		`SELECT ar.skor_ai, tr.id, tr.skor_final, COALESCE(tr.catatan_guru, ''), COALESCE(tr.reviewer_id::text, '')
+         FROM essay_submissions es
+         LEFT JOIN ai_results ar ON ar.submission_id = es.id
+         LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+         WHERE es.id = $1`,
+		submissionID,
+	).Scan(&skorAI, &reviewID, &skorFinal, &scores.CatatanGuru, &scores.ReviewerID)
+	if err != nil {
+		return nil, err
+	}
+	if skorAI.Valid {
+		scores.SkorAI = &skorAI.Float64
+	}
+	if skorFinal.Valid {
+		scores.SkorFinal = &skorFinal.Float64
+	}
+	if !reviewID.Valid {
+		return &scores, nil
+	}
+
+	rows, err := s.db.Query(`SELECT rubric_id, skor FROM teacher_aspect_scores WHERE review_id = $1`, reviewID.String)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rubricID string
+		var skor float64
+		if err := rows.Scan(&rubricID, &skor); err != nil {
+			return nil, err
+		}
+		if scores.Aspek == nil {
+			scores.Aspek = map[string]float64{}
+		}
+		scores.Aspek[rubricID] = skor
+	}
+	return &scores, rows.Err()
+}
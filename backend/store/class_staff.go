@@ -0,0 +1,141 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk Class Staff ---
+
+// Mengembalikan peran pengguna di kelas ("owner", "co_teacher", "assistant", atau kosong jika
+// bukan staf) beserta status arsip kelas. sql.ErrNoRows dikembalikan jika kelas tidak ada.
+func (s *PostgresStore) GetClassAccess(classID string, userID string) (*models.ClassAccess, error) {
+	query := `SELECT CASE WHEN c.guru_id = $2 THEN 'owner' ELSE COALESCE(cs.peran::text, '') END,
+                     c.archived_at IS NOT NULL
+              FROM classes c
+              LEFT JOIN class_staff cs ON cs.kelas_id = c.id AND cs.user_id = $2 AND cs.status = 'accepted'
+              WHERE c.id = $1`
+	var access models.ClassAccess
+	if err := s.db.QueryRow(query, classID, userID).Scan(&access.Peran, &access.Archived); err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
+
+// Daftar staf kelas, dimulai dari pemilik kelas
+func (s *PostgresStore) GetClassStaff(classID string) ([]*models.ClassStaff, error) {
+	query := `SELECT '', c.id, u.id, u.nama_lengkap, COALESCE(u.username, ''), 'owner', 'accepted', '', c.created_at::text, c.created_at::text
+              FROM classes c JOIN users u ON u.id = c.guru_id
+              WHERE c.id = $1
+              UNION ALL
+              SELECT cs.id, cs.kelas_id, u.id, u.nama_lengkap, COALESCE(u.username, ''), cs.peran::text, cs.status::text,
+                     COALESCE(cs.invited_by::text, ''), cs.invited_at::text, COALESCE(cs.accepted_at::text, '')
+              FROM class_staff cs JOIN users u ON u.id = cs.user_id
+              WHERE cs.kelas_id = $1 AND u.deleted_at IS NULL`
+	rows, err := s.db.Query(query, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staff := []*models.ClassStaff{}
+	for rows.Next() {
+		var m models.ClassStaff
+		if err := rows.Scan(&m.ID, &m.KelasID, &m.UserID, &m.NamaLengkap, &m.Username, &m.Peran, &m.Status, &m.InvitedBy, &m.InvitedAt, &m.AcceptedAt); err != nil {
+			return nil, err
+		}
+		staff = append(staff, &m)
+	}
+	return staff, rows.Err()
+}
+
+// Membuat undangan staf berstatus pending untuk guru dengan username atau email tersebut
+func (s *PostgresStore) InviteClassStaff(classID string, identifier string, peran string, invitedBy string) (*models.ClassStaff, error) {
+	var invitee struct {
+		id, peran, guruID string
+		active            bool
+	}
+	query := `SELECT u.id, u.peran::text, u.is_active, c.guru_id
+              FROM users u, classes c
+              WHERE (u.email = $1 OR u.username = $1) AND u.deleted_at IS NULL AND c.id = $2`
+	err := s.db.QueryRow(query, identifier, classID).Scan(&invitee.id, &invitee.peran, &invitee.active, &invitee.guruID)
+	if err == sql.ErrNoRows {
+		return nil, ErrInviteeNotTeacher
+	}
+	if err != nil {
+		return nil, err
+	}
+	if invitee.peran != "teacher" || !invitee.active {
+		return nil, ErrInviteeNotTeacher
+	}
+	if invitee.id == invitee.guruID {
+		return nil, ErrAlreadyClassStaff
+	}
+
+	staff := models.ClassStaff{KelasID: classID, UserID: invitee.id, Peran: peran, Status: "pending", InvitedBy: invitedBy}
+	query = `INSERT INTO class_staff (kelas_id, user_id, peran, invited_by)
+             VALUES ($1, $2, $3, $4)
+             RETURNING id, invited_at`
+	err = s.db.QueryRow(query, classID, invitee.id, peran, invitedBy).Scan(&staff.ID, &staff.InvitedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "class_staff_kelas_id_user_id_key" {
+		return nil, ErrAlreadyClassStaff
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &staff, nil
+}
+
+func (s *PostgresStore) GetPendingStaffInvitations(userID string) ([]*models.ClassStaff, error) {
+	query := `SELECT cs.id, cs.kelas_id, cs.user_id, c.nama_kelas, cs.peran::text, cs.status::text,
+                     COALESCE(cs.invited_by::text, ''), cs.invited_at::text
+              FROM class_staff cs JOIN classes c ON c.id = cs.kelas_id
+              WHERE cs.user_id = $1 AND cs.status = 'pending' AND c.archived_at IS NULL
+              ORDER BY cs.invited_at DESC`
+	rows, err := s.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.ClassStaff{}
+	for rows.Next() {
+		var m models.ClassStaff
+		if err := rows.Scan(&m.ID, &m.KelasID, &m.UserID, &m.NamaKelas, &m.Peran, &m.Status, &m.InvitedBy, &m.InvitedAt); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, &m)
+	}
+	return invitations, rows.Err()
+}
+
+// Menerima (status menjadi accepted) atau menolak (baris dihapus) undangan milik userID
+func (s *PostgresStore) RespondStaffInvitation(invitationID string, userID string, accept bool) (int64, error) {
+	query := `DELETE FROM class_staff WHERE id = $1 AND user_id = $2 AND status = 'pending'`
+	if accept {
+		query = `UPDATE class_staff SET status = 'accepted', accepted_at = NOW() WHERE id = $1 AND user_id = $2 AND status = 'pending'`
+	}
+	result, err := s.db.Exec(query, invitationID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *PostgresStore) UpdateClassStaffRole(classID string, userID string, peran string) (int64, error) {
+	result, err := s.db.Exec(`UPDATE class_staff SET peran = $3 WHERE kelas_id = $1 AND user_id = $2`, classID, userID, peran)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *PostgresStore) RemoveClassStaff(classID string, userID string) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM class_staff WHERE kelas_id = $1 AND user_id = $2`, classID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
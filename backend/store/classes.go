@@ -0,0 +1,245 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk Class ---
+
+// Kolom kelas yang dipilih dengan alias tabel "c"
+const classColumns = `c.id, COALESCE(c.guru_id::text, ''), c.nama_kelas, COALESCE(c.deskripsi, ''),
+                      COALESCE(c.mata_pelajaran, ''), COALESCE(c.tahun_ajaran, ''), COALESCE(c.semester, ''),
+                      COALESCE(c.cloned_from::text, ''), c.created_at, COALESCE(c.updated_at::text, ''),
+                      COALESCE(c.archived_at::text, '')`
+
+func classScanTargets(c *models.Class) []any {
+	return []any{
+		&c.ID, &c.GuruID, &c.NamaKelas, &c.Deskripsi,
+		&c.MataPelajaran, &c.TahunAjaran, &c.Semester,
+		&c.ClonedFrom, &c.CreatedAt, &c.UpdatedAt,
+		&c.ArchivedAt,
+	}
+}
+
+func (s *PostgresStore) CreateClass(class *models.Class) error {
+	query := `INSERT INTO classes (guru_id, nama_kelas, deskripsi, mata_pelajaran, tahun_ajaran, semester)
+              VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''))
+              RETURNING id, created_at`
+
+	return s.db.QueryRow(
+		query,
+		class.GuruID,
+		class.NamaKelas,
+		class.Deskripsi,
+		class.MataPelajaran,
+		class.TahunAjaran,
+		class.Semester,
+	).Scan(&class.ID, &class.CreatedAt)
+}
+
+func (s *PostgresStore) GetClassByID(id string) (*models.Class, error) {
+	var c models.Class
+	query := `SELECT ` + classColumns + ` FROM classes c WHERE c.id = $1`
+	if err := s.db.QueryRow(query, id).Scan(classScanTargets(&c)...); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Mengembalikan kelas yang dimiliki guru maupun kelas tempat ia menjadi staf.
+// Kelas arsip hanya disertakan jika includeArchived bernilai true.
+func (s *PostgresStore) GetClassesByTeacherID(teacherID string, includeArchived bool) ([]*models.Class, error) {
+	query := `SELECT ` + classColumns + `,
+                     CASE WHEN c.guru_id = $1 THEN 'owner' ELSE cs.peran::text END
+              FROM classes c
+              LEFT JOIN class_staff cs ON cs.kelas_id = c.id AND cs.user_id = $1 AND cs.status = 'accepted'
+              WHERE (c.guru_id = $1 OR cs.id IS NOT NULL) AND ($2 OR c.archived_at IS NULL)
+              ORDER BY c.archived_at DESC NULLS FIRST, c.created_at DESC`
+	rows, err := s.db.Query(query, teacherID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var classes []*models.Class
+	for rows.Next() {
+		var c models.Class
+		if err := rows.Scan(append(classScanTargets(&c), &c.PeranSaya)...); err != nil {
+			return nil, err
+		}
+		classes = append(classes, &c)
+	}
+	return classes, nil
+}
+
+// Hanya kelas aktif yang dimiliki guru; dipakai saat offboarding
+func (s *PostgresStore) GetOwnedClassesByTeacherID(teacherID string) ([]*models.Class, error) {
+	query := `SELECT ` + classColumns + ` FROM classes c WHERE c.guru_id = $1 AND c.archived_at IS NULL ORDER BY c.created_at DESC`
+	rows, err := s.db.Query(query, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var classes []*models.Class
+	for rows.Next() {
+		c := models.Class{PeranSaya: models.ClassRoleOwner}
+		if err := rows.Scan(classScanTargets(&c)...); err != nil {
+			return nil, err
+		}
+		classes = append(classes, &c)
+	}
+	return classes, nil
+}
+
+func (s *PostgresStore) UpdateClass(id string, req models.UpdateClassRequest) (*models.Class, error) {
+	query := `UPDATE classes c SET
+                nama_kelas = COALESCE($2, nama_kelas),
+                deskripsi = COALESCE($3, deskripsi),
+                mata_pelajaran = COALESCE($4, mata_pelajaran),
+                tahun_ajaran = COALESCE($5, tahun_ajaran),
+                semester = COALESCE(NULLIF($6, ''), semester),
+                updated_at = NOW()
+              WHERE c.id = $1 AND c.archived_at IS NULL
+              RETURNING ` + classColumns
+	var c models.Class
+	err := s.db.QueryRow(query, id, req.NamaKelas, req.Deskripsi, req.MataPelajaran, req.TahunAjaran, req.Semester).Scan(classScanTargets(&c)...)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PostgresStore) SetClassArchived(id string, archived bool) (int64, error) {
+	query := `UPDATE classes SET archived_at = NOW(), updated_at = NOW() WHERE id = $1 AND archived_at IS NULL`
+	if !archived {
+		query = `UPDATE classes SET archived_at = NULL, updated_at = NOW() WHERE id = $1 AND archived_at IS NOT NULL`
+	}
+	result, err := s.db.Exec(query, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Membuat kelas baru dari kelas sumber beserta salinan materi, soal, dan rubriknya.
+// Anggota kelas, staf, dan jawaban siswa tidak ikut disalin.
+func (s *PostgresStore) CloneClass(sourceID string, target *models.Class) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO classes (guru_id, nama_kelas, deskripsi, mata_pelajaran, tahun_ajaran, semester, cloned_from)
+              SELECT $2, COALESCE(NULLIF($3, ''), nama_kelas), deskripsi, mata_pelajaran,
+                     COALESCE(NULLIF($4, ''), tahun_ajaran), COALESCE(NULLIF($5, ''), semester), id
+              FROM classes WHERE id = $1
+              RETURNING id, nama_kelas, COALESCE(deskripsi, ''), COALESCE(mata_pelajaran, ''),
+                     COALESCE(tahun_ajaran, ''), COALESCE(semester, ''), cloned_from, created_at`
+	err = tx.QueryRow(query, sourceID, target.GuruID, target.NamaKelas, target.TahunAjaran, target.Semester).Scan(
+		&target.ID, &target.NamaKelas, &target.Deskripsi, &target.MataPelajaran,
+		&target.TahunAjaran, &target.Semester, &target.ClonedFrom, &target.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Salin materi, simpan pemetaan id lama -> id baru. FOR SHARE menahan penghapusan materi
+	// asal sampai salinan (yang ikut merujuk file yang sama) selesai disimpan.
+	materialIDs := map[string]string{}
+	rows, err := tx.Query(`SELECT id FROM materials WHERE kelas_id = $1 ORDER BY created_at FOR SHARE`, sourceID)
+	if err != nil {
+		return err
+	}
+	var sourceMaterials []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		sourceMaterials = append(sourceMaterials, id)
+	}
+	rows.Close()
+	for _, oldID := range sourceMaterials {
+		var newID string
+		err := tx.QueryRow(
+			`INSERT INTO materials (kelas_id, pengunggah_id, judul, isi_materi, file_url, nama_file, tipe_file, ukuran_file)
+             SELECT $2, pengunggah_id, judul, isi_materi, file_url, nama_file, tipe_file, ukuran_file FROM materials WHERE id = $1
+             RETURNING id`,
+			oldID, target.ID,
+		).Scan(&newID)
+		if err != nil {
+			return err
+		}
+		materialIDs[oldID] = newID
+
+		// Versi terkini materi asal menjadi versi pertama salinan, termasuk chunk-nya
+		var newVersionID string
+		err = tx.QueryRow(
+			`INSERT INTO material_versions (materi_id, nomor_versi, judul, isi_materi, file_url, content_hash, editor_id)
+             SELECT $2, 1, v.judul, v.isi_materi, v.file_url, v.content_hash, $3
+             FROM materials m JOIN material_versions v ON v.materi_id = m.id AND v.nomor_versi = m.versi_terkini
+             WHERE m.id = $1
+             RETURNING id`,
+			oldID, newID, target.GuruID,
+		).Scan(&newVersionID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`INSERT INTO material_chunks (versi_id, urutan, isi)
+             SELECT $2, c.urutan, c.isi
+             FROM materials m
+             JOIN material_versions v ON v.materi_id = m.id AND v.nomor_versi = m.versi_terkini
+             JOIN material_chunks c ON c.versi_id = v.id
+             WHERE m.id = $1`,
+			oldID, newVersionID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Salin soal per materi, lalu rubrik per soal
+	for oldMaterialID, newMaterialID := range materialIDs {
+		rows, err := tx.Query(`SELECT id FROM essay_questions WHERE materi_id = $1`, oldMaterialID)
+		if err != nil {
+			return err
+		}
+		var sourceQuestions []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			sourceQuestions = append(sourceQuestions, id)
+		}
+		rows.Close()
+
+		for _, oldQuestionID := range sourceQuestions {
+			var newQuestionID string
+			err := tx.QueryRow(
+				`INSERT INTO essay_questions (materi_id, teks_soal, level_kognitif, kunci_jawaban)
+                 SELECT $2, teks_soal, level_kognitif, kunci_jawaban FROM essay_questions WHERE id = $1
+                 RETURNING id`,
+				oldQuestionID, newMaterialID,
+			).Scan(&newQuestionID)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(
+				`INSERT INTO rubrics (soal_id, nama_aspek, deskripsi, bobot, skor_min, skor_maks)
+                 SELECT $2, nama_aspek, deskripsi, bobot, skor_min, skor_maks FROM rubrics WHERE soal_id = $1`,
+				oldQuestionID, newQuestionID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
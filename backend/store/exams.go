@@ -0,0 +1,243 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"time"
+)
+
+// --- Implementasi method untuk Exam Session ---
+
+// StartExamSession membuat sesi ujian baru. Jika sesi siswa sudah berjalan, hanya tokennya
+// yang diganti (waktu berakhir tetap) dan nilai kembalian pertama bernilai true. Sesi yang
+// sudah selesai tidak dapat dimulai lagi (ErrExamFinished).
+func (s *PostgresStore) StartExamSession(session *models.ExamSession, tokenHash string) (bool, error) {
+	query := `INSERT INTO exam_sessions (assignment_id, siswa_id, token_hash, dimulai_pada, berakhir_pada, ip_awal, ip_terakhir)
+              VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($6, ''))
+              ON CONFLICT (assignment_id, siswa_id) DO UPDATE SET
+                token_hash = EXCLUDED.token_hash,
+                ip_terakhir = EXCLUDED.ip_terakhir
+              WHERE exam_sessions.selesai_pada IS NULL
+              RETURNING id, dimulai_pada, berakhir_pada, COALESCE(ip_awal, ''), COALESCE(ip_terakhir, ''), xmax <> 0`
+	var reissued bool
+	err := s.db.QueryRow(
+		query,
+		session.AssignmentID,
+		session.SiswaID,
+		tokenHash,
+		session.DimulaiPada,
+		session.BerakhirPada,
+		session.IPTerakhir,
+	).Scan(&session.ID, &session.DimulaiPada, &session.BerakhirPada, &session.IPAwal, &session.IPTerakhir, &reissued)
+	if err == sql.ErrNoRows {
+		return false, ErrExamFinished
+	}
+	return reissued, err
+}
+
+const examSessionColumns = `es.id, es.assignment_id, es.siswa_id, u.nama_lengkap, es.dimulai_pada, es.berakhir_pada,
+                            es.selesai_pada, COALESCE(es.alasan_selesai, ''), COALESCE(es.ip_awal, ''),
+                            COALESCE(es.ip_terakhir, ''),
+                            (SELECT COUNT(*) FROM exam_integrity_events ev WHERE ev.session_id = es.id)`
+
+func scanExamSession(row rowScanner, extra ...any) (*models.ExamSession, error) {
+	var session models.ExamSession
+	var selesai sql.NullTime
+	targets := []any{
+		&session.ID, &session.AssignmentID, &session.SiswaID, &session.NamaSiswa, &session.DimulaiPada, &session.BerakhirPada,
+		&selesai, &session.AlasanSelesai, &session.IPAwal,
+		&session.IPTerakhir,
+		&session.JumlahPeristiwa,
+	}
+	if err := row.Scan(append(targets, extra...)...); err != nil {
+		return nil, err
+	}
+	if selesai.Valid {
+		session.SelesaiPada = &selesai.Time
+	}
+	return &session, nil
+}
+
+// GetExamSession mengembalikan sesi ujian siswa beserta hash tokennya
+func (s *PostgresStore) GetExamSession(assignmentID string, siswaID string) (*models.ExamSession, string, error) {
+	var tokenHash string
+	query := `SELECT ` + examSessionColumns + `, es.token_hash
+              FROM exam_sessions es JOIN users u ON u.id = es.siswa_id
+              WHERE es.assignment_id = $1 AND es.siswa_id = $2`
+	session, err := scanExamSession(s.db.QueryRow(query, assignmentID, siswaID), &tokenHash)
+	if err != nil {
+		return nil, "", err
+	}
+	return session, tokenHash, nil
+}
+
+func (s *PostgresStore) GetExamSessions(assignmentID string) ([]*models.ExamSession, error) {
+	query := `SELECT ` + examSessionColumns + `
+              FROM exam_sessions es JOIN users u ON u.id = es.siswa_id
+              WHERE es.assignment_id = $1 ORDER BY u.nama_lengkap`
+	return s.queryExamSessions(query, assignmentID)
+}
+
+// Sesi yang waktunya sudah habis tetapi belum diselesaikan
+func (s *PostgresStore) GetExpiredExamSessions(now time.Time) ([]*models.ExamSession, error) {
+	query := `SELECT ` + examSessionColumns + `
+              FROM exam_sessions es JOIN users u ON u.id = es.siswa_id
+              WHERE es.selesai_pada IS NULL AND es.berakhir_pada <= $1
+              ORDER BY es.berakhir_pada`
+	return s.queryExamSessions(query, now)
+}
+
+func (s *PostgresStore) queryExamSessions(query string, args ...any) ([]*models.ExamSession, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*models.ExamSession{}
+	for rows.Next() {
+		session, err := scanExamSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *PostgresStore) UpdateExamSessionIP(sessionID string, ip string) error {
+	_, err := s.db.Exec(`UPDATE exam_sessions SET ip_terakhir = $2 WHERE id = $1`, sessionID, ip)
+	return err
+}
+
+// FinishExamSession menutup sesi ujian dan mengumpulkan draf terakhir setiap soal yang isinya
+// berbeda dari percobaan terakhir, selama batas percobaan tugas belum tercapai. Mengembalikan
+// jawaban yang dikumpulkan; ErrExamFinished jika sesi sudah ditutup sebelumnya.
+func (s *PostgresStore) FinishExamSession(sessionID string, alasan string, hariTerlambat int, penaltiPersen float64) ([]*models.Submission, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var assignmentID, siswaID string
+	var maksPercobaan int
+	var selesai sql.NullTime
+	err = tx.QueryRow(
+		`SELECT es.assignment_id, es.siswa_id, es.selesai_pada, a.maks_percobaan
+         FROM exam_sessions es JOIN assignments a ON a.id = es.assignment_id
+         WHERE es.id = $1 FOR UPDATE OF es`,
+		sessionID,
+	).Scan(&assignmentID, &siswaID, &selesai, &maksPercobaan)
+	if err != nil {
+		return nil, err
+	}
+	if selesai.Valid {
+		return nil, ErrExamFinished
+	}
+
+	// Draf terakhir tiap soal beserta jumlah dan isi percobaan terakhirnya
+	rows, err := tx.Query(
+		`SELECT d.soal_id, d.teks_jawaban,
+                (SELECT COUNT(*) FROM essay_submissions es
+                 WHERE es.assignment_id = d.assignment_id AND es.soal_id = d.soal_id AND es.siswa_id = d.siswa_id),
+                COALESCE((SELECT es.teks_jawaban FROM essay_submissions es
+                          WHERE es.assignment_id = d.assignment_id AND es.soal_id = d.soal_id AND es.siswa_id = d.siswa_id
+                          ORDER BY es.percobaan_ke DESC LIMIT 1), '')
+         FROM essay_drafts d
+         WHERE d.assignment_id = $1 AND d.siswa_id = $2 AND btrim(d.teks_jawaban) <> ''
+         FOR UPDATE OF d`,
+		assignmentID, siswaID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	type pendingDraft struct {
+		soalID, teks string
+		attempts     int
+	}
+	var pending []pendingDraft
+	for rows.Next() {
+		var d pendingDraft
+		var lastSubmitted string
+		if err := rows.Scan(&d.soalID, &d.teks, &d.attempts, &lastSubmitted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if d.teks != lastSubmitted && d.attempts < maksPercobaan {
+			pending = append(pending, d)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	submitted := []*models.Submission{}
+	for _, d := range pending {
+		sub := models.Submission{
+			SoalID:        d.soalID,
+			SiswaID:       siswaID,
+			AssignmentID:  assignmentID,
+			PercobaanKe:   d.attempts + 1,
+			HariTerlambat: hariTerlambat,
+			PenaltiPersen: penaltiPersen,
+		}
+		err := tx.QueryRow(
+			`INSERT INTO essay_submissions (soal_id, siswa_id, assignment_id, teks_jawaban, percobaan_ke, hari_terlambat, penalti_persen)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)
+             RETURNING id, submitted_at`,
+			d.soalID, siswaID, assignmentID, d.teks, sub.PercobaanKe, hariTerlambat, penaltiPersen,
+		).Scan(&sub.ID, &sub.SubmittedAt)
+		if err != nil {
+			return nil, err
+		}
+		submitted = append(submitted, &sub)
+	}
+
+	_, err = tx.Exec(`UPDATE exam_sessions SET selesai_pada = NOW(), alasan_selesai = $2 WHERE id = $1`, sessionID, alasan)
+	if err != nil {
+		return nil, err
+	}
+	return submitted, tx.Commit()
+}
+
+// --- Peristiwa integritas ---
+
+func (s *PostgresStore) RecordIntegrityEvent(event *models.IntegrityEvent) error {
+	query := `INSERT INTO exam_integrity_events (session_id, jenis, detail, ip, terjadi_pada)
+              VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
+              RETURNING id, dicatat_pada`
+	return s.db.QueryRow(
+		query,
+		event.SessionID,
+		event.Jenis,
+		event.Detail,
+		event.IP,
+		nullTime(event.TerjadiPada),
+	).Scan(&event.ID, &event.DicatatPada)
+}
+
+func (s *PostgresStore) GetIntegrityEvents(sessionID string) ([]*models.IntegrityEvent, error) {
+	query := `SELECT id, session_id, jenis, COALESCE(detail, ''), COALESCE(ip, ''), terjadi_pada, dicatat_pada
+              FROM exam_integrity_events WHERE session_id = $1 ORDER BY dicatat_pada`
+	rows, err := s.db.Query(query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.IntegrityEvent{}
+	for rows.Next() {
+		var e models.IntegrityEvent
+		var terjadi sql.NullTime
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.Jenis, &e.Detail, &e.IP, &terjadi, &e.DicatatPada); err != nil {
+			return nil, err
+		}
+		if terjadi.Valid {
+			e.TerjadiPada = &terjadi.Time
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk ekspor buku nilai ---
+
+// StreamGradebook membaca percobaan setiap siswa kelas (atau satu siswa jika siswaID diisi)
+// dan memanggil fn sekali per siswa sesuai urutan nama, tanpa memuat seluruh kelas ke memori.
+// withDetails menyertakan skor per aspek, umpan balik AI, dan catatan guru.
+func (s *PostgresStore) StreamGradebook(classID string, siswaID string, withDetails bool, fn func(*models.GradebookStudent) error) error {
+	query := `SELECT u.id, u.nama_lengkap, COALESCE(u.nomor_identitas, ''),
+                     es.assignment_id::text, es.soal_id::text, es.percobaan_ke, es.penalti_persen,
+                     ar.skor_ai, tr.skor_final,
+                     CASE WHEN $3 THEN (SELECT json_object_agg(rubric_id, skor) FROM ai_aspect_scores
+                                        WHERE ai_result_id = ar.id AND rubric_id IS NOT NULL) END,
+                     CASE WHEN $3 THEN (SELECT json_object_agg(rubric_id, skor) FROM teacher_aspect_scores
+                                        WHERE review_id = tr.id) END,
+                     CASE WHEN $3 THEN COALESCE(ar.umpan_balik_ai, '') ELSE '' END,
+                     CASE WHEN $3 THEN COALESCE(tr.catatan_guru, '') ELSE '' END
+              FROM class_members cm
+              JOIN users u ON u.id = cm.siswa_id
+              LEFT JOIN essay_submissions es ON es.siswa_id = cm.siswa_id
+                   AND es.assignment_id IN (SELECT id FROM assignments WHERE kelas_id = $1)
+              LEFT JOIN ai_results ar ON ar.submission_id = es.id AND ar.status = 'berhasil'
+              LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+              WHERE cm.kelas_id = $1 AND ($2 = '' OR cm.siswa_id::text = $2)
+              ORDER BY u.nama_lengkap, u.id, es.assignment_id, es.soal_id, es.percobaan_ke`
+	rows, err := s.db.Query(query, classID, siswaID, withDetails)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current *models.GradebookStudent
+	for rows.Next() {
+		var id, nama, nomor string
+		var assignmentID, soalID sql.NullString
+		var percobaan sql.NullInt64
+		var penalti, skorAI, skorGuru sql.NullFloat64
+		var aspekAI, aspekGuru []byte
+		var a models.GradebookAttempt
+		err := rows.Scan(&id, &nama, &nomor, &assignmentID, &soalID, &percobaan, &penalti,
+			&skorAI, &skorGuru, &aspekAI, &aspekGuru, &a.UmpanBalikAI, &a.CatatanGuru)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || current.SiswaID != id {
+			if current != nil {
+				if err := fn(current); err != nil {
+					return err
+				}
+			}
+			current = &models.GradebookStudent{SiswaID: id, NamaSiswa: nama, NomorIdentitas: nomor, Percobaan: []*models.GradebookAttempt{}}
+		}
+		if !soalID.Valid {
+			continue // Siswa belum mengumpulkan jawaban apa pun
+		}
+
+		a.AssignmentID, a.SoalID = assignmentID.String, soalID.String
+		a.PercobaanKe, a.PenaltiPersen = int(percobaan.Int64), penalti.Float64
+		if skorAI.Valid {
+			a.SkorAI = &skorAI.Float64
+		}
+		if skorGuru.Valid {
+			a.SkorGuru = &skorGuru.Float64
+		}
+		if aspekAI != nil {
+			if err := json.Unmarshal(aspekAI, &a.AspekAI); err != nil {
+				return err
+			}
+		}
+		if aspekGuru != nil {
+			if err := json.Unmarshal(aspekGuru, &a.AspekGuru); err != nil {
+				return err
+			}
+		}
+		current.Percobaan = append(current.Percobaan, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
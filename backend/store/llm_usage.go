@@ -0,0 +1,199 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+	"time"
+)
+
+// --- Implementasi method untuk pemakaian model bahasa ---
+
+// RecordLLMUsage mencatat panggilan model beserta kelas dan pemilik kelas jawaban tersebut.
+// Biaya dihitung dari harga model dengan awalan nama terpanjang yang cocok.
+func (s *PostgresStore) RecordLLMUsage(usage []*models.LLMUsage) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO llm_usage (submission_id, kelas_id, guru_id, provider, model, prompt_tokens, completion_tokens,
+                                     latency_ms, biaya_usd, keluaran_valid)
+              SELECT es.id, c.id, c.guru_id, $2, $3, $4, $5, $6,
+                     COALESCE((SELECT ($4 * p.harga_prompt_per_juta + $5 * p.harga_completion_per_juta) / 1000000
+                               FROM llm_prices p
+                               WHERE p.provider = $2 AND $3 LIKE p.model || '%'
+                               ORDER BY length(p.model) DESC LIMIT 1), 0),
+                     $7
+              FROM essay_submissions es
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              JOIN classes c ON c.id = m.kelas_id
+              WHERE es.id = $1`
+	for _, u := range usage {
+		_, err := tx.Exec(query, u.SubmissionID, u.Provider, u.Model, u.PromptTokens, u.CompletionTokens, u.LatencyMS, u.KeluaranValid)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// IsLLMBudgetExceeded melaporkan apakah anggaran sekolah atau anggaran guru pemilik kelas
+// jawaban tersebut sudah habis sejak awal bulan (since)
+func (s *PostgresStore) IsLLMBudgetExceeded(submissionID string, since time.Time) (bool, error) {
+	query := `WITH pemilik AS (
+                  SELECT c.guru_id FROM essay_submissions es
+                  JOIN essay_questions q ON q.id = es.soal_id
+                  JOIN materials m ON m.id = q.materi_id
+                  JOIN classes c ON c.id = m.kelas_id
+                  WHERE es.id = $1
+              )
+              SELECT EXISTS (
+                  SELECT 1 FROM llm_budgets b
+                  WHERE (b.guru_id IS NULL OR b.guru_id = (SELECT guru_id FROM pemilik))
+                    AND b.batas_usd <= (
+                        SELECT COALESCE(SUM(u.biaya_usd), 0) FROM llm_usage u
+                        WHERE u.created_at >= $2 AND (b.guru_id IS NULL OR u.guru_id = b.guru_id)
+                    )
+              )`
+	var exceeded bool
+	err := s.db.QueryRow(query, submissionID, since).Scan(&exceeded)
+	return exceeded, err
+}
+
+// GetLLMUsageReport mengelompokkan pemakaian per bulan, dan per kelas atau guru sesuai filter.
+// Bulan dihitung pada zona waktu loc.
+func (s *PostgresStore) GetLLMUsageReport(filter models.LLMUsageFilter, loc *time.Location) ([]*models.LLMUsageReport, error) {
+	keyColumns, joins, groupBy := `'', '', '', ''`, ``, ``
+	switch filter.GroupBy {
+	case "class":
+		keyColumns = `COALESCE(u.kelas_id::text, ''), COALESCE(c.nama_kelas, ''), '', ''`
+		joins = ` LEFT JOIN classes c ON c.id = u.kelas_id`
+		groupBy = `, u.kelas_id, c.nama_kelas`
+	case "teacher":
+		keyColumns = `'', '', COALESCE(u.guru_id::text, ''), COALESCE(g.nama_lengkap, '')`
+		joins = ` LEFT JOIN users g ON g.id = u.guru_id`
+		groupBy = `, u.guru_id, g.nama_lengkap`
+	}
+
+	query := `SELECT to_char(date_trunc('month', u.created_at AT TIME ZONE $1), 'YYYY-MM') AS bulan, ` + keyColumns + `,
+                     COUNT(*), COUNT(DISTINCT u.submission_id), COALESCE(SUM(u.prompt_tokens), 0), COALESCE(SUM(u.completion_tokens), 0),
+                     COALESCE(SUM(u.biaya_usd), 0), COALESCE(AVG(u.latency_ms), 0), COUNT(*) FILTER (WHERE NOT u.keluaran_valid)
+              FROM llm_usage u` + joins + `
+              WHERE u.created_at >= $2 AND u.created_at < $3
+                AND ($4 = '' OR u.kelas_id::text = $4)
+                AND ($5 = '' OR u.guru_id::text = $5)
+              GROUP BY bulan` + groupBy + `
+              ORDER BY bulan DESC, 10 DESC`
+	rows, err := s.db.Query(query, loc.String(), filter.Dari, filter.Sampai, filter.KelasID, filter.GuruID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := []*models.LLMUsageReport{}
+	for rows.Next() {
+		var r models.LLMUsageReport
+		err := rows.Scan(
+			&r.Bulan, &r.KelasID, &r.NamaKelas, &r.GuruID, &r.NamaGuru,
+			&r.JumlahPanggilan, &r.JumlahJawaban, &r.PromptTokens, &r.CompletionTokens,
+			&r.BiayaUSD, &r.RataLatencyMS, &r.KeluaranTidakValid,
+		)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, &r)
+	}
+	return report, rows.Err()
+}
+
+func (s *PostgresStore) GetLLMPrices() ([]*models.LLMPrice, error) {
+	rows, err := s.db.Query(`SELECT provider, model, harga_prompt_per_juta, harga_completion_per_juta, updated_at
+                             FROM llm_prices ORDER BY provider, model`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []*models.LLMPrice{}
+	for rows.Next() {
+		var p models.LLMPrice
+		if err := rows.Scan(&p.Provider, &p.Model, &p.HargaPromptPerJuta, &p.HargaCompletionPerJuta, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &p)
+	}
+	return prices, rows.Err()
+}
+
+// Harga baru hanya berlaku untuk panggilan berikutnya; biaya yang sudah tercatat tidak dihitung ulang
+func (s *PostgresStore) SetLLMPrice(price *models.LLMPrice) error {
+	query := `INSERT INTO llm_prices (provider, model, harga_prompt_per_juta, harga_completion_per_juta)
+              VALUES ($1, $2, $3, $4)
+              ON CONFLICT (provider, model) DO UPDATE SET
+                harga_prompt_per_juta = EXCLUDED.harga_prompt_per_juta,
+                harga_completion_per_juta = EXCLUDED.harga_completion_per_juta,
+                updated_at = NOW()
+              RETURNING updated_at`
+	return s.db.QueryRow(query, price.Provider, price.Model, price.HargaPromptPerJuta, price.HargaCompletionPerJuta).Scan(&price.UpdatedAt)
+}
+
+func (s *PostgresStore) DeleteLLMPrice(provider string, model string) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM llm_prices WHERE provider = $1 AND model = $2`, provider, model)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetLLMBudgets mengembalikan semua anggaran beserta pemakaian sejak awal bulan (since)
+func (s *PostgresStore) GetLLMBudgets(since time.Time) ([]*models.LLMBudget, error) {
+	query := `SELECT b.id, COALESCE(b.guru_id::text, ''), COALESCE(g.nama_lengkap, ''), b.batas_usd,
+                     (SELECT COALESCE(SUM(u.biaya_usd), 0) FROM llm_usage u
+                      WHERE u.created_at >= $1 AND (b.guru_id IS NULL OR u.guru_id = b.guru_id)),
+                     b.updated_at
+              FROM llm_budgets b
+              LEFT JOIN users g ON g.id = b.guru_id
+              ORDER BY b.guru_id NULLS FIRST, g.nama_lengkap`
+	rows, err := s.db.Query(query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []*models.LLMBudget{}
+	for rows.Next() {
+		var b models.LLMBudget
+		if err := rows.Scan(&b.ID, &b.GuruID, &b.NamaGuru, &b.BatasUSD, &b.TerpakaiUSD, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, &b)
+	}
+	return budgets, rows.Err()
+}
+
+// SetLLMBudget mengatur anggaran bulanan; guruID kosong untuk anggaran sekolah
+func (s *PostgresStore) SetLLMBudget(guruID string, batasUSD float64) error {
+	if guruID == "" {
+		_, err := s.db.Exec(
+			`INSERT INTO llm_budgets (batas_usd) VALUES ($1)
+             ON CONFLICT ((guru_id IS NULL)) WHERE guru_id IS NULL DO UPDATE SET batas_usd = EXCLUDED.batas_usd, updated_at = NOW()`,
+			batasUSD,
+		)
+		return err
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO llm_budgets (guru_id, batas_usd) VALUES ($1, $2)
+         ON CONFLICT (guru_id) DO UPDATE SET batas_usd = EXCLUDED.batas_usd, updated_at = NOW()`,
+		guruID, batasUSD,
+	)
+	return err
+}
+
+func (s *PostgresStore) DeleteLLMBudget(guruID string) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM llm_budgets WHERE guru_id IS NOT DISTINCT FROM NULLIF($1, '')::uuid`, guruID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,460 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"sistem-skripsi/backend/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// --- Implementasi method untuk integrasi LTI 1.3 ---
+
+const ltiPlatformColumns = `id, nama, issuer, client_id, auth_login_url, auth_token_url, jwks_url, deployment_ids,
+                            trust_email, created_at, COALESCE(updated_at::text, '')`
+
+func scanLTIPlatform(row rowScanner) (*models.LTIPlatform, error) {
+	var p models.LTIPlatform
+	var deployments pq.StringArray
+	err := row.Scan(&p.ID, &p.Nama, &p.Issuer, &p.ClientID, &p.AuthLoginURL, &p.AuthTokenURL, &p.JWKSURL,
+		&deployments, &p.TrustEmail, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.DeploymentIDs = []string(deployments)
+	return &p, nil
+}
+
+func (s *PostgresStore) GetLTIPlatforms() ([]*models.LTIPlatform, error) {
+	rows, err := s.db.Query(`SELECT ` + ltiPlatformColumns + ` FROM lti_platforms ORDER BY nama`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	platforms := []*models.LTIPlatform{}
+	for rows.Next() {
+		p, err := scanLTIPlatform(rows)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, p)
+	}
+	return platforms, rows.Err()
+}
+
+func (s *PostgresStore) GetLTIPlatform(id string) (*models.LTIPlatform, error) {
+	return scanLTIPlatform(s.db.QueryRow(`SELECT `+ltiPlatformColumns+` FROM lti_platforms WHERE id = $1`, id))
+}
+
+// FindLTIPlatform mencari platform berdasarkan issuer dan client_id. client_id boleh kosong
+// (tidak dikirim sebagian platform saat inisiasi login) jika issuer hanya terdaftar sekali.
+func (s *PostgresStore) FindLTIPlatform(issuer string, clientID string) (*models.LTIPlatform, error) {
+	rows, err := s.db.Query(
+		`SELECT `+ltiPlatformColumns+` FROM lti_platforms WHERE issuer = $1 AND ($2 = '' OR client_id = $2) LIMIT 2`,
+		issuer, clientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []*models.LTIPlatform
+	for rows.Next() {
+		p, err := scanLTIPlatform(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(found) != 1 {
+		return nil, sql.ErrNoRows
+	}
+	return found[0], nil
+}
+
+func (s *PostgresStore) CreateLTIPlatform(p *models.LTIPlatform) error {
+	query := `INSERT INTO lti_platforms (nama, issuer, client_id, auth_login_url, auth_token_url, jwks_url, deployment_ids, trust_email)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+              RETURNING id, created_at`
+	return s.db.QueryRow(query, p.Nama, p.Issuer, p.ClientID, p.AuthLoginURL, p.AuthTokenURL, p.JWKSURL,
+		pq.Array(nonNilStrings(p.DeploymentIDs)), p.TrustEmail).Scan(&p.ID, &p.CreatedAt)
+}
+
+func (s *PostgresStore) UpdateLTIPlatform(p *models.LTIPlatform) (int64, error) {
+	res, err := s.db.Exec(
+		`UPDATE lti_platforms SET nama = $2, issuer = $3, client_id = $4, auth_login_url = $5, auth_token_url = $6,
+                jwks_url = $7, deployment_ids = $8, trust_email = $9, updated_at = NOW()
+         WHERE id = $1`,
+		p.ID, p.Nama, p.Issuer, p.ClientID, p.AuthLoginURL, p.AuthTokenURL, p.JWKSURL, pq.Array(nonNilStrings(p.DeploymentIDs)), p.TrustEmail,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *PostgresStore) DeleteLTIPlatform(id string) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM lti_platforms WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetLTIKeys mengembalikan kunci tool dari yang terbaru
+func (s *PostgresStore) GetLTIKeys() ([]*models.LTIKey, error) {
+	rows, err := s.db.Query(`SELECT kid, private_key, aktif, created_at FROM lti_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.LTIKey{}
+	for rows.Next() {
+		var k models.LTIKey
+		if err := rows.Scan(&k.Kid, &k.PrivateKey, &k.Aktif, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &k)
+	}
+	return keys, rows.Err()
+}
+
+func (s *PostgresStore) CreateLTIKey(kid string, privateKey string) error {
+	_, err := s.db.Exec(`INSERT INTO lti_keys (kid, private_key) VALUES ($1, $2)`, kid, privateKey)
+	return err
+}
+
+// EnsureLTIKey mengembalikan kunci aktif terbaru, atau menyimpan kunci yang diberikan jika belum
+// ada kunci aktif. Advisory lock mencegah peluncuran pertama yang bersamaan membuat beberapa
+// kunci aktif.
+func (s *PostgresStore) EnsureLTIKey(kid string, privateKey string) (*models.LTIKey, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('lti_keys'))`); err != nil {
+		return nil, err
+	}
+	var k models.LTIKey
+	err = tx.QueryRow(
+		`SELECT kid, private_key, aktif, created_at FROM lti_keys WHERE aktif ORDER BY created_at DESC LIMIT 1`,
+	).Scan(&k.Kid, &k.PrivateKey, &k.Aktif, &k.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRow(
+			`INSERT INTO lti_keys (kid, private_key) VALUES ($1, $2) RETURNING kid, private_key, aktif, created_at`,
+			kid, privateKey,
+		).Scan(&k.Kid, &k.PrivateKey, &k.Aktif, &k.CreatedAt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// DeleteRetiredLTIKeys menghapus kunci yang sudah digantikan kunci aktif yang lebih baru sebelum
+// replacedBefore, sehingga platform sempat memuat ulang JWKS sebelum kunci lama hilang
+func (s *PostgresStore) DeleteRetiredLTIKeys(replacedBefore time.Time) (int64, error) {
+	res, err := s.db.Exec(
+		`DELETE FROM lti_keys k
+         WHERE EXISTS (SELECT 1 FROM lti_keys n
+                       WHERE n.aktif AND n.created_at > k.created_at AND n.created_at < $1)`,
+		replacedBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SaveLTILoginState menyimpan state dan nonce inisiasi login OIDC sampai expiresAt
+func (s *PostgresStore) SaveLTILoginState(state string, nonce string, platformID string, expiresAt time.Time) error {
+	_, err := s.db.Exec(
+		`INSERT INTO lti_login_states (state, nonce, platform_id, expires_at) VALUES ($1, $2, $3, $4)`,
+		state, nonce, platformID, expiresAt,
+	)
+	return err
+}
+
+// ConsumeLTILoginState menghapus state dan mengembalikan nonce serta platformnya. State yang
+// tidak ada atau kedaluwarsa menghasilkan sql.ErrNoRows sehingga peluncuran tidak dapat diulang.
+func (s *PostgresStore) ConsumeLTILoginState(state string) (string, string, error) {
+	var nonce, platformID string
+	var expiresAt time.Time
+	err := s.db.QueryRow(
+		`DELETE FROM lti_login_states WHERE state = $1 RETURNING nonce, platform_id, expires_at`, state,
+	).Scan(&nonce, &platformID, &expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+	if time.Now().After(expiresAt) {
+		return "", "", sql.ErrNoRows
+	}
+	return nonce, platformID, nil
+}
+
+// ResolveLTIUser mengembalikan akun untuk pengguna platform. Klaim email dikendalikan platform
+// dan tidak terverifikasi, sehingga pengguna baru hanya dicocokkan dengan akun guru atau siswa
+// yang emailnya sama jika platform ditandai trust_email oleh superadmin (akun superadmin tidak
+// pernah ditautkan otomatis). Selain itu dibuat akun LTI tersendiri dengan password acak
+// sehingga login hanya lewat LTI sampai password direset.
+func (s *PostgresStore) ResolveLTIUser(identity *models.LTIIdentity) (*models.User, error) {
+	var userID string
+	err := s.db.QueryRow(
+		`SELECT user_id FROM lti_users WHERE platform_id = $1 AND sub = $2`, identity.PlatformID, identity.Sub,
+	).Scan(&userID)
+	if err == nil {
+		return s.GetUserByID(userID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var trustEmail bool
+	if err := tx.QueryRow(`SELECT trust_email FROM lti_platforms WHERE id = $1`, identity.PlatformID).Scan(&trustEmail); err != nil {
+		return nil, err
+	}
+	email := strings.ToLower(strings.TrimSpace(identity.Email))
+	if trustEmail && email != "" {
+		err = tx.QueryRow(`SELECT id FROM users WHERE LOWER(email) = $1 AND deleted_at IS NULL AND peran <> 'superadmin'`, email).Scan(&userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+	}
+	if userID == "" {
+		digest := sha256.Sum256([]byte(identity.PlatformID + "\x00" + identity.Sub))
+		handle := "lti_" + hex.EncodeToString(digest[:8])
+		// Email yang tidak terverifikasi tidak disimpan agar tidak menempati email pemilik aslinya
+		if !trustEmail || email == "" {
+			email = handle + "@lti.invalid"
+		}
+		password := make([]byte, 24)
+		if _, err := rand.Read(password); err != nil {
+			return nil, err
+		}
+		hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(password)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		nama := identity.NamaLengkap
+		if nama == "" {
+			nama = handle
+		}
+		err = tx.QueryRow(
+			`INSERT INTO users (nama_lengkap, username, email, password, peran) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			nama, handle, email, string(hashed), identity.Peran,
+		).Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO lti_users (platform_id, sub, user_id) VALUES ($1, $2, $3) ON CONFLICT (platform_id, sub) DO NOTHING`,
+		identity.PlatformID, identity.Sub, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetUserByID(userID)
+}
+
+// GetLTIUserSubs memetakan ID pengguna ke sub platform untuk pengguna yang terhubung
+func (s *PostgresStore) GetLTIUserSubs(platformID string, userIDs []string) (map[string]string, error) {
+	rows, err := s.db.Query(
+		`SELECT user_id, sub FROM lti_users WHERE platform_id = $1 AND user_id = ANY($2::uuid[])`,
+		platformID, pq.Array(userIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := map[string]string{}
+	for rows.Next() {
+		var userID, sub string
+		if err := rows.Scan(&userID, &sub); err != nil {
+			return nil, err
+		}
+		subs[userID] = sub
+	}
+	return subs, rows.Err()
+}
+
+const ltiContextColumns = `id, platform_id, context_id, kelas_id, COALESCE(judul, ''), COALESCE(memberships_url, ''), COALESCE(lineitems_url, '')`
+
+func scanLTIContext(row rowScanner) (*models.LTIContext, error) {
+	var c models.LTIContext
+	err := row.Scan(&c.ID, &c.PlatformID, &c.ContextID, &c.KelasID, &c.Judul, &c.MembershipsURL, &c.LineItemsURL)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PostgresStore) GetLTIContext(platformID string, contextID string) (*models.LTIContext, error) {
+	return scanLTIContext(s.db.QueryRow(
+		`SELECT `+ltiContextColumns+` FROM lti_contexts WHERE platform_id = $1 AND context_id = $2`, platformID, contextID,
+	))
+}
+
+func (s *PostgresStore) GetLTIContextsByClass(classID string) ([]*models.LTIContext, error) {
+	rows, err := s.db.Query(`SELECT `+ltiContextColumns+` FROM lti_contexts WHERE kelas_id = $1`, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contexts := []*models.LTIContext{}
+	for rows.Next() {
+		c, err := scanLTIContext(rows)
+		if err != nil {
+			return nil, err
+		}
+		contexts = append(contexts, c)
+	}
+	return contexts, rows.Err()
+}
+
+// SaveLTIContext menautkan kursus ke kelas atau memperbarui URL layanannya. URL yang kosong
+// pada peluncuran tidak menghapus URL yang sudah tersimpan.
+func (s *PostgresStore) SaveLTIContext(c *models.LTIContext) error {
+	query := `INSERT INTO lti_contexts (platform_id, context_id, kelas_id, judul, memberships_url, lineitems_url)
+              VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''))
+              ON CONFLICT (platform_id, context_id) DO UPDATE SET
+                judul = COALESCE(EXCLUDED.judul, lti_contexts.judul),
+                memberships_url = COALESCE(EXCLUDED.memberships_url, lti_contexts.memberships_url),
+                lineitems_url = COALESCE(EXCLUDED.lineitems_url, lti_contexts.lineitems_url),
+                updated_at = NOW()
+              RETURNING id, kelas_id`
+	return s.db.QueryRow(query, c.PlatformID, c.ContextID, c.KelasID, c.Judul, c.MembershipsURL, c.LineItemsURL).Scan(&c.ID, &c.KelasID)
+}
+
+// SaveLTIResourceLink menyimpan tautan aktivitas. Tugas dan kolom nilai yang kosong tidak
+// menimpa nilai yang sudah tersimpan.
+func (s *PostgresStore) SaveLTIResourceLink(link *models.LTIResourceLink) error {
+	query := `INSERT INTO lti_resource_links (context_id, resource_link_id, assignment_id, lineitem_url)
+              VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, ''))
+              ON CONFLICT (context_id, resource_link_id) DO UPDATE SET
+                assignment_id = COALESCE(EXCLUDED.assignment_id, lti_resource_links.assignment_id),
+                lineitem_url = COALESCE(EXCLUDED.lineitem_url, lti_resource_links.lineitem_url),
+                updated_at = NOW()
+              RETURNING id, COALESCE(assignment_id::text, ''), COALESCE(lineitem_url, '')`
+	return s.db.QueryRow(query, link.ContextID, link.ResourceLinkID, link.AssignmentID, link.LineItemURL).
+		Scan(&link.ID, &link.AssignmentID, &link.LineItemURL)
+}
+
+// GetLTIResourceLinksByAssignment mengembalikan tautan platform untuk sebuah tugas
+func (s *PostgresStore) GetLTIResourceLinksByAssignment(assignmentID string) ([]*models.LTIResourceLink, error) {
+	rows, err := s.db.Query(
+		`SELECT rl.id, rl.context_id, rl.resource_link_id, rl.assignment_id, COALESCE(rl.lineitem_url, ''),
+                c.platform_id, COALESCE(c.lineitems_url, '')
+         FROM lti_resource_links rl
+         JOIN lti_contexts c ON c.id = rl.context_id
+         WHERE rl.assignment_id = $1`,
+		assignmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*models.LTIResourceLink{}
+	for rows.Next() {
+		var l models.LTIResourceLink
+		if err := rows.Scan(&l.ID, &l.ContextID, &l.ResourceLinkID, &l.AssignmentID, &l.LineItemURL, &l.PlatformID, &l.LineItemsURL); err != nil {
+			return nil, err
+		}
+		links = append(links, &l)
+	}
+	return links, rows.Err()
+}
+
+// AddClassMember mendaftarkan siswa ke kelas; tidak melakukan apa pun jika sudah terdaftar.
+// Mengembalikan true jika siswa baru ditambahkan.
+func (s *PostgresStore) AddClassMember(classID string, siswaID string) (bool, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO class_members (kelas_id, siswa_id) VALUES ($1, $2) ON CONFLICT (kelas_id, siswa_id) DO NOTHING`,
+		classID, siswaID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RemoveLTIClassMembers mengeluarkan siswa yang terhubung ke platform tetapi tidak ada di
+// keep. Siswa yang didaftarkan langsung (tanpa akun platform) tidak tersentuh.
+func (s *PostgresStore) RemoveLTIClassMembers(classID string, platformID string, keep []string) (int64, error) {
+	res, err := s.db.Exec(
+		`DELETE FROM class_members cm
+         USING lti_users lu
+         WHERE cm.kelas_id = $1 AND lu.platform_id = $2 AND lu.user_id = cm.siswa_id
+           AND NOT (cm.siswa_id = ANY($3::uuid[]))`,
+		classID, platformID, pq.Array(nonNilStrings(keep)),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *PostgresStore) CreateLTIDeepLinkSession(session *models.LTIDeepLinkSession) error {
+	query := `INSERT INTO lti_deep_link_sessions (platform_id, deployment_id, return_url, data, user_id, kelas_id, expires_at)
+              VALUES ($1, $2, $3, NULLIF($4, ''), $5, NULLIF($6, '')::uuid, $7)
+              RETURNING id`
+	return s.db.QueryRow(query, session.PlatformID, session.DeploymentID, session.ReturnURL, session.Data,
+		session.UserID, session.KelasID, session.ExpiresAt).Scan(&session.ID)
+}
+
+// GetLTIDeepLinkSession mengembalikan sesi milik userID yang belum kedaluwarsa
+func (s *PostgresStore) GetLTIDeepLinkSession(id string, userID string) (*models.LTIDeepLinkSession, error) {
+	var session models.LTIDeepLinkSession
+	err := s.db.QueryRow(
+		`SELECT id, platform_id, deployment_id, return_url, COALESCE(data, ''), user_id, COALESCE(kelas_id::text, ''), expires_at
+         FROM lti_deep_link_sessions WHERE id = $1 AND user_id = $2 AND expires_at > NOW()`,
+		id, userID,
+	).Scan(&session.ID, &session.PlatformID, &session.DeploymentID, &session.ReturnURL, &session.Data,
+		&session.UserID, &session.KelasID, &session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *PostgresStore) DeleteLTIDeepLinkSession(id string) error {
+	_, err := s.db.Exec(`DELETE FROM lti_deep_link_sessions WHERE id = $1`, id)
+	return err
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,315 @@
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"sistem-skripsi/backend/models"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk Material ---
+
+const materialColumns = `id, kelas_id, COALESCE(pengunggah_id::text, ''), judul, COALESCE(isi_materi, ''),
+                         COALESCE(file_url, ''), COALESCE(nama_file, ''), COALESCE(tipe_file, ''),
+                         COALESCE(ukuran_file, 0), versi_terkini, created_at, COALESCE(updated_at::text, '')`
+
+func scanMaterial(row rowScanner) (*models.Material, error) {
+	var m models.Material
+	err := row.Scan(
+		&m.ID, &m.KelasID, &m.PengunggahID, &m.Judul, &m.IsiMateri,
+		&m.FileURL, &m.NamaFile, &m.TipeFile,
+		&m.UkuranFile, &m.VersiTerkini, &m.CreatedAt, &m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Menyimpan materi baru sekaligus versi pertamanya beserta chunk konteks RAG
+func (s *PostgresStore) CreateMaterial(material *models.Material, chunks []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO materials (kelas_id, pengunggah_id, judul, isi_materi, file_url, nama_file, tipe_file, ukuran_file)
+              VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, 0))
+              RETURNING id, versi_terkini, created_at, updated_at`
+	err = tx.QueryRow(
+		query,
+		material.KelasID,
+		material.PengunggahID,
+		material.Judul,
+		material.IsiMateri,
+		material.FileURL,
+		material.NamaFile,
+		material.TipeFile,
+		material.UkuranFile,
+	).Scan(&material.ID, &material.VersiTerkini, &material.CreatedAt, &material.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	var versionID string
+	query = `INSERT INTO material_versions (materi_id, nomor_versi, judul, isi_materi, file_url, content_hash, editor_id)
+             VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+             RETURNING id`
+	err = tx.QueryRow(
+		query,
+		material.ID,
+		material.VersiTerkini,
+		material.Judul,
+		material.IsiMateri,
+		material.FileURL,
+		materialContentHash(material.Judul, material.IsiMateri),
+		material.PengunggahID,
+	).Scan(&versionID)
+	if err != nil {
+		return err
+	}
+	if err := insertChunks(tx, versionID, chunks); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) GetMaterialByID(id string) (*models.Material, error) {
+	return scanMaterial(s.db.QueryRow(`SELECT `+materialColumns+` FROM materials WHERE id = $1`, id))
+}
+
+func (s *PostgresStore) GetMaterialsByClassID(classID string) ([]*models.Material, error) {
+	rows, err := s.db.Query(`SELECT `+materialColumns+` FROM materials WHERE kelas_id = $1 ORDER BY created_at`, classID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	materials := []*models.Material{}
+	for rows.Next() {
+		m, err := scanMaterial(rows)
+		if err != nil {
+			return nil, err
+		}
+		materials = append(materials, m)
+	}
+	return materials, rows.Err()
+}
+
+// Menghapus materi beserta versinya dan mengembalikan kunci file yang tidak lagi dirujuk oleh
+// materi atau versi mana pun. File dapat dipakai bersama oleh salinan kelas, sehingga hanya
+// file yang benar-benar yatim yang boleh dihapus dari blobstore.
+func (s *PostgresStore) DeleteMaterial(id string) (int64, []string, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	var files []string
+	err = tx.QueryRow(
+		`SELECT COALESCE(ARRAY_AGG(DISTINCT f) FILTER (WHERE f IS NOT NULL AND f <> ''), '{}')
+         FROM (SELECT file_url AS f FROM materials WHERE id = $1
+               UNION ALL
+               SELECT file_url FROM material_versions WHERE materi_id = $1) t`,
+		id,
+	).Scan(pq.Array(&files))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM materials WHERE id = $1`, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var orphaned []string
+	if rowsAffected > 0 && len(files) > 0 {
+		err = tx.QueryRow(
+			`SELECT COALESCE(ARRAY_AGG(f), '{}') FROM unnest($1::text[]) AS f
+             WHERE NOT EXISTS (SELECT 1 FROM materials WHERE file_url = f)
+               AND NOT EXISTS (SELECT 1 FROM material_versions WHERE file_url = f)`,
+			pq.Array(files),
+		).Scan(pq.Array(&orphaned))
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+	return rowsAffected, orphaned, nil
+}
+
+// --- Implementasi method untuk Material Version ---
+
+// Hash konten yang dilihat AI. Rumus ini harus sama dengan backfill pada migrasi 000009.
+func materialContentHash(judul string, isiMateri string) string {
+	sum := sha256.Sum256([]byte(judul + "\n\n" + isiMateri))
+	return hex.EncodeToString(sum[:])
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertChunks menyimpan chunk sesuai urutannya; chunk yang sudah ada tidak diubah
+func insertChunks(db execer, versionID string, chunks []string) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	_, err := db.Exec(
+		`INSERT INTO material_chunks (versi_id, urutan, isi)
+         SELECT $1, t.urutan, t.isi FROM unnest($2::text[]) WITH ORDINALITY AS t(isi, urutan)
+         ON CONFLICT (versi_id, urutan) DO NOTHING`,
+		versionID, pq.Array(chunks),
+	)
+	return err
+}
+
+// CreateMaterialVersion menyimpan konten baru sebagai versi berikutnya. Jika hash konten sama
+// dengan versi terkini, tidak ada versi baru dan versi terkini dikembalikan dengan created=false.
+func (s *PostgresStore) CreateMaterialVersion(materialID string, judul string, isiMateri string, editorID string, chunks []string) (*models.MaterialVersion, bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var current int
+	var currentHash, fileURL string
+	err = tx.QueryRow(
+		`SELECT m.versi_terkini, v.content_hash, COALESCE(m.file_url, '')
+         FROM materials m JOIN material_versions v ON v.materi_id = m.id AND v.nomor_versi = m.versi_terkini
+         WHERE m.id = $1 FOR UPDATE OF m`,
+		materialID,
+	).Scan(&current, &currentHash, &fileURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash := materialContentHash(judul, isiMateri)
+	if hash == currentHash {
+		version, err := s.GetMaterialVersion(materialID, current)
+		return version, false, err
+	}
+
+	version := models.MaterialVersion{
+		MateriID:    materialID,
+		NomorVersi:  current + 1,
+		Judul:       judul,
+		IsiMateri:   isiMateri,
+		FileURL:     fileURL,
+		ContentHash: hash,
+		EditorID:    editorID,
+	}
+	query := `INSERT INTO material_versions (materi_id, nomor_versi, judul, isi_materi, file_url, content_hash, editor_id)
+              VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+              RETURNING id, created_at`
+	err = tx.QueryRow(query, materialID, version.NomorVersi, judul, isiMateri, fileURL, hash, editorID).Scan(&version.ID, &version.CreatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := insertChunks(tx, version.ID, chunks); err != nil {
+		return nil, false, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE materials SET judul = $2, isi_materi = $3, versi_terkini = $4, updated_at = NOW() WHERE id = $1`,
+		materialID, judul, isiMateri, version.NomorVersi,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	version.Chunks, err = s.getVersionChunks(version.ID, nil)
+	return &version, true, err
+}
+
+const materialVersionColumns = `id, materi_id, nomor_versi, judul, COALESCE(isi_materi, ''), COALESCE(file_url, ''),
+                                content_hash, COALESCE(editor_id::text, ''), created_at`
+
+func scanMaterialVersion(row rowScanner) (*models.MaterialVersion, error) {
+	var v models.MaterialVersion
+	err := row.Scan(&v.ID, &v.MateriID, &v.NomorVersi, &v.Judul, &v.IsiMateri, &v.FileURL, &v.ContentHash, &v.EditorID, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Riwayat versi tanpa isi materi, dari yang terbaru
+func (s *PostgresStore) GetMaterialVersions(materialID string) ([]*models.MaterialVersion, error) {
+	query := `SELECT id, materi_id, nomor_versi, judul, '', COALESCE(file_url, ''), content_hash, COALESCE(editor_id::text, ''), created_at
+              FROM material_versions WHERE materi_id = $1 ORDER BY nomor_versi DESC`
+	rows, err := s.db.Query(query, materialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*models.MaterialVersion{}
+	for rows.Next() {
+		v, err := scanMaterialVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Satu versi lengkap beserta seluruh chunk-nya
+func (s *PostgresStore) GetMaterialVersion(materialID string, nomorVersi int) (*models.MaterialVersion, error) {
+	query := `SELECT ` + materialVersionColumns + ` FROM material_versions WHERE materi_id = $1 AND nomor_versi = $2`
+	version, err := scanMaterialVersion(s.db.QueryRow(query, materialID, nomorVersi))
+	if err != nil {
+		return nil, err
+	}
+	version.Chunks, err = s.getVersionChunks(version.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// Melengkapi chunk untuk versi yang belum memilikinya (mis. hasil backfill migrasi)
+func (s *PostgresStore) SaveVersionChunks(versionID string, chunks []string) error {
+	return insertChunks(s.db, versionID, chunks)
+}
+
+// getVersionChunks mengambil chunk sebuah versi; jika ids tidak nil hanya chunk tersebut
+func (s *PostgresStore) getVersionChunks(versionID string, ids []string) ([]*models.MaterialChunk, error) {
+	query := `SELECT id, versi_id, urutan, isi FROM material_chunks
+              WHERE versi_id = $1 AND ($2::uuid[] IS NULL OR id = ANY($2::uuid[]))
+              ORDER BY urutan`
+	var idFilter any
+	if ids != nil {
+		idFilter = pq.Array(ids)
+	}
+	rows, err := s.db.Query(query, versionID, idFilter)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chunks := []*models.MaterialChunk{}
+	for rows.Next() {
+		var c models.MaterialChunk
+		if err := rows.Scan(&c.ID, &c.VersiID, &c.Urutan, &c.Isi); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, &c)
+	}
+	return chunks, rows.Err()
+}
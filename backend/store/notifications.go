@@ -0,0 +1,385 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk notifikasi ---
+
+const notificationColumns = `id, user_id, jenis, judul, isi, COALESCE(tautan, ''), COALESCE(kelas_id::text, ''),
+                             dibaca_pada, created_at`
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var n models.Notification
+	var dibaca sql.NullTime
+	err := row.Scan(&n.ID, &n.UserID, &n.Jenis, &n.Judul, &n.Isi, &n.Tautan, &n.KelasID, &dibaca, &n.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if dibaca.Valid {
+		n.Dibaca = true
+		n.DibacaPada = &dibaca.Time
+	}
+	return &n, nil
+}
+
+// Notifikasi jenis ini tidak dibuat jika pengguna menonaktifkannya
+const notificationEnabled = `NOT EXISTS (SELECT 1 FROM notification_preferences p
+                                         WHERE p.user_id = $1 AND p.jenis = $2 AND NOT p.aktif)`
+
+// CreateNotifications menyimpan notifikasi sesuai preferensi penerima dan mengembalikan yang
+// benar-benar dibuat. Notifikasi dengan kunci yang sudah ada untuk penerima yang sama dilewati.
+func (s *PostgresStore) CreateNotifications(notifications []*models.Notification) ([]*models.Notification, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO notifications (user_id, jenis, judul, isi, tautan, kelas_id, kunci)
+         SELECT $1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, '')::uuid, NULLIF($7, '')
+         WHERE ` + notificationEnabled + `
+         ON CONFLICT (user_id, kunci) DO NOTHING
+         RETURNING id, created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	created := []*models.Notification{}
+	for _, n := range notifications {
+		err := stmt.QueryRow(n.UserID, n.Jenis, n.Judul, n.Isi, n.Tautan, n.KelasID, n.Kunci).Scan(&n.ID, &n.CreatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, n)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *PostgresStore) GetNotifications(userID string, unreadOnly bool, page int, limit int) ([]*models.Notification, int, error) {
+	var total int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND (NOT $2 OR dibaca_pada IS NULL)`,
+		userID, unreadOnly,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+notificationColumns+` FROM notifications
+         WHERE user_id = $1 AND (NOT $2 OR dibaca_pada IS NULL)
+         ORDER BY created_at DESC
+         LIMIT $3 OFFSET $4`,
+		userID, unreadOnly, limit, (page-1)*limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, total, rows.Err()
+}
+
+func (s *PostgresStore) CountUnreadNotifications(userID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND dibaca_pada IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkNotification menandai notifikasi milik pengguna sebagai dibaca atau belum dibaca.
+// Waktu baca pertama dipertahankan jika notifikasi ditandai dibaca lagi.
+func (s *PostgresStore) MarkNotification(userID string, id string, read bool) (*models.Notification, error) {
+	return scanNotification(s.db.QueryRow(
+		`UPDATE notifications
+         SET dibaca_pada = CASE WHEN $3 THEN COALESCE(dibaca_pada, NOW()) ELSE NULL END
+         WHERE id = $1 AND user_id = $2
+         RETURNING `+notificationColumns,
+		id, userID, read,
+	))
+}
+
+func (s *PostgresStore) MarkAllNotificationsRead(userID string) (int64, error) {
+	res, err := s.db.Exec(`UPDATE notifications SET dibaca_pada = NOW() WHERE user_id = $1 AND dibaca_pada IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetNotificationPreferences mengembalikan preferensi semua jenis notifikasi, termasuk nilai
+// default untuk jenis yang belum pernah diatur
+func (s *PostgresStore) GetNotificationPreferences(userID string) ([]*models.NotificationPreference, error) {
+	rows, err := s.db.Query(`SELECT jenis, aktif, email FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := map[string]*models.NotificationPreference{}
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := rows.Scan(&p.Jenis, &p.Aktif, &p.Email); err != nil {
+			return nil, err
+		}
+		saved[p.Jenis] = &p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prefs := make([]*models.NotificationPreference, 0, len(models.NotificationKinds))
+	for _, jenis := range models.NotificationKinds {
+		if p, ok := saved[jenis]; ok {
+			prefs = append(prefs, p)
+		} else {
+			prefs = append(prefs, &models.NotificationPreference{Jenis: jenis, Aktif: true, Email: true})
+		}
+	}
+	return prefs, nil
+}
+
+func (s *PostgresStore) SaveNotificationPreferences(userID string, prefs []models.NotificationPreference) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		_, err := tx.Exec(
+			`INSERT INTO notification_preferences (user_id, jenis, aktif, email) VALUES ($1, $2, $3, $4)
+             ON CONFLICT (user_id, jenis) DO UPDATE SET aktif = EXCLUDED.aktif, email = EXCLUDED.email, updated_at = NOW()`,
+			userID, p.Jenis, p.Aktif, p.Email,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetClassMemberIDs mengembalikan ID siswa aktif di kelas
+func (s *PostgresStore) GetClassMemberIDs(classID string) ([]string, error) {
+	return s.queryIDs(
+		`SELECT cm.siswa_id FROM class_members cm
+         JOIN users u ON u.id = cm.siswa_id AND u.is_active AND u.deleted_at IS NULL
+         WHERE cm.kelas_id = $1`,
+		classID,
+	)
+}
+
+// GetClassReviewerIDs mengembalikan pemilik kelas dan staf yang sudah menerima undangan
+func (s *PostgresStore) GetClassReviewerIDs(classID string) ([]string, error) {
+	return s.queryIDs(
+		`SELECT guru_id FROM classes WHERE id = $1
+         UNION
+         SELECT user_id FROM class_staff WHERE kelas_id = $1 AND status = 'accepted'`,
+		classID,
+	)
+}
+
+func (s *PostgresStore) queryIDs(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetOpenedAssignments mengembalikan tugas di kelas aktif yang mulai terlihat siswa dalam
+// rentang (since, now], yaitu saat dibuat atau saat dibuka, mana yang lebih akhir
+func (s *PostgresStore) GetOpenedAssignments(since time.Time, now time.Time) ([]*models.Assignment, error) {
+	rows, err := s.db.Query(
+		`SELECT a.id, a.kelas_id, a.judul, a.ditutup_pada, a.zona_waktu, c.nama_kelas
+         FROM assignments a
+         JOIN classes c ON c.id = a.kelas_id AND c.archived_at IS NULL
+         WHERE GREATEST(a.dibuka_pada, a.created_at) > $1 AND GREATEST(a.dibuka_pada, a.created_at) <= $2
+         ORDER BY a.dibuka_pada`,
+		since, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*models.Assignment{}
+	for rows.Next() {
+		var a models.Assignment
+		if err := rows.Scan(&a.ID, &a.KelasID, &a.Judul, &a.DitutupPada, &a.ZonaWaktu, &a.NamaKelas); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, &a)
+	}
+	return assignments, rows.Err()
+}
+
+// GetDeadlineReminders mengembalikan siswa yang belum mengumpulkan semua soal tugas dengan
+// tenggat (termasuk perpanjangan) dalam rentang (now, now+lead] dan belum diingatkan
+func (s *PostgresStore) GetDeadlineReminders(now time.Time, lead time.Duration) ([]*models.DeadlineReminder, error) {
+	rows, err := s.db.Query(
+		`SELECT a.id, a.kelas_id, c.nama_kelas, a.judul, cm.siswa_id,
+                COALESCE(e.ditutup_pada, a.ditutup_pada) AS tenggat, a.zona_waktu
+         FROM assignments a
+         JOIN classes c ON c.id = a.kelas_id AND c.archived_at IS NULL
+         JOIN class_members cm ON cm.kelas_id = a.kelas_id
+         JOIN users u ON u.id = cm.siswa_id AND u.is_active AND u.deleted_at IS NULL
+         LEFT JOIN assignment_extensions e ON e.assignment_id = a.id AND e.siswa_id = cm.siswa_id
+         WHERE a.dibuka_pada <= $1
+           AND COALESCE(e.ditutup_pada, a.ditutup_pada) > $1
+           AND COALESCE(e.ditutup_pada, a.ditutup_pada) <= $2
+           AND (SELECT COUNT(DISTINCT es.soal_id) FROM essay_submissions es
+                WHERE es.assignment_id = a.id AND es.siswa_id = cm.siswa_id)
+               < (SELECT COUNT(*) FROM assignment_questions aq WHERE aq.assignment_id = a.id)
+           AND NOT EXISTS (SELECT 1 FROM notifications n
+                           WHERE n.user_id = cm.siswa_id AND n.kunci = 'tenggat_dekat:' || a.id::text)
+         ORDER BY tenggat`,
+		now, now.Add(lead),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []*models.DeadlineReminder{}
+	for rows.Next() {
+		var r models.DeadlineReminder
+		if err := rows.Scan(&r.AssignmentID, &r.KelasID, &r.NamaKelas, &r.Judul, &r.SiswaID, &r.DitutupPada, &r.ZonaWaktu); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, &r)
+	}
+	return reminders, rows.Err()
+}
+
+// Notifikasi yang masuk ringkasan email: belum dibaca, belum pernah diemailkan, dan jenisnya
+// tidak dimatikan untuk email
+const digestPending = `n.dibaca_pada IS NULL AND n.email_dikirim_pada IS NULL
+                       AND NOT EXISTS (SELECT 1 FROM notification_preferences p
+                                       WHERE p.user_id = n.user_id AND p.jenis = n.jenis AND NOT p.email)`
+
+// GetDigestRecipients mengembalikan pengguna aktif yang memiliki notifikasi untuk ringkasan dan
+// belum menerima ringkasan pada tanggal tersebut
+func (s *PostgresStore) GetDigestRecipients(date string) ([]*models.DigestRecipient, error) {
+	rows, err := s.db.Query(
+		`SELECT u.id, u.nama_lengkap, u.email FROM users u
+         WHERE u.is_active AND u.deleted_at IS NULL
+           AND EXISTS (SELECT 1 FROM notifications n WHERE n.user_id = u.id AND `+digestPending+`)
+           AND NOT EXISTS (SELECT 1 FROM notification_digests d WHERE d.user_id = u.id AND d.tanggal = $1::date)`,
+		date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []*models.DigestRecipient{}
+	for rows.Next() {
+		var r models.DigestRecipient
+		if err := rows.Scan(&r.UserID, &r.NamaLengkap, &r.Email); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, &r)
+	}
+	return recipients, rows.Err()
+}
+
+func (s *PostgresStore) GetDigestNotifications(userID string, limit int) ([]*models.Notification, error) {
+	rows, err := s.db.Query(
+		`SELECT `+notificationColumns+` FROM notifications n
+         WHERE n.user_id = $1 AND `+digestPending+`
+         ORDER BY n.created_at DESC
+         LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// ClaimNotificationDigest mencatat ringkasan hari itu sebelum dikirim agar instance lain tidak
+// mengirim ringkasan yang sama. Mengembalikan false jika sudah diklaim.
+func (s *PostgresStore) ClaimNotificationDigest(userID string, date string) (bool, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO notification_digests (user_id, tanggal) VALUES ($1, $2::date) ON CONFLICT DO NOTHING`,
+		userID, date,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CompleteNotificationDigest menandai notifikasi yang sudah masuk ringkasan
+func (s *PostgresStore) CompleteNotificationDigest(userID string, date string, notificationIDs []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE notifications SET email_dikirim_pada = NOW() WHERE user_id = $1 AND id = ANY($2::uuid[])`,
+		userID, pq.Array(notificationIDs),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE notification_digests SET jumlah = $3, dikirim_pada = NOW() WHERE user_id = $1 AND tanggal = $2::date`,
+		userID, date, len(notificationIDs),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReleaseNotificationDigest menghapus klaim ringkasan yang gagal dikirim agar dicoba lagi
+func (s *PostgresStore) ReleaseNotificationDigest(userID string, date string) error {
+	_, err := s.db.Exec(`DELETE FROM notification_digests WHERE user_id = $1 AND tanggal = $2::date`, userID, date)
+	return err
+}
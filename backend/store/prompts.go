@@ -0,0 +1,115 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk Prompt Template ---
+
+const promptTemplateColumns = `pt.id, pt.level_kognitif::text, pt.versi, pt.isi, COALESCE(pt.catatan, ''),
+                               COALESCE(pt.dibuat_oleh::text, ''), pa.template_id IS NOT NULL, pt.created_at`
+
+const promptTemplateFrom = ` FROM prompt_templates pt
+              LEFT JOIN prompt_template_aktif pa ON pa.template_id = pt.id`
+
+func scanPromptTemplate(row rowScanner) (*models.PromptTemplate, error) {
+	var t models.PromptTemplate
+	err := row.Scan(&t.ID, &t.LevelKognitif, &t.Versi, &t.Isi, &t.Catatan, &t.DibuatOleh, &t.Aktif, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *PostgresStore) queryPromptTemplates(query string, args ...any) ([]*models.PromptTemplate, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.PromptTemplate{}
+	for rows.Next() {
+		t, err := scanPromptTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// Template aktif untuk setiap level kognitif
+func (s *PostgresStore) GetActivePromptTemplates() ([]*models.PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + promptTemplateFrom + ` WHERE pa.template_id IS NOT NULL ORDER BY pt.level_kognitif`
+	return s.queryPromptTemplates(query)
+}
+
+// Riwayat versi template sebuah level, dari yang terbaru
+func (s *PostgresStore) GetPromptTemplateVersions(level string) ([]*models.PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + promptTemplateFrom + ` WHERE pt.level_kognitif = $1 ORDER BY pt.versi DESC`
+	return s.queryPromptTemplates(query, level)
+}
+
+func (s *PostgresStore) GetPromptTemplate(level string, versi int) (*models.PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + promptTemplateFrom + ` WHERE pt.level_kognitif = $1 AND pt.versi = $2`
+	return scanPromptTemplate(s.db.QueryRow(query, level, versi))
+}
+
+func (s *PostgresStore) GetActivePromptTemplate(level string) (*models.PromptTemplate, error) {
+	query := `SELECT ` + promptTemplateColumns + promptTemplateFrom + ` WHERE pa.level_kognitif = $1`
+	return scanPromptTemplate(s.db.QueryRow(query, level))
+}
+
+// CreatePromptTemplate menyimpan versi baru (nomor versi terakhir + 1) dan, jika diminta,
+// langsung menjadikannya versi aktif
+func (s *PostgresStore) CreatePromptTemplate(template *models.PromptTemplate, activate bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Kunci per level agar dua penyimpanan bersamaan tidak mendapat nomor versi yang sama
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('prompt_templates:' || $1))`, template.LevelKognitif); err != nil {
+		return err
+	}
+	query := `INSERT INTO prompt_templates (level_kognitif, versi, isi, catatan, dibuat_oleh)
+              SELECT $1::cognitive_level, COALESCE(MAX(versi), 0) + 1, $2, NULLIF($3, ''), NULLIF($4, '')::uuid
+              FROM prompt_templates WHERE level_kognitif = $1::cognitive_level
+              RETURNING id, versi, created_at`
+	err = tx.QueryRow(query, template.LevelKognitif, template.Isi, template.Catatan, template.DibuatOleh).
+		Scan(&template.ID, &template.Versi, &template.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if activate {
+		if err := setActivePromptTemplate(tx, template.LevelKognitif, template.ID); err != nil {
+			return err
+		}
+		template.Aktif = true
+	}
+	return tx.Commit()
+}
+
+// ActivatePromptTemplate menjadikan versi tertentu sebagai template aktif levelnya
+func (s *PostgresStore) ActivatePromptTemplate(level string, versi int) (*models.PromptTemplate, error) {
+	var id string
+	err := s.db.QueryRow(`SELECT id FROM prompt_templates WHERE level_kognitif = $1 AND versi = $2`, level, versi).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	if err := setActivePromptTemplate(s.db, level, id); err != nil {
+		return nil, err
+	}
+	return s.GetPromptTemplate(level, versi)
+}
+
+func setActivePromptTemplate(db execer, level string, templateID string) error {
+	_, err := db.Exec(
+		`INSERT INTO prompt_template_aktif (level_kognitif, template_id) VALUES ($1, $2)
+         ON CONFLICT (level_kognitif) DO UPDATE SET template_id = EXCLUDED.template_id, updated_at = NOW()`,
+		level, templateID,
+	)
+	return err
+}
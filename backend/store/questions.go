@@ -0,0 +1,189 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk Question ---
+
+// Menyimpan soal beserta rubriknya dalam satu transaksi
+func (s *PostgresStore) CreateQuestion(question *models.Question) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO essay_questions (materi_id, teks_soal, level_kognitif, kunci_jawaban)
+              VALUES ($1, $2, NULLIF($3, '')::cognitive_level, NULLIF($4, ''))
+              RETURNING id`
+	err = tx.QueryRow(query, question.MateriID, question.TeksSoal, question.LevelKognitif, question.KunciJawaban).Scan(&question.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, rubric := range question.Rubrik {
+		rubric.SoalID = question.ID
+		err := tx.QueryRow(
+			`INSERT INTO rubrics (soal_id, nama_aspek, deskripsi, bobot, skor_min, skor_maks)
+             VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6) RETURNING id`,
+			rubric.SoalID, rubric.NamaAspek, rubric.Deskripsi, rubric.Bobot, rubric.SkorMin, rubric.SkorMaks,
+		).Scan(&rubric.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const questionColumns = `q.id, q.materi_id, q.teks_soal, COALESCE(q.level_kognitif::text, ''), COALESCE(q.kunci_jawaban, '')`
+
+func (s *PostgresStore) GetQuestionsByMaterialID(materialID string) ([]*models.Question, error) {
+	query := `SELECT ` + questionColumns + ` FROM essay_questions q WHERE q.materi_id = $1 ORDER BY q.level_kognitif, q.id`
+	return s.queryQuestions(query, materialID)
+}
+
+// Soal pada tugas sesuai urutan yang ditentukan guru
+func (s *PostgresStore) GetAssignmentQuestions(assignmentID string) ([]*models.Question, error) {
+	query := `SELECT ` + questionColumns + ` FROM essay_questions q
+              JOIN assignment_questions aq ON aq.soal_id = q.id
+              WHERE aq.assignment_id = $1 ORDER BY aq.urutan`
+	return s.queryQuestions(query, assignmentID)
+}
+
+// queryQuestions menjalankan query soal lalu melengkapinya dengan rubrik masing-masing
+func (s *PostgresStore) queryQuestions(query string, args ...any) ([]*models.Question, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := []*models.Question{}
+	byID := map[string]*models.Question{}
+	var ids []string
+	for rows.Next() {
+		var q models.Question
+		if err := rows.Scan(&q.ID, &q.MateriID, &q.TeksSoal, &q.LevelKognitif, &q.KunciJawaban); err != nil {
+			return nil, err
+		}
+		questions = append(questions, &q)
+		byID[q.ID] = &q
+		ids = append(ids, q.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return questions, nil
+	}
+
+	rows, err = s.db.Query(
+		`SELECT id, soal_id, nama_aspek, COALESCE(deskripsi, ''), COALESCE(bobot, 0), skor_min, skor_maks
+         FROM rubrics WHERE soal_id = ANY($1::uuid[]) ORDER BY nama_aspek`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.Rubric
+		if err := rows.Scan(&r.ID, &r.SoalID, &r.NamaAspek, &r.Deskripsi, &r.Bobot, &r.SkorMin, &r.SkorMaks); err != nil {
+			return nil, err
+		}
+		byID[r.SoalID].Rubrik = append(byID[r.SoalID].Rubrik, &r)
+	}
+	return questions, rows.Err()
+}
+
+// --- Analisis butir soal ---
+
+// GetItemObservations mengambil skor akhir percobaan terakhir untuk soal-soal materi, beserta
+// skor soal lain pada tugas yang memuat soal tersebut untuk menghitung skor total tugas
+func (s *PostgresStore) GetItemObservations(materialID string) ([]*models.ItemObservation, error) {
+	query := `SELECT DISTINCT ON (f.siswa_id, f.soal_id, f.assignment_id)
+                     f.soal_id, COALESCE(f.assignment_id::text, ''), f.siswa_id, f.skor
+              FROM submission_final_scores f
+              WHERE f.soal_id IN (SELECT id FROM essay_questions WHERE materi_id = $1)
+                 OR f.assignment_id IN (
+                    SELECT aq.assignment_id FROM assignment_questions aq
+                    JOIN essay_questions q ON q.id = aq.soal_id
+                    WHERE q.materi_id = $1)
+              ORDER BY f.siswa_id, f.soal_id, f.assignment_id, f.submitted_at DESC`
+	rows, err := s.db.Query(query, materialID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	observations := []*models.ItemObservation{}
+	for rows.Next() {
+		var o models.ItemObservation
+		if err := rows.Scan(&o.SoalID, &o.AssignmentID, &o.SiswaID, &o.Skor); err != nil {
+			return nil, err
+		}
+		observations = append(observations, &o)
+	}
+	return observations, rows.Err()
+}
+
+// GetItemReviewStats menghitung jawaban yang ditinjau guru dan yang skornya berbeda dari
+// skor AI lebih dari tolerance, per soal materi
+func (s *PostgresStore) GetItemReviewStats(materialID string, tolerance float64) ([]*models.ItemReviewStats, error) {
+	query := `SELECT q.id, COUNT(*), COUNT(*) FILTER (WHERE ABS(tr.skor_final - ar.skor_ai) > $2)
+              FROM essay_questions q
+              JOIN essay_submissions es ON es.soal_id = q.id
+              JOIN teacher_reviews tr ON tr.submission_id = es.id
+              JOIN ai_results ar ON ar.submission_id = es.id AND ar.status = 'berhasil' AND ar.skor_ai IS NOT NULL
+              WHERE q.materi_id = $1
+              GROUP BY q.id`
+	rows, err := s.db.Query(query, materialID, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.ItemReviewStats{}
+	for rows.Next() {
+		var st models.ItemReviewStats
+		if err := rows.Scan(&st.SoalID, &st.Ditinjau, &st.Dikoreksi); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &st)
+	}
+	return stats, rows.Err()
+}
+
+// GetItemFeedback mengambil umpan balik AI dan catatan guru terbaru, paling banyak limit
+// jawaban per soal materi
+func (s *PostgresStore) GetItemFeedback(materialID string, limit int) ([]*models.ItemFeedback, error) {
+	query := `SELECT soal_id, teks FROM (
+                SELECT es.soal_id, x.teks,
+                       DENSE_RANK() OVER (PARTITION BY es.soal_id ORDER BY es.submitted_at DESC, es.id) AS peringkat
+                FROM essay_submissions es
+                JOIN essay_questions q ON q.id = es.soal_id
+                LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+                LEFT JOIN ai_results ar ON ar.submission_id = es.id AND ar.status = 'berhasil'
+                CROSS JOIN LATERAL (VALUES (tr.catatan_guru), (ar.umpan_balik_ai)) AS x(teks)
+                WHERE q.materi_id = $1 AND COALESCE(x.teks, '') <> ''
+              ) t
+              WHERE peringkat <= $2`
+	rows, err := s.db.Query(query, materialID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := []*models.ItemFeedback{}
+	for rows.Next() {
+		var f models.ItemFeedback
+		if err := rows.Scan(&f.SoalID, &f.Teks); err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, &f)
+	}
+	return feedback, rows.Err()
+}
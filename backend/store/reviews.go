@@ -0,0 +1,108 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+)
+
+// --- Implementasi method untuk tinjauan guru ---
+
+// GetReviewQueue mengembalikan jawaban di kelas yang belum ditinjau guru. Jawaban dengan
+// tanda kemiripan berstatus baru ditampilkan lebih dulu, lalu dari yang terlama.
+func (s *PostgresStore) GetReviewQueue(classID string, filter models.ReviewQueueFilter) ([]*models.ReviewQueueItem, int, error) {
+	base := `FROM essay_submissions es
+             JOIN essay_questions q ON q.id = es.soal_id
+             JOIN materials m ON m.id = q.materi_id
+             JOIN users u ON u.id = es.siswa_id
+             LEFT JOIN ai_results ar ON ar.submission_id = es.id
+             LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+             LEFT JOIN LATERAL (
+                 SELECT MAX(f.skor) AS maks
+                 FROM similarity_flags f
+                 WHERE (f.submission_id = es.id OR f.pembanding_id = es.id) AND f.status = 'baru'
+             ) flag ON true
+             WHERE m.kelas_id = $1 AND tr.id IS NULL
+               AND ($2 = '' OR es.assignment_id::text = $2)
+               AND (NOT $3 OR flag.maks IS NOT NULL)`
+	args := []any{classID, filter.AssignmentID, filter.HanyaDitandai}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) `+base, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT es.id, COALESCE(es.assignment_id::text, ''), es.soal_id, q.teks_soal, es.siswa_id, u.nama_lengkap,
+                     es.teks_jawaban, es.percobaan_ke, es.penalti_persen, ar.skor_ai, COALESCE(ar.status::text, ''), es.submitted_at, flag.maks ` + base + `
+              ORDER BY flag.maks DESC NULLS LAST, es.submitted_at
+              LIMIT $4 OFFSET $5`
+	rows, err := s.db.Query(query, append(args, filter.Limit, (filter.Page-1)*filter.Limit)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := []*models.ReviewQueueItem{}
+	for rows.Next() {
+		var item models.ReviewQueueItem
+		var skorAI, maks sql.NullFloat64
+		err := rows.Scan(
+			&item.SubmissionID, &item.AssignmentID, &item.SoalID, &item.TeksSoal, &item.SiswaID, &item.NamaSiswa,
+			&item.TeksJawaban, &item.PercobaanKe, &item.PenaltiPersen, &skorAI, &item.StatusAI, &item.SubmittedAt, &maks,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		if skorAI.Valid {
+			item.SkorAI = &skorAI.Float64
+		}
+		if maks.Valid {
+			item.KemiripanMaks = &maks.Float64
+		}
+		items = append(items, &item)
+	}
+	return items, total, rows.Err()
+}
+
+// Menyimpan atau mengganti tinjauan guru untuk sebuah jawaban beserta skor per aspeknya.
+// Mengembalikan ErrRubricNotInQuestion jika aspek bukan milik soal jawaban tersebut.
+func (s *PostgresStore) SaveTeacherReview(review *models.TeacherReview) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO teacher_reviews (submission_id, skor_final, catatan_guru, reviewer_id)
+              VALUES ($1, $2, NULLIF($3, ''), $4)
+              ON CONFLICT (submission_id) DO UPDATE SET
+                skor_final = EXCLUDED.skor_final,
+                catatan_guru = EXCLUDED.catatan_guru,
+                reviewer_id = EXCLUDED.reviewer_id,
+                reviewed_at = NOW()
+              RETURNING id, reviewed_at`
+	err = tx.QueryRow(query, review.SubmissionID, review.SkorFinal, review.CatatanGuru, review.ReviewerID).Scan(&review.ID, &review.ReviewedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM teacher_aspect_scores WHERE review_id = $1`, review.ID); err != nil {
+		return err
+	}
+	for _, aspect := range review.Aspek {
+		res, err := tx.Exec(
+			`INSERT INTO teacher_aspect_scores (review_id, rubric_id, skor)
+             SELECT $1, r.id, $3 FROM rubrics r
+             JOIN essay_submissions es ON es.soal_id = r.soal_id
+             WHERE r.id::text = $2 AND es.id = $4
+             ON CONFLICT (review_id, rubric_id) DO UPDATE SET skor = EXCLUDED.skor`,
+			review.ID, aspect.RubricID, aspect.Skor, review.SubmissionID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrRubricNotInQuestion
+		}
+	}
+	return tx.Commit()
+}
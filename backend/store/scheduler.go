@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"sistem-skripsi/backend/models"
+	"time"
+)
+
+// --- Implementasi method untuk scheduler ---
+
+// Namespace advisory lock scheduler agar tidak bentrok dengan advisory lock lain
+const jobLockNamespace = 4801
+
+// TryJobLock memakai advisory lock tingkat sesi pada koneksi khusus. Jika instance mati, sesi
+// Postgres berakhir dan kunci terlepas dengan sendirinya.
+func (s *PostgresStore) TryJobLock(ctx context.Context, job string) (func(), bool, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1, hashtext($2))`, jobLockNamespace, job).Scan(&acquired)
+	if err != nil || !acquired {
+		conn.Close()
+		return nil, false, err
+	}
+	release := func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, jobLockNamespace, job); err != nil {
+			log.Printf("Gagal melepas kunci job %s: %v", job, err)
+			// Koneksi dibuang agar kunci ikut terlepas bersama sesinya
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+func (s *PostgresStore) ClaimJobSlot(job string, slot time.Time) (bool, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO scheduler_jobs (nama, jadwal_terakhir) VALUES ($1, $2)
+         ON CONFLICT (nama) DO UPDATE SET jadwal_terakhir = EXCLUDED.jadwal_terakhir, updated_at = NOW()
+         WHERE scheduler_jobs.jadwal_terakhir < EXCLUDED.jadwal_terakhir`,
+		job, slot,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *PostgresStore) StartJobRun(run *models.JobRun) error {
+	return s.db.QueryRow(
+		`INSERT INTO job_runs (job, pemicu, dipicu_oleh, jadwal, status, instance, mulai_pada)
+         VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7)
+         RETURNING id`,
+		run.Job, run.Pemicu, run.DipicuOleh, run.Jadwal, run.Status, run.Instance, run.MulaiPada,
+	).Scan(&run.ID)
+}
+
+func (s *PostgresStore) FinishJobRun(run *models.JobRun) error {
+	_, err := s.db.Exec(
+		`UPDATE job_runs SET status = $2, jumlah_diproses = $3, galat = NULLIF($4, ''), selesai_pada = $5, durasi_ms = $6
+         WHERE id = $1`,
+		run.ID, run.Status, run.JumlahDiproses, run.Galat, run.SelesaiPada, run.DurasiMS,
+	)
+	return err
+}
+
+const jobRunColumns = `id, job, pemicu, COALESCE(dipicu_oleh::text, ''), jadwal, status, jumlah_diproses,
+                       COALESCE(galat, ''), instance, mulai_pada, selesai_pada, COALESCE(durasi_ms, 0)`
+
+func scanJobRun(row rowScanner) (*models.JobRun, error) {
+	var run models.JobRun
+	var jadwal, selesai sql.NullTime
+	err := row.Scan(&run.ID, &run.Job, &run.Pemicu, &run.DipicuOleh, &jadwal, &run.Status, &run.JumlahDiproses,
+		&run.Galat, &run.Instance, &run.MulaiPada, &selesai, &run.DurasiMS)
+	if err != nil {
+		return nil, err
+	}
+	if jadwal.Valid {
+		run.Jadwal = &jadwal.Time
+	}
+	if selesai.Valid {
+		run.SelesaiPada = &selesai.Time
+	}
+	return &run, nil
+}
+
+func (s *PostgresStore) GetJobRuns(job string, status string, page int, limit int) ([]*models.JobRun, int, error) {
+	var total int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM job_runs WHERE job = $1 AND ($2 = '' OR status = $2)`,
+		job, status,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+jobRunColumns+` FROM job_runs
+         WHERE job = $1 AND ($2 = '' OR status = $2)
+         ORDER BY mulai_pada DESC
+         LIMIT $3 OFFSET $4`,
+		job, status, limit, (page-1)*limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	runs := []*models.JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, rows.Err()
+}
+
+// GetLatestJobRuns mengembalikan eksekusi terakhir setiap job
+func (s *PostgresStore) GetLatestJobRuns() (map[string]*models.JobRun, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT ON (job) ` + jobRunColumns + ` FROM job_runs ORDER BY job, mulai_pada DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := map[string]*models.JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs[run.Job] = run
+	}
+	return runs, rows.Err()
+}
+
+func (s *PostgresStore) PruneJobRuns(before time.Time) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM job_runs WHERE mulai_pada < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
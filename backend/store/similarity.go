@@ -0,0 +1,160 @@
+package store
+
+import (
+	"sistem-skripsi/backend/models"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk deteksi kemiripan ---
+
+// Jawaban yang belum memiliki sidik jari, dari yang terlama
+func (s *PostgresStore) GetUnfingerprintedSubmissions(limit int) ([]*models.Submission, error) {
+	query := `SELECT es.id, es.soal_id, es.siswa_id, COALESCE(es.assignment_id::text, ''), es.teks_jawaban, es.submitted_at
+              FROM essay_submissions es
+              LEFT JOIN submission_fingerprints f ON f.submission_id = es.id
+              WHERE f.submission_id IS NULL
+              ORDER BY es.submitted_at
+              LIMIT $1`
+	rows, err := s.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []*models.Submission{}
+	for rows.Next() {
+		var sub models.Submission
+		if err := rows.Scan(&sub.ID, &sub.SoalID, &sub.SiswaID, &sub.AssignmentID, &sub.TeksJawaban, &sub.SubmittedAt); err != nil {
+			return nil, err
+		}
+		submissions = append(submissions, &sub)
+	}
+	return submissions, rows.Err()
+}
+
+// Sidik jari jawaban siswa lain untuk soal yang sama. Percobaan milik siswa yang sama
+// dikecualikan karena wajar mirip satu sama lain.
+func (s *PostgresStore) GetQuestionFingerprints(soalID string, excludeSiswaID string) ([]*models.SubmissionFingerprint, error) {
+	query := `SELECT f.submission_id, es.siswa_id, f.minhash, f.simhash, f.jumlah_shingle
+              FROM submission_fingerprints f
+              JOIN essay_submissions es ON es.id = f.submission_id
+              WHERE es.soal_id = $1 AND es.siswa_id <> $2`
+	rows, err := s.db.Query(query, soalID, excludeSiswaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fingerprints := []*models.SubmissionFingerprint{}
+	for rows.Next() {
+		var f models.SubmissionFingerprint
+		var minhash pq.Int64Array
+		var simhash int64
+		if err := rows.Scan(&f.SubmissionID, &f.SiswaID, &minhash, &simhash, &f.JumlahShingle); err != nil {
+			return nil, err
+		}
+		f.MinHash = make([]uint64, len(minhash))
+		for i, v := range minhash {
+			f.MinHash[i] = uint64(v)
+		}
+		f.SimHash = uint64(simhash)
+		fingerprints = append(fingerprints, &f)
+	}
+	return fingerprints, rows.Err()
+}
+
+// Isi materi terkini tempat soal berada
+func (s *PostgresStore) GetQuestionMaterialText(soalID string) (string, error) {
+	var isi string
+	query := `SELECT COALESCE(m.isi_materi, '') FROM essay_questions q JOIN materials m ON m.id = q.materi_id WHERE q.id = $1`
+	err := s.db.QueryRow(query, soalID).Scan(&isi)
+	return isi, err
+}
+
+// Menyimpan sidik jari jawaban beserta tanda kemiripan yang ditemukan
+func (s *PostgresStore) SaveFingerprint(fingerprint *models.SubmissionFingerprint, flags []*models.SimilarityFlag) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	minhash := make(pq.Int64Array, len(fingerprint.MinHash))
+	for i, v := range fingerprint.MinHash {
+		minhash[i] = int64(v)
+	}
+	_, err = tx.Exec(
+		`INSERT INTO submission_fingerprints (submission_id, minhash, simhash, jumlah_shingle)
+         VALUES ($1, $2, $3, $4)
+         ON CONFLICT (submission_id) DO NOTHING`,
+		fingerprint.SubmissionID, minhash, int64(fingerprint.SimHash), fingerprint.JumlahShingle,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, flag := range flags {
+		_, err := tx.Exec(
+			`INSERT INTO similarity_flags (submission_id, pembanding_id, jenis, metode, skor)
+             VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5)
+             ON CONFLICT (submission_id, COALESCE(pembanding_id, '00000000-0000-0000-0000-000000000000'::uuid), jenis) DO NOTHING`,
+			flag.SubmissionID, flag.PembandingID, flag.Jenis, flag.Metode, flag.Skor,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Tanda kemiripan yang melibatkan jawaban, baik sebagai jawaban baru maupun pembanding.
+// Untuk tanda yang dilihat dari sisi pembanding, kedua id ditukar agar SubmissionID selalu
+// jawaban yang diminta.
+func (s *PostgresStore) GetSimilarityFlags(submissionID string) ([]*models.SimilarityFlag, error) {
+	query := `SELECT f.id, $1::uuid::text,
+                     COALESCE(CASE WHEN f.submission_id = $1 THEN f.pembanding_id ELSE f.submission_id END::text, ''),
+                     COALESCE(u.nama_lengkap, ''), f.jenis::text, f.metode, f.skor, f.status::text,
+                     COALESCE(f.ditinjau_oleh::text, ''), f.created_at
+              FROM similarity_flags f
+              LEFT JOIN essay_submissions other
+                     ON other.id = CASE WHEN f.submission_id = $1 THEN f.pembanding_id ELSE f.submission_id END
+              LEFT JOIN users u ON u.id = other.siswa_id
+              WHERE f.submission_id = $1 OR f.pembanding_id = $1
+              ORDER BY f.skor DESC`
+	rows, err := s.db.Query(query, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []*models.SimilarityFlag{}
+	for rows.Next() {
+		var f models.SimilarityFlag
+		err := rows.Scan(&f.ID, &f.SubmissionID, &f.PembandingID, &f.SiswaPembanding, &f.Jenis, &f.Metode, &f.Skor, &f.Status, &f.DitinjauOleh, &f.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, &f)
+	}
+	return flags, rows.Err()
+}
+
+func (s *PostgresStore) GetSimilarityFlagClassID(flagID string) (string, error) {
+	var classID string
+	query := `SELECT m.kelas_id FROM similarity_flags f
+              JOIN essay_submissions es ON es.id = f.submission_id
+              JOIN essay_questions q ON q.id = es.soal_id
+              JOIN materials m ON m.id = q.materi_id
+              WHERE f.id = $1`
+	err := s.db.QueryRow(query, flagID).Scan(&classID)
+	return classID, err
+}
+
+func (s *PostgresStore) UpdateSimilarityFlagStatus(flagID string, status string, reviewerID string) (int64, error) {
+	result, err := s.db.Exec(`UPDATE similarity_flags SET status = $2, ditinjau_oleh = $3 WHERE id = $1`, flagID, status, reviewerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
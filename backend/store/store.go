@@ -1,8 +1,13 @@
 package store
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"sistem-skripsi/backend/models"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -13,12 +18,205 @@ type Store interface {
 	CreateUser(user *models.User) error
 	GetUserByIdentifier(identifier string) (*models.User, string, error)
 	GetTeachers() ([]*models.User, error)
-	DeleteUserByID(id string, role string) (int64, error)
+	SoftDeleteUser(id string, role string) (int64, error)
+	RestoreUser(id string, deletedAfter time.Time) (int64, error)
+	PurgeDeletedUsers(deletedBefore time.Time) (int64, error)
+	OffboardTeacher(teacherID string, handovers []models.ClassHandover) error
+	GetUserByID(id string) (*models.User, error)
+	GetUserPasswordHash(id string) (string, error)
+	ListUsers(filter models.UserFilter) ([]*models.User, int, error)
+	UpdateUserProfile(id string, req models.UpdateUserRequest) (*models.User, error)
+	UpdateUserRole(id string, peran string) (int64, error)
+	SetUserActive(id string, active bool) (int64, error)
+	SetUserPassword(id string, password string, mustChange bool) (int64, error)
 	// Class methods
 	CreateClass(class *models.Class) error
-	GetClassesByTeacherID(teacherID string) ([]*models.Class, error)
+	GetClassByID(id string) (*models.Class, error)
+	GetClassesByTeacherID(teacherID string, includeArchived bool) ([]*models.Class, error)
+	GetOwnedClassesByTeacherID(teacherID string) ([]*models.Class, error)
+	UpdateClass(id string, req models.UpdateClassRequest) (*models.Class, error)
+	SetClassArchived(id string, archived bool) (int64, error)
+	CloneClass(sourceID string, target *models.Class) error
+	// Material methods
+	CreateMaterial(material *models.Material, chunks []string) error
+	GetMaterialByID(id string) (*models.Material, error)
+	GetMaterialsByClassID(classID string) ([]*models.Material, error)
+	DeleteMaterial(id string) (int64, []string, error)
+	CreateMaterialVersion(materialID string, judul string, isiMateri string, editorID string, chunks []string) (*models.MaterialVersion, bool, error)
+	GetMaterialVersions(materialID string) ([]*models.MaterialVersion, error)
+	GetMaterialVersion(materialID string, nomorVersi int) (*models.MaterialVersion, error)
+	SaveVersionChunks(versionID string, chunks []string) error
+	// AI result methods
+	SaveAIResult(result *models.AIResult) error
+	GetGradingContext(submissionID string) (*models.GradingContext, error)
+	GetSubmissionClassID(submissionID string) (string, error)
+	GetSubmissionWithClass(submissionID string) (*models.Submission, string, error)
+	GetGradingInput(submissionID string) (*models.GradingInput, error)
+	GetUngradedSubmissions(limit int, now time.Time) ([]*models.Submission, error)
+	RecordGradingFailure(submissionID string, galat string, now time.Time, base time.Duration, maxDelay time.Duration) (int, error)
+	// Prompt template methods
+	GetActivePromptTemplates() ([]*models.PromptTemplate, error)
+	GetPromptTemplateVersions(level string) ([]*models.PromptTemplate, error)
+	GetPromptTemplate(level string, versi int) (*models.PromptTemplate, error)
+	GetActivePromptTemplate(level string) (*models.PromptTemplate, error)
+	CreatePromptTemplate(template *models.PromptTemplate, activate bool) error
+	ActivatePromptTemplate(level string, versi int) (*models.PromptTemplate, error)
+	// LLM usage methods
+	RecordLLMUsage(usage []*models.LLMUsage) error
+	IsLLMBudgetExceeded(submissionID string, since time.Time) (bool, error)
+	GetLLMUsageReport(filter models.LLMUsageFilter, loc *time.Location) ([]*models.LLMUsageReport, error)
+	GetLLMPrices() ([]*models.LLMPrice, error)
+	SetLLMPrice(price *models.LLMPrice) error
+	DeleteLLMPrice(provider string, model string) (int64, error)
+	GetLLMBudgets(since time.Time) ([]*models.LLMBudget, error)
+	SetLLMBudget(guruID string, batasUSD float64) error
+	DeleteLLMBudget(guruID string) (int64, error)
+	// Question methods
+	CreateQuestion(question *models.Question) error
+	GetQuestionsByMaterialID(materialID string) ([]*models.Question, error)
+	// Assignment methods
+	CreateAssignment(assignment *models.Assignment) error
+	GetAssignmentByID(id string) (*models.Assignment, error)
+	GetAssignmentsByClassID(classID string) ([]*models.Assignment, error)
+	UpdateAssignment(assignment *models.Assignment) error
+	DeleteAssignment(id string) (int64, error)
+	GetAssignmentQuestions(assignmentID string) ([]*models.Question, error)
+	GetItemObservations(materialID string) ([]*models.ItemObservation, error)
+	GetItemReviewStats(materialID string, tolerance float64) ([]*models.ItemReviewStats, error)
+	GetItemFeedback(materialID string, limit int) ([]*models.ItemFeedback, error)
+	SetAssignmentExtension(extension *models.AssignmentExtension) error
+	GetAssignmentExtension(assignmentID string, siswaID string) (*models.AssignmentExtension, error)
+	GetAssignmentExtensions(assignmentID string) ([]*models.AssignmentExtension, error)
+	DeleteAssignmentExtension(assignmentID string, siswaID string) (int64, error)
+	GetUpcomingAssignments(siswaID string, now time.Time) ([]*models.Assignment, error)
+	IsClassMember(classID string, siswaID string) (bool, error)
+	// Draft & submission methods
+	GetDraft(assignmentID string, soalID string, siswaID string) (*models.Draft, error)
+	SaveDraft(draft *models.Draft, expectedVersi int) error
+	SubmitDraft(submission *models.Submission, versi int, maksPercobaan int) error
+	GetSubmissionAttempts(assignmentID string, siswaID string) ([]*models.Submission, error)
+	// Exam methods
+	StartExamSession(session *models.ExamSession, tokenHash string) (bool, error)
+	GetExamSession(assignmentID string, siswaID string) (*models.ExamSession, string, error)
+	GetExamSessions(assignmentID string) ([]*models.ExamSession, error)
+	GetExpiredExamSessions(now time.Time) ([]*models.ExamSession, error)
+	UpdateExamSessionIP(sessionID string, ip string) error
+	FinishExamSession(sessionID string, alasan string, hariTerlambat int, penaltiPersen float64) ([]*models.Submission, error)
+	RecordIntegrityEvent(event *models.IntegrityEvent) error
+	GetIntegrityEvents(sessionID string) ([]*models.IntegrityEvent, error)
+	// Similarity methods
+	GetUnfingerprintedSubmissions(limit int) ([]*models.Submission, error)
+	GetQuestionFingerprints(soalID string, excludeSiswaID string) ([]*models.SubmissionFingerprint, error)
+	GetQuestionMaterialText(soalID string) (string, error)
+	SaveFingerprint(fingerprint *models.SubmissionFingerprint, flags []*models.SimilarityFlag) error
+	GetSimilarityFlags(submissionID string) ([]*models.SimilarityFlag, error)
+	GetSimilarityFlagClassID(flagID string) (string, error)
+	UpdateSimilarityFlagStatus(flagID string, status string, reviewerID string) (int64, error)
+	// Review methods
+	GetReviewQueue(classID string, filter models.ReviewQueueFilter) ([]*models.ReviewQueueItem, int, error)
+	SaveTeacherReview(review *models.TeacherReview) error
+	GetAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error)
+	GetAspectAgreementPairs(filter models.AgreementFilter) ([]*models.AgreementPair, error)
+	// Analytics methods
+	RefreshMasteryRollup() error
+	GetClassMastery(classID string, siswaID string, batasTuntas float64) (*models.ClassMastery, error)
+	GetMasteryTrend(classID string, siswaID string, interval string, zone string) ([]*models.MasteryTrendPoint, error)
+	GetMasteryHistogram(classID string, level string) ([]*models.HistogramBucket, error)
+	// Gradebook methods
+	StreamGradebook(classID string, siswaID string, withDetails bool, fn func(*models.GradebookStudent) error) error
+	// Class staff methods
+	GetClassAccess(classID string, userID string) (*models.ClassAccess, error)
+	GetClassStaff(classID string) ([]*models.ClassStaff, error)
+	InviteClassStaff(classID string, identifier string, peran string, invitedBy string) (*models.ClassStaff, error)
+	GetPendingStaffInvitations(userID string) ([]*models.ClassStaff, error)
+	RespondStaffInvitation(invitationID string, userID string, accept bool) (int64, error)
+	UpdateClassStaffRole(classID string, userID string, peran string) (int64, error)
+	RemoveClassStaff(classID string, userID string) (int64, error)
+	// LTI methods
+	GetLTIPlatforms() ([]*models.LTIPlatform, error)
+	GetLTIPlatform(id string) (*models.LTIPlatform, error)
+	FindLTIPlatform(issuer string, clientID string) (*models.LTIPlatform, error)
+	CreateLTIPlatform(p *models.LTIPlatform) error
+	UpdateLTIPlatform(p *models.LTIPlatform) (int64, error)
+	DeleteLTIPlatform(id string) (int64, error)
+	GetLTIKeys() ([]*models.LTIKey, error)
+	CreateLTIKey(kid string, privateKey string) error
+	EnsureLTIKey(kid string, privateKey string) (*models.LTIKey, error)
+	DeleteRetiredLTIKeys(replacedBefore time.Time) (int64, error)
+	SaveLTILoginState(state string, nonce string, platformID string, expiresAt time.Time) error
+	ConsumeLTILoginState(state string) (string, string, error)
+	ResolveLTIUser(identity *models.LTIIdentity) (*models.User, error)
+	GetLTIUserSubs(platformID string, userIDs []string) (map[string]string, error)
+	GetLTIContext(platformID string, contextID string) (*models.LTIContext, error)
+	GetLTIContextsByClass(classID string) ([]*models.LTIContext, error)
+	SaveLTIContext(c *models.LTIContext) error
+	SaveLTIResourceLink(link *models.LTIResourceLink) error
+	GetLTIResourceLinksByAssignment(assignmentID string) ([]*models.LTIResourceLink, error)
+	AddClassMember(classID string, siswaID string) (bool, error)
+	RemoveLTIClassMembers(classID string, platformID string, keep []string) (int64, error)
+	CreateLTIDeepLinkSession(session *models.LTIDeepLinkSession) error
+	GetLTIDeepLinkSession(id string, userID string) (*models.LTIDeepLinkSession, error)
+	DeleteLTIDeepLinkSession(id string) error
+	// Webhook methods
+	GetWebhooks(classID string) ([]*models.Webhook, error)
+	GetWebhook(id string) (*models.Webhook, error)
+	CreateWebhook(w *models.Webhook) error
+	UpdateWebhook(w *models.Webhook) (int64, error)
+	DeleteWebhook(id string) (int64, error)
+	EnqueueWebhookEvent(classID string, eventID string, event string, payload []byte) (int64, error)
+	CreateWebhookDelivery(webhookID string, eventID string, event string, payload []byte) (*models.WebhookDelivery, error)
+	RedeliverWebhookDelivery(webhookID string, deliveryID string) (*models.WebhookDelivery, error)
+	ClaimWebhookDeliveries(now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordWebhookAttempt(deliveryID string, attempt *models.WebhookAttempt, status string, nextAttemptAt time.Time) error
+	GetWebhookDeliveries(webhookID string, status string, page int, limit int) ([]*models.WebhookDelivery, int, error)
+	GetWebhookDelivery(webhookID string, deliveryID string) (*models.WebhookDelivery, error)
+	// Notification methods
+	CreateNotifications(notifications []*models.Notification) ([]*models.Notification, error)
+	GetNotifications(userID string, unreadOnly bool, page int, limit int) ([]*models.Notification, int, error)
+	CountUnreadNotifications(userID string) (int, error)
+	MarkNotification(userID string, id string, read bool) (*models.Notification, error)
+	MarkAllNotificationsRead(userID string) (int64, error)
+	GetNotificationPreferences(userID string) ([]*models.NotificationPreference, error)
+	SaveNotificationPreferences(userID string, prefs []models.NotificationPreference) error
+	GetClassMemberIDs(classID string) ([]string, error)
+	GetClassReviewerIDs(classID string) ([]string, error)
+	GetOpenedAssignments(since time.Time, now time.Time) ([]*models.Assignment, error)
+	GetDeadlineReminders(now time.Time, lead time.Duration) ([]*models.DeadlineReminder, error)
+	GetDigestRecipients(date string) ([]*models.DigestRecipient, error)
+	GetDigestNotifications(userID string, limit int) ([]*models.Notification, error)
+	ClaimNotificationDigest(userID string, date string) (bool, error)
+	CompleteNotificationDigest(userID string, date string, notificationIDs []string) error
+	ReleaseNotificationDigest(userID string, date string) error
+	// Scheduler methods
+	TryJobLock(ctx context.Context, job string) (func(), bool, error)
+	ClaimJobSlot(job string, slot time.Time) (bool, error)
+	StartJobRun(run *models.JobRun) error
+	FinishJobRun(run *models.JobRun) error
+	GetJobRuns(job string, status string, page int, limit int) ([]*models.JobRun, int, error)
+	GetLatestJobRuns() (map[string]*models.JobRun, error)
+	PruneJobRuns(before time.Time) (int64, error)
+	// Audit methods
+	AppendAuditEntry(e *models.AuditEntry) error
+	GetAuditLog(filter models.AuditFilter) ([]*models.AuditEntry, int, error)
+	StreamAuditLog(fn func(*models.AuditEntry) error) error
+	GetSubmissionScores(submissionID string) (*models.SubmissionScores, error)
 }
 
+// Error domain yang dikembalikan Store agar handler dapat memilih status HTTP yang tepat
+var (
+	ErrActiveClassesRemain   = errors.New("pengguna masih memiliki kelas aktif")
+	ErrInvalidTransferTarget = errors.New("guru tujuan tidak valid")
+	ErrIncompleteHandover    = errors.New("serah terima kelas tidak lengkap")
+	ErrInviteeNotTeacher     = errors.New("pengguna yang diundang bukan guru aktif")
+	ErrAlreadyClassStaff     = errors.New("pengguna sudah menjadi staf kelas")
+	ErrQuestionNotInClass    = errors.New("soal bukan milik kelas ini")
+	ErrDraftVersionConflict  = errors.New("draf telah diubah di tempat lain")
+	ErrEmptyAnswer           = errors.New("jawaban masih kosong")
+	ErrAttemptLimitReached   = errors.New("batas jumlah pengumpulan tercapai")
+	ErrExamFinished          = errors.New("sesi ujian sudah selesai")
+	ErrRubricNotInQuestion   = errors.New("aspek rubrik bukan milik soal ini")
+)
+
 // Implementasi Store untuk PostgreSQL
 type PostgresStore struct {
 	db *sql.DB
@@ -45,8 +243,8 @@ func (s *PostgresStore) CreateUser(user *models.User) error {
 		return err
 	}
 
-	query := `INSERT INTO users (nama_lengkap, username, email, password, peran) 
-              VALUES ($1, $2, $3, $4, $5) 
+	query := `INSERT INTO users (nama_lengkap, username, email, password, peran, nomor_identitas, must_change_password) 
+              VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7) 
               RETURNING id`
 	
 	return s.db.QueryRow(
@@ -56,6 +254,8 @@ func (s *PostgresStore) CreateUser(user *models.User) error {
 		user.Email,
 		string(hashedPassword),
 		user.Peran,
+		user.NomorIdentitas,
+		user.MustChangePassword,
 	).Scan(&user.ID)
 }
 
@@ -65,7 +265,7 @@ func (s *PostgresStore) GetUserByIdentifier(identifier string) (*models.User, st
 	var storedPasswordHash string
 	var username sql.NullString 
 
-	query := `SELECT id, nama_lengkap, username, email, password, peran FROM users WHERE email=$1 OR username=$1`
+	query := `SELECT id, nama_lengkap, username, email, password, peran, is_active, must_change_password, token_version FROM users WHERE (email=$1 OR username=$1) AND deleted_at IS NULL`
 	
 	err := s.db.QueryRow(query, identifier).Scan(
 		&user.ID,
@@ -74,6 +274,9 @@ func (s *PostgresStore) GetUserByIdentifier(identifier string) (*models.User, st
 		&user.Email,
 		&storedPasswordHash,
 		&user.Peran,
+		&user.IsActive,
+		&user.MustChangePassword,
+		&user.TokenVersion,
 	)
 	if err != nil {
 		return nil, "", err
@@ -87,7 +290,7 @@ func (s *PostgresStore) GetUserByIdentifier(identifier string) (*models.User, st
 }
 
 func (s *PostgresStore) GetTeachers() ([]*models.User, error) {
-	query := `SELECT id, nama_lengkap, username, email, peran FROM users WHERE peran = 'teacher'`
+	query := `SELECT id, nama_lengkap, username, email, peran FROM users WHERE peran = 'teacher' AND deleted_at IS NULL`
 	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -110,45 +313,312 @@ func (s *PostgresStore) GetTeachers() ([]*models.User, error) {
 }
 
 
-func (s *PostgresStore) DeleteUserByID(id string, role string) (int64, error) {
-	query := "DELETE FROM users WHERE id = $1 AND peran = $2"
-	result, err := s.db.Exec(query, id, role)
+// Menandai pengguna sebagai terhapus tanpa menghapus datanya. Role kosong berarti semua peran.
+// Guru yang masih memiliki kelas aktif harus melalui OffboardTeacher terlebih dahulu.
+func (s *PostgresStore) SoftDeleteUser(id string, role string) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Baris pengguna dikunci agar pemeriksaan peran, status dan kelas aktif tidak balapan
+	// dengan perubahan lain; kunci ini juga menahan pembuatan kelas baru untuk guru tersebut.
+	var locked string
+	err = tx.QueryRow(
+		`SELECT id FROM users WHERE id = $1 AND ($2 = '' OR peran::text = $2) AND deleted_at IS NULL FOR UPDATE`,
+		id, role,
+	).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var activeClasses int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM classes WHERE guru_id = $1 AND archived_at IS NULL`, id).Scan(&activeClasses)
+	if err != nil {
+		return 0, err
+	}
+	if activeClasses > 0 {
+		return 0, ErrActiveClassesRemain
+	}
+
+	result, err := tx.Exec(`UPDATE users SET deleted_at = NOW(), token_version = token_version + 1, updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return rowsAffected, tx.Commit()
+}
+
+// Memulihkan pengguna yang dihapus setelah deletedAfter (masih dalam masa retensi)
+func (s *PostgresStore) RestoreUser(id string, deletedAfter time.Time) (int64, error) {
+	query := `UPDATE users SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND deleted_at > $2 AND purged_at IS NULL`
+	result, err := s.db.Exec(query, id, deletedAfter)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-// --- Implementasi method untuk Class ---
+// Menganonimkan pengguna yang masa retensinya sudah lewat. Baris users tidak dihapus karena
+// jawaban siswa terhubung dengan ON DELETE CASCADE; data pribadi dihapus, id tetap dipakai
+// oleh jawaban dan nilai. Tautan LTI, keanggotaan staf dan notifikasi ikut dihapus.
+func (s *PostgresStore) PurgeDeletedUsers(deletedBefore time.Time) (int64, error) {
+	query := `WITH purged AS (
+                  UPDATE users SET nama_lengkap = 'Pengguna dihapus',
+                         username = 'dihapus-' || id::text,
+                         email = id::text || '@dihapus.invalid',
+                         password = '!',
+                         nomor_identitas = NULL,
+                         is_active = FALSE,
+                         must_change_password = FALSE,
+                         token_version = token_version + 1,
+                         purged_at = NOW(),
+                         updated_at = NOW()
+                  WHERE deleted_at IS NOT NULL AND deleted_at <= $1 AND purged_at IS NULL
+                  RETURNING id
+              ),
+              staff AS (DELETE FROM class_staff WHERE user_id IN (SELECT id FROM purged)),
+              lti AS (DELETE FROM lti_users WHERE user_id IN (SELECT id FROM purged)),
+              prefs AS (DELETE FROM notification_preferences WHERE user_id IN (SELECT id FROM purged)),
+              digests AS (DELETE FROM notification_digests WHERE user_id IN (SELECT id FROM purged)),
+              notes AS (DELETE FROM notifications WHERE user_id IN (SELECT id FROM purged))
+              SELECT COUNT(*) FROM purged`
+	var purged int64
+	if err := s.db.QueryRow(query, deletedBefore).Scan(&purged); err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
 
-func (s *PostgresStore) CreateClass(class *models.Class) error {
-	query := `INSERT INTO classes (guru_id, nama_kelas, deskripsi) 
-              VALUES ($1, $2, $3) 
-              RETURNING id, created_at`
+// Memindahkan atau mengarsipkan seluruh kelas aktif milik guru, lalu men-soft delete akunnya,
+// dalam satu transaksi. Setiap kelas aktif wajib memiliki keputusan serah terima.
+func (s *PostgresStore) OffboardTeacher(teacherID string, handovers []models.ClassHandover) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	return s.db.QueryRow(
-		query,
-		class.GuruID,
-		class.NamaKelas,
-		class.Deskripsi,
-	).Scan(&class.ID, &class.CreatedAt)
+	rows, err := tx.Query(`SELECT id FROM classes WHERE guru_id = $1 AND archived_at IS NULL FOR UPDATE`, teacherID)
+	if err != nil {
+		return err
+	}
+	owned := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		owned[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	decided := map[string]bool{}
+	for _, h := range handovers {
+		if !owned[h.KelasID] {
+			return ErrIncompleteHandover
+		}
+		decided[h.KelasID] = true
+	}
+	if len(decided) != len(owned) {
+		return ErrIncompleteHandover
+	}
+
+	for _, h := range handovers {
+		switch h.Aksi {
+		case "transfer":
+			if h.GuruTujuanID == teacherID {
+				return ErrInvalidTransferTarget
+			}
+			var valid bool
+			err := tx.QueryRow(
+				`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND peran = 'teacher' AND is_active AND deleted_at IS NULL)`,
+				h.GuruTujuanID,
+			).Scan(&valid)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				return ErrInvalidTransferTarget
+			}
+			if _, err := tx.Exec(`UPDATE classes SET guru_id = $2 WHERE id = $1`, h.KelasID, h.GuruTujuanID); err != nil {
+				return err
+			}
+			// Guru tujuan yang sebelumnya staf kini menjadi pemilik
+			if _, err := tx.Exec(`DELETE FROM class_staff WHERE kelas_id = $1 AND user_id = $2`, h.KelasID, h.GuruTujuanID); err != nil {
+				return err
+			}
+		case "archive":
+			if _, err := tx.Exec(`UPDATE classes SET archived_at = NOW() WHERE id = $1`, h.KelasID); err != nil {
+				return err
+			}
+		}
+	}
+
+	result, err := tx.Exec(
+		`UPDATE users SET deleted_at = NOW(), token_version = token_version + 1, updated_at = NOW()
+         WHERE id = $1 AND peran = 'teacher' AND deleted_at IS NULL`,
+		teacherID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
 }
 
-func (s *PostgresStore) GetClassesByTeacherID(teacherID string) ([]*models.Class, error) {
-	query := `SELECT id, guru_id, nama_kelas, deskripsi, created_at FROM classes WHERE guru_id = $1 ORDER BY created_at DESC`
-	rows, err := s.db.Query(query, teacherID)
+// Kolom yang dipilih setiap kali data lengkap pengguna dibaca
+const userColumns = `id, nama_lengkap, username, email, peran, nomor_identitas, is_active, must_change_password, token_version, created_at, updated_at, deleted_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	var username, nomorIdentitas, updatedAt, deletedAt sql.NullString
+	err := row.Scan(
+		&user.ID,
+		&user.NamaLengkap,
+		&username,
+		&user.Email,
+		&user.Peran,
+		&nomorIdentitas,
+		&user.IsActive,
+		&user.MustChangePassword,
+		&user.TokenVersion,
+		&user.CreatedAt,
+		&updatedAt,
+		&deletedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
+	user.Username = username.String
+	user.NomorIdentitas = nomorIdentitas.String
+	user.UpdatedAt = updatedAt.String
+	user.DeletedAt = deletedAt.String
+	return &user, nil
+}
+
+func (s *PostgresStore) GetUserByID(id string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(s.db.QueryRow(query, id))
+}
+
+func (s *PostgresStore) GetUserPasswordHash(id string) (string, error) {
+	var hash string
+	err := s.db.QueryRow(`SELECT password FROM users WHERE id = $1`, id).Scan(&hash)
+	return hash, err
+}
+
+func (s *PostgresStore) ListUsers(filter models.UserFilter) ([]*models.User, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Query != "" {
+		args = append(args, "%"+strings.ToLower(filter.Query)+"%")
+		n := len(args)
+		conditions = append(conditions, fmt.Sprintf(
+			"(LOWER(nama_lengkap) LIKE $%d OR LOWER(username) LIKE $%d OR LOWER(email) LIKE $%d OR LOWER(nomor_identitas) LIKE $%d)",
+			n, n, n, n,
+		))
+	}
+	if filter.Peran != "" {
+		args = append(args, filter.Peran)
+		conditions = append(conditions, fmt.Sprintf("peran = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.Deleted {
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	} else {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`,
+		userColumns, where, len(args)-1, len(args))
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
 	defer rows.Close()
 
-	var classes []*models.Class
+	users := []*models.User{}
 	for rows.Next() {
-		var c models.Class
-		if err := rows.Scan(&c.ID, &c.GuruID, &c.NamaKelas, &c.Deskripsi, &c.CreatedAt); err != nil {
-			return nil, err
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
 		}
-		classes = append(classes, &c)
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+func (s *PostgresStore) UpdateUserProfile(id string, req models.UpdateUserRequest) (*models.User, error) {
+	query := `UPDATE users SET
+                nama_lengkap = COALESCE($2, nama_lengkap),
+                username = COALESCE($3, username),
+                email = COALESCE($4, email),
+                nomor_identitas = COALESCE($5, nomor_identitas),
+                updated_at = NOW()
+              WHERE id = $1 AND deleted_at IS NULL
+              RETURNING ` + userColumns
+	return scanUser(s.db.QueryRow(query, id, req.NamaLengkap, req.Username, req.Email, req.NomorIdentitas))
+}
+
+// Mengganti peran sekaligus menaikkan token_version agar token dengan peran lama ditolak
+func (s *PostgresStore) UpdateUserRole(id string, peran string) (int64, error) {
+	query := `UPDATE users SET peran = $2, token_version = token_version + 1, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	result, err := s.db.Exec(query, id, peran)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *PostgresStore) SetUserActive(id string, active bool) (int64, error) {
+	query := `UPDATE users SET is_active = $2, token_version = token_version + 1, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	result, err := s.db.Exec(query, id, active)
+	if err != nil {
+		return 0, err
 	}
-	return classes, nil
+	return result.RowsAffected()
+}
+
+func (s *PostgresStore) SetUserPassword(id string, password string, mustChange bool) (int64, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE users SET password = $2, must_change_password = $3, token_version = token_version + 1, updated_at = NOW() WHERE id = $1`
+	result, err := s.db.Exec(query, id, string(hashedPassword), mustChange)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
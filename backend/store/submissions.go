@@ -0,0 +1,137 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"strings"
+)
+
+// --- Implementasi method untuk Draft & Submission ---
+
+func (s *PostgresStore) GetDraft(assignmentID string, soalID string, siswaID string) (*models.Draft, error) {
+	draft := models.Draft{AssignmentID: assignmentID, SoalID: soalID, SiswaID: siswaID}
+	query := `SELECT teks_jawaban, versi, updated_at FROM essay_drafts
+              WHERE assignment_id = $1 AND soal_id = $2 AND siswa_id = $3`
+	err := s.db.QueryRow(query, assignmentID, soalID, siswaID).Scan(&draft.TeksJawaban, &draft.Versi, &draft.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// SaveDraft menyimpan draf hanya jika versi di database masih sama dengan expectedVersi
+// (0 untuk draf baru). Jika tidak, ErrDraftVersionConflict dikembalikan dan draf tidak berubah.
+func (s *PostgresStore) SaveDraft(draft *models.Draft, expectedVersi int) error {
+	query := `UPDATE essay_drafts SET teks_jawaban = $4, versi = versi + 1, updated_at = NOW()
+              WHERE assignment_id = $1 AND soal_id = $2 AND siswa_id = $3 AND versi = $5
+              RETURNING versi, updated_at`
+	args := []any{draft.AssignmentID, draft.SoalID, draft.SiswaID, draft.TeksJawaban, expectedVersi}
+	if expectedVersi == 0 {
+		query = `INSERT INTO essay_drafts (assignment_id, soal_id, siswa_id, teks_jawaban)
+                 VALUES ($1, $2, $3, $4)
+                 ON CONFLICT (assignment_id, soal_id, siswa_id) DO NOTHING
+                 RETURNING versi, updated_at`
+		args = args[:4]
+	}
+
+	err := s.db.QueryRow(query, args...).Scan(&draft.Versi, &draft.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrDraftVersionConflict
+	}
+	return err
+}
+
+// SubmitDraft mengumpulkan isi draf pada versi tertentu sebagai percobaan berikutnya.
+// Baris draf dikunci selama transaksi sehingga pengumpulan bersamaan tidak melampaui
+// maksPercobaan. submission harus sudah berisi tugas, soal, siswa, dan data keterlambatan.
+func (s *PostgresStore) SubmitDraft(submission *models.Submission, versi int, maksPercobaan int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var currentVersi int
+	err = tx.QueryRow(
+		`SELECT teks_jawaban, versi FROM essay_drafts
+         WHERE assignment_id = $1 AND soal_id = $2 AND siswa_id = $3 FOR UPDATE`,
+		submission.AssignmentID, submission.SoalID, submission.SiswaID,
+	).Scan(&submission.TeksJawaban, &currentVersi)
+	if err != nil {
+		return err
+	}
+	if currentVersi != versi {
+		return ErrDraftVersionConflict
+	}
+	if strings.TrimSpace(submission.TeksJawaban) == "" {
+		return ErrEmptyAnswer
+	}
+
+	var attempts int
+	err = tx.QueryRow(
+		`SELECT COUNT(*) FROM essay_submissions WHERE assignment_id = $1 AND soal_id = $2 AND siswa_id = $3`,
+		submission.AssignmentID, submission.SoalID, submission.SiswaID,
+	).Scan(&attempts)
+	if err != nil {
+		return err
+	}
+	if attempts >= maksPercobaan {
+		return ErrAttemptLimitReached
+	}
+
+	submission.PercobaanKe = attempts + 1
+	query := `INSERT INTO essay_submissions (soal_id, siswa_id, assignment_id, teks_jawaban, percobaan_ke, hari_terlambat, penalti_persen)
+              VALUES ($1, $2, $3, $4, $5, $6, $7)
+              RETURNING id, submitted_at`
+	err = tx.QueryRow(
+		query,
+		submission.SoalID,
+		submission.SiswaID,
+		submission.AssignmentID,
+		submission.TeksJawaban,
+		submission.PercobaanKe,
+		submission.HariTerlambat,
+		submission.PenaltiPersen,
+	).Scan(&submission.ID, &submission.SubmittedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Semua percobaan siswa pada tugas beserta nilai AI dan nilai guru, urut per soal dan percobaan
+func (s *PostgresStore) GetSubmissionAttempts(assignmentID string, siswaID string) ([]*models.Submission, error) {
+	query := `SELECT es.id, es.soal_id, es.siswa_id, es.assignment_id, es.teks_jawaban, es.percobaan_ke,
+                     es.hari_terlambat, es.penalti_persen, ar.skor_ai, tr.skor_final, es.submitted_at
+              FROM essay_submissions es
+              LEFT JOIN ai_results ar ON ar.submission_id = es.id
+              LEFT JOIN teacher_reviews tr ON tr.submission_id = es.id
+              WHERE es.assignment_id = $1 AND es.siswa_id = $2
+              ORDER BY es.soal_id, es.percobaan_ke`
+	rows, err := s.db.Query(query, assignmentID, siswaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []*models.Submission{}
+	for rows.Next() {
+		var sub models.Submission
+		var skorAI, skorGuru sql.NullFloat64
+		err := rows.Scan(
+			&sub.ID, &sub.SoalID, &sub.SiswaID, &sub.AssignmentID, &sub.TeksJawaban, &sub.PercobaanKe,
+			&sub.HariTerlambat, &sub.PenaltiPersen, &skorAI, &skorGuru, &sub.SubmittedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if skorAI.Valid {
+			sub.SkorAI = &skorAI.Float64
+		}
+		if skorGuru.Valid {
+			sub.SkorGuru = &skorGuru.Float64
+		}
+		submissions = append(submissions, &sub)
+	}
+	return submissions, rows.Err()
+}
@@ -0,0 +1,303 @@
+package store
+
+import (
+	"database/sql"
+	"sistem-skripsi/backend/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// --- Implementasi method untuk webhook keluar ---
+
+const webhookColumns = `id, COALESCE(kelas_id::text, ''), url, events, COALESCE(deskripsi, ''), aktif,
+                        COALESCE(dibuat_oleh::text, ''), created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var w models.Webhook
+	var events pq.StringArray
+	err := row.Scan(&w.ID, &w.KelasID, &w.URL, &events, &w.Deskripsi, &w.Aktif, &w.DibuatOleh, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	w.Events = []string(events)
+	return &w, nil
+}
+
+// Langganan sebuah kelas; classID kosong mengembalikan langganan global
+func (s *PostgresStore) GetWebhooks(classID string) ([]*models.Webhook, error) {
+	rows, err := s.db.Query(
+		`SELECT `+webhookColumns+` FROM webhooks
+         WHERE ($1 = '' AND kelas_id IS NULL) OR kelas_id::text = $1
+         ORDER BY created_at`,
+		classID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (s *PostgresStore) GetWebhook(id string) (*models.Webhook, error) {
+	return scanWebhook(s.db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+}
+
+func (s *PostgresStore) CreateWebhook(w *models.Webhook) error {
+	query := `INSERT INTO webhooks (kelas_id, url, secret, events, deskripsi, aktif, dibuat_oleh)
+              VALUES (NULLIF($1, '')::uuid, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, '')::uuid)
+              RETURNING id, created_at, updated_at`
+	return s.db.QueryRow(
+		query, w.KelasID, w.URL, w.Secret, pq.Array(w.Events), w.Deskripsi, w.Aktif, w.DibuatOleh,
+	).Scan(&w.ID, &w.CreatedAt, &w.UpdatedAt)
+}
+
+// UpdateWebhook mengubah URL, peristiwa, deskripsi, dan status aktif; secret tidak berubah.
+// Menonaktifkan langganan membatalkan pengiriman yang masih menunggu.
+func (s *PostgresStore) UpdateWebhook(w *models.Webhook) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`UPDATE webhooks SET url = $2, events = $3, deskripsi = NULLIF($4, ''), aktif = $5, updated_at = NOW()
+         WHERE id = $1`,
+		w.ID, w.URL, pq.Array(w.Events), w.Deskripsi, w.Aktif,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 && !w.Aktif {
+		_, err = tx.Exec(
+			`UPDATE webhook_deliveries SET status = 'dibatalkan', galat_terakhir = 'Webhook dinonaktifkan'
+             WHERE webhook_id = $1 AND status = 'menunggu'`,
+			w.ID,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, tx.Commit()
+}
+
+func (s *PostgresStore) DeleteWebhook(id string) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// EnqueueWebhookEvent membuat pengiriman untuk setiap langganan aktif yang melanggan peristiwa,
+// yaitu langganan kelas terkait dan langganan global
+func (s *PostgresStore) EnqueueWebhookEvent(classID string, eventID string, event string, payload []byte) (int64, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+         SELECT id, $2, $3, $4 FROM webhooks
+         WHERE aktif AND $3 = ANY(events) AND (kelas_id IS NULL OR kelas_id::text = $1)`,
+		classID, eventID, event, string(payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CreateWebhookDelivery menjadwalkan satu pengiriman langsung ke sebuah langganan, mis. ping uji
+func (s *PostgresStore) CreateWebhookDelivery(webhookID string, eventID string, event string, payload []byte) (*models.WebhookDelivery, error) {
+	return scanWebhookDelivery(s.db.QueryRow(
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload)
+         VALUES ($1, $2, $3, $4)
+         RETURNING `+webhookDeliveryColumns,
+		webhookID, eventID, event, string(payload),
+	))
+}
+
+// RedeliverWebhookDelivery menjadwalkan ulang payload pengiriman lama sebagai pengiriman baru.
+// Pengiriman asal dan log percobaannya tidak diubah.
+func (s *PostgresStore) RedeliverWebhookDelivery(webhookID string, deliveryID string) (*models.WebhookDelivery, error) {
+	return scanWebhookDelivery(s.db.QueryRow(
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, dikirim_ulang_dari)
+         SELECT webhook_id, event_id, event, payload, id FROM webhook_deliveries
+         WHERE id = $1 AND webhook_id = $2
+         RETURNING `+webhookDeliveryColumns,
+		deliveryID, webhookID,
+	))
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event, payload, status, percobaan, next_attempt_at,
+                                status_code_terakhir, COALESCE(galat_terakhir, ''), COALESCE(dikirim_ulang_dari::text, ''),
+                                created_at, delivered_at`
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	var next time.Time
+	var delivered sql.NullTime
+	var statusCode sql.NullInt64
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &payload, &d.Status, &d.Percobaan, &next,
+		&statusCode, &d.GalatTerakhir, &d.DikirimUlangDari, &d.CreatedAt, &delivered)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if d.Status == models.WebhookDeliveryPending {
+		d.NextAttemptAt = &next
+	}
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		d.StatusCodeTerakhir = &code
+	}
+	if delivered.Valid {
+		d.DeliveredAt = &delivered.Time
+	}
+	return &d, nil
+}
+
+// ClaimWebhookDeliveries mengambil pengiriman yang jatuh tempo milik langganan aktif dan menunda
+// jadwalnya selama lease agar tidak diambil instance lain saat sedang dikirim
+func (s *PostgresStore) ClaimWebhookDeliveries(now time.Time, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	rows, err := s.db.Query(
+		`WITH due AS (
+             SELECT d.id FROM webhook_deliveries d
+             JOIN webhooks w ON w.id = d.webhook_id AND w.aktif
+             WHERE d.status = 'menunggu' AND d.next_attempt_at <= $1
+             ORDER BY d.next_attempt_at
+             LIMIT $2
+             FOR UPDATE OF d SKIP LOCKED
+         )
+         UPDATE webhook_deliveries d SET next_attempt_at = $3
+         FROM due, webhooks w
+         WHERE d.id = due.id AND w.id = d.webhook_id
+         RETURNING d.id, d.webhook_id, d.event_id, d.event, d.payload, d.percobaan, w.url, w.secret`,
+		now, limit, now.Add(lease),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &payload, &d.Percobaan, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		d.Status = models.WebhookDeliveryPending
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordWebhookAttempt mencatat hasil satu percobaan dan memperbarui status pengiriman.
+// nextAttemptAt hanya dipakai jika status tetap menunggu.
+func (s *PostgresStore) RecordWebhookAttempt(deliveryID string, attempt *models.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO webhook_delivery_attempts (delivery_id, percobaan_ke, status_code, respons, galat, durasi_ms, dicoba_pada)
+         VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7)`,
+		deliveryID, attempt.PercobaanKe, attempt.StatusCode, attempt.Respons, attempt.Galat, attempt.DurasiMS, attempt.DicobaPada,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`UPDATE webhook_deliveries
+         SET status = $2, percobaan = $3, next_attempt_at = $4, status_code_terakhir = $5,
+             galat_terakhir = NULLIF($6, ''),
+             delivered_at = CASE WHEN $2 = 'berhasil' THEN $7 ELSE delivered_at END
+         WHERE id = $1`,
+		deliveryID, status, attempt.PercobaanKe, nextAttemptAt, attempt.StatusCode, attempt.Galat, attempt.DicobaPada,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Log pengiriman sebuah langganan, terbaru lebih dulu. Payload tidak disertakan.
+func (s *PostgresStore) GetWebhookDeliveries(webhookID string, status string, page int, limit int) ([]*models.WebhookDelivery, int, error) {
+	base := `FROM webhook_deliveries WHERE webhook_id = $1 AND ($2 = '' OR status = $2)`
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) `+base, webhookID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+webhookDeliveryColumns+` `+base+` ORDER BY created_at DESC LIMIT $3 OFFSET $4`,
+		webhookID, status, limit, (page-1)*limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		d.Payload = nil
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// Detail pengiriman beserta payload dan log setiap percobaannya
+func (s *PostgresStore) GetWebhookDelivery(webhookID string, deliveryID string) (*models.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(s.db.QueryRow(
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2`,
+		deliveryID, webhookID,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT percobaan_ke, status_code, COALESCE(respons, ''), COALESCE(galat, ''), durasi_ms, dicoba_pada
+         FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY percobaan_ke`,
+		deliveryID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	d.RiwayatPercobaan = []*models.WebhookAttempt{}
+	for rows.Next() {
+		var a models.WebhookAttempt
+		var statusCode sql.NullInt64
+		if err := rows.Scan(&a.PercobaanKe, &statusCode, &a.Respons, &a.Galat, &a.DurasiMS, &a.DicobaPada); err != nil {
+			return nil, err
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			a.StatusCode = &code
+		}
+		d.RiwayatPercobaan = append(d.RiwayatPercobaan, &a)
+	}
+	return d, rows.Err()
+}
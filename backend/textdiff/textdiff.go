@@ -0,0 +1,125 @@
+// Package textdiff menghitung perbedaan per baris antara dua teks (algoritma Myers).
+package textdiff
+
+import "strings"
+
+// Jenis operasi pada hasil diff
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Op adalah satu blok baris berurutan dengan jenis operasi yang sama
+type Op struct {
+	Op    string   `json:"op"`
+	Baris []string `json:"baris"`
+}
+
+// Lines mengembalikan urutan operasi yang mengubah teks a menjadi teks b
+func Lines(a string, b string) []Op {
+	x, y := splitLines(a), splitLines(b)
+	var ops []Op
+	push := func(op string, line string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Baris = append(ops[n-1].Baris, line)
+			return
+		}
+		ops = append(ops, Op{Op: op, Baris: []string{line}})
+	}
+
+	for _, e := range editScript(x, y) {
+		switch e.op {
+		case OpEqual:
+			push(OpEqual, x[e.i])
+		case OpDelete:
+			push(OpDelete, x[e.i])
+		case OpInsert:
+			push(OpInsert, y[e.j])
+		}
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type edit struct {
+	op   string
+	i, j int
+}
+
+// editScript mengimplementasikan algoritma Myers O((N+M)D) dengan backtracking
+func editScript(x []string, y []string) []edit {
+	n, m := len(x), len(y)
+	max := n + m
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Telusuri balik jejak untuk menyusun edit dari akhir ke awal
+	var edits []edit
+	i, j := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := v[offset+prevK]
+		prevJ := prevI - prevK
+
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			edits = append(edits, edit{op: OpEqual, i: i, j: j})
+		}
+		if d > 0 {
+			if i == prevI {
+				j--
+				edits = append(edits, edit{op: OpInsert, i: i, j: j})
+			} else {
+				i--
+				edits = append(edits, edit{op: OpDelete, i: i, j: j})
+			}
+		}
+	}
+
+	for l, r := 0, len(edits)-1; l < r; l, r = l+1, r-1 {
+		edits[l], edits[r] = edits[r], edits[l]
+	}
+	return edits
+}
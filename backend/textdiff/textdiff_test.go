@@ -0,0 +1,91 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{"keduanya kosong", "", "", nil},
+		{"teks baru", "", "a\nb\n", []Op{{OpInsert, []string{"a", "b"}}}},
+		{"teks dihapus", "a\nb", "", []Op{{OpDelete, []string{"a", "b"}}}},
+		{"sama persis", "a\nb\n", "a\nb", []Op{{OpEqual, []string{"a", "b"}}}},
+		{
+			"satu baris diganti", "a\nb\nc", "a\nx\nc",
+			[]Op{{OpEqual, []string{"a"}}, {OpDelete, []string{"b"}}, {OpInsert, []string{"x"}}, {OpEqual, []string{"c"}}},
+		},
+		{
+			"baris disisipkan di tengah", "a\nc", "a\nb\nc",
+			[]Op{{OpEqual, []string{"a"}}, {OpInsert, []string{"b"}}, {OpEqual, []string{"c"}}},
+		},
+		{
+			"baris terakhir dihapus", "a\nb\nc", "a\nb",
+			[]Op{{OpEqual, []string{"a", "b"}}, {OpDelete, []string{"c"}}},
+		},
+	}
+	for _, tt := range tests {
+		if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Lines = %+v, ingin %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Menerapkan hasil diff ke teks lama harus menghasilkan teks baru, dan diff harus minimal
+func TestLinesReconstructs(t *testing.T) {
+	pairs := [][2]string{
+		{"a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc"},
+		{"Bab 1\nPendahuluan\nLatar belakang\nTujuan", "Bab 1\nLatar belakang\nRumusan masalah\nTujuan\nManfaat"},
+		{"x\ny\nz", "p\nq\nr"},
+	}
+	for _, p := range pairs {
+		ops := Lines(p[0], p[1])
+		var oldLines, newLines []string
+		changed := 0
+		for _, op := range ops {
+			switch op.Op {
+			case OpEqual:
+				oldLines = append(oldLines, op.Baris...)
+				newLines = append(newLines, op.Baris...)
+			case OpDelete:
+				oldLines = append(oldLines, op.Baris...)
+				changed += len(op.Baris)
+			case OpInsert:
+				newLines = append(newLines, op.Baris...)
+				changed += len(op.Baris)
+			}
+		}
+		if got := strings.Join(oldLines, "\n"); got != p[0] {
+			t.Errorf("sisi lama = %q, ingin %q", got, p[0])
+		}
+		if got := strings.Join(newLines, "\n"); got != p[1] {
+			t.Errorf("sisi baru = %q, ingin %q", got, p[1])
+		}
+		if want := len(splitLines(p[0])) + len(splitLines(p[1])) - 2*lcs(splitLines(p[0]), splitLines(p[1])); changed != want {
+			t.Errorf("%q -> %q: %d baris berubah, ingin minimal %d", p[0], p[1], changed, want)
+		}
+	}
+}
+
+// lcs menghitung panjang subbarisan bersama terpanjang sebagai pembanding
+func lcs(x, y []string) int {
+	dp := make([][]int, len(x)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(y)+1)
+	}
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			if x[i-1] == y[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else {
+				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
+			}
+		}
+	}
+	return dp[len(x)][len(y)]
+}
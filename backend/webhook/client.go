@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress dikembalikan saat URL langganan mengarah ke jaringan internal
+var ErrBlockedAddress = errors.New("alamat tujuan webhook tidak diizinkan")
+
+// Rentang yang tidak tercakup oleh pemeriksaan netip: jaringan "this host" dan CGNAT (dipakai
+// sebagian penyedia cloud untuk layanan metadata)
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// NewClient membuat klien HTTP untuk pengiriman webhook. URL langganan ditentukan pengelola
+// kelas, sehingga koneksi ke alamat loopback, privat, link-local (termasuk layanan metadata
+// cloud) dan unspecified ditolak setelah DNS di-resolve, dan redirect tidak diikuti.
+// allowPrivate mematikan pemeriksaan alamat untuk pengembangan lokal.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: RequestTimeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network string, address string, _ syscall.RawConn) error {
+			return checkAddress(address)
+		}
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // Proxy akan membuat pemeriksaan alamat tujuan tidak berlaku
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          20,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   RequestTimeout,
+		ResponseHeaderTimeout: RequestTimeout,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   RequestTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			// Respons 3xx dicatat apa adanya sebagai pengiriman yang gagal
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkAddress memeriksa alamat host:port yang akan dihubungi setelah DNS di-resolve
+func checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !AllowedAddr(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
+
+// AllowedAddr melaporkan apakah ip boleh dihubungi oleh pengiriman webhook
+func AllowedAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+// Package webhook mengirim peristiwa penilaian ke URL langganan pihak ketiga (bot obrolan,
+// sistem rapor sekolah). Setiap payload ditandatangani HMAC-SHA256 memakai secret langganan;
+// pengiriman yang gagal dicoba ulang dengan jeda yang makin panjang.
+//
+// Penerima memverifikasi header X-SAGE-Signature dengan menghitung
+// HMAC-SHA256(secret, X-SAGE-Timestamp + "." + body) dan membandingkannya dengan nilai v1,
+// serta menolak timestamp yang terlalu lama untuk mencegah replay.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mrand "math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Jenis peristiwa yang dapat dilanggan
+const (
+	EventSubmissionCreated = "submission.created"
+	EventGradingCompleted  = "grading.completed"
+	EventGradingFailed     = "grading.failed"
+	EventReviewPublished   = "review.published"
+	EventPing              = "ping" // Dikirim saat langganan diuji; selalu diterima
+)
+
+// Events adalah daftar peristiwa yang dapat dipilih pada langganan
+var Events = []string{EventSubmissionCreated, EventGradingCompleted, EventGradingFailed, EventReviewPublished}
+
+// Header yang dikirim pada setiap pengiriman
+const (
+	HeaderEvent     = "X-SAGE-Event"
+	HeaderDelivery  = "X-SAGE-Delivery"
+	HeaderTimestamp = "X-SAGE-Timestamp"
+	HeaderSignature = "X-SAGE-Signature"
+)
+
+const (
+	MaxAttempts     = 8
+	RequestTimeout  = 10 * time.Second
+	baseBackoff     = 30 * time.Second
+	maxBackoff      = 6 * time.Hour
+	maxResponseBody = 2048 // Potongan respons yang disimpan di log pengiriman
+)
+
+var ErrInvalidSignature = errors.New("tanda tangan webhook tidak valid")
+
+// Payload adalah isi JSON yang dikirim ke penerima. ID peristiwa sama untuk semua
+// langganan dan pengiriman ulang, sehingga penerima dapat mengabaikan duplikat.
+type Payload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	KelasID   string    `json:"kelas_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// NewPayload membuat payload peristiwa baru dengan ID acak
+func NewPayload(event string, classID string, data any, now time.Time) (*Payload, error) {
+	id, err := RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	return &Payload{ID: id, Event: event, KelasID: classID, CreatedAt: now.UTC(), Data: data}, nil
+}
+
+// IsEvent melaporkan apakah nama peristiwa dapat dilanggan
+func IsEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
+// NewSecret membuat secret penandatanganan untuk langganan baru
+func NewSecret() (string, error) {
+	s, err := RandomHex(24)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + s, nil
+}
+
+func RandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign menghasilkan nilai header X-SAGE-Signature untuk body pada timestamp tertentu
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "t=" + strconv.FormatInt(timestamp, 10) + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa header tanda tangan dari sisi penerima. Timestamp yang berselisih lebih
+// dari tolerance terhadap now ditolak.
+func Verify(secret string, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp, _ = strconv.ParseInt(value, 10, 64)
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if math.Abs(now.Sub(time.Unix(timestamp, 0)).Seconds()) > tolerance.Seconds() {
+		return fmt.Errorf("%w: timestamp di luar toleransi", ErrInvalidSignature)
+	}
+	_, expected, _ := strings.Cut(Sign(secret, timestamp, body), ",v1=")
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Backoff mengembalikan jeda sebelum percobaan berikutnya setelah attempt percobaan gagal:
+// 30 detik, 1 menit, 2 menit, ... hingga maksimal 6 jam, dengan jitter ±20%
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := baseBackoff << min(attempt-1, 20)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	jitter := 0.8 + 0.4*mrand.Float64()
+	return time.Duration(float64(delay) * jitter)
+}
+
+// Request adalah satu pengiriman ke URL langganan
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID string
+	Event      string
+	Body       []byte
+}
+
+// Result adalah hasil satu percobaan pengiriman
+type Result struct {
+	StatusCode int
+	Response   string
+	Duration   time.Duration
+	Err        error
+}
+
+// Success melaporkan apakah penerima menerima pengiriman (status 2xx)
+func (r Result) Success() bool {
+	return r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Retryable melaporkan apakah kegagalan layak dicoba ulang. Status 4xx selain 408 dan 429
+// berarti penerima menolak payload sehingga percobaan ulang tidak akan berhasil.
+func (r Result) Retryable() bool {
+	if r.Err != nil {
+		return true
+	}
+	return r.StatusCode >= 500 || r.StatusCode == http.StatusRequestTimeout || r.StatusCode == http.StatusTooManyRequests
+}
+
+// Deliver mengirim body ke URL langganan dengan header tanda tangan
+func Deliver(ctx context.Context, client *http.Client, req Request, now time.Time) Result {
+	started := time.Now()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Result{Err: err}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "SAGE-Webhook/1.0")
+	httpReq.Header.Set(HeaderEvent, req.Event)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, now.Unix(), req.Body))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Result{Duration: time.Since(started), Err: err}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	return Result{StatusCode: resp.StatusCode, Response: string(body), Duration: time.Since(started)}
+}
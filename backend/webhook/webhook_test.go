@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"grading.completed"}`)
+	header := Sign("whsec_rahasia", now.Unix(), body)
+
+	if err := Verify("whsec_rahasia", header, body, 5*time.Minute, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Verify tanda tangan valid: %v", err)
+	}
+	// Penerima boleh menerima beberapa tanda tangan saat secret dirotasi
+	rotated := Sign("whsec_lama", now.Unix(), body) + ",v1=" + header[len("t=1700000000,v1="):]
+	if err := Verify("whsec_rahasia", rotated, body, 5*time.Minute, now); err != nil {
+		t.Errorf("Verify dengan beberapa v1: %v", err)
+	}
+
+	tests := map[string]struct {
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		"secret salah":      {"whsec_lain", header, body, now},
+		"body diubah":       {"whsec_rahasia", header, []byte(`{"event":"ping"}`), now},
+		"timestamp lama":    {"whsec_rahasia", header, body, now.Add(10 * time.Minute)},
+		"timestamp depan":   {"whsec_rahasia", header, body, now.Add(-10 * time.Minute)},
+		"tanpa v1":          {"whsec_rahasia", "t=1700000000", body, now},
+		"header kosong":     {"whsec_rahasia", "", body, now},
+		"timestamp diganti": {"whsec_rahasia", "t=1700000001," + header[len("t=1700000000,"):], body, now},
+	}
+	for name, tt := range tests {
+		if err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, ingin ErrInvalidSignature", name, err)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{MaxAttempts, 64 * time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := Backoff(tt.attempt)
+			lo, hi := time.Duration(float64(tt.base)*0.8), time.Duration(float64(tt.base)*1.2)
+			if got < lo || got > hi {
+				t.Fatalf("Backoff(%d) = %s, ingin antara %s dan %s", tt.attempt, got, lo, hi)
+			}
+		}
+	}
+}
+
+func TestResultRetryable(t *testing.T) {
+	tests := []struct {
+		result    Result
+		success   bool
+		retryable bool
+	}{
+		{Result{StatusCode: 204}, true, false},
+		{Result{StatusCode: 302}, false, false},
+		{Result{StatusCode: 400}, false, false},
+		{Result{StatusCode: 408}, false, true},
+		{Result{StatusCode: 429}, false, true},
+		{Result{StatusCode: 503}, false, true},
+		{Result{Err: errors.New("koneksi ditolak")}, false, true},
+	}
+	for _, tt := range tests {
+		if tt.result.Success() != tt.success || tt.result.Retryable() != tt.retryable {
+			t.Errorf("%+v: Success %v, Retryable %v; ingin %v, %v", tt.result, tt.result.Success(), tt.result.Retryable(), tt.success, tt.retryable)
+		}
+	}
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"abc"}`)
+	var verifyErr error
+	var event string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := io.ReadAll(r.Body)
+		verifyErr = Verify("whsec_rahasia", r.Header.Get(HeaderSignature), got, time.Minute, now)
+		event = r.Header.Get(HeaderEvent)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("diterima"))
+	}))
+	defer srv.Close()
+
+	result := Deliver(context.Background(), NewClient(true), Request{URL: srv.URL, Secret: "whsec_rahasia", DeliveryID: "d1", Event: EventGradingCompleted, Body: body}, now)
+	if !result.Success() || result.Response != "diterima" {
+		t.Fatalf("Deliver = %+v, ingin berhasil", result)
+	}
+	if verifyErr != nil {
+		t.Errorf("tanda tangan yang diterima tidak valid: %v", verifyErr)
+	}
+	if event != EventGradingCompleted {
+		t.Errorf("header event = %q, ingin %q", event, EventGradingCompleted)
+	}
+}
+
+func TestNewClientBlocksPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/lain", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	result := Deliver(context.Background(), NewClient(false), Request{URL: srv.URL}, time.Now())
+	if !errors.Is(result.Err, ErrBlockedAddress) {
+		t.Errorf("pengiriman ke loopback: err = %v, ingin ErrBlockedAddress", result.Err)
+	}
+
+	// Redirect tidak diikuti dan dicatat sebagai kegagalan
+	result = Deliver(context.Background(), NewClient(true), Request{URL: srv.URL}, time.Now())
+	if result.Err != nil || result.StatusCode != http.StatusFound || result.Success() {
+		t.Errorf("redirect = %+v, ingin status 302 yang gagal", result)
+	}
+}
+
+func TestAllowedAddr(t *testing.T) {
+	tests := map[string]bool{
+		"203.0.113.10":     true,
+		"8.8.8.8":          true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"0.0.0.0":          false,
+		"0.1.2.3":          false,
+		"100.100.100.200":  false,
+		"224.0.0.1":        false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	}
+	for addr, want := range tests {
+		if got := AllowedAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("AllowedAddr(%s) = %v, ingin %v", addr, got, want)
+		}
+	}
+}
+
+func TestNewPayloadAndSecret(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	p, err := NewPayload(EventReviewPublished, "k1", map[string]string{"a": "b"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.ID) != 32 || p.CreatedAt.Location() != time.UTC {
+		t.Errorf("payload = %+v, ingin ID 32 karakter heksadesimal dan waktu UTC", p)
+	}
+	secret, err := NewSecret()
+	if err != nil || len(secret) != len("whsec_")+48 {
+		t.Errorf("NewSecret = %q (%v)", secret, err)
+	}
+	if !IsEvent(EventGradingFailed) || IsEvent(EventPing) {
+		t.Error("ping tidak dapat dilanggan, grading.failed dapat dilanggan")
+	}
+}